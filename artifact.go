@@ -0,0 +1,110 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/spec"
+)
+
+// ConvertArtifactToImageManifest converts the artifact manifest described by
+// desc in the storage, either an ORAS artifact manifest or an OCI artifact
+// manifest, to the equivalent OCI image-spec v1.1 image manifest, and pushes
+// it to the storage. Returns the descriptor of the image manifest.
+// The generated image manifest carries the artifact type and the subject of
+// the artifact, references the blobs of the artifact as its layers, and uses
+// the well-known empty JSON blob as its config, as well as its only layer if
+// the artifact has no blob. The annotations of the artifact are preserved,
+// where the creation time in AnnotationArtifactCreated is copied to
+// ocispec.AnnotationCreated if the latter is absent.
+// It is useful for migrating the content off the deprecated artifact
+// manifests. The artifact manifest is neither deleted nor untagged.
+// Returns errdef.ErrUnsupported if desc does not describe an artifact manifest.
+func ConvertArtifactToImageManifest(ctx context.Context, storage content.Storage, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	if desc.MediaType != artifactspec.MediaTypeArtifactManifest && desc.MediaType != ocispec.MediaTypeArtifactManifest {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: not an artifact manifest: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+	artifactJSON, err := fetchManifest(ctx, storage, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	// the ORAS artifact manifest and the OCI artifact manifest share the same
+	// structure
+	var artifact artifactspec.Manifest
+	if err := json.Unmarshal(artifactJSON, &artifact); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: failed to decode artifact manifest: %w", desc.Digest, desc.MediaType, err)
+	}
+
+	if err := content.PushEmptyJSON(ctx, storage); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push config: %w", err)
+	}
+	layers := make([]ocispec.Descriptor, 0, len(artifact.Blobs))
+	for _, blob := range artifact.Blobs {
+		layers = append(layers, descriptor.ArtifactToOCI(blob))
+	}
+	if len(layers) == 0 {
+		layers = append(layers, content.DescriptorEmptyJSON)
+	}
+	var subject *ocispec.Descriptor
+	if artifact.Subject != nil {
+		desc := descriptor.ArtifactToOCI(*artifact.Subject)
+		subject = &desc
+	}
+	annotations := artifact.Annotations
+	if created, ok := annotations[artifactspec.AnnotationArtifactCreated]; ok {
+		if _, ok := annotations[ocispec.AnnotationCreated]; !ok {
+			annotations = make(map[string]string, len(artifact.Annotations)+1)
+			for k, v := range artifact.Annotations {
+				annotations[k] = v
+			}
+			annotations[ocispec.AnnotationCreated] = created
+		}
+	}
+
+	manifest := spec.Manifest{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2, // historical value. does not pertain to OCI or docker version
+		},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifact.ArtifactType,
+		Config:       content.DescriptorEmptyJSON,
+		Layers:       layers,
+		Subject:      subject,
+		Annotations:  annotations,
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+	manifestDesc.ArtifactType = manifest.ArtifactType
+	manifestDesc.Annotations = manifest.Annotations
+	if err := storage.Push(ctx, manifestDesc, bytes.NewReader(manifestJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push manifest: %w", err)
+	}
+	return manifestDesc, nil
+}
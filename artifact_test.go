@@ -0,0 +1,165 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestConvertArtifactToImageManifest(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	subjectDesc := push(ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2}`))
+	blobDesc := push("application/vnd.test", []byte("hello"))
+	artifact := artifactspec.Manifest{
+		MediaType:    artifactspec.MediaTypeArtifactManifest,
+		ArtifactType: "application/vnd.test.artifact",
+		Blobs: []artifactspec.Descriptor{{
+			MediaType: blobDesc.MediaType,
+			Digest:    blobDesc.Digest,
+			Size:      blobDesc.Size,
+		}},
+		Subject: &artifactspec.Descriptor{
+			MediaType: subjectDesc.MediaType,
+			Digest:    subjectDesc.Digest,
+			Size:      subjectDesc.Size,
+		},
+		Annotations: map[string]string{
+			artifactspec.AnnotationArtifactCreated: "2000-01-01T00:00:00Z",
+			"foo":                                  "bar",
+		},
+	}
+	artifactJSON, err := json.Marshal(artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifactDesc := push(artifactspec.MediaTypeArtifactManifest, artifactJSON)
+
+	got, err := oras.ConvertArtifactToImageManifest(ctx, s, artifactDesc)
+	if err != nil {
+		t.Fatalf("ConvertArtifactToImageManifest() error = %v", err)
+	}
+	if got.MediaType != ocispec.MediaTypeImageManifest {
+		t.Errorf("ConvertArtifactToImageManifest() media type = %v, want %v", got.MediaType, ocispec.MediaTypeImageManifest)
+	}
+	manifestJSON, err := content.FetchAll(ctx, s, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest struct {
+		ocispec.Manifest
+		ArtifactType string              `json:"artifactType"`
+		Subject      *ocispec.Descriptor `json:"subject"`
+	}
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.ArtifactType != artifact.ArtifactType {
+		t.Errorf("artifactType = %v, want %v", manifest.ArtifactType, artifact.ArtifactType)
+	}
+	if !reflect.DeepEqual(manifest.Config, content.DescriptorEmptyJSON) {
+		t.Errorf("config = %v, want %v", manifest.Config, content.DescriptorEmptyJSON)
+	}
+	if want := []ocispec.Descriptor{blobDesc}; !reflect.DeepEqual(manifest.Layers, want) {
+		t.Errorf("layers = %v, want %v", manifest.Layers, want)
+	}
+	if manifest.Subject == nil || !reflect.DeepEqual(*manifest.Subject, subjectDesc) {
+		t.Errorf("subject = %v, want %v", manifest.Subject, subjectDesc)
+	}
+	wantAnnotations := map[string]string{
+		artifactspec.AnnotationArtifactCreated: "2000-01-01T00:00:00Z",
+		ocispec.AnnotationCreated:              "2000-01-01T00:00:00Z",
+		"foo":                                  "bar",
+	}
+	if !reflect.DeepEqual(manifest.Annotations, wantAnnotations) {
+		t.Errorf("annotations = %v, want %v", manifest.Annotations, wantAnnotations)
+	}
+	exists, err := s.Exists(ctx, content.DescriptorEmptyJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Error("empty JSON config is not pushed")
+	}
+
+	// converting again should be idempotent
+	again, err := oras.ConvertArtifactToImageManifest(ctx, s, artifactDesc)
+	if err != nil {
+		t.Fatalf("ConvertArtifactToImageManifest() error = %v", err)
+	}
+	if again.Digest != got.Digest {
+		t.Errorf("ConvertArtifactToImageManifest() digest = %v, want %v", again.Digest, got.Digest)
+	}
+}
+
+func TestConvertArtifactToImageManifest_NoBlob(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	artifactJSON := []byte(`{"mediaType":"` + ocispec.MediaTypeArtifactManifest + `","artifactType":"application/vnd.test"}`)
+	artifactDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeArtifactManifest, artifactJSON)
+	if err := s.Push(ctx, artifactDesc, bytes.NewReader(artifactJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := oras.ConvertArtifactToImageManifest(ctx, s, artifactDesc)
+	if err != nil {
+		t.Fatalf("ConvertArtifactToImageManifest() error = %v", err)
+	}
+	manifestJSON, err := content.FetchAll(ctx, s, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if want := []ocispec.Descriptor{content.DescriptorEmptyJSON}; !reflect.DeepEqual(manifest.Layers, want) {
+		t.Errorf("layers = %v, want %v", manifest.Layers, want)
+	}
+	if got.ArtifactType != "application/vnd.test" {
+		t.Errorf("artifact type = %v, want %v", got.ArtifactType, "application/vnd.test")
+	}
+}
+
+func TestConvertArtifactToImageManifest_Unsupported(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte(`{}`))
+	_, err := oras.ConvertArtifactToImageManifest(ctx, s, desc)
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("ConvertArtifactToImageManifest() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
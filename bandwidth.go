@@ -0,0 +1,159 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// maxThrottledReadBytes is the maximum number of bytes read at once from a
+// throttled stream, so that the concurrent copies share the bandwidth fairly.
+const maxThrottledReadBytes = 32 * 1024 // 32 KiB
+
+// bandwidthLimiter is a token bucket limiting the bytes transferred per
+// second, shared by the concurrent copies.
+// A nil *bandwidthLimiter does not limit the transfer.
+type bandwidthLimiter struct {
+	lock   sync.Mutex
+	rate   float64 // bytes per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newBandwidthLimiter returns a limiter of bytesPerSecond, or nil if
+// bytesPerSecond is not positive.
+// The bucket holds the bytes of one second at most.
+func newBandwidthLimiter(bytesPerSecond int64) *bandwidthLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &bandwidthLimiter{
+		rate:   float64(bytesPerSecond),
+		burst:  float64(bytesPerSecond),
+		tokens: float64(bytesPerSecond),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until n bytes are permitted, or the context is done.
+func (l *bandwidthLimiter) wait(ctx context.Context, n int) error {
+	l.lock.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.lock.Unlock()
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.refund(n)
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// refund returns n unused bytes to the bucket.
+func (l *bandwidthLimiter) refund(n int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.tokens += float64(n)
+}
+
+// fetcher returns a fetcher of which the fetched content is read at the
+// limited rate.
+func (l *bandwidthLimiter) fetcher(f content.Fetcher) content.Fetcher {
+	if l == nil {
+		return f
+	}
+	return &throttledFetcher{
+		Fetcher: f,
+		limiter: l,
+	}
+}
+
+// throttledFetcher throttles the reads of the fetched content.
+type throttledFetcher struct {
+	content.Fetcher
+	limiter *bandwidthLimiter
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (f *throttledFetcher) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := f.Fetcher.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: &throttledReader{
+			ctx:     ctx,
+			r:       rc,
+			limiter: f.limiter,
+		},
+		Closer: rc,
+	}, nil
+}
+
+// throttledReader reads at the rate permitted by the limiter.
+type throttledReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *bandwidthLimiter
+}
+
+// Read reads up to len(p) bytes into p, waiting for the limiter.
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return r.r.Read(p)
+	}
+	size := len(p)
+	if size > maxThrottledReadBytes {
+		size = maxThrottledReadBytes
+	}
+	if burst := int(r.limiter.burst); size > burst {
+		size = burst
+	}
+	if err := r.limiter.wait(r.ctx, size); err != nil {
+		return 0, err
+	}
+	n, err := r.r.Read(p[:size])
+	if n < size {
+		r.limiter.refund(size - n)
+	}
+	return n, err
+}
@@ -0,0 +1,42 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// baseBlobs returns the digests of the blobs referenced by the base manifest
+// in dst, which are known to exist in dst.
+func baseBlobs(ctx context.Context, dst content.ReadOnlyStorage, base ocispec.Descriptor) (map[digest.Digest]bool, error) {
+	successors, err := content.Successors(ctx, dst, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the blobs of the base %s: %w", base.Digest, err)
+	}
+	blobs := make(map[digest.Digest]bool, len(successors))
+	for _, successor := range successors {
+		if !descriptor.IsManifest(successor) {
+			blobs[successor.Digest] = true
+		}
+	}
+	return blobs, nil
+}
@@ -18,6 +18,7 @@ package oras
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -50,6 +51,10 @@ const (
 
 	// defaultMaxBytes is the default value of FetchBytesOptions.MaxBytes.
 	defaultMaxBytes int64 = 4 * 1024 * 1024 // 4 MiB
+
+	// defaultMaxConfigBytes is the default value of
+	// FetchImageOptions.MaxConfigBytes.
+	defaultMaxConfigBytes int64 = 4 * 1024 * 1024 // 4 MiB
 )
 
 // DefaultTagNOptions provides the default TagNOptions.
@@ -342,6 +347,63 @@ func FetchBytes(ctx context.Context, target ReadOnlyTarget, reference string, op
 	return desc, bytes, nil
 }
 
+// DefaultFetchImageOptions provides the default FetchImageOptions.
+var DefaultFetchImageOptions FetchImageOptions
+
+// FetchImageOptions contains parameters for oras.FetchImage.
+type FetchImageOptions struct {
+	// FetchBytesOptions contains parameters for fetching the manifest.
+	// TargetPlatform can be specified to select the image from an index.
+	FetchBytesOptions
+	// MaxConfigBytes limits the maximum size of the fetched config bytes.
+	// If less than or equal to 0, a default (currently 4 MiB) is used.
+	MaxConfigBytes int64
+}
+
+// FetchImage fetches the image manifest identified by the reference, and the
+// config bytes of the image. Both OCI and docker image manifests are
+// supported.
+// Returns errdef.ErrUnsupported if the reference points to an index or a
+// docker manifest list, where opts.TargetPlatform should be specified to
+// select the image of a platform first.
+func FetchImage(ctx context.Context, target ReadOnlyTarget, reference string, opts FetchImageOptions) (ocispec.Manifest, []byte, error) {
+	if opts.MaxConfigBytes <= 0 {
+		opts.MaxConfigBytes = defaultMaxConfigBytes
+	}
+
+	desc, manifestBytes, err := FetchBytes(ctx, target, reference, opts.FetchBytesOptions)
+	if err != nil {
+		return ocispec.Manifest{}, nil, err
+	}
+	switch desc.MediaType {
+	case docker.MediaTypeManifest, ocispec.MediaTypeImageManifest:
+	case docker.MediaTypeManifestList, ocispec.MediaTypeImageIndex:
+		return ocispec.Manifest{}, nil, fmt.Errorf("%s: %s: %s is a multi-manifest index, specify a target platform to select an image: %w",
+			desc.Digest, desc.MediaType, reference, errdef.ErrUnsupported)
+	default:
+		return ocispec.Manifest{}, nil, fmt.Errorf("%s: %s: not an image manifest: %w",
+			desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return ocispec.Manifest{}, nil, fmt.Errorf("%s: %s: failed to decode manifest: %w", desc.Digest, desc.MediaType, err)
+	}
+
+	configDesc := manifest.Config
+	if configDesc.Size > opts.MaxConfigBytes {
+		return ocispec.Manifest{}, nil, fmt.Errorf(
+			"config size %v exceeds MaxConfigBytes %v: %w",
+			configDesc.Size,
+			opts.MaxConfigBytes,
+			errdef.ErrSizeExceedsLimit)
+	}
+	configBytes, err := content.FetchAll(ctx, target, configDesc)
+	if err != nil {
+		return ocispec.Manifest{}, nil, err
+	}
+	return manifest, configBytes, nil
+}
+
 // PushBytes describes the contentBytes using the given mediaType and pushes it.
 // If mediaType is not specified, "application/octet-stream" is used.
 func PushBytes(ctx context.Context, pusher content.Pusher, mediaType string, contentBytes []byte) (ocispec.Descriptor, error) {
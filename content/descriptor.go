@@ -16,13 +16,59 @@ limitations under the License.
 package content
 
 import (
+	"io"
+
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/spec"
 )
 
 // defaultMediaType is the media type used when no media type is specified.
 const defaultMediaType string = "application/octet-stream"
 
+var (
+	// EmptyJSON is the content of the empty JSON blob.
+	EmptyJSON = []byte("{}")
+	// DescriptorEmptyJSON is the descriptor of the empty JSON blob, which is
+	// used as the config of artifacts packed as OCI image manifests.
+	// Reference: https://github.com/opencontainers/image-spec/blob/main/manifest.md#guidance-for-an-empty-descriptor
+	DescriptorEmptyJSON = ocispec.Descriptor{
+		MediaType: spec.MediaTypeEmptyJSON,
+		Digest:    "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a",
+		Size:      2,
+	}
+
+	// EmptyGzipLayer is the content of the well-known empty layer, which is a
+	// gzipped empty tar archive, i.e. 1024 zero bytes, as generated by docker
+	// for the instructions not changing the file system.
+	EmptyGzipLayer = []byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x00, 0xff, 0x62, 0x18, 0x05, 0xa3, 0x60, 0x14,
+		0x8c, 0x58, 0x00, 0x08, 0x00, 0x00, 0xff, 0xff, 0x2e, 0xaf, 0xb5, 0xef, 0x00, 0x04, 0x00, 0x00,
+	}
+	// DescriptorEmptyGzipLayer is the descriptor of the empty layer.
+	DescriptorEmptyGzipLayer = ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayerGzip,
+		Digest:    "sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4",
+		Size:      32,
+	}
+)
+
+// IsEmptyJSON returns true if desc describes the empty JSON blob, i.e. its
+// digest and size match DescriptorEmptyJSON, regardless of its media type.
+// The empty JSON blob is commonly shared by artifacts as their config, which
+// allows the callers to special-case it, e.g. to skip its existence probe.
+func IsEmptyJSON(desc ocispec.Descriptor) bool {
+	return desc.Digest == DescriptorEmptyJSON.Digest && desc.Size == DescriptorEmptyJSON.Size
+}
+
+// IsEmptyGzipLayer returns true if desc describes the empty layer, i.e. its
+// digest and size match DescriptorEmptyGzipLayer, regardless of its media
+// type.
+func IsEmptyGzipLayer(desc ocispec.Descriptor) bool {
+	return desc.Digest == DescriptorEmptyGzipLayer.Digest && desc.Size == DescriptorEmptyGzipLayer.Size
+}
+
 // NewDescriptorFromBytes returns a descriptor, given the content and media type.
 // If no media type is specified, "application/octet-stream" will be used.
 func NewDescriptorFromBytes(mediaType string, content []byte) ocispec.Descriptor {
@@ -36,7 +82,54 @@ func NewDescriptorFromBytes(mediaType string, content []byte) ocispec.Descriptor
 	}
 }
 
+// NewDescriptorFromBytesWithAlgorithm returns a descriptor, given the content
+// and media type, where the digest is calculated using alg.
+// If no media type is specified, "application/octet-stream" will be used.
+// If no algorithm is specified, digest.Canonical will be used.
+// The hash function of alg must be available, e.g. by importing
+// "crypto/sha512" for digest.SHA512.
+func NewDescriptorFromBytesWithAlgorithm(mediaType string, content []byte, alg digest.Algorithm) ocispec.Descriptor {
+	if mediaType == "" {
+		mediaType = defaultMediaType
+	}
+	if alg == "" {
+		alg = digest.Canonical
+	}
+	return ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    alg.FromBytes(content),
+		Size:      int64(len(content)),
+	}
+}
+
+// NewDescriptorFromReader reads r to the end, and returns the descriptor of the
+// content read, given the media type, as well as the content.
+// If no media type is specified, "application/octet-stream" will be used.
+// See also PushFromReader() for ingesting large content without holding it in
+// the memory.
+func NewDescriptorFromReader(mediaType string, r io.Reader) (ocispec.Descriptor, []byte, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	return NewDescriptorFromBytes(mediaType, content), content, nil
+}
+
 // Equal returns true if two descriptors point to the same content.
+// Only the media type, the digest, and the size are compared. Other fields
+// such as annotations and URLs are ignored.
 func Equal(a, b ocispec.Descriptor) bool {
 	return a.Size == b.Size && a.Digest == b.Digest && a.MediaType == b.MediaType
 }
+
+// DescriptorKey is a comparable key of a descriptor, consisting of the media
+// type, the digest, and the size of the descriptor.
+// It is the key used by oras for deduplicating descriptors.
+type DescriptorKey = descriptor.Descriptor
+
+// Key returns the comparable key of the descriptor, which can be used as a
+// map key.
+// Key(a) == Key(b) if and only if Equal(a, b) returns true.
+func Key(desc ocispec.Descriptor) DescriptorKey {
+	return descriptor.FromOCI(desc)
+}
@@ -16,6 +16,8 @@ limitations under the License.
 package content
 
 import (
+	"bytes"
+	_ "crypto/sha512"
 	"reflect"
 	"testing"
 
@@ -71,6 +73,43 @@ func TestGenerateDescriptor(t *testing.T) {
 	}
 }
 
+func TestNewDescriptorFromBytesWithAlgorithm(t *testing.T) {
+	content := []byte("foo")
+	tests := []struct {
+		name string
+		alg  digest.Algorithm
+		want digest.Digest
+	}{
+		{
+			name: "default algorithm",
+			want: digest.FromBytes(content),
+		},
+		{
+			name: "sha256",
+			alg:  digest.SHA256,
+			want: digest.FromBytes(content),
+		},
+		{
+			name: "sha512",
+			alg:  digest.SHA512,
+			want: digest.SHA512.FromBytes(content),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewDescriptorFromBytesWithAlgorithm("", content, tt.alg)
+			want := ocispec.Descriptor{
+				MediaType: defaultMediaType,
+				Digest:    tt.want,
+				Size:      int64(len(content)),
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("NewDescriptorFromBytesWithAlgorithm() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
 func TestEqual(t *testing.T) {
 	contentFoo := []byte("foo")
 	contentBar := []byte("bar")
@@ -155,12 +194,88 @@ func TestEqual(t *testing.T) {
 				ocispec.Descriptor{}},
 			want: true,
 		},
+		{
+			name: "different annotations and URLs",
+			args: args{
+				ocispec.Descriptor{
+					MediaType:   "example media type",
+					Digest:      digest.FromBytes(contentFoo),
+					Size:        int64(len(contentFoo)),
+					Annotations: map[string]string{"foo": "bar"}},
+				ocispec.Descriptor{
+					MediaType: "example media type",
+					Digest:    digest.FromBytes(contentFoo),
+					Size:      int64(len(contentFoo)),
+					URLs:      []string{"https://example.com"}}},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if got := Equal(tt.args.a, tt.args.b); got != tt.want {
 				t.Errorf("Equal() = %v, want %v", got, tt.want)
 			}
+			if got := Key(tt.args.a) == Key(tt.args.b); got != tt.want {
+				t.Errorf("Key(a) == Key(b) = %v, want %v", got, tt.want)
+			}
 		})
 	}
 }
+
+func TestNewDescriptorFromReader(t *testing.T) {
+	content := []byte("foo")
+	desc, got, err := NewDescriptorFromReader("test", bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("NewDescriptorFromReader() error = %v", err)
+	}
+	if want := NewDescriptorFromBytes("test", content); !reflect.DeepEqual(desc, want) {
+		t.Errorf("NewDescriptorFromReader() desc = %v, want %v", desc, want)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("NewDescriptorFromReader() content = %v, want %v", got, content)
+	}
+}
+
+func TestIsEmptyJSON(t *testing.T) {
+	if got, want := DescriptorEmptyJSON.Digest, digest.FromBytes(EmptyJSON); got != want {
+		t.Fatalf("DescriptorEmptyJSON.Digest = %v, want %v", got, want)
+	}
+	if got, want := DescriptorEmptyJSON.Size, int64(len(EmptyJSON)); got != want {
+		t.Fatalf("DescriptorEmptyJSON.Size = %v, want %v", got, want)
+	}
+
+	config := DescriptorEmptyJSON
+	config.MediaType = ocispec.MediaTypeImageConfig
+	if !IsEmptyJSON(config) {
+		t.Errorf("IsEmptyJSON(%v) = false, want true", config)
+	}
+	if IsEmptyJSON(DescriptorEmptyGzipLayer) {
+		t.Errorf("IsEmptyJSON(%v) = true, want false", DescriptorEmptyGzipLayer)
+	}
+	other := NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, []byte("[]"))
+	if IsEmptyJSON(other) {
+		t.Errorf("IsEmptyJSON(%v) = true, want false", other)
+	}
+}
+
+func TestIsEmptyGzipLayer(t *testing.T) {
+	want := digest.Digest("sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4")
+	if got := digest.FromBytes(EmptyGzipLayer); got != want {
+		t.Fatalf("digest of EmptyGzipLayer = %v, want %v", got, want)
+	}
+	if got := DescriptorEmptyGzipLayer.Digest; got != want {
+		t.Fatalf("DescriptorEmptyGzipLayer.Digest = %v, want %v", got, want)
+	}
+	if got, want := DescriptorEmptyGzipLayer.Size, int64(len(EmptyGzipLayer)); got != want {
+		t.Fatalf("DescriptorEmptyGzipLayer.Size = %v, want %v", got, want)
+	}
+
+	layer := DescriptorEmptyGzipLayer
+	layer.MediaType = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+	if !IsEmptyGzipLayer(layer) {
+		t.Errorf("IsEmptyGzipLayer(%v) = false, want true", layer)
+	}
+	if IsEmptyGzipLayer(DescriptorEmptyJSON) {
+		t.Errorf("IsEmptyGzipLayer(%v) = true, want false", DescriptorEmptyJSON)
+	}
+}
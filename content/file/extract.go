@@ -0,0 +1,269 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+const (
+	// whiteoutPrefix is the file name prefix of the overlay whiteouts, which
+	// mark the files of the lower layers as deleted.
+	// Reference: https://github.com/opencontainers/image-spec/blob/main/layer.md#whiteouts
+	whiteoutPrefix = ".wh."
+	// whiteoutOpaqueDir is the file name of the opaque whiteouts, which mark
+	// the directories containing them as replacing the ones of the lower
+	// layers.
+	whiteoutOpaqueDir = whiteoutPrefix + whiteoutPrefix + ".opq"
+)
+
+// ExtractOptions contains parameters for Extract.
+type ExtractOptions struct {
+	// TargetPlatform selects the manifest of the target platform if the
+	// reference resolves to a manifest list or an index.
+	// It is required for manifest lists and indexes.
+	TargetPlatform *ocispec.Platform
+}
+
+// Extract resolves the image manifest referenced by ref in src, and extracts
+// its tar and tar+gzip layers in order to the directory dir, applying the
+// overlay whiteouts of the upper layers to the lower layers.
+// The layers are streamed from src and verified against their descriptors
+// without being stored in temporary files.
+// Entries with absolute paths, or paths traversing outside of dir, are
+// rejected with ErrPathTraversalDisallowed. Symbolic links are created as is,
+// and are never followed on extraction.
+// Returns the descriptor of the extracted manifest.
+func Extract(ctx context.Context, src oras.ReadOnlyTarget, ref string, dir string, opts ExtractOptions) (ocispec.Descriptor, error) {
+	desc, err := oras.Resolve(ctx, src, ref, oras.ResolveOptions{
+		TargetPlatform: opts.TargetPlatform,
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	switch desc.MediaType {
+	case docker.MediaTypeManifest, ocispec.MediaTypeImageManifest:
+	default:
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: not an image manifest: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+
+	manifestJSON, err := content.FetchAll(ctx, src, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	bufPtr := bufPool.Get().(*[]byte)
+	defer bufPool.Put(bufPtr)
+	for _, layer := range manifest.Layers {
+		if err := extractLayer(ctx, src, layer, dir, *bufPtr); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	return desc, nil
+}
+
+// extractLayer fetches the layer and applies it to dir.
+func extractLayer(ctx context.Context, fetcher content.Fetcher, layer ocispec.Descriptor, dir string, buf []byte) (err error) {
+	var compressed bool
+	switch layer.MediaType {
+	case ocispec.MediaTypeImageLayer, ocispec.MediaTypeImageLayerNonDistributable:
+	case ocispec.MediaTypeImageLayerGzip, ocispec.MediaTypeImageLayerNonDistributableGzip,
+		docker.MediaTypeLayer, docker.MediaTypeForeignLayer:
+		compressed = true
+	default:
+		return fmt.Errorf("%s: %s: %w", layer.Digest, layer.MediaType, errdef.ErrUnsupported)
+	}
+
+	rc, err := fetcher.Fetch(ctx, layer)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	vr := content.NewVerifyReader(rc, layer)
+
+	var r io.Reader = vr
+	if compressed {
+		gzr, err := gzip.NewReader(vr)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", layer.Digest, layer.MediaType, err)
+		}
+		defer func() {
+			closeErr := gzr.Close()
+			if err == nil {
+				err = closeErr
+			}
+		}()
+		r = gzr
+	}
+	if err := applyLayer(dir, r, buf); err != nil {
+		return fmt.Errorf("%s: %s: %w", layer.Digest, layer.MediaType, err)
+	}
+
+	// consume the padding of the tar archive before verification
+	if _, err := io.CopyBuffer(io.Discard, vr, buf); err != nil {
+		return err
+	}
+	return vr.Verify()
+}
+
+// applyLayer extracts the tar archive read from r to dir, applying the
+// whiteouts in the archive.
+func applyLayer(dir string, r io.Reader, buf []byte) error {
+	// unpacked records the entries unpacked from the current layer, which are
+	// preserved by the opaque whiteouts.
+	unpacked := make(map[string]struct{})
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		name, err := cleanEntryName(header.Name)
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			continue
+		}
+		// no symbolic link allowed in the parent path
+		if _, err := ensureBasePath(dir, ".", name); err != nil {
+			return err
+		}
+		parent, base := filepath.Split(name)
+		parent = filepath.Clean(parent)
+
+		// apply whiteouts
+		if base == whiteoutOpaqueDir {
+			if err := removeOpaque(dir, parent, unpacked); err != nil {
+				return err
+			}
+			continue
+		}
+		if strings.HasPrefix(base, whiteoutPrefix) {
+			target := filepath.Join(dir, parent, strings.TrimPrefix(base, whiteoutPrefix))
+			if err := os.RemoveAll(target); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// replace the existing entry unless both are directories
+		path := filepath.Join(dir, name)
+		if info, err := os.Lstat(path); err == nil {
+			if !info.IsDir() || header.Typeflag != tar.TypeDir {
+				if err := os.RemoveAll(path); err != nil {
+					return err
+				}
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		// create content
+		mode := header.FileInfo().Mode().Perm()
+		switch header.Typeflag {
+		case tar.TypeReg:
+			err = writeFile(path, tr, mode, buf)
+		case tar.TypeDir:
+			if err = os.MkdirAll(path, mode); err == nil {
+				err = os.Chmod(path, mode)
+			}
+		case tar.TypeLink:
+			var target string
+			if target, err = cleanEntryName(header.Linkname); err == nil {
+				if _, err = ensureBasePath(dir, ".", target); err == nil {
+					err = os.Link(filepath.Join(dir, target), path)
+				}
+			}
+		case tar.TypeSymlink:
+			err = os.Symlink(header.Linkname, path)
+		default:
+			continue // Non-regular files are skipped
+		}
+		if err != nil {
+			return err
+		}
+		unpacked[name] = struct{}{}
+
+		// Change access time and modification time if possible (error ignored)
+		if header.Typeflag != tar.TypeSymlink {
+			os.Chtimes(path, header.AccessTime, header.ModTime)
+		}
+	}
+}
+
+// removeOpaque removes the entries of the directory parent in dir, which are
+// not unpacked from the current layer.
+func removeOpaque(dir, parent string, unpacked map[string]struct{}) error {
+	entries, err := os.ReadDir(filepath.Join(dir, parent))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		name := filepath.Join(parent, entry.Name())
+		if _, ok := unpacked[name]; ok {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanEntryName cleans the name of a tar entry, rejecting absolute paths and
+// paths traversing outside of the extraction directory.
+func cleanEntryName(name string) (string, error) {
+	if strings.HasPrefix(name, "/") || filepath.IsAbs(name) {
+		return "", fmt.Errorf("%q: %w", name, ErrPathTraversalDisallowed)
+	}
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%q: %w", name, ErrPathTraversalDisallowed)
+	}
+	return cleaned, nil
+}
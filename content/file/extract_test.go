@@ -0,0 +1,235 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// tarEntry is an entry of a test tar archive.
+type tarEntry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+}
+
+// buildLayer builds a tar archive of the entries, compressed by gzip if
+// compress is set.
+func buildLayer(t *testing.T, entries []tarEntry, compress bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.name,
+			Typeflag: entry.typeflag,
+			Linkname: entry.linkname,
+			Mode:     0644,
+			Size:     int64(len(entry.content)),
+		}
+		if entry.typeflag == tar.TypeDir {
+			header.Mode = 0755
+		}
+		if entry.typeflag != tar.TypeReg {
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Size > 0 {
+			if _, err := tw.Write([]byte(entry.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !compress {
+		return buf.Bytes()
+	}
+	var gzBuf bytes.Buffer
+	gw := gzip.NewWriter(&gzBuf)
+	if _, err := gw.Write(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return gzBuf.Bytes()
+}
+
+// packImage packs the layers into an image tagged by ref in a memory store.
+func packImage(t *testing.T, ref string, layers ...[]byte) *memory.Store {
+	t.Helper()
+	ctx := context.Background()
+	s := memory.New()
+	var descs []ocispec.Descriptor
+	for i, layer := range layers {
+		mediaType := ocispec.MediaTypeImageLayerGzip
+		if i%2 == 1 {
+			mediaType = ocispec.MediaTypeImageLayer
+		}
+		desc := content.NewDescriptorFromBytes(mediaType, layer)
+		if err := s.Push(ctx, desc, bytes.NewReader(layer)); err != nil {
+			t.Fatal(err)
+		}
+		descs = append(descs, desc)
+	}
+	manifestDesc, err := oras.Pack(ctx, s, descs, oras.PackOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Tag(ctx, manifestDesc, ref); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestExtract(t *testing.T) {
+	layer1 := buildLayer(t, []tarEntry{
+		{name: "etc/", typeflag: tar.TypeDir},
+		{name: "etc/a", typeflag: tar.TypeReg, content: "a"},
+		{name: "etc/b", typeflag: tar.TypeReg, content: "b"},
+		{name: "bin/x", typeflag: tar.TypeReg, content: "x"},
+		{name: "link", typeflag: tar.TypeSymlink, linkname: "/etc/a"},
+		{name: "hardlink", typeflag: tar.TypeLink, linkname: "etc/b"},
+	}, true)
+	layer2 := buildLayer(t, []tarEntry{
+		{name: "etc/a", typeflag: tar.TypeReg, content: "updated"},
+		{name: "etc/.wh.b", typeflag: tar.TypeReg},
+		{name: "bin/y", typeflag: tar.TypeReg, content: "y"},
+		{name: "bin/.wh..wh..opq", typeflag: tar.TypeReg},
+	}, false)
+	ref := "foobar"
+	s := packImage(t, ref, layer1, layer2)
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	desc, err := Extract(ctx, s, ref, dir, ExtractOptions{})
+	if err != nil {
+		t.Fatal("Extract() error =", err)
+	}
+	if desc.MediaType != ocispec.MediaTypeImageManifest {
+		t.Errorf("Extract() = %v, want image manifest", desc)
+	}
+
+	wantFiles := map[string]string{
+		"etc/a":    "updated",
+		"bin/y":    "y",
+		"hardlink": "b",
+	}
+	for name, want := range wantFiles {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("os.ReadFile(%s) error = %v", name, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("os.ReadFile(%s) = %q, want %q", name, got, want)
+		}
+	}
+	for _, name := range []string{"etc/b", "bin/x"} {
+		if _, err := os.Lstat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("os.Lstat(%s) error = %v, want not exist", name, err)
+		}
+	}
+	linkname, err := os.Readlink(filepath.Join(dir, "link"))
+	if err != nil {
+		t.Fatal("os.Readlink() error =", err)
+	}
+	if want := "/etc/a"; linkname != want {
+		t.Errorf("os.Readlink() = %v, want %v", linkname, want)
+	}
+}
+
+func TestExtract_PathTraversal(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{
+			name: "parent directory",
+			entries: []tarEntry{
+				{name: "../evil", typeflag: tar.TypeReg, content: "evil"},
+			},
+		},
+		{
+			name: "nested parent directory",
+			entries: []tarEntry{
+				{name: "foo/../../evil", typeflag: tar.TypeReg, content: "evil"},
+			},
+		},
+		{
+			name: "absolute path",
+			entries: []tarEntry{
+				{name: "/evil", typeflag: tar.TypeReg, content: "evil"},
+			},
+		},
+		{
+			name: "hard link outside",
+			entries: []tarEntry{
+				{name: "evil", typeflag: tar.TypeLink, linkname: "../outside"},
+			},
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := "foobar"
+			s := packImage(t, ref, buildLayer(t, tt.entries, true))
+			dir := filepath.Join(t.TempDir(), "rootfs")
+			_, err := Extract(ctx, s, ref, dir, ExtractOptions{})
+			if !errors.Is(err, ErrPathTraversalDisallowed) {
+				t.Errorf("Extract() error = %v, wantErr %v", err, ErrPathTraversalDisallowed)
+			}
+			if _, err := os.Lstat(filepath.Join(filepath.Dir(dir), "evil")); !os.IsNotExist(err) {
+				t.Errorf("file outside of the directory is created: %v", err)
+			}
+		})
+	}
+}
+
+func TestExtract_SymlinkNotFollowed(t *testing.T) {
+	outside := t.TempDir()
+	layer := buildLayer(t, []tarEntry{
+		{name: "escape", typeflag: tar.TypeSymlink, linkname: outside},
+		{name: "escape/evil", typeflag: tar.TypeReg, content: "evil"},
+	}, true)
+	ref := "foobar"
+	s := packImage(t, ref, layer)
+
+	dir := t.TempDir()
+	if _, err := Extract(context.Background(), s, ref, dir, ExtractOptions{}); err == nil {
+		t.Error("Extract() error = nil, wantErr true")
+	}
+	if _, err := os.Lstat(filepath.Join(outside, "evil")); !os.IsNotExist(err) {
+		t.Errorf("file outside of the directory is created: %v", err)
+	}
+}
@@ -193,7 +193,7 @@ func (s *Store) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCl
 		fp, err := os.Open(path)
 		if err != nil {
 			if os.IsNotExist(err) {
-				return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrNotFound)
+				return nil, &errdef.NotFoundError{Descriptor: target}
 			}
 			return nil, err
 		}
@@ -357,7 +357,7 @@ func (s *Store) Tag(ctx context.Context, desc ocispec.Descriptor, ref string) er
 		return err
 	}
 	if !exists {
-		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrNotFound)
+		return &errdef.NotFoundError{Descriptor: desc}
 	}
 
 	return s.resolver.Tag(ctx, desc, ref)
@@ -0,0 +1,114 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// PackDirectoryOptions contains parameters for PackDirectory.
+type PackDirectoryOptions struct {
+	// Name is the title of the layer, and the path prefix of the entries in
+	// the tar archive.
+	// If empty, the base name of the directory is used.
+	Name string
+	// MediaType is the media type of the layer.
+	// If empty, ocispec.MediaTypeImageLayerGzip is used if Gzip is set, or
+	// ocispec.MediaTypeImageLayer otherwise.
+	MediaType string
+	// Gzip compresses the tar archive with gzip. The compressed layer is
+	// annotated to be unpacked by the file store on pull.
+	Gzip bool
+}
+
+// PackDirectory creates a reproducible tar archive of the directory dir,
+// optionally compressed with gzip, and pushes it to pusher as a layer.
+// The entries are sorted by name, the timestamps are stripped, and the
+// owners are reset to root, so that the digest of the layer is stable for
+// identical inputs across runs. Symbolic links are archived as is without
+// being followed, and empty directories are preserved.
+// Returns the descriptor of the layer annotated with its title.
+func PackDirectory(ctx context.Context, pusher content.Pusher, dir string, opts PackDirectoryOptions) (ocispec.Descriptor, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if !fi.IsDir() {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: not a directory", dir)
+	}
+	name := opts.Name
+	if name == "" {
+		name = filepath.Base(filepath.Clean(dir))
+	}
+	mediaType := opts.MediaType
+	if mediaType == "" {
+		if opts.Gzip {
+			mediaType = ocispec.MediaTypeImageLayerGzip
+		} else {
+			mediaType = ocispec.MediaTypeImageLayer
+		}
+	}
+
+	// archive the directory while pushing
+	pr, pw := io.Pipe()
+	tarDigester := digest.Canonical.Digester()
+	go func() {
+		pw.CloseWithError(packDirectory(dir, name, pw, tarDigester, opts.Gzip))
+	}()
+	desc, err := content.PushFromReader(ctx, pusher, mediaType, pr)
+	pr.CloseWithError(err)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to pack %s: %w", dir, err)
+	}
+
+	desc.Annotations = map[string]string{
+		ocispec.AnnotationTitle: name,
+	}
+	if opts.Gzip {
+		desc.Annotations[AnnotationDigest] = tarDigester.Digest().String()
+		desc.Annotations[AnnotationUnpack] = "true"
+	}
+	return desc, nil
+}
+
+// packDirectory writes the reproducible tar archive of dir to w, and digests
+// the uncompressed archive by tarDigester.
+func packDirectory(dir, name string, w io.Writer, tarDigester digest.Digester, compress bool) (err error) {
+	tw := io.MultiWriter(w, tarDigester.Hash())
+	if compress {
+		gzw := gzip.NewWriter(w)
+		defer func() {
+			closeErr := gzw.Close()
+			if err == nil {
+				err = closeErr
+			}
+		}()
+		tw = io.MultiWriter(gzw, tarDigester.Hash())
+	}
+
+	buf := bufPool.Get().(*[]byte)
+	defer bufPool.Put(buf)
+	return tarDirectory(dir, name, tw, true, *buf)
+}
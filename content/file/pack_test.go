@@ -0,0 +1,132 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestPackDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	for _, sub := range []string{"empty", "sub"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, data := range map[string]string{
+		"b.txt":     "bar",
+		"a.txt":     "foo",
+		"sub/c.txt": "hello",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("a.txt", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	s := memory.New()
+	desc, err := PackDirectory(ctx, s, dir, PackDirectoryOptions{})
+	if err != nil {
+		t.Fatal("PackDirectory() error =", err)
+	}
+	if got, want := desc.MediaType, ocispec.MediaTypeImageLayer; got != want {
+		t.Errorf("PackDirectory() media type = %v, want %v", got, want)
+	}
+	if got, want := desc.Annotations[ocispec.AnnotationTitle], "data"; got != want {
+		t.Errorf("PackDirectory() title = %v, want %v", got, want)
+	}
+
+	// verify the entries
+	layer, err := content.FetchAll(ctx, s, desc)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(layer))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("tar.Reader.Next() error =", err)
+		}
+		names = append(names, hdr.Name)
+		if hdr.Uid != 0 || hdr.Gid != 0 || hdr.Uname != "" || hdr.Gname != "" {
+			t.Errorf("entry %s: owner = %d:%d (%q:%q), want root", hdr.Name, hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname)
+		}
+		if hdr.Name == "data/link" {
+			if hdr.Typeflag != tar.TypeSymlink || hdr.Linkname != "a.txt" {
+				t.Errorf("entry %s: type = %v, link = %v, want symlink to a.txt", hdr.Name, hdr.Typeflag, hdr.Linkname)
+			}
+		}
+	}
+	wantNames := []string{"data", "data/a.txt", "data/b.txt", "data/empty", "data/link", "data/sub", "data/sub/c.txt"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("entries = %v, want %v", names, wantNames)
+	}
+
+	// test reproducibility across timestamps
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "a.txt"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	for _, gzip := range []bool{false, true} {
+		opts := PackDirectoryOptions{Gzip: gzip}
+		want, err := PackDirectory(ctx, memory.New(), dir, opts)
+		if err != nil {
+			t.Fatal("PackDirectory() error =", err)
+		}
+		if err := os.Chtimes(filepath.Join(dir, "sub"), future, future); err != nil {
+			t.Fatal(err)
+		}
+		got, err := PackDirectory(ctx, memory.New(), dir, opts)
+		if err != nil {
+			t.Fatal("PackDirectory() error =", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("PackDirectory(gzip=%v) = %v, want %v", gzip, got, want)
+		}
+		if gzip && got.Annotations[AnnotationUnpack] != "true" {
+			t.Errorf("PackDirectory(gzip=%v) annotations = %v, want %s", gzip, got.Annotations, AnnotationUnpack)
+		}
+	}
+}
+
+func TestPackDirectory_NotDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte("foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PackDirectory(context.Background(), memory.New(), path, PackDirectoryOptions{}); err == nil {
+		t.Error("PackDirectory() error = nil, wantErr true")
+	}
+}
@@ -18,13 +18,47 @@ package content
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/descriptor"
 	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/spec"
 )
 
+// MaxManifestBytes is the maximum size of the manifests and indexes decoded by
+// Successors, and by the functions in the oras package without a more
+// specific limit. As manifests are small, larger ones are likely malicious or
+// broken, and are rejected with ErrManifestTooLarge before being fetched, so
+// that the memory used for decoding them is bounded. Blobs are not limited.
+// It is not safe to change MaxManifestBytes concurrently with its use.
+var MaxManifestBytes int64 = 4 * 1024 * 1024 // 4 MiB
+
+// ErrManifestTooLarge is returned when a manifest or an index exceeds the size
+// limit before being decoded. It matches errdef.ErrSizeExceedsLimit.
+var ErrManifestTooLarge = fmt.Errorf("manifest too large: %w", errdef.ErrSizeExceedsLimit)
+
+// CheckManifestSize returns an error wrapping ErrManifestTooLarge if the size
+// of the manifest described by desc exceeds limit.
+func CheckManifestSize(desc ocispec.Descriptor, limit int64) error {
+	if desc.Size > limit {
+		return fmt.Errorf("%s: %s: manifest size %v exceeds limit %v: %w",
+			desc.Digest, desc.MediaType, desc.Size, limit, ErrManifestTooLarge)
+	}
+	return nil
+}
+
+// fetchManifest safely fetches the manifest or the index described by desc,
+// which is rejected if it exceeds MaxManifestBytes.
+func fetchManifest(ctx context.Context, fetcher Fetcher, desc ocispec.Descriptor) ([]byte, error) {
+	if err := CheckManifestSize(desc, MaxManifestBytes); err != nil {
+		return nil, err
+	}
+	return FetchAll(ctx, fetcher, desc)
+}
+
 // PredecessorFinder finds out the nodes directly pointing to a given node of a
 // directed acyclic graph.
 // In other words, returns the "parents" of the current descriptor.
@@ -48,10 +82,15 @@ type ReadOnlyGraphStorage interface {
 
 // Successors returns the nodes directly pointed by the current node.
 // In other words, returns the "children" of the current descriptor.
+// The successors of a docker manifest list or an OCI index are the entries of
+// its `manifests` field in order, with their platforms, including the
+// `variant`, `os.version`, and `os.features` fields, preserved as is.
+// Manifests and indexes exceeding MaxManifestBytes are rejected with
+// ErrManifestTooLarge without being fetched.
 func Successors(ctx context.Context, fetcher Fetcher, node ocispec.Descriptor) ([]ocispec.Descriptor, error) {
 	switch node.MediaType {
 	case docker.MediaTypeManifest, ocispec.MediaTypeImageManifest:
-		content, err := FetchAll(ctx, fetcher, node)
+		content, err := fetchManifest(ctx, fetcher, node)
 		if err != nil {
 			return nil, err
 		}
@@ -61,21 +100,31 @@ func Successors(ctx context.Context, fetcher Fetcher, node ocispec.Descriptor) (
 		if err := json.Unmarshal(content, &manifest); err != nil {
 			return nil, err
 		}
-		return append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...), nil
+		nodes := append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...)
+		if manifest.Subject != nil {
+			// the subject is placed last so that the config is always the
+			// first successor.
+			nodes = append(nodes, *manifest.Subject)
+		}
+		return nodes, nil
 	case docker.MediaTypeManifestList, ocispec.MediaTypeImageIndex:
-		content, err := FetchAll(ctx, fetcher, node)
+		content, err := fetchManifest(ctx, fetcher, node)
 		if err != nil {
 			return nil, err
 		}
 
 		// docker manifest list and oci index are equivalent for successors.
-		var index ocispec.Index
+		var index spec.Index
 		if err := json.Unmarshal(content, &index); err != nil {
 			return nil, err
 		}
+		if index.Subject != nil {
+			// the subject is placed last, following the manifests.
+			return append(index.Manifests, *index.Subject), nil
+		}
 		return index.Manifests, nil
 	case artifactspec.MediaTypeArtifactManifest: // TODO: deprecate
-		content, err := FetchAll(ctx, fetcher, node)
+		content, err := fetchManifest(ctx, fetcher, node)
 		if err != nil {
 			return nil, err
 		}
@@ -93,7 +142,7 @@ func Successors(ctx context.Context, fetcher Fetcher, node ocispec.Descriptor) (
 		}
 		return nodes, nil
 	case ocispec.MediaTypeArtifactManifest:
-		content, err := FetchAll(ctx, fetcher, node)
+		content, err := fetchManifest(ctx, fetcher, node)
 		if err != nil {
 			return nil, err
 		}
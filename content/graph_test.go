@@ -0,0 +1,165 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+// bytesFetcher fetches the content from a map keyed by digest.
+type bytesFetcher map[digest.Digest][]byte
+
+func (f bytesFetcher) Fetch(_ context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	content, ok := f[target.Digest]
+	if !ok {
+		return nil, errdef.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func TestSuccessors_DockerManifestList(t *testing.T) {
+	// a docker manifest list in the format served by Docker Hub, with the
+	// platforms of the Windows and the ARM images
+	manifestList := []byte(`{
+   "manifests": [
+      {
+         "digest": "sha256:f54a58bc1aac5ea1a25d796ae155dc228b3f0e11d046ae276b39c4bf2f13d8c4",
+         "mediaType": "application\/vnd.docker.distribution.manifest.v2+json",
+         "platform": {
+            "architecture": "amd64",
+            "os": "linux"
+         },
+         "size": 525
+      },
+      {
+         "digest": "sha256:7b8b7289d0536a08eabdf71c20246e23f7116641db7e1d278592236ea4dcb30c",
+         "mediaType": "application\/vnd.docker.distribution.manifest.v2+json",
+         "platform": {
+            "architecture": "arm",
+            "os": "linux",
+            "variant": "v7"
+         },
+         "size": 525
+      },
+      {
+         "digest": "sha256:db2d6f4e1a4b9ebc67d0c1b8cf0bb65a26e0cf8c2c4ab04b6b8ff2a28e8a7c2b",
+         "mediaType": "application\/vnd.docker.distribution.manifest.v2+json",
+         "platform": {
+            "architecture": "amd64",
+            "os": "windows",
+            "os.version": "10.0.17763.4010",
+            "os.features": [
+               "win32k"
+            ]
+         },
+         "size": 1125
+      }
+   ],
+   "mediaType": "application\/vnd.docker.distribution.manifest.list.v2+json",
+   "schemaVersion": 2
+}`)
+	desc := NewDescriptorFromBytes(docker.MediaTypeManifestList, manifestList)
+	fetcher := bytesFetcher{
+		desc.Digest: manifestList,
+	}
+
+	got, err := Successors(context.Background(), fetcher, desc)
+	if err != nil {
+		t.Fatal("Successors() error =", err)
+	}
+	want := []ocispec.Descriptor{
+		{
+			MediaType: docker.MediaTypeManifest,
+			Digest:    "sha256:f54a58bc1aac5ea1a25d796ae155dc228b3f0e11d046ae276b39c4bf2f13d8c4",
+			Size:      525,
+			Platform: &ocispec.Platform{
+				Architecture: "amd64",
+				OS:           "linux",
+			},
+		},
+		{
+			MediaType: docker.MediaTypeManifest,
+			Digest:    "sha256:7b8b7289d0536a08eabdf71c20246e23f7116641db7e1d278592236ea4dcb30c",
+			Size:      525,
+			Platform: &ocispec.Platform{
+				Architecture: "arm",
+				OS:           "linux",
+				Variant:      "v7",
+			},
+		},
+		{
+			MediaType: docker.MediaTypeManifest,
+			Digest:    "sha256:db2d6f4e1a4b9ebc67d0c1b8cf0bb65a26e0cf8c2c4ab04b6b8ff2a28e8a7c2b",
+			Size:      1125,
+			Platform: &ocispec.Platform{
+				Architecture: "amd64",
+				OS:           "windows",
+				OSVersion:    "10.0.17763.4010",
+				OSFeatures:   []string{"win32k"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Successors() = %v, want %v", got, want)
+	}
+}
+
+func TestSuccessors_ManifestTooLarge(t *testing.T) {
+	manifest := []byte(`{"config":{},"layers":[]}`)
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	fetcher := bytesFetcher{desc.Digest: manifest}
+	ctx := context.Background()
+
+	maxManifestBytes := MaxManifestBytes
+	defer func() { MaxManifestBytes = maxManifestBytes }()
+	MaxManifestBytes = desc.Size - 1
+	_, err := Successors(ctx, fetcher, desc)
+	if !errors.Is(err, ErrManifestTooLarge) {
+		t.Errorf("Successors() error = %v, want %v", err, ErrManifestTooLarge)
+	}
+	if !errors.Is(err, errdef.ErrSizeExceedsLimit) {
+		t.Errorf("Successors() error = %v, want %v", err, errdef.ErrSizeExceedsLimit)
+	}
+
+	// blobs are not limited
+	blob := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayer,
+		Digest:    digest.FromString("large"),
+		Size:      MaxManifestBytes + 1,
+	}
+	if _, err := Successors(ctx, fetcher, blob); err != nil {
+		t.Errorf("Successors() error = %v", err)
+	}
+
+	MaxManifestBytes = desc.Size
+	if _, err := Successors(ctx, fetcher, desc); err != nil {
+		t.Errorf("Successors() error = %v", err)
+	}
+}
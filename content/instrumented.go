@@ -0,0 +1,202 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// AccessEvent describes an access to the content of a storage.
+type AccessEvent struct {
+	// Descriptor is the descriptor of the accessed content.
+	Descriptor ocispec.Descriptor
+	// Bytes is the number of bytes transferred by the access.
+	Bytes int64
+	// Duration is the duration of the access.
+	Duration time.Duration
+	// Err is the error of the access, if any.
+	Err error
+}
+
+// InstrumentHooks contains the callbacks invoked on the accesses to an
+// instrumented storage. All hooks are optional.
+type InstrumentHooks struct {
+	// OnFetch is called when the content returned by Fetch is closed, or when
+	// Fetch fails. The event reports the number of bytes read, and the
+	// duration from the start of Fetch to the close of the content.
+	OnFetch func(ctx context.Context, event AccessEvent)
+	// OnPush is called after Push returns. The event reports the number of
+	// bytes read from the pushed content.
+	OnPush func(ctx context.Context, event AccessEvent)
+	// OnExists is called after Exists returns.
+	OnExists func(ctx context.Context, event AccessEvent)
+}
+
+// NewInstrumented returns a storage wrapping s, which invokes the hooks on
+// every Fetch, Push, and Exists without modifying s.
+// The returned storage implements PredecessorFinder, Deleter, and TagResolver
+// if s implements them, passing the calls through to s.
+func NewInstrumented(s Storage, hooks InstrumentHooks) Storage {
+	is := &instrumentedStorage{
+		base:  s,
+		hooks: hooks,
+	}
+	pf, isPredecessorFinder := s.(PredecessorFinder)
+	d, isDeleter := s.(Deleter)
+	tr, isTagResolver := s.(TagResolver)
+	switch {
+	case isPredecessorFinder && isDeleter && isTagResolver:
+		return struct {
+			*instrumentedStorage
+			PredecessorFinder
+			Deleter
+			TagResolver
+		}{is, pf, d, tr}
+	case isPredecessorFinder && isDeleter:
+		return struct {
+			*instrumentedStorage
+			PredecessorFinder
+			Deleter
+		}{is, pf, d}
+	case isPredecessorFinder && isTagResolver:
+		return struct {
+			*instrumentedStorage
+			PredecessorFinder
+			TagResolver
+		}{is, pf, tr}
+	case isDeleter && isTagResolver:
+		return struct {
+			*instrumentedStorage
+			Deleter
+			TagResolver
+		}{is, d, tr}
+	case isPredecessorFinder:
+		return struct {
+			*instrumentedStorage
+			PredecessorFinder
+		}{is, pf}
+	case isDeleter:
+		return struct {
+			*instrumentedStorage
+			Deleter
+		}{is, d}
+	case isTagResolver:
+		return struct {
+			*instrumentedStorage
+			TagResolver
+		}{is, tr}
+	default:
+		return is
+	}
+}
+
+// instrumentedStorage is a storage invoking hooks on accesses.
+type instrumentedStorage struct {
+	base  Storage
+	hooks InstrumentHooks
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (s *instrumentedStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	start := time.Now()
+	rc, err := s.base.Fetch(ctx, target)
+	if s.hooks.OnFetch == nil {
+		return rc, err
+	}
+	if err != nil {
+		s.hooks.OnFetch(ctx, AccessEvent{
+			Descriptor: target,
+			Duration:   time.Since(start),
+			Err:        err,
+		})
+		return nil, err
+	}
+	cr := &countingReader{r: rc}
+	var once sync.Once
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: cr,
+		Closer: closerFunc(func() error {
+			err := rc.Close()
+			once.Do(func() {
+				s.hooks.OnFetch(ctx, AccessEvent{
+					Descriptor: target,
+					Bytes:      cr.n,
+					Duration:   time.Since(start),
+					Err:        cr.err,
+				})
+			})
+			return err
+		}),
+	}, nil
+}
+
+// Push pushes the content, matching the expected descriptor.
+func (s *instrumentedStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	if s.hooks.OnPush == nil {
+		return s.base.Push(ctx, expected, content)
+	}
+	start := time.Now()
+	cr := &countingReader{r: content}
+	err := s.base.Push(ctx, expected, cr)
+	s.hooks.OnPush(ctx, AccessEvent{
+		Descriptor: expected,
+		Bytes:      cr.n,
+		Duration:   time.Since(start),
+		Err:        err,
+	})
+	return err
+}
+
+// Exists returns true if the described content exists.
+func (s *instrumentedStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	if s.hooks.OnExists == nil {
+		return s.base.Exists(ctx, target)
+	}
+	start := time.Now()
+	exists, err := s.base.Exists(ctx, target)
+	s.hooks.OnExists(ctx, AccessEvent{
+		Descriptor: target,
+		Duration:   time.Since(start),
+		Err:        err,
+	})
+	return exists, err
+}
+
+// countingReader counts the number of bytes read and records the read error
+// other than io.EOF.
+type countingReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+// Read reads up to len(p) bytes into p.
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,152 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content_test
+
+import (
+	"bytes"
+	"context"
+	_ "crypto/sha256"
+	"errors"
+	"io"
+	"testing"
+
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/cas"
+)
+
+func TestNewInstrumented(t *testing.T) {
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+
+	var fetched, pushed, existed []content.AccessEvent
+	hooks := content.InstrumentHooks{
+		OnFetch: func(ctx context.Context, event content.AccessEvent) {
+			fetched = append(fetched, event)
+		},
+		OnPush: func(ctx context.Context, event content.AccessEvent) {
+			pushed = append(pushed, event)
+		},
+		OnExists: func(ctx context.Context, event content.AccessEvent) {
+			existed = append(existed, event)
+		},
+	}
+	s := content.NewInstrumented(memory.New(), hooks)
+	ctx := context.Background()
+
+	// test interfaces
+	if _, ok := s.(content.PredecessorFinder); !ok {
+		t.Error("instrumented storage does not implement content.PredecessorFinder")
+	}
+	if _, ok := s.(content.TagResolver); !ok {
+		t.Error("instrumented storage does not implement content.TagResolver")
+	}
+	if _, ok := s.(content.Deleter); ok {
+		t.Error("instrumented storage unexpectedly implements content.Deleter")
+	}
+
+	// test push
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatalf("Storage.Push() error = %v", err)
+	}
+	if len(pushed) != 1 || pushed[0].Bytes != desc.Size || pushed[0].Err != nil || !content.Equal(pushed[0].Descriptor, desc) {
+		t.Errorf("OnPush events = %v, want 1 event of %d bytes", pushed, desc.Size)
+	}
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); !errors.Is(err, errdef.ErrAlreadyExists) {
+		t.Fatalf("Storage.Push() error = %v, wantErr %v", err, errdef.ErrAlreadyExists)
+	}
+	if len(pushed) != 2 || !errors.Is(pushed[1].Err, errdef.ErrAlreadyExists) {
+		t.Errorf("OnPush events = %v, want a failed event", pushed)
+	}
+
+	// test exists
+	exists, err := s.Exists(ctx, desc)
+	if err != nil {
+		t.Fatalf("Storage.Exists() error = %v", err)
+	}
+	if !exists {
+		t.Errorf("Storage.Exists() = %v, want %v", exists, true)
+	}
+	if len(existed) != 1 || !content.Equal(existed[0].Descriptor, desc) {
+		t.Errorf("OnExists events = %v, want 1 event", existed)
+	}
+
+	// test fetch
+	rc, err := s.Fetch(ctx, desc)
+	if err != nil {
+		t.Fatalf("Storage.Fetch() error = %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("Storage.Fetch().Read() error = %v", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("Storage.Fetch() = %v, want %v", got, blob)
+	}
+	if len(fetched) != 0 {
+		t.Errorf("OnFetch events = %v, want none before close", fetched)
+	}
+	if err := rc.Close(); err != nil {
+		t.Fatalf("Storage.Fetch().Close() error = %v", err)
+	}
+	if len(fetched) != 1 || fetched[0].Bytes != desc.Size || fetched[0].Err != nil {
+		t.Errorf("OnFetch events = %v, want 1 event of %d bytes", fetched, desc.Size)
+	}
+	missing := content.NewDescriptorFromBytes("test", []byte("missing"))
+	if _, err := s.Fetch(ctx, missing); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("Storage.Fetch() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+	if len(fetched) != 2 || !errors.Is(fetched[1].Err, errdef.ErrNotFound) {
+		t.Errorf("OnFetch events = %v, want a failed event", fetched)
+	}
+
+	// test pass-through
+	if err := s.(content.Tagger).Tag(ctx, desc, "foobar"); err != nil {
+		t.Fatalf("Storage.Tag() error = %v", err)
+	}
+	resolved, err := s.(content.Resolver).Resolve(ctx, "foobar")
+	if err != nil {
+		t.Fatalf("Storage.Resolve() error = %v", err)
+	}
+	if !content.Equal(resolved, desc) {
+		t.Errorf("Storage.Resolve() = %v, want %v", resolved, desc)
+	}
+}
+
+func TestNewInstrumented_PlainStorage(t *testing.T) {
+	s := content.NewInstrumented(cas.NewMemory(), content.InstrumentHooks{})
+	if _, ok := s.(content.PredecessorFinder); ok {
+		t.Error("instrumented storage unexpectedly implements content.PredecessorFinder")
+	}
+	if _, ok := s.(content.TagResolver); ok {
+		t.Error("instrumented storage unexpectedly implements content.TagResolver")
+	}
+
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	ctx := context.Background()
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatalf("Storage.Push() error = %v", err)
+	}
+	got, err := content.FetchAll(ctx, s, desc)
+	if err != nil {
+		t.Fatalf("content.FetchAll() error = %v", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("content.FetchAll() = %v, want %v", got, blob)
+	}
+}
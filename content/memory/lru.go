@@ -0,0 +1,180 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/graph"
+	"oras.land/oras-go/v2/internal/resolver"
+)
+
+// NewLRU creates a new memory based store, which caches up to maxBytes of
+// blobs and evicts the least recently used blobs when the cap is exceeded.
+// Pushing and fetching a blob count as a use of it.
+//
+// Manifests are pinned: they are never evicted, and are not counted against
+// maxBytes, so that the tags and the predecessors of the store remain valid.
+// Blobs being read, i.e. fetched but not yet closed, are not evicted until
+// their readers are closed, which may exceed the cap temporarily.
+// Pushing a blob larger than maxBytes returns errdef.ErrSizeExceedsLimit.
+// If maxBytes is not positive, the cache size is unlimited, and no blob is
+// evicted.
+func NewLRU(maxBytes int64) *Store {
+	return &Store{
+		storage: &lruStorage{
+			maxBytes: maxBytes,
+			entries:  make(map[descriptor.Descriptor]*lruEntry),
+			lru:      list.New(),
+		},
+		resolver: resolver.NewMemory(),
+		graph:    graph.NewMemory(),
+	}
+}
+
+// lruStorage is a memory based CAS evicting the least recently used blobs.
+type lruStorage struct {
+	maxBytes int64
+
+	lock    sync.Mutex
+	size    int64 // total size of the blobs in lru
+	entries map[descriptor.Descriptor]*lruEntry
+	lru     *list.List // of *lruEntry, the most recently used at the front
+}
+
+// lruEntry is a content cached in lruStorage.
+type lruEntry struct {
+	key     descriptor.Descriptor
+	content []byte
+	// element is the element of the entry in the LRU list, which is nil for
+	// the pinned manifests.
+	element *list.Element
+	// refs counts the readers of the entry not yet closed.
+	refs int
+}
+
+// Fetch fetches the content identified by the descriptor, and marks it as
+// the most recently used.
+func (s *lruStorage) Fetch(_ context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	entry, ok := s.entries[descriptor.FromOCI(target)]
+	if !ok {
+		return nil, &errdef.NotFoundError{Descriptor: target}
+	}
+	if entry.element != nil {
+		s.lru.MoveToFront(entry.element)
+	}
+	entry.refs++
+	return &lruReader{
+		Reader: bytes.NewReader(entry.content),
+		release: func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			entry.refs--
+			s.evict()
+		},
+	}, nil
+}
+
+// Push pushes the content, matching the expected descriptor.
+func (s *lruStorage) Push(_ context.Context, expected ocispec.Descriptor, reader io.Reader) error {
+	key := descriptor.FromOCI(expected)
+	pinned := descriptor.IsManifest(expected)
+	if !pinned && s.maxBytes > 0 && expected.Size > s.maxBytes {
+		return fmt.Errorf("%s: %s: content size %v exceeds the cache size %v: %w",
+			key.Digest, key.MediaType, expected.Size, s.maxBytes, errdef.ErrSizeExceedsLimit)
+	}
+
+	// check if the content exists in advance to avoid reading from the content.
+	if exists, _ := s.Exists(context.Background(), expected); exists {
+		return fmt.Errorf("%s: %s: %w", key.Digest, key.MediaType, errdef.ErrAlreadyExists)
+	}
+
+	value, err := content.ReadAll(reader, expected)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, exists := s.entries[key]; exists {
+		return fmt.Errorf("%s: %s: %w", key.Digest, key.MediaType, errdef.ErrAlreadyExists)
+	}
+	entry := &lruEntry{
+		key:     key,
+		content: value,
+	}
+	s.entries[key] = entry
+	if !pinned {
+		entry.element = s.lru.PushFront(entry)
+		s.size += int64(len(value))
+		s.evict()
+	}
+	return nil
+}
+
+// Exists returns true if the described content exists.
+func (s *lruStorage) Exists(_ context.Context, target ocispec.Descriptor) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, exists := s.entries[descriptor.FromOCI(target)]
+	return exists, nil
+}
+
+// evict evicts the least recently used blobs not being read until the total
+// size is within the cap.
+// The caller must hold the lock.
+func (s *lruStorage) evict() {
+	if s.maxBytes <= 0 {
+		// unlimited
+		return
+	}
+	for e := s.lru.Back(); e != nil && s.size > s.maxBytes; {
+		entry := e.Value.(*lruEntry)
+		prev := e.Prev()
+		if entry.refs == 0 {
+			s.lru.Remove(e)
+			delete(s.entries, entry.key)
+			s.size -= int64(len(entry.content))
+		}
+		e = prev
+	}
+}
+
+// lruReader releases the fetched entry on Close.
+type lruReader struct {
+	io.Reader
+	release func()
+	once    sync.Once
+}
+
+// Close releases the fetched entry.
+func (r *lruReader) Close() error {
+	r.once.Do(r.release)
+	return nil
+}
@@ -18,7 +18,6 @@ package memory
 
 import (
 	"context"
-	"fmt"
 	"io"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -80,7 +79,7 @@ func (s *Store) Tag(ctx context.Context, desc ocispec.Descriptor, reference stri
 		return err
 	}
 	if !exists {
-		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrNotFound)
+		return &errdef.NotFoundError{Descriptor: desc}
 	}
 	return s.resolver.Tag(ctx, desc, reference)
 }
@@ -29,11 +29,13 @@ import (
 
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
 	"golang.org/x/sync/errgroup"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/cas"
+	"oras.land/oras-go/v2/internal/docker"
 	"oras.land/oras-go/v2/internal/resolver"
 )
 
@@ -133,6 +135,13 @@ func TestStoreContentNotFound(t *testing.T) {
 	if !errors.Is(err, errdef.ErrNotFound) {
 		t.Errorf("Store.Fetch() error = %v, want %v", err, errdef.ErrNotFound)
 	}
+	var notFound *errdef.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Store.Fetch() error = %v, want *errdef.NotFoundError", err)
+	}
+	if !reflect.DeepEqual(notFound.Descriptor, desc) {
+		t.Errorf("NotFoundError.Descriptor = %v, want %v", notFound.Descriptor, desc)
+	}
 }
 
 func TestStoreContentAlreadyExists(t *testing.T) {
@@ -184,6 +193,13 @@ func TestStoreTagNotFound(t *testing.T) {
 	if !errors.Is(err, errdef.ErrNotFound) {
 		t.Errorf("Store.Resolve() error = %v, want %v", err, errdef.ErrNotFound)
 	}
+	var notFound *errdef.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Store.Resolve() error = %v, want *errdef.NotFoundError", err)
+	}
+	if notFound.Reference != ref {
+		t.Errorf("NotFoundError.Reference = %v, want %v", notFound.Reference, ref)
+	}
 }
 
 func TestStoreTagUnknownContent(t *testing.T) {
@@ -420,3 +436,171 @@ func equalDescriptorSet(actual []ocispec.Descriptor, expected []ocispec.Descript
 	}
 	return true
 }
+
+func TestStore_LRU(t *testing.T) {
+	s := NewLRU(10)
+	ctx := context.Background()
+
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatalf("Store.Push(%s) error = %v", blob, err)
+		}
+		return desc
+	}
+	checkExists := func(desc ocispec.Descriptor, want bool) {
+		t.Helper()
+		exists, err := s.Exists(ctx, desc)
+		if err != nil {
+			t.Fatal("Store.Exists() error =", err)
+		}
+		if exists != want {
+			t.Errorf("Store.Exists(%s) = %v, want %v", desc.Digest, exists, want)
+		}
+	}
+
+	foo := push("test", []byte("foo"))
+	bar := push("test", []byte("bar"))
+	baz := push("test", []byte("baz"))
+	manifest := push(ocispec.MediaTypeImageManifest, []byte(`{"layers":[],"annotations":{"pinned":"true"}}`))
+	if err := s.Tag(ctx, manifest, "latest"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+
+	// fetching foo makes bar the least recently used
+	if _, err := content.FetchAll(ctx, s, foo); err != nil {
+		t.Fatal("content.FetchAll() error =", err)
+	}
+	qux := push("test", []byte("qux"))
+	checkExists(foo, true)
+	checkExists(bar, false)
+	checkExists(baz, true)
+	checkExists(qux, true)
+	checkExists(manifest, true)
+
+	// the least recently used content being read is not evicted
+	rc, err := s.Fetch(ctx, baz)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	for _, desc := range []ocispec.Descriptor{foo, qux} {
+		if _, err := content.FetchAll(ctx, s, desc); err != nil {
+			t.Fatal("content.FetchAll() error =", err)
+		}
+	}
+	quux := push("test", []byte("quux"))
+	checkExists(foo, false)
+	checkExists(baz, true)
+	checkExists(qux, true)
+	checkExists(quux, true)
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal("io.ReadAll() error =", err)
+	}
+	if want := []byte("baz"); !bytes.Equal(got, want) {
+		t.Errorf("Store.Fetch() = %s, want %s", got, want)
+	}
+	if err := rc.Close(); err != nil {
+		t.Fatal("ReadCloser.Close() error =", err)
+	}
+	checkExists(baz, true)
+	checkExists(quux, true)
+
+	// evict the released content once the cap is exceeded
+	rc, err = s.Fetch(ctx, baz)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	corge := push("test", []byte("corge"))
+	checkExists(qux, false)
+	checkExists(quux, false)
+	checkExists(baz, true)
+	checkExists(corge, true)
+	rc.Close()
+	checkExists(baz, true)
+	grault := push("test", []byte("grault"))
+	checkExists(baz, false)
+	checkExists(corge, false)
+	checkExists(grault, true)
+
+	// the pinned manifest is kept
+	checkExists(manifest, true)
+	if _, err := s.Resolve(ctx, "latest"); err != nil {
+		t.Error("Store.Resolve() error =", err)
+	}
+
+	// test pushing content larger than the cap
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); !errors.Is(err, errdef.ErrSizeExceedsLimit) {
+		t.Errorf("Store.Push() error = %v, want %v", err, errdef.ErrSizeExceedsLimit)
+	}
+
+	// test pushing existing content
+	if err := s.Push(ctx, grault, bytes.NewReader([]byte("grault"))); !errors.Is(err, errdef.ErrAlreadyExists) {
+		t.Errorf("Store.Push() error = %v, want %v", err, errdef.ErrAlreadyExists)
+	}
+}
+
+func TestStore_LRU_PinnedManifests(t *testing.T) {
+	s := NewLRU(3)
+	ctx := context.Background()
+
+	var manifests []ocispec.Descriptor
+	for _, mediaType := range []string{
+		ocispec.MediaTypeImageManifest,
+		ocispec.MediaTypeImageIndex,
+		ocispec.MediaTypeArtifactManifest,
+		artifactspec.MediaTypeArtifactManifest,
+		docker.MediaTypeManifest,
+		docker.MediaTypeManifestList,
+	} {
+		blob := []byte(`{"mediaType":"` + mediaType + `"}`)
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatalf("Store.Push(%s) error = %v", mediaType, err)
+		}
+		manifests = append(manifests, desc)
+	}
+	for _, blob := range [][]byte{[]byte("foo"), []byte("bar")} {
+		desc := content.NewDescriptorFromBytes("test", blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatalf("Store.Push(%s) error = %v", blob, err)
+		}
+	}
+
+	for _, desc := range manifests {
+		exists, err := s.Exists(ctx, desc)
+		if err != nil {
+			t.Fatal("Store.Exists() error =", err)
+		}
+		if !exists {
+			t.Errorf("Store.Exists(%s) = %v, want %v", desc.MediaType, exists, true)
+		}
+	}
+}
+
+func TestStore_LRU_Unlimited(t *testing.T) {
+	for _, maxBytes := range []int64{0, -1} {
+		s := NewLRU(maxBytes)
+		ctx := context.Background()
+
+		var descs []ocispec.Descriptor
+		for _, blob := range [][]byte{[]byte("foo"), []byte("hello world")} {
+			desc := content.NewDescriptorFromBytes("test", blob)
+			if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+				t.Fatalf("NewLRU(%d).Push(%s) error = %v", maxBytes, blob, err)
+			}
+			descs = append(descs, desc)
+		}
+		for _, desc := range descs {
+			exists, err := s.Exists(ctx, desc)
+			if err != nil {
+				t.Fatal("Store.Exists() error =", err)
+			}
+			if !exists {
+				t.Errorf("NewLRU(%d).Exists(%s) = %v, want %v", maxBytes, desc.Digest, exists, true)
+			}
+		}
+	}
+}
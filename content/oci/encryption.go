@@ -0,0 +1,262 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// encryptedSegmentSize is the size of the plaintext segments of the encrypted
+// blobs, which bounds the memory used for encrypting and decrypting a blob.
+const encryptedSegmentSize = 64 * 1024 // 64 KiB
+
+// minEncryptionNonceSize is the minimum nonce size of the AEADs supported for
+// encrypting blobs, where the last 5 bytes of the nonce are used by the
+// segment counter and the last segment flag.
+const minEncryptionNonceSize = 12
+
+// An encrypted blob is stored as a random nonce prefix followed by the
+// segments of the plaintext, each sealed by the AEAD with the nonce
+// `prefix || counter || last` and the digest of the plaintext as the
+// additional data, so that the segments cannot be reordered, truncated, or
+// moved to another blob undetected.
+
+// segmentNonce writes the nonce of the segment to nonce.
+func segmentNonce(nonce, prefix []byte, counter uint32, last bool) {
+	n := copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[n:], counter)
+	if last {
+		nonce[n+4] = 1
+	} else {
+		nonce[n+4] = 0
+	}
+}
+
+// encryptedSize returns the size of the encrypted blob of the given plaintext
+// size.
+func encryptedSize(aead cipher.AEAD, size int64) int64 {
+	segments := (size + encryptedSegmentSize - 1) / encryptedSegmentSize
+	if segments == 0 {
+		segments = 1
+	}
+	return int64(aead.NonceSize()-5) + size + segments*int64(aead.Overhead())
+}
+
+// plaintextSize returns the size of the plaintext of an encrypted blob of the
+// given size.
+func plaintextSize(aead cipher.AEAD, size int64) (int64, error) {
+	size -= int64(aead.NonceSize() - 5)
+	sealedSegmentSize := int64(encryptedSegmentSize + aead.Overhead())
+	segments := (size + sealedSegmentSize - 1) / sealedSegmentSize
+	if segments == 0 {
+		segments = 1
+	}
+	plainSize := size - segments*int64(aead.Overhead())
+	if plainSize < 0 || encryptedSize(aead, plainSize) != size+int64(aead.NonceSize()-5) {
+		return 0, errors.New("invalid encrypted blob size")
+	}
+	return plainSize, nil
+}
+
+// encryptWriter encrypts the content written to it into the underlying
+// writer. Close must be called to seal the last segment.
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	ad      []byte
+	prefix  []byte
+	nonce   []byte
+	buf     []byte
+	sealed  []byte
+	counter uint32
+}
+
+// newEncryptWriter returns an encryptWriter writing to w, after writing a
+// random nonce prefix to w.
+func newEncryptWriter(w io.Writer, aead cipher.AEAD, dgst digest.Digest) (*encryptWriter, error) {
+	prefix := make([]byte, aead.NonceSize()-5)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	if _, err := w.Write(prefix); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{
+		w:      w,
+		aead:   aead,
+		ad:     []byte(dgst),
+		prefix: prefix,
+		nonce:  make([]byte, aead.NonceSize()),
+		buf:    make([]byte, 0, encryptedSegmentSize),
+		sealed: make([]byte, 0, encryptedSegmentSize+aead.Overhead()),
+	}, nil
+}
+
+// Write encrypts p into the underlying writer. A full segment is sealed only
+// when more content is written, since the last segment is sealed differently.
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		if len(w.buf) == encryptedSegmentSize {
+			if err := w.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):encryptedSegmentSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close seals the last segment.
+func (w *encryptWriter) Close() error {
+	return w.seal(true)
+}
+
+// seal seals the buffered segment into the underlying writer.
+func (w *encryptWriter) seal(last bool) error {
+	if w.counter == math.MaxUint32 {
+		return errors.New("too many segments to encrypt")
+	}
+	segmentNonce(w.nonce, w.prefix, w.counter, last)
+	w.sealed = w.aead.Seal(w.sealed[:0], w.nonce, w.buf, w.ad)
+	if _, err := w.w.Write(w.sealed); err != nil {
+		return err
+	}
+	w.counter++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// decryptReader decrypts an encrypted blob, and verifies the size and the
+// digest of the plaintext against the descriptor on reaching the end.
+type decryptReader struct {
+	r        *bufio.Reader
+	aead     cipher.AEAD
+	target   ocispec.Descriptor
+	verifier digest.Verifier
+	prefix   []byte
+	nonce    []byte
+	sealed   []byte
+	plain    []byte
+	counter  uint32
+	read     int64
+	last     bool
+	err      error
+}
+
+// newDecryptReader returns a decryptReader reading the encrypted blob of the
+// target from r.
+func newDecryptReader(r io.Reader, aead cipher.AEAD, target ocispec.Descriptor) *decryptReader {
+	return &decryptReader{
+		r:        bufio.NewReader(r),
+		aead:     aead,
+		target:   target,
+		verifier: target.Digest.Verifier(),
+		nonce:    make([]byte, aead.NonceSize()),
+		sealed:   make([]byte, encryptedSegmentSize+aead.Overhead()),
+	}
+}
+
+// Read reads the decrypted content into p.
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.last {
+			r.err = r.verify()
+			return 0, r.err
+		}
+		if err := r.open(); err != nil {
+			r.err = err
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// open reads and opens the next segment.
+func (r *decryptReader) open() error {
+	if r.prefix == nil {
+		r.prefix = make([]byte, r.aead.NonceSize()-5)
+		if _, err := io.ReadFull(r.r, r.prefix); err != nil {
+			return r.decryptError(io.ErrUnexpectedEOF)
+		}
+	}
+	n, err := io.ReadFull(r.r, r.sealed)
+	switch err {
+	case nil:
+		// a full segment is the last one if nothing follows
+		if _, err := r.r.Peek(1); err == io.EOF {
+			r.last = true
+		} else if err != nil {
+			return err
+		}
+	case io.ErrUnexpectedEOF:
+		r.last = true
+	case io.EOF:
+		return r.decryptError(io.ErrUnexpectedEOF)
+	default:
+		return err
+	}
+	segmentNonce(r.nonce, r.prefix, r.counter, r.last)
+	plain, err := r.aead.Open(r.sealed[:0], r.nonce, r.sealed[:n], []byte(r.target.Digest))
+	if err != nil {
+		return r.decryptError(err)
+	}
+	r.counter++
+	r.read += int64(len(plain))
+	if r.read > r.target.Size {
+		return fmt.Errorf("%s: %s: %w", r.target.Digest, r.target.MediaType, content.ErrTrailingData)
+	}
+	if _, err := r.verifier.Write(plain); err != nil {
+		return err
+	}
+	r.plain = plain
+	return nil
+}
+
+// verify verifies the decrypted content against the descriptor.
+func (r *decryptReader) verify() error {
+	if r.read != r.target.Size {
+		return fmt.Errorf("%s: %s: %w", r.target.Digest, r.target.MediaType, io.ErrUnexpectedEOF)
+	}
+	if !r.verifier.Verified() {
+		return fmt.Errorf("%s: %s: %w", r.target.Digest, r.target.MediaType, content.ErrMismatchedDigest)
+	}
+	return io.EOF
+}
+
+// decryptError returns the error of failing to decrypt the blob.
+func (r *decryptReader) decryptError(err error) error {
+	return fmt.Errorf("%s: %s: failed to decrypt blob: %w", r.target.Digest, r.target.MediaType, err)
+}
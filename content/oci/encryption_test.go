@@ -0,0 +1,267 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// newTestAEAD returns an AES-GCM AEAD with a random key.
+func newTestAEAD(t *testing.T) cipher.AEAD {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aead
+}
+
+func TestEncryptedStorage(t *testing.T) {
+	aead := newTestAEAD(t)
+	tempDir := t.TempDir()
+	s, err := NewEncryptedStorage(tempDir, aead)
+	if err != nil {
+		t.Fatal("NewEncryptedStorage() error =", err)
+	}
+	ctx := context.Background()
+
+	for _, size := range []int{0, 1, encryptedSegmentSize - 1, encryptedSegmentSize, encryptedSegmentSize + 1, 3 * encryptedSegmentSize} {
+		blob := make([]byte, size)
+		if _, err := rand.Read(blob); err != nil {
+			t.Fatal(err)
+		}
+		desc := content.NewDescriptorFromBytes("test", blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatalf("Storage.Push(%d bytes) error = %v", size, err)
+		}
+
+		exists, err := s.Exists(ctx, desc)
+		if err != nil {
+			t.Fatal("Storage.Exists() error =", err)
+		}
+		if !exists {
+			t.Errorf("Storage.Exists() = %v, want %v", exists, true)
+		}
+		got, err := content.FetchAll(ctx, s, desc)
+		if err != nil {
+			t.Fatalf("FetchAll(%d bytes) error = %v", size, err)
+		}
+		if !bytes.Equal(got, blob) {
+			t.Errorf("FetchAll(%d bytes) content mismatch", size)
+		}
+
+		// verify the blob is encrypted at rest
+		path, err := s.blobPath(desc.Digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size > 0 && bytes.Contains(stored, blob) {
+			t.Errorf("blob of %d bytes is stored in plaintext", size)
+		}
+		if want := encryptedSize(aead, int64(size)); int64(len(stored)) != want {
+			t.Errorf("stored size = %d, want %d", len(stored), want)
+		}
+		plainSize, err := s.blobSize(path, desc.Digest)
+		if err != nil {
+			t.Fatal("Storage.blobSize() error =", err)
+		}
+		if plainSize != int64(size) {
+			t.Errorf("Storage.blobSize() = %d, want %d", plainSize, size)
+		}
+	}
+
+	// test push verifying the plaintext
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	if err := s.Push(ctx, desc, bytes.NewReader([]byte("hello earth"))); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Storage.Push() error = %v, want %v", err, content.ErrMismatchedDigest)
+	}
+	exists, err := s.Exists(ctx, desc)
+	if err != nil {
+		t.Fatal("Storage.Exists() error =", err)
+	}
+	if exists {
+		t.Errorf("Storage.Exists() = %v, want %v", exists, false)
+	}
+}
+
+func TestEncryptedStorage_Tampered(t *testing.T) {
+	aead := newTestAEAD(t)
+	tempDir := t.TempDir()
+	s, err := NewEncryptedStorage(tempDir, aead)
+	if err != nil {
+		t.Fatal("NewEncryptedStorage() error =", err)
+	}
+	ctx := context.Background()
+	blob := bytes.Repeat([]byte("foo"), encryptedSegmentSize)
+	desc := content.NewDescriptorFromBytes("test", blob)
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Storage.Push() error =", err)
+	}
+	path, err := s.blobPath(desc.Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("wrong key", func(t *testing.T) {
+		other, err := NewEncryptedStorage(tempDir, newTestAEAD(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := content.FetchAll(ctx, other, desc); err == nil {
+			t.Error("FetchAll() error = nil, wantErr true")
+		}
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		modified := append([]byte(nil), stored...)
+		modified[len(modified)/2] ^= 0xff
+		if err := os.WriteFile(path, modified, 0666); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := content.FetchAll(ctx, s, desc); err == nil {
+			t.Error("FetchAll() error = nil, wantErr true")
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		// drop the last segment
+		truncated := stored[:encryptedSize(aead, encryptedSegmentSize)]
+		if err := os.WriteFile(path, truncated, 0666); err != nil {
+			t.Fatal(err)
+		}
+		rc, err := s.Fetch(ctx, desc)
+		if err != nil {
+			t.Fatal("Storage.Fetch() error =", err)
+		}
+		defer rc.Close()
+		if _, err := io.ReadAll(rc); err == nil {
+			t.Error("ReadAll() error = nil, wantErr true")
+		}
+	})
+}
+
+func TestNewEncryptedStorage_NonceSize(t *testing.T) {
+	block, err := aes.NewCipher(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCMWithNonceSize(block, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEncryptedStorage(t.TempDir(), aead); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("NewEncryptedStorage() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
+
+func TestNewEncrypted(t *testing.T) {
+	aead := newTestAEAD(t)
+	tempDir := t.TempDir()
+	ctx := context.Background()
+	s, err := NewEncrypted(ctx, tempDir, aead)
+	if err != nil {
+		t.Fatal("NewEncrypted() error =", err)
+	}
+
+	layer := []byte("hello world")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	if err := s.Push(ctx, layerDesc, bytes.NewReader(layer)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"` + ocispec.MediaTypeImageManifest + `","config":` +
+		`{"mediaType":"application/vnd.oci.empty.v1+json","digest":"` + content.DescriptorEmptyJSON.Digest.String() + `","size":2},` +
+		`"layers":[{"mediaType":"` + layerDesc.MediaType + `","digest":"` + layerDesc.Digest.String() + `","size":11}]}`)
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest)
+	if err := content.PushEmptyJSON(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Push(ctx, manifestDesc, bytes.NewReader(manifest)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	if err := s.Tag(ctx, manifestDesc, "foobar"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+
+	// reopen the store with the same key
+	s, err = NewEncrypted(ctx, tempDir, aead)
+	if err != nil {
+		t.Fatal("NewEncrypted() error =", err)
+	}
+	got, err := s.Resolve(ctx, "foobar")
+	if err != nil {
+		t.Fatal("Store.Resolve() error =", err)
+	}
+	if !content.Equal(got, manifestDesc) {
+		t.Errorf("Store.Resolve() = %v, want %v", got, manifestDesc)
+	}
+	successors, err := content.Successors(ctx, s, manifestDesc)
+	if err != nil {
+		t.Fatal("Successors() error =", err)
+	}
+	if len(successors) != 2 || !content.Equal(successors[1], layerDesc) {
+		t.Errorf("Successors() = %v, want config and %v", successors, layerDesc)
+	}
+
+	// the manifests are sniffed from the decrypted blobs
+	var manifests []ocispec.Descriptor
+	if err := s.Manifests(ctx, func(descs []ocispec.Descriptor) error {
+		manifests = append(manifests, descs...)
+		return nil
+	}); err != nil {
+		t.Fatal("Store.Manifests() error =", err)
+	}
+	if len(manifests) != 1 || !content.Equal(manifests[0], manifestDesc) {
+		t.Errorf("Store.Manifests() = %v, want %v", manifests, []ocispec.Descriptor{manifestDesc})
+	}
+	gotLayer, err := s.ResolvePrefix(ctx, "", layerDesc.Digest.Encoded()[:12])
+	if err != nil {
+		t.Fatal("Store.ResolvePrefix() error =", err)
+	}
+	if gotLayer.Size != layerDesc.Size {
+		t.Errorf("Store.ResolvePrefix() size = %d, want %d", gotLayer.Size, layerDesc.Size)
+	}
+}
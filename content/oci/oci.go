@@ -19,17 +19,26 @@ package oci
 
 import (
 	"context"
+	"crypto/cipher"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 
+	"github.com/opencontainers/go-digest"
 	specs "github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
 	"oras.land/oras-go/v2/internal/graph"
 	"oras.land/oras-go/v2/internal/resolver"
 )
@@ -39,6 +48,15 @@ import (
 // Reference: https://github.com/opencontainers/image-spec/blob/master/image-layout.md#indexjson-file
 const ociImageIndexFile = "index.json"
 
+// ErrAmbiguousPrefix is returned by Store.ResolvePrefix() when more than one
+// blob in the store shares the digest prefix.
+var ErrAmbiguousPrefix = errors.New("ambiguous digest prefix")
+
+// encodedPrefixRegexp matches the valid prefixes of the encoded portion of a
+// digest.
+// Reference: https://github.com/opencontainers/image-spec/blob/main/descriptor.md#digests
+var encodedPrefixRegexp = regexp.MustCompile(`^[a-zA-Z0-9=_-]+$`)
+
 // Store implements `oras.Target`, and represents a content store
 // based on file system with the OCI-Image layout.
 // Reference: https://github.com/opencontainers/image-spec/blob/master/image-layout.md
@@ -48,16 +66,27 @@ type Store struct {
 	// If AutoSaveIndex is set to true, the OCI store will automatically call
 	// this method on each Tag() call.
 	// If AutoSaveIndex is set to false, it's the caller's responsibility
-	// to manually call SaveIndex() when needed.
+	// to manually call SaveIndex() or Flush() when needed. The index is
+	// then saved once for a batch of Tag() calls.
+	// The copy functions in the oras package call Flush() on completion.
 	// Default value: true.
 	AutoSaveIndex bool
 	root          string
 	indexPath     string
 
-	storage  content.Storage
+	storage  *Storage
 	resolver *resolver.Memory
 	graph    *graph.Memory
 	index    *ocispec.Index
+
+	// predecessorIndexPath is the path of the persisted predecessor index.
+	predecessorIndexPath string
+	// predecessorIndexLock serializes writes to the persisted predecessor
+	// index.
+	predecessorIndexLock sync.Mutex
+	// predecessorIndexDisabled is set if the persisted predecessor index
+	// failed to be written, and is no longer written.
+	predecessorIndexDisabled bool
 }
 
 // New creates a new OCI store with context.Background().
@@ -67,13 +96,32 @@ func New(root string) (*Store, error) {
 
 // NewWithContext creates a new OCI store.
 func NewWithContext(ctx context.Context, root string) (*Store, error) {
+	return newStore(ctx, root, NewStorage(root))
+}
+
+// NewEncrypted creates a new OCI store, where the blobs are encrypted at rest
+// with aead, which is useful for caching sensitive content locally.
+// The index and the tags are stored in plaintext.
+// See also `NewEncryptedStorage()`.
+func NewEncrypted(ctx context.Context, root string, aead cipher.AEAD) (*Store, error) {
+	storage, err := NewEncryptedStorage(root, aead)
+	if err != nil {
+		return nil, err
+	}
+	return newStore(ctx, root, storage)
+}
+
+// newStore creates a new OCI store with the storage.
+func newStore(ctx context.Context, root string, storage *Storage) (*Store, error) {
 	store := &Store{
 		AutoSaveIndex: true,
 		root:          root,
 		indexPath:     filepath.Join(root, ociImageIndexFile),
-		storage:       NewStorage(root),
+		storage:       storage,
 		resolver:      resolver.NewMemory(),
 		graph:         graph.NewMemory(),
+
+		predecessorIndexPath: filepath.Join(root, predecessorIndexFile),
 	}
 
 	if err := ensureDir(root); err != nil {
@@ -102,7 +150,17 @@ func (s *Store) Push(ctx context.Context, expected ocispec.Descriptor, reader io
 		return err
 	}
 
-	return s.graph.Index(ctx, s.storage, expected)
+	successors, err := s.indexNode(ctx, expected)
+	if err != nil {
+		return err
+	}
+	if len(successors) > 0 {
+		s.appendPredecessorRecords([]predecessorRecord{{
+			Node:       expected,
+			Successors: successors,
+		}})
+	}
+	return nil
 }
 
 // Exists returns true if the described content exists.
@@ -124,7 +182,7 @@ func (s *Store) Tag(ctx context.Context, desc ocispec.Descriptor, reference stri
 		return err
 	}
 	if !exists {
-		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrNotFound)
+		return &errdef.NotFoundError{Descriptor: desc}
 	}
 
 	if desc.Annotations == nil {
@@ -151,13 +209,139 @@ func (s *Store) Resolve(ctx context.Context, reference string) (ocispec.Descript
 	return s.resolver.Resolve(ctx, reference)
 }
 
+// Tags lists the tags of the store, which are the
+// `org.opencontainers.image.ref.name` annotations of the manifests in the
+// index, excluding the references by digest.
+// The tags are sorted lexically and passed to fn in a single page.
+// If `last` is NOT empty, the tags start after the tag specified by `last`.
+// Tags implements the tag listing of registry.Repository, so that the store
+// can be listed in the same way as a remote repository.
+// See also `Tags()` in the registry package.
+func (s *Store) Tags(ctx context.Context, last string, fn func(tags []string) error) error {
+	return listTags(s.resolver.Map(), last, fn)
+}
+
 // Predecessors returns the nodes directly pointing to the current node.
 // Predecessors returns nil without error if the node does not exists in the
 // store.
+// The predecessors are indexed on Push, and the index is persisted in the
+// root directory so that it is restored without fetching the manifests when
+// the store is opened again. The persisted index is rebuilt if it is missing,
+// corrupted, or stale.
 func (s *Store) Predecessors(ctx context.Context, node ocispec.Descriptor) ([]ocispec.Descriptor, error) {
 	return s.graph.Predecessors(ctx, node)
 }
 
+// Manifests lists all the manifests in the store, including the untagged
+// ones, by scanning the blob directory.
+// A blob is recognized as a manifest if it is a JSON document no larger than
+// 4 MiB with a manifest media type. Blobs without the `mediaType` field are
+// recognized as OCI image manifests or indexes by their structure.
+// The manifests are passed to fn in batches, one batch per digest algorithm.
+func (s *Store) Manifests(ctx context.Context, fn func(manifests []ocispec.Descriptor) error) error {
+	blobRoot := filepath.Join(s.root, "blobs")
+	algDirs, err := os.ReadDir(blobRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, algDir := range algDirs {
+		if !algDir.IsDir() {
+			continue
+		}
+		algPath := filepath.Join(blobRoot, algDir.Name())
+		entries, err := os.ReadDir(algPath)
+		if err != nil {
+			return err
+		}
+		var manifests []ocispec.Descriptor
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				continue
+			}
+			dgst := digest.NewDigestFromEncoded(digest.Algorithm(algDir.Name()), entry.Name())
+			desc, ok, err := sniffManifest(s.storage, filepath.Join(algPath, entry.Name()), dgst)
+			if err != nil {
+				return err
+			}
+			if ok {
+				manifests = append(manifests, desc)
+			}
+		}
+		if len(manifests) == 0 {
+			continue
+		}
+		if err := fn(manifests); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolvePrefix resolves the blob in the store whose digest of the algorithm
+// alg has the encoded portion starting with prefix, e.g. "b94d27" for
+// "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+// by scanning the blob directory.
+// If alg is empty, digest.Canonical is used.
+// The media type of the returned descriptor is inferred for manifests, and is
+// "application/octet-stream" for other blobs.
+// Returns ErrAmbiguousPrefix if more than one blob matches the prefix, and
+// errdef.ErrNotFound if none matches.
+func (s *Store) ResolvePrefix(ctx context.Context, alg digest.Algorithm, prefix string) (ocispec.Descriptor, error) {
+	if alg == "" {
+		alg = digest.Canonical
+	}
+	if !encodedPrefixRegexp.MatchString(prefix) {
+		return ocispec.Descriptor{}, fmt.Errorf("%s:%s: invalid digest prefix: %w", alg, prefix, errdef.ErrInvalidDigest)
+	}
+
+	algPath := filepath.Join(s.root, "blobs", alg.String())
+	entries, err := os.ReadDir(algPath)
+	if err != nil && !os.IsNotExist(err) {
+		return ocispec.Descriptor{}, err
+	}
+	var match string
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+		if match != "" {
+			return ocispec.Descriptor{}, fmt.Errorf("%s:%s: %w", alg, prefix, ErrAmbiguousPrefix)
+		}
+		match = entry.Name()
+	}
+	if match == "" {
+		return ocispec.Descriptor{}, &errdef.NotFoundError{Reference: alg.String() + ":" + prefix}
+	}
+
+	path := filepath.Join(algPath, match)
+	dgst := digest.NewDigestFromEncoded(alg, match)
+	desc, ok, err := sniffManifest(s.storage, path, dgst)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if ok {
+		return desc, nil
+	}
+	size, err := s.storage.blobSize(path, dgst)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return ocispec.Descriptor{
+		MediaType: "application/octet-stream",
+		Digest:    dgst,
+		Size:      size,
+	}, nil
+}
+
 // ensureOCILayoutFile ensures the `oci-layout` file.
 func (s *Store) ensureOCILayoutFile() error {
 	layoutFilePath := filepath.Join(s.root, ocispec.ImageLayoutFile)
@@ -217,14 +401,11 @@ func (s *Store) loadIndex(ctx context.Context) error {
 				return err
 			}
 		}
-
-		// traverse the whole DAG and index predecessors for all the nodes.
-		if err := s.graph.IndexAll(ctx, s.storage, desc); err != nil {
-			return err
-		}
 	}
 
-	return nil
+	// restore the persisted predecessor index, and traverse the whole DAG to
+	// index predecessors for all the nodes missing in the persisted index.
+	return s.loadPredecessors(ctx, s.index.Manifests)
 }
 
 // SaveIndex writes the `index.json` file to the file system.
@@ -249,6 +430,162 @@ func (s *Store) SaveIndex() error {
 	return os.WriteFile(s.indexPath, indexJSON, 0666)
 }
 
+// Flush saves the index file, persisting the tags buffered in memory if
+// AutoSaveIndex is set to false.
+// Flush implements content.Flusher.
+func (s *Store) Flush(_ context.Context) error {
+	return s.SaveIndex()
+}
+
+// GC removes the blobs, which are not reachable from the manifests in the
+// index, from the blob directory.
+// Blobs shared by multiple manifests are kept as long as any of the manifests
+// is reachable.
+// GC must not be called while any other operation is in flight.
+func (s *Store) GC(ctx context.Context) error {
+	_, err := s.GCWithReport(ctx)
+	return err
+}
+
+// GCWithReport is the same as GC, and reports the number of bytes reclaimed.
+func (s *Store) GCWithReport(ctx context.Context) (int64, error) {
+	// the roots include both the saved and the unsaved index entries.
+	roots := append([]ocispec.Descriptor{}, s.index.Manifests...)
+	for _, desc := range s.resolver.Map() {
+		roots = append(roots, desc)
+	}
+
+	// mark: collect the blobs reachable from the roots.
+	reachable := make(map[digest.Digest]struct{})
+	stack := append([]ocispec.Descriptor{}, roots...)
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := reachable[node.Digest]; ok {
+			continue
+		}
+		reachable[node.Digest] = struct{}{}
+		successors, err := content.Successors(ctx, s.storage, node)
+		if err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				// dangling reference in a partial layout
+				continue
+			}
+			return 0, err
+		}
+		stack = append(stack, successors...)
+	}
+
+	// sweep: remove the blobs not reachable.
+	var reclaimed int64
+	blobRoot := filepath.Join(s.root, "blobs")
+	algDirs, err := os.ReadDir(blobRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	for _, algDir := range algDirs {
+		if !algDir.IsDir() {
+			continue
+		}
+		algPath := filepath.Join(blobRoot, algDir.Name())
+		entries, err := os.ReadDir(algPath)
+		if err != nil {
+			return reclaimed, err
+		}
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return reclaimed, err
+			}
+			if entry.IsDir() {
+				continue
+			}
+			dgst := digest.NewDigestFromEncoded(digest.Algorithm(algDir.Name()), entry.Name())
+			if _, ok := reachable[dgst]; ok {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return reclaimed, err
+			}
+			if err := os.Remove(filepath.Join(algPath, entry.Name())); err != nil {
+				return reclaimed, fmt.Errorf("failed to remove blob %s: %w", dgst, err)
+			}
+			reclaimed += info.Size()
+		}
+	}
+
+	// re-index predecessors since the removed nodes are no longer available.
+	if err := s.rebuildPredecessors(ctx, roots); err != nil && !errors.Is(err, errdef.ErrNotFound) {
+		return reclaimed, err
+	}
+	return reclaimed, nil
+}
+
+// maxManifestBytes is the size limit of the blobs inspected by Manifests().
+const maxManifestBytes = 4 * 1024 * 1024 // 4 MiB
+
+// sniffManifest reads the blob at path from the storage and returns its
+// descriptor if the blob is a manifest.
+func sniffManifest(storage *Storage, path string, dgst digest.Digest) (ocispec.Descriptor, bool, error) {
+	data, ok, err := storage.readBlob(path, dgst, maxManifestBytes)
+	if err != nil || !ok {
+		return ocispec.Descriptor{}, false, err
+	}
+
+	var manifest struct {
+		MediaType string          `json:"mediaType"`
+		Config    json.RawMessage `json:"config"`
+		Manifests json.RawMessage `json:"manifests"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		// not a JSON object
+		return ocispec.Descriptor{}, false, nil
+	}
+	mediaType := manifest.MediaType
+	if mediaType == "" {
+		switch {
+		case manifest.Manifests != nil:
+			mediaType = ocispec.MediaTypeImageIndex
+		case manifest.Config != nil:
+			mediaType = ocispec.MediaTypeImageManifest
+		}
+	}
+	switch mediaType {
+	case docker.MediaTypeManifest, docker.MediaTypeManifestList,
+		ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageIndex,
+		ocispec.MediaTypeArtifactManifest, artifactspec.MediaTypeArtifactManifest:
+		return ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    dgst,
+			Size:      int64(len(data)),
+		}, true, nil
+	}
+	return ocispec.Descriptor{}, false, nil
+}
+
+// listTags passes the sorted tags in refMap after last to fn.
+func listTags(refMap map[string]ocispec.Descriptor, last string, fn func(tags []string) error) error {
+	var tags []string
+	for ref := range refMap {
+		if _, err := digest.Parse(ref); err == nil {
+			// skip the references by digest
+			continue
+		}
+		if last != "" && ref <= last {
+			continue
+		}
+		tags = append(tags, ref)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	sort.Strings(tags)
+	return fn(tags)
+}
+
 // validateReference validates ref against desc.
 func validateReference(ref string) error {
 	if ref == "" {
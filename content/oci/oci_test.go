@@ -39,6 +39,7 @@ import (
 	"oras.land/oras-go/v2/content/memory"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/cas"
+	"oras.land/oras-go/v2/registry"
 )
 
 // storageTracker tracks storage API counts.
@@ -72,6 +73,12 @@ func TestStoreInterface(t *testing.T) {
 	if _, ok := store.(content.PredecessorFinder); !ok {
 		t.Error("&Store{} does not conform content.PredecessorFinder")
 	}
+	if _, ok := store.(content.Flusher); !ok {
+		t.Error("&Store{} does not conform content.Flusher")
+	}
+	if _, ok := store.(registry.TagLister); !ok {
+		t.Error("&Store{} does not conform registry.TagLister")
+	}
 }
 
 func TestStore_Success(t *testing.T) {
@@ -345,6 +352,13 @@ func TestStore_ContentNotFound(t *testing.T) {
 	if !errors.Is(err, errdef.ErrNotFound) {
 		t.Errorf("Store.Fetch() error = %v, want %v", err, errdef.ErrNotFound)
 	}
+	var notFound *errdef.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Store.Fetch() error = %v, want *errdef.NotFoundError", err)
+	}
+	if !reflect.DeepEqual(notFound.Descriptor, desc) {
+		t.Errorf("NotFoundError.Descriptor = %v, want %v", notFound.Descriptor, desc)
+	}
 }
 
 func TestStore_ContentAlreadyExists(t *testing.T) {
@@ -408,6 +422,13 @@ func TestStore_TagNotFound(t *testing.T) {
 	if !errors.Is(err, errdef.ErrNotFound) {
 		t.Errorf("Store.Resolve() error = %v, want %v", err, errdef.ErrNotFound)
 	}
+	var notFound *errdef.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Store.Resolve() error = %v, want *errdef.NotFoundError", err)
+	}
+	if notFound.Reference != ref {
+		t.Errorf("NotFoundError.Reference = %v, want %v", notFound.Reference, ref)
+	}
 }
 
 func TestStore_TagUnknownContent(t *testing.T) {
@@ -740,6 +761,522 @@ func TestStore_Predecessors(t *testing.T) {
 	}
 }
 
+func TestStore_GC(t *testing.T) {
+	tempDir := t.TempDir()
+	s, err := New(tempDir)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	ctx := context.Background()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("bar"))     // Blob 2
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("hello"))   // Blob 3
+	generateManifest(descs[0], descs[1:3]...)                  // Blob 4
+	generateManifest(descs[0], descs[2:4]...)                  // Blob 5
+
+	for i := range blobs {
+		if err := s.Push(ctx, descs[i], bytes.NewReader(blobs[i])); err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+	// only Blob 4 is tagged, which shares Blob 0 and Blob 2 with Blob 5.
+	if err := s.Tag(ctx, descs[4], "foobar"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+
+	reclaimed, err := s.GCWithReport(ctx)
+	if err != nil {
+		t.Fatal("Store.GCWithReport() error =", err)
+	}
+	if want := descs[3].Size + descs[5].Size; reclaimed != want {
+		t.Errorf("Store.GCWithReport() = %v, want %v", reclaimed, want)
+	}
+
+	wantExists := []bool{true, true, true, false, true, false}
+	for i, want := range wantExists {
+		exists, err := s.Exists(ctx, descs[i])
+		if err != nil {
+			t.Fatalf("Store.Exists(%d) error = %v", i, err)
+		}
+		if exists != want {
+			t.Errorf("Store.Exists(%d) = %v, want %v", i, exists, want)
+		}
+	}
+
+	// predecessors of the removed blobs should be removed
+	preds, err := s.Predecessors(ctx, descs[2])
+	if err != nil {
+		t.Fatal("Store.Predecessors() error =", err)
+	}
+	if len(preds) != 1 || preds[0].Digest != descs[4].Digest {
+		t.Errorf("Store.Predecessors() = %v, want %v", preds, descs[4:5])
+	}
+
+	// GC again should reclaim nothing
+	if err := s.GC(ctx); err != nil {
+		t.Fatal("Store.GC() error =", err)
+	}
+	for i, want := range wantExists {
+		exists, err := s.Exists(ctx, descs[i])
+		if err != nil {
+			t.Fatalf("Store.Exists(%d) error = %v", i, err)
+		}
+		if exists != want {
+			t.Errorf("Store.Exists(%d) = %v, want %v", i, exists, want)
+		}
+	}
+}
+
+func TestStore_Manifests(t *testing.T) {
+	tempDir := t.TempDir()
+	s, err := New(tempDir)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	ctx := context.Background()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	generateIndex := func(manifests ...ocispec.Descriptor) {
+		index := ocispec.Index{
+			MediaType: ocispec.MediaTypeImageIndex,
+			Manifests: manifests,
+		}
+		indexJSON, err := json.Marshal(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageIndex, indexJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("{}"))  // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))  // Blob 1
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("bar"))  // Blob 2
+	generateManifest(descs[0], descs[1])                    // Blob 3
+	generateManifest(descs[0], descs[2])                    // Blob 4
+	generateIndex(descs[3])                                 // Blob 5
+	appendBlob("application/json", []byte(`{"foo":"bar"}`)) // Blob 6
+
+	for i := range blobs {
+		if err := s.Push(ctx, descs[i], bytes.NewReader(blobs[i])); err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+	// only Blob 5 is tagged, leaving Blob 4 dangling.
+	if err := s.Tag(ctx, descs[5], "foobar"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+
+	var got []ocispec.Descriptor
+	if err := s.Manifests(ctx, func(manifests []ocispec.Descriptor) error {
+		got = append(got, manifests...)
+		return nil
+	}); err != nil {
+		t.Fatal("Store.Manifests() error =", err)
+	}
+	want := []ocispec.Descriptor{descs[3], descs[4], descs[5]}
+	if !equalDescriptorSet(got, want) {
+		t.Errorf("Store.Manifests() = %v, want %v", got, want)
+	}
+
+	// errors returned by fn should be propagated
+	errStop := errors.New("stop")
+	if err := s.Manifests(ctx, func(manifests []ocispec.Descriptor) error {
+		return errStop
+	}); !errors.Is(err, errStop) {
+		t.Errorf("Store.Manifests() error = %v, want %v", err, errStop)
+	}
+}
+
+func TestStore_Tags(t *testing.T) {
+	tempDir := t.TempDir()
+	s, err := New(tempDir)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	ctx := context.Background()
+
+	manifest := []byte(`{"layers":[]}`)
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest)
+	if err := s.Push(ctx, desc, bytes.NewReader(manifest)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	for _, ref := range []string{"v2", "latest", "v1", desc.Digest.String()} {
+		if err := s.Tag(ctx, desc, ref); err != nil {
+			t.Fatal("Store.Tag() error =", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		last string
+		want []string
+	}{
+		{
+			name: "all tags",
+			want: []string{"latest", "v1", "v2"},
+		},
+		{
+			name: "tags after last",
+			last: "v1",
+			want: []string{"v2"},
+		},
+		{
+			name: "no tags after last",
+			last: "v2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			if err := s.Tags(ctx, tt.last, func(tags []string) error {
+				got = append(got, tags...)
+				return nil
+			}); err != nil {
+				t.Fatal("Store.Tags() error =", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Store.Tags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// test listing the tags of the reopened store
+	rs, err := NewFromFS(ctx, os.DirFS(tempDir))
+	if err != nil {
+		t.Fatal("NewFromFS() error =", err)
+	}
+	got, err := registry.Tags(ctx, rs)
+	if err != nil {
+		t.Fatal("registry.Tags() error =", err)
+	}
+	if want := []string{"latest", "v1", "v2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("registry.Tags() = %v, want %v", got, want)
+	}
+
+	// errors returned by fn should be propagated
+	errStop := errors.New("stop")
+	if err := s.Tags(ctx, "", func(tags []string) error {
+		return errStop
+	}); !errors.Is(err, errStop) {
+		t.Errorf("Store.Tags() error = %v, want %v", err, errStop)
+	}
+}
+
+func TestStore_ResolvePrefix(t *testing.T) {
+	tempDir := t.TempDir()
+	s, err := New(tempDir)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	ctx := context.Background()
+
+	// push enough blobs so that at least two of them share the first
+	// character of the encoded digest
+	var descs []ocispec.Descriptor
+	for i := 0; i < 17; i++ {
+		blob := []byte(fmt.Sprintf("blob %d", i))
+		desc := content.NewDescriptorFromBytes("test", blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Store.Push() error =", err)
+		}
+		descs = append(descs, desc)
+	}
+	manifest := []byte(`{"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[]}`)
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest)
+	if err := s.Push(ctx, manifestDesc, bytes.NewReader(manifest)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+
+	// test resolving unique prefixes
+	for _, desc := range append(descs, manifestDesc) {
+		got, err := s.ResolvePrefix(ctx, "", desc.Digest.Encoded()[:16])
+		if err != nil {
+			t.Fatalf("Store.ResolvePrefix() error = %v", err)
+		}
+		want := desc
+		if want.MediaType == "test" {
+			want.MediaType = "application/octet-stream"
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Store.ResolvePrefix() = %v, want %v", got, want)
+		}
+	}
+
+	// test resolving an ambiguous prefix
+	seen := make(map[string]bool)
+	var ambiguous string
+	for _, desc := range descs {
+		prefix := desc.Digest.Encoded()[:1]
+		if seen[prefix] {
+			ambiguous = prefix
+			break
+		}
+		seen[prefix] = true
+	}
+	_, err = s.ResolvePrefix(ctx, digest.SHA256, ambiguous)
+	if !errors.Is(err, ErrAmbiguousPrefix) {
+		t.Errorf("Store.ResolvePrefix() error = %v, want %v", err, ErrAmbiguousPrefix)
+	}
+
+	// test resolving an unknown prefix
+	unknown := strings.Repeat("0", 32)
+	_, err = s.ResolvePrefix(ctx, digest.SHA256, unknown)
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.ResolvePrefix() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// test resolving an unknown algorithm
+	_, err = s.ResolvePrefix(ctx, digest.SHA512, "abc")
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.ResolvePrefix() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// test resolving invalid prefixes
+	for _, prefix := range []string{"", "../index.json", "ab/cd"} {
+		_, err = s.ResolvePrefix(ctx, digest.SHA256, prefix)
+		if !errors.Is(err, errdef.ErrInvalidDigest) {
+			t.Errorf("Store.ResolvePrefix(%q) error = %v, want %v", prefix, err, errdef.ErrInvalidDigest)
+		}
+	}
+}
+
+func TestStore_PersistedPredecessors(t *testing.T) {
+	tempDir := t.TempDir()
+	s, err := New(tempDir)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	ctx := context.Background()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("bar"))     // Blob 2
+	generateManifest(descs[0], descs[1])                       // Blob 3
+	generateManifest(descs[0], descs[2])                       // Blob 4
+
+	for i := range blobs {
+		if err := s.Push(ctx, descs[i], bytes.NewReader(blobs[i])); err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+	// only Blob 3 is tagged
+	if err := s.Tag(ctx, descs[3], "foobar"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+
+	indexPath := filepath.Join(tempDir, predecessorIndexFile)
+	if _, err := os.Stat(indexPath); err != nil {
+		t.Fatalf("predecessor index not persisted: %v", err)
+	}
+
+	wantPredecessors := func(t *testing.T, s *Store, node ocispec.Descriptor, want ...ocispec.Descriptor) {
+		t.Helper()
+		preds, err := s.Predecessors(ctx, node)
+		if err != nil {
+			t.Fatal("Store.Predecessors() error =", err)
+		}
+		var got []digest.Digest
+		for _, pred := range preds {
+			got = append(got, pred.Digest)
+		}
+		var wantDigests []digest.Digest
+		for _, desc := range want {
+			wantDigests = append(wantDigests, desc.Digest)
+		}
+		if !equalDigestSet(got, wantDigests) {
+			t.Errorf("Store.Predecessors() = %v, want %v", got, wantDigests)
+		}
+	}
+
+	// the untagged manifest is restored from the persisted index
+	s, err = New(tempDir)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	wantPredecessors(t, s, descs[0], descs[3], descs[4])
+	wantPredecessors(t, s, descs[2], descs[4])
+
+	// corrupted index is rebuilt from the index file
+	if err := os.WriteFile(indexPath, []byte("corrupted\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	s, err = New(tempDir)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	wantPredecessors(t, s, descs[0], descs[3])
+	wantPredecessors(t, s, descs[1], descs[3])
+
+	// the rebuilt index is persisted
+	s, err = New(tempDir)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	wantPredecessors(t, s, descs[0], descs[3])
+}
+
+func TestStore_PersistedPredecessors_ReadOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	s, err := New(tempDir)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	ctx := context.Background()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	generateManifest(descs[0], descs[1])                       // Blob 2
+
+	for i := range blobs {
+		if err := s.Push(ctx, descs[i], bytes.NewReader(blobs[i])); err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+	if err := s.Tag(ctx, descs[2], "foobar"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+
+	// drop the persisted index to be rebuilt on a read-only layout
+	if err := os.Remove(filepath.Join(tempDir, predecessorIndexFile)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(tempDir, 0555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chmod(tempDir, 0777)
+	})
+
+	s, err = New(tempDir)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	for _, node := range descs[:2] {
+		preds, err := s.Predecessors(ctx, node)
+		if err != nil {
+			t.Fatal("Store.Predecessors() error =", err)
+		}
+		var got []digest.Digest
+		for _, pred := range preds {
+			got = append(got, pred.Digest)
+		}
+		if want := []digest.Digest{descs[2].Digest}; !equalDigestSet(got, want) {
+			t.Errorf("Store.Predecessors() = %v, want %v", got, want)
+		}
+	}
+}
+
+// equalDigestSet returns true if two digest slices have the same elements
+// regardless of the order.
+func equalDigestSet(actual, expected []digest.Digest) bool {
+	if len(actual) != len(expected) {
+		return false
+	}
+	set := make(map[digest.Digest]int)
+	for _, d := range actual {
+		set[d]++
+	}
+	for _, d := range expected {
+		if set[d] == 0 {
+			return false
+		}
+		set[d]--
+	}
+	return true
+}
+
 func TestStore_ExistingStore(t *testing.T) {
 	tempDir := t.TempDir()
 	s, err := New(tempDir)
@@ -1057,6 +1594,50 @@ func TestCopy_MemoryToOCI_FullCopy(t *testing.T) {
 	}
 }
 
+func TestCopy_MemoryToOCI_FlushOnCompletion(t *testing.T) {
+	src := memory.New()
+
+	tempDir := t.TempDir()
+	dst, err := New(tempDir)
+	if err != nil {
+		t.Fatal("OCI.New() error =", err)
+	}
+	// buffer the tags in memory
+	dst.AutoSaveIndex = false
+
+	index := []byte(`{"manifests":[]}`)
+	root := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(index),
+		Size:      int64(len(index)),
+	}
+	ctx := context.Background()
+	if err := src.Push(ctx, root, bytes.NewReader(index)); err != nil {
+		t.Fatal("failed to push test content to src:", err)
+	}
+	ref := "foobar"
+	if err := src.Tag(ctx, root, ref); err != nil {
+		t.Fatal("fail to tag root node", err)
+	}
+
+	if _, err := oras.Copy(ctx, src, ref, dst, "", oras.CopyOptions{}); err != nil {
+		t.Fatalf("Copy() error = %v, wantErr %v", err, false)
+	}
+
+	// the tag should be persisted on completion
+	reopened, err := New(tempDir)
+	if err != nil {
+		t.Fatal("OCI.New() error =", err)
+	}
+	gotDesc, err := reopened.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatal("Store.Resolve() error =", err)
+	}
+	if gotDesc.Digest != root.Digest {
+		t.Errorf("Store.Resolve() = %v, want %v", gotDesc, root)
+	}
+}
+
 func TestCopyGraph_MemoryToOCI_FullCopy(t *testing.T) {
 	src := cas.NewMemory()
 
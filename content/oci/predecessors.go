@@ -0,0 +1,263 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/graph"
+)
+
+// predecessorIndexFile is the file name of the persisted predecessor index.
+// The file is a cache rebuildable from the blobs, and is not part of the OCI
+// Image Layout Specification.
+const predecessorIndexFile = ".oras-predecessors.json"
+
+// maxPredecessorRecordBytes limits the size of a record in the persisted
+// predecessor index.
+const maxPredecessorRecordBytes = 4 * 1024 * 1024 // 4 MiB
+
+// predecessorRecord is a record in the persisted predecessor index, which
+// stores the direct successors of an indexed node.
+// The persisted predecessor index is an append-only log of records, one JSON
+// object per line.
+type predecessorRecord struct {
+	Node       ocispec.Descriptor   `json:"node"`
+	Successors []ocispec.Descriptor `json:"successors"`
+}
+
+// loadPredecessors restores the predecessor index from the persisted index,
+// and indexes the nodes reachable from roots, which are missing in the
+// persisted index.
+// If the persisted index is missing, corrupted, or references content no
+// longer in the store, the predecessor index is rebuilt from roots.
+func (s *Store) loadPredecessors(ctx context.Context, roots []ocispec.Descriptor) error {
+	restored, err := s.restorePredecessors(ctx)
+	if err != nil {
+		return s.rebuildPredecessors(ctx, roots)
+	}
+	var records []predecessorRecord
+	for _, root := range roots {
+		if successors, ok := restored[content.Key(root)]; ok {
+			// re-index the root with the descriptor in the index, which may
+			// carry annotations.
+			if err := s.graph.IndexSuccessors(ctx, root, successors); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.indexAll(ctx, root, &records); err != nil {
+			return err
+		}
+	}
+	s.appendPredecessorRecords(records)
+	return nil
+}
+
+// restorePredecessors restores the predecessor index from the persisted index,
+// and returns the restored successors of the nodes.
+func (s *Store) restorePredecessors(ctx context.Context) (map[content.DescriptorKey][]ocispec.Descriptor, error) {
+	fp, err := os.Open(s.predecessorIndexPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+
+	restored := make(map[content.DescriptorKey][]ocispec.Descriptor)
+	scanner := bufio.NewScanner(fp)
+	scanner.Buffer(nil, maxPredecessorRecordBytes)
+	for scanner.Scan() {
+		var record predecessorRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to decode predecessor index: %w", err)
+		}
+		exists, err := s.storage.Exists(ctx, record.Node)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("stale predecessor index: %s: %s: not found", record.Node.Digest, record.Node.MediaType)
+		}
+		if err := s.graph.IndexSuccessors(ctx, record.Node, record.Successors); err != nil {
+			return nil, err
+		}
+		restored[content.Key(record.Node)] = record.Successors
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// rebuildPredecessors drops the predecessor index, rebuilds it from the nodes
+// reachable from roots, and replaces the persisted index.
+func (s *Store) rebuildPredecessors(ctx context.Context, roots []ocispec.Descriptor) error {
+	s.graph = graph.NewMemory()
+	var records []predecessorRecord
+	for _, root := range roots {
+		if err := s.indexAll(ctx, root, &records); err != nil {
+			return err
+		}
+	}
+	s.writePredecessorIndex(records)
+	return nil
+}
+
+// indexAll indexes predecessors for all the nodes reachable from the given
+// node, which are not yet indexed, and appends the records to be persisted to
+// records.
+func (s *Store) indexAll(ctx context.Context, node ocispec.Descriptor, records *[]predecessorRecord) error {
+	if s.graph.Indexed(node) {
+		return nil
+	}
+	successors, err := s.indexNode(ctx, node)
+	if err != nil {
+		return err
+	}
+	if len(successors) == 0 {
+		// leaf nodes are not persisted as they are indexed without fetching.
+		return nil
+	}
+	*records = append(*records, predecessorRecord{
+		Node:       node,
+		Successors: successors,
+	})
+	for _, successor := range successors {
+		if err := s.indexAll(ctx, successor, records); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexNode indexes predecessors for each direct successor of the given node.
+func (s *Store) indexNode(ctx context.Context, node ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	successors, err := content.Successors(ctx, s.storage, node)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.graph.IndexSuccessors(ctx, node, successors); err != nil {
+		return nil, err
+	}
+	return successors, nil
+}
+
+// appendPredecessorRecords appends the records to the persisted predecessor
+// index in a single write.
+// The persisted index is a cache, so failures are not fatal: the records are
+// kept in memory only, and the persisted index is no longer written, as it
+// would miss the records.
+func (s *Store) appendPredecessorRecords(records []predecessorRecord) {
+	if len(records) == 0 {
+		return
+	}
+	data, err := encodePredecessorRecords(records)
+	if err != nil {
+		s.disablePredecessorIndex()
+		return
+	}
+
+	s.predecessorIndexLock.Lock()
+	defer s.predecessorIndexLock.Unlock()
+	if s.predecessorIndexDisabled {
+		return
+	}
+	fp, err := os.OpenFile(s.predecessorIndexPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		s.predecessorIndexDisabled = true
+		return
+	}
+	_, err = fp.Write(data)
+	if closeErr := fp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		s.predecessorIndexDisabled = true
+		// drop the partially written index, which would be restored as
+		// complete
+		os.Remove(s.predecessorIndexPath)
+	}
+}
+
+// writePredecessorIndex replaces the persisted predecessor index with the
+// records, through a temporary file renamed into place.
+// Failures are not fatal, e.g. on a read-only layout, where the predecessor
+// index is kept in memory only.
+func (s *Store) writePredecessorIndex(records []predecessorRecord) {
+	data, err := encodePredecessorRecords(records)
+	if err != nil {
+		s.disablePredecessorIndex()
+		return
+	}
+
+	s.predecessorIndexLock.Lock()
+	defer s.predecessorIndexLock.Unlock()
+	if err := writeFileAtomic(s.root, s.predecessorIndexPath, data); err != nil {
+		s.predecessorIndexDisabled = true
+		return
+	}
+	s.predecessorIndexDisabled = false
+}
+
+// disablePredecessorIndex stops writing the persisted predecessor index.
+func (s *Store) disablePredecessorIndex() {
+	s.predecessorIndexLock.Lock()
+	defer s.predecessorIndexLock.Unlock()
+	s.predecessorIndexDisabled = true
+}
+
+// encodePredecessorRecords encodes the records into the persisted predecessor
+// index format, one JSON object per line.
+func encodePredecessorRecords(records []predecessorRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return nil, fmt.Errorf("failed to marshal predecessor record: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeFileAtomic writes data to a temporary file in dir, and renames it to
+// path.
+func writeFileAtomic(dir, path string, data []byte) error {
+	fp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := fp.Name()
+	_, err = fp.Write(data)
+	if closeErr := fp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, path)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+func TestReadOnlyStoreInterface(t *testing.T) {
+	var store interface{} = &ReadOnlyStore{}
+	if _, ok := store.(oras.ReadOnlyGraphTarget); !ok {
+		t.Error("&ReadOnlyStore{} does not conform oras.ReadOnlyGraphTarget")
+	}
+	if _, ok := store.(content.TagResolver); !ok {
+		t.Error("&ReadOnlyStore{} does not conform content.TagResolver")
+	}
+	if _, ok := store.(registry.TagLister); !ok {
+		t.Error("&ReadOnlyStore{} does not conform registry.TagLister")
+	}
+	if _, ok := store.(content.Pusher); ok {
+		t.Error("&ReadOnlyStore{} should not conform content.Pusher")
+	}
+}
+
+func TestReadOnlyStore_Tag(t *testing.T) {
+	s := &ReadOnlyStore{}
+	err := s.Tag(context.Background(), ocispec.Descriptor{}, "foobar")
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("ReadOnlyStore.Tag() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
+
+func TestReadOnlyStore(t *testing.T) {
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	manifest := ocispec.Manifest{
+		Config: descs[0],
+		Layers: descs[1:2],
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendBlob(ocispec.MediaTypeImageManifest, manifestJSON) // Blob 2
+
+	ref := "foobar"
+	manifestDesc := descs[2]
+	manifestDesc.Annotations = map[string]string{
+		ocispec.AnnotationRefName: ref,
+	}
+	index := ocispec.Index{
+		Manifests: []ocispec.Descriptor{manifestDesc},
+	}
+	indexJSON, err := json.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layoutJSON, err := json.Marshal(ocispec.ImageLayout{
+		Version: ocispec.ImageLayoutVersion,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		ocispec.ImageLayoutFile: layoutJSON,
+		ociImageIndexFile:       indexJSON,
+	}
+	for i, desc := range descs {
+		files["blobs/"+desc.Digest.Algorithm().String()+"/"+desc.Digest.Encoded()] = blobs[i]
+	}
+
+	// generate the tar archive
+	buf := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buf)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0444,
+			Size:     int64(len(content)),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// generate the file system
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: content}
+	}
+
+	ctx := context.Background()
+	tarStore, err := NewFromTar(ctx, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal("NewFromTar() error =", err)
+	}
+	fsStore, err := NewFromFS(ctx, fsys)
+	if err != nil {
+		t.Fatal("NewFromFS() error =", err)
+	}
+
+	for name, s := range map[string]*ReadOnlyStore{"tar": tarStore, "fs": fsStore} {
+		t.Run(name, func(t *testing.T) {
+			// test resolve
+			gotDesc, err := s.Resolve(ctx, ref)
+			if err != nil {
+				t.Fatal("ReadOnlyStore.Resolve() error =", err)
+			}
+			if !reflect.DeepEqual(gotDesc, manifestDesc) {
+				t.Errorf("ReadOnlyStore.Resolve() = %v, want %v", gotDesc, manifestDesc)
+			}
+			if _, err := s.Resolve(ctx, "unknown"); !errors.Is(err, errdef.ErrNotFound) {
+				t.Errorf("ReadOnlyStore.Resolve() error = %v, wantErr %v", err, errdef.ErrNotFound)
+			}
+
+			// test fetch and exists
+			for i, desc := range descs {
+				got, err := content.FetchAll(ctx, s, desc)
+				if err != nil {
+					t.Fatalf("ReadOnlyStore.Fetch(%d) error = %v", i, err)
+				}
+				if !bytes.Equal(got, blobs[i]) {
+					t.Errorf("ReadOnlyStore.Fetch(%d) = %v, want %v", i, got, blobs[i])
+				}
+				exists, err := s.Exists(ctx, desc)
+				if err != nil {
+					t.Fatalf("ReadOnlyStore.Exists(%d) error = %v", i, err)
+				}
+				if !exists {
+					t.Errorf("ReadOnlyStore.Exists(%d) = %v, want %v", i, exists, true)
+				}
+			}
+			unknown := content.NewDescriptorFromBytes("test", []byte("unknown"))
+			if _, err := s.Fetch(ctx, unknown); !errors.Is(err, errdef.ErrNotFound) {
+				t.Errorf("ReadOnlyStore.Fetch() error = %v, wantErr %v", err, errdef.ErrNotFound)
+			}
+			exists, err := s.Exists(ctx, unknown)
+			if err != nil {
+				t.Fatal("ReadOnlyStore.Exists() error =", err)
+			}
+			if exists {
+				t.Errorf("ReadOnlyStore.Exists() = %v, want %v", exists, false)
+			}
+
+			// test predecessors
+			preds, err := s.Predecessors(ctx, descs[0])
+			if err != nil {
+				t.Fatal("ReadOnlyStore.Predecessors() error =", err)
+			}
+			if want := []ocispec.Descriptor{manifestDesc}; !reflect.DeepEqual(preds, want) {
+				t.Errorf("ReadOnlyStore.Predecessors() = %v, want %v", preds, want)
+			}
+
+			// test copy out of the store
+			dst := memory.New()
+			if _, err := oras.Copy(ctx, s, ref, dst, "", oras.DefaultCopyOptions); err != nil {
+				t.Fatal("oras.Copy() error =", err)
+			}
+			for i, desc := range descs {
+				exists, err := dst.Exists(ctx, desc)
+				if err != nil {
+					t.Fatalf("dst.Exists(%d) error = %v", i, err)
+				}
+				if !exists {
+					t.Errorf("dst.Exists(%d) = %v, want %v", i, exists, true)
+				}
+			}
+		})
+	}
+}
+
+func TestReadOnlyStore_BadLayout(t *testing.T) {
+	ctx := context.Background()
+	if _, err := NewFromFS(ctx, fstest.MapFS{}); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("NewFromFS() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+	fsys := fstest.MapFS{
+		ocispec.ImageLayoutFile: &fstest.MapFile{Data: []byte(`{"imageLayoutVersion":"0.0.0"}`)},
+	}
+	if _, err := NewFromFS(ctx, fsys); !errors.Is(err, errdef.ErrUnsupportedVersion) {
+		t.Errorf("NewFromFS() error = %v, wantErr %v", err, errdef.ErrUnsupportedVersion)
+	}
+}
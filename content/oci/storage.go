@@ -17,6 +17,7 @@ package oci
 
 import (
 	"context"
+	"crypto/cipher"
 	"errors"
 	"fmt"
 	"io"
@@ -49,6 +50,8 @@ type Storage struct {
 	blobRoot string
 	// ingestRoot is the root directory of the temporary ingest files.
 	ingestRoot string
+	// aead, if not nil, encrypts the stored blobs.
+	aead cipher.AEAD
 }
 
 // NewStorage creates a new CAS based on file system with the OCI-Image layout.
@@ -59,6 +62,25 @@ func NewStorage(root string) *Storage {
 	}
 }
 
+// NewEncryptedStorage creates a new CAS based on file system with the
+// OCI-Image layout, where the blobs are encrypted at rest with aead.
+// The blobs are stored under the digests of their plaintext, and are verified
+// against the descriptors both on Push before being encrypted and on Fetch
+// after being decrypted. The sizes of the descriptors are the sizes of the
+// plaintext.
+// The nonce size of aead must be at least 12 bytes. Since the nonces are
+// partially random, AEADs with larger nonces, such as XChaCha20-Poly1305,
+// are preferred for storages holding a large number of blobs.
+// Note: the encrypted blobs cannot be read as a regular OCI layout.
+func NewEncryptedStorage(root string, aead cipher.AEAD) (*Storage, error) {
+	if aead.NonceSize() < minEncryptionNonceSize {
+		return nil, fmt.Errorf("nonce size %d less than %d: %w", aead.NonceSize(), minEncryptionNonceSize, errdef.ErrUnsupported)
+	}
+	s := NewStorage(root)
+	s.aead = aead
+	return s, nil
+}
+
 // Fetch fetches the content identified by the descriptor.
 func (s *Storage) Fetch(_ context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
 	path, err := s.blobPath(target.Digest)
@@ -69,12 +91,21 @@ func (s *Storage) Fetch(_ context.Context, target ocispec.Descriptor) (io.ReadCl
 	fp, err := os.Open(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrNotFound)
+			return nil, &errdef.NotFoundError{Descriptor: target}
 		}
 		return nil, err
 	}
+	if s.aead == nil {
+		return fp, nil
+	}
 
-	return fp, nil
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: newDecryptReader(fp, s.aead, target),
+		Closer: fp,
+	}, nil
 }
 
 // Push pushes the content, matching the expected descriptor.
@@ -163,8 +194,22 @@ func (s *Storage) ingest(expected ocispec.Descriptor, content io.Reader) (path s
 
 	buf := bufPool.Get().(*[]byte)
 	defer bufPool.Put(buf)
-	if err := ioutil.CopyBuffer(fp, content, *buf, expected); err != nil {
-		return "", fmt.Errorf("failed to ingest: %w", err)
+	if s.aead == nil {
+		if err := ioutil.CopyBuffer(fp, content, *buf, expected); err != nil {
+			return "", fmt.Errorf("failed to ingest: %w", err)
+		}
+	} else {
+		// the plaintext is verified before the last segment is sealed
+		ew, err := newEncryptWriter(fp, s.aead, expected.Digest)
+		if err != nil {
+			return "", fmt.Errorf("failed to ingest: %w", err)
+		}
+		if err := ioutil.CopyBuffer(ew, content, *buf, expected); err != nil {
+			return "", fmt.Errorf("failed to ingest: %w", err)
+		}
+		if err := ew.Close(); err != nil {
+			return "", fmt.Errorf("failed to ingest: %w", err)
+		}
 	}
 
 	// change to readonly
@@ -175,6 +220,53 @@ func (s *Storage) ingest(expected ocispec.Descriptor, content io.Reader) (path s
 	return
 }
 
+// blobSize returns the size of the content of the blob at path, stored under
+// the digest.
+func (s *Storage) blobSize(path string, dgst digest.Digest) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if s.aead == nil {
+		return info.Size(), nil
+	}
+	size, err := plaintextSize(s.aead, info.Size())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", dgst, err)
+	}
+	return size, nil
+}
+
+// readBlob reads the blob at path, stored under the digest, if the size of
+// its content does not exceed maxSize. Returns false if the blob is larger.
+func (s *Storage) readBlob(path string, dgst digest.Digest, maxSize int64) ([]byte, bool, error) {
+	size, err := s.blobSize(path, dgst)
+	if err != nil {
+		return nil, false, err
+	}
+	if size > maxSize {
+		return nil, false, nil
+	}
+	if s.aead == nil {
+		data, err := os.ReadFile(path)
+		return data, err == nil, err
+	}
+
+	fp, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer fp.Close()
+	data, err := io.ReadAll(newDecryptReader(fp, s.aead, ocispec.Descriptor{
+		Digest: dgst,
+		Size:   size,
+	}))
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
 // blobPath calculates blob path from the given digest.
 func (s *Storage) blobPath(dgst digest.Digest) (string, error) {
 	if err := dgst.Validate(); err != nil {
@@ -0,0 +1,71 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import "io"
+
+// ProgressReader reads from an underlying reader and reports the cumulative
+// number of bytes read after each read.
+type ProgressReader struct {
+	base   io.Reader
+	total  int64
+	copied int64
+	report func(copied int64)
+}
+
+// NewProgressReader wraps r for reporting the progress of reading content of
+// total bytes. fn is called after each Read() with the cumulative number of
+// bytes read so far, and can be nil.
+// If the total size is unknown, a negative value can be passed.
+func NewProgressReader(r io.Reader, total int64, fn func(copied int64)) *ProgressReader {
+	return &ProgressReader{
+		base:   r,
+		total:  total,
+		report: fn,
+	}
+}
+
+// Read reads up to len(p) bytes into p. It returns the number of bytes
+// read (0 <= n <= len(p)) and any error encountered.
+func (pr *ProgressReader) Read(p []byte) (int, error) {
+	n, err := pr.base.Read(p)
+	if n > 0 {
+		pr.copied += int64(n)
+		if pr.report != nil {
+			pr.report(pr.copied)
+		}
+	}
+	return n, err
+}
+
+// Close closes the underlying reader if it implements io.Closer.
+func (pr *ProgressReader) Close() error {
+	if rc, ok := pr.base.(io.Closer); ok {
+		return rc.Close()
+	}
+	return nil
+}
+
+// Copied returns the cumulative number of bytes read so far.
+func (pr *ProgressReader) Copied() int64 {
+	return pr.copied
+}
+
+// Total returns the total number of bytes to be read as passed to
+// NewProgressReader().
+func (pr *ProgressReader) Total() int64 {
+	return pr.total
+}
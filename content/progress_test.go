@@ -0,0 +1,79 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestProgressReader(t *testing.T) {
+	content := []byte("hello world")
+	var got []int64
+	pr := NewProgressReader(iotest.OneByteReader(bytes.NewReader(content)), int64(len(content)), func(copied int64) {
+		got = append(got, copied)
+	})
+	read, err := io.ReadAll(pr)
+	if err != nil {
+		t.Fatalf("ProgressReader.Read() error = %v", err)
+	}
+	if !bytes.Equal(read, content) {
+		t.Errorf("ProgressReader.Read() = %v, want %v", read, content)
+	}
+	var want []int64
+	for i := range content {
+		want = append(want, int64(i+1))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("progress = %v, want %v", got, want)
+	}
+	if copied := pr.Copied(); copied != int64(len(content)) {
+		t.Errorf("ProgressReader.Copied() = %v, want %v", copied, len(content))
+	}
+	if total := pr.Total(); total != int64(len(content)) {
+		t.Errorf("ProgressReader.Total() = %v, want %v", total, len(content))
+	}
+	if err := pr.Close(); err != nil {
+		t.Errorf("ProgressReader.Close() error = %v", err)
+	}
+}
+
+func TestProgressReader_Close(t *testing.T) {
+	rc := &closeRecorder{Reader: bytes.NewReader([]byte("foo"))}
+	pr := NewProgressReader(rc, 3, nil)
+	if _, err := io.ReadAll(pr); err != nil {
+		t.Fatalf("ProgressReader.Read() error = %v", err)
+	}
+	if err := pr.Close(); err != nil {
+		t.Fatalf("ProgressReader.Close() error = %v", err)
+	}
+	if !rc.closed {
+		t.Error("ProgressReader.Close() did not close the underlying reader")
+	}
+}
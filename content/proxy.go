@@ -0,0 +1,118 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// ReadProxy represents a CAS serving reads from a primary storage, and falling
+// back to a read-only storage if the content is not found in the primary
+// storage.
+// It is the building block for pull-through caches.
+type ReadProxy struct {
+	Storage                  // primary storage
+	Fallback ReadOnlyStorage // fallback storage
+
+	// WriteBack controls if the content fetched from the fallback storage is
+	// stored into the primary storage while being read.
+	// Default value: true.
+	WriteBack bool
+}
+
+// NewReadProxy returns a storage serving reads from primary, and falling back
+// to fallback if the content is not found in primary.
+// The content fetched from fallback is written back into primary.
+func NewReadProxy(primary Storage, fallback ReadOnlyStorage) *ReadProxy {
+	return &ReadProxy{
+		Storage:   primary,
+		Fallback:  fallback,
+		WriteBack: true,
+	}
+}
+
+// Fetch fetches the content identified by the descriptor from the primary
+// storage, or from the fallback storage if the content is not found in the
+// primary storage.
+// If WriteBack is true, the content fetched from the fallback storage is
+// stored into the primary storage when the returned reader is read to the end
+// and closed. Failures of storing the content, e.g. errdef.ErrAlreadyExists
+// on concurrent fetches, are ignored and never fail the read.
+func (p *ReadProxy) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := p.Storage.Fetch(ctx, target)
+	if err == nil {
+		return rc, nil
+	}
+	if !errors.Is(err, errdef.ErrNotFound) {
+		return nil, err
+	}
+
+	rc, err = p.Fallback.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if !p.WriteBack {
+		return rc, nil
+	}
+
+	// write back content while reading
+	pr, pw := io.Pipe()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// unblock the pending writes if the push returns early
+		pr.CloseWithError(p.Storage.Push(ctx, target, pr))
+	}()
+	closer := closerFunc(func() error {
+		rcErr := rc.Close()
+		pw.Close()
+		wg.Wait()
+		return rcErr
+	})
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.TeeReader(rc, &mirrorWriter{w: pw}),
+		Closer: closer,
+	}, nil
+}
+
+// Exists returns true if the described content exists in either the primary
+// storage or the fallback storage.
+func (p *ReadProxy) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	exists, err := p.Storage.Exists(ctx, target)
+	if err == nil && exists {
+		return true, nil
+	}
+	return p.Fallback.Exists(ctx, target)
+}
+
+// closerFunc is the basic Close method defined in io.Closer.
+type closerFunc func() error
+
+// Close performs close operation by the closerFunc.
+func (fn closerFunc) Close() error {
+	return fn()
+}
@@ -0,0 +1,196 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content_test
+
+import (
+	"bytes"
+	"context"
+	_ "crypto/sha256"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestReadProxy(t *testing.T) {
+	blob := []byte("hello world")
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+
+	ctx := context.Background()
+	fallback := memory.New()
+	if err := fallback.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	primary := memory.New()
+	s := content.NewReadProxy(primary, fallback)
+
+	// first fetch should be served by the fallback storage
+	exists, err := s.Exists(ctx, desc)
+	if err != nil {
+		t.Fatal("ReadProxy.Exists() error =", err)
+	}
+	if !exists {
+		t.Errorf("ReadProxy.Exists() = %v, want %v", exists, true)
+	}
+	got, err := content.FetchAll(ctx, s, desc)
+	if err != nil {
+		t.Fatal("ReadProxy.Fetch() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("ReadProxy.Fetch() = %v, want %v", got, blob)
+	}
+
+	// fetched content should be written back to the primary storage
+	exists, err = primary.Exists(ctx, desc)
+	if err != nil {
+		t.Fatal("Store.Exists() error =", err)
+	}
+	if !exists {
+		t.Errorf("Store.Exists() = %v, want %v", exists, true)
+	}
+
+	// repeated fetch should not touch the fallback storage
+	// nil fallback will generate panic if the fallback storage is touched
+	s.Fallback = nil
+	exists, err = s.Exists(ctx, desc)
+	if err != nil {
+		t.Fatal("ReadProxy.Exists() error =", err)
+	}
+	if !exists {
+		t.Errorf("ReadProxy.Exists() = %v, want %v", exists, true)
+	}
+	got, err = content.FetchAll(ctx, s, desc)
+	if err != nil {
+		t.Fatal("ReadProxy.Fetch() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("ReadProxy.Fetch() = %v, want %v", got, blob)
+	}
+}
+
+func TestReadProxy_NoWriteBack(t *testing.T) {
+	blob := []byte("hello world")
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+
+	ctx := context.Background()
+	fallback := memory.New()
+	if err := fallback.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	primary := memory.New()
+	s := content.NewReadProxy(primary, fallback)
+	s.WriteBack = false
+
+	rc, err := s.Fetch(ctx, desc)
+	if err != nil {
+		t.Fatal("ReadProxy.Fetch() error =", err)
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal("ReadProxy.Fetch().Read() error =", err)
+	}
+	if err := rc.Close(); err != nil {
+		t.Error("ReadProxy.Fetch().Close() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("ReadProxy.Fetch() = %v, want %v", got, blob)
+	}
+
+	exists, err := primary.Exists(ctx, desc)
+	if err != nil {
+		t.Fatal("Store.Exists() error =", err)
+	}
+	if exists {
+		t.Errorf("Store.Exists() = %v, want %v", exists, false)
+	}
+}
+
+// pushFailingStorage is a storage failing the pushes without reading the
+// content.
+type pushFailingStorage struct {
+	content.Storage
+	err error
+}
+
+func (s *pushFailingStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	return s.err
+}
+
+func TestReadProxy_WriteBackFailure(t *testing.T) {
+	blob := []byte("hello world")
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+
+	ctx := context.Background()
+	fallback := memory.New()
+	if err := fallback.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "push failure",
+			err:  errors.New("disk full"),
+		},
+		{
+			name: "concurrently stored",
+			err:  errdef.ErrAlreadyExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &pushFailingStorage{
+				Storage: memory.New(),
+				err:     tt.err,
+			}
+			s := content.NewReadProxy(primary, fallback)
+
+			rc, err := s.Fetch(ctx, desc)
+			if err != nil {
+				t.Fatal("ReadProxy.Fetch() error =", err)
+			}
+			got, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatal("ReadProxy.Fetch().Read() error =", err)
+			}
+			if err := rc.Close(); err != nil {
+				t.Error("ReadProxy.Fetch().Close() error =", err)
+			}
+			if !bytes.Equal(got, blob) {
+				t.Errorf("ReadProxy.Fetch() = %v, want %v", got, blob)
+			}
+		})
+	}
+}
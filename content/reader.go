@@ -22,6 +22,7 @@ import (
 
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
 )
 
 var (
@@ -98,7 +99,15 @@ func (vr *VerifyReader) Verify() error {
 }
 
 // NewVerifyReader wraps r for reading content with verification against desc.
+// The content is verified using the digest algorithm of desc. If the digest of
+// desc is invalid or its algorithm is not available, reading fails with an
+// error wrapping errdef.ErrInvalidDigest or errdef.ErrUnsupported.
 func NewVerifyReader(r io.Reader, desc ocispec.Descriptor) *VerifyReader {
+	if err := validateDigest(desc.Digest); err != nil {
+		return &VerifyReader{
+			err: err,
+		}
+	}
 	verifier := desc.Digest.Verifier()
 	lr := &io.LimitedReader{
 		R: io.TeeReader(r, verifier),
@@ -129,6 +138,21 @@ func ReadAll(r io.Reader, desc ocispec.Descriptor) ([]byte, error) {
 	return buf, nil
 }
 
+// validateDigest validates the digest and ensures that the hash function of
+// its algorithm is available for verification.
+func validateDigest(dgst digest.Digest) error {
+	if err := dgst.Validate(); err != nil {
+		if errors.Is(err, digest.ErrDigestUnsupported) {
+			return fmt.Errorf("%s: %v: %w", dgst, err, errdef.ErrUnsupported)
+		}
+		return fmt.Errorf("%s: %v: %w", dgst, err, errdef.ErrInvalidDigest)
+	}
+	if alg := dgst.Algorithm(); !alg.Available() {
+		return fmt.Errorf("%s: digest algorithm %q not available: %w", dgst, alg, errdef.ErrUnsupported)
+	}
+	return nil
+}
+
 // ensureEOF ensures the read operation ends with an EOF and no
 // trailing data is present.
 func ensureEOF(r io.Reader) error {
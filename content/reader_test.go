@@ -18,12 +18,14 @@ package content
 import (
 	"bytes"
 	_ "crypto/sha256"
+	_ "crypto/sha512"
 	"errors"
 	"io"
 	"testing"
 
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
 )
 
 func TestVerifyReader_Read(t *testing.T) {
@@ -224,3 +226,39 @@ func TestReadAll_InvalidDescriptorSize(t *testing.T) {
 		t.Errorf("ReadAll() error = %v, want %v", err, ErrInvalidDescriptorSize)
 	}
 }
+
+func TestVerifyReader_DigestAlgorithm(t *testing.T) {
+	content := []byte("example content")
+
+	// the digest algorithm of the descriptor is used
+	desc := NewDescriptorFromBytesWithAlgorithm("test", content, digest.SHA512)
+	got, err := ReadAll(bytes.NewReader(content), desc)
+	if err != nil {
+		t.Fatal("ReadAll() error = ", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("ReadAll() = %v, want %v", got, content)
+	}
+
+	// unsupported algorithm
+	desc = ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.NewDigestFromEncoded("unknown", "1234"),
+		Size:      int64(len(content)),
+	}
+	_, err = ReadAll(bytes.NewReader(content), desc)
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("ReadAll() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+
+	// invalid digest
+	desc = ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.NewDigestFromEncoded(digest.SHA256, "1234"),
+		Size:      int64(len(content)),
+	}
+	_, err = ReadAll(bytes.NewReader(content), desc)
+	if !errors.Is(err, errdef.ErrInvalidDigest) {
+		t.Errorf("ReadAll() error = %v, want %v", err, errdef.ErrInvalidDigest)
+	}
+}
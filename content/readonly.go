@@ -0,0 +1,88 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// ReadOnly returns a storage wrapping s, which passes through the reads to s
+// and rejects any modification, e.g. when handing a storage to untrusted
+// code. Push, Delete, and Tag of the returned storage return
+// errdef.ErrUnsupported.
+// The returned storage implements PredecessorFinder and Resolver if s
+// implements them, so that it can still be used as the source of
+// oras.ExtendedCopy.
+func ReadOnly(s ReadOnlyStorage) Storage {
+	rs := &readOnlyStorage{base: s}
+	pf, isPredecessorFinder := s.(PredecessorFinder)
+	r, isResolver := s.(Resolver)
+	switch {
+	case isPredecessorFinder && isResolver:
+		return struct {
+			*readOnlyStorage
+			PredecessorFinder
+			Resolver
+		}{rs, pf, r}
+	case isPredecessorFinder:
+		return struct {
+			*readOnlyStorage
+			PredecessorFinder
+		}{rs, pf}
+	case isResolver:
+		return struct {
+			*readOnlyStorage
+			Resolver
+		}{rs, r}
+	default:
+		return rs
+	}
+}
+
+// readOnlyStorage is a storage rejecting modifications.
+type readOnlyStorage struct {
+	base ReadOnlyStorage
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (s *readOnlyStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	return s.base.Fetch(ctx, target)
+}
+
+// Exists returns true if the described content exists.
+func (s *readOnlyStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	return s.base.Exists(ctx, target)
+}
+
+// Push returns errdef.ErrUnsupported.
+func (s *readOnlyStorage) Push(_ context.Context, expected ocispec.Descriptor, _ io.Reader) error {
+	return fmt.Errorf("%s: %s: push: %w", expected.Digest, expected.MediaType, errdef.ErrUnsupported)
+}
+
+// Delete returns errdef.ErrUnsupported.
+func (s *readOnlyStorage) Delete(_ context.Context, target ocispec.Descriptor) error {
+	return fmt.Errorf("%s: %s: delete: %w", target.Digest, target.MediaType, errdef.ErrUnsupported)
+}
+
+// Tag returns errdef.ErrUnsupported.
+func (s *readOnlyStorage) Tag(_ context.Context, _ ocispec.Descriptor, reference string) error {
+	return fmt.Errorf("%s: tag: %w", reference, errdef.ErrUnsupported)
+}
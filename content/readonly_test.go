@@ -0,0 +1,104 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/cas"
+)
+
+func TestReadOnly(t *testing.T) {
+	ctx := context.Background()
+	base := memory.New()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	if err := base.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatalf("Store.Push() error = %v", err)
+	}
+	ref := "foobar"
+	if err := base.Tag(ctx, desc, ref); err != nil {
+		t.Fatalf("Store.Tag() error = %v", err)
+	}
+
+	s := content.ReadOnly(base)
+	if _, ok := s.(oras.ReadOnlyGraphTarget); !ok {
+		t.Error("read-only storage does not conform oras.ReadOnlyGraphTarget")
+	}
+
+	// test reads
+	got, err := content.FetchAll(ctx, s, desc)
+	if err != nil {
+		t.Fatalf("content.FetchAll() error = %v", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("content.FetchAll() = %v, want %v", got, blob)
+	}
+	exists, err := s.Exists(ctx, desc)
+	if err != nil {
+		t.Fatalf("Storage.Exists() error = %v", err)
+	}
+	if !exists {
+		t.Errorf("Storage.Exists() = %v, want %v", exists, true)
+	}
+	gotDesc, err := s.(content.Resolver).Resolve(ctx, ref)
+	if err != nil {
+		t.Fatalf("Storage.Resolve() error = %v", err)
+	}
+	if gotDesc.Digest != desc.Digest {
+		t.Errorf("Storage.Resolve() = %v, want %v", gotDesc, desc)
+	}
+
+	// test modifications
+	other := []byte("foo")
+	otherDesc := content.NewDescriptorFromBytes("test", other)
+	if err := s.Push(ctx, otherDesc, bytes.NewReader(other)); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Storage.Push() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	if exists, _ := base.Exists(ctx, otherDesc); exists {
+		t.Error("Storage.Push() modified the underlying storage")
+	}
+	if err := s.(content.Deleter).Delete(ctx, desc); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Storage.Delete() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	if err := s.(content.Tagger).Tag(ctx, desc, "other"); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Storage.Tag() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	if _, err := base.Resolve(ctx, "other"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Storage.Tag() modified the underlying storage, Resolve() error = %v", err)
+	}
+}
+
+func TestReadOnly_PlainStorage(t *testing.T) {
+	s := content.ReadOnly(cas.NewMemory())
+	if _, ok := s.(content.PredecessorFinder); ok {
+		t.Error("read-only storage unexpectedly implements content.PredecessorFinder")
+	}
+	if _, ok := s.(content.Resolver); ok {
+		t.Error("read-only storage unexpectedly implements content.Resolver")
+	}
+	if err := s.Push(context.Background(), ocispec.Descriptor{}, nil); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Storage.Push() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// FetchResumable fetches the content described by the descriptor, and resumes
+// the fetch from the last read byte offset using fetcher.FetchRange() if a
+// read fails, up to maxRetries times.
+// The assembled content is verified against the size and the digest of the
+// descriptor, where the verification error is returned instead of io.EOF on
+// mismatch.
+func FetchResumable(ctx context.Context, fetcher RangeFetcher, desc ocispec.Descriptor, maxRetries int) (io.ReadCloser, error) {
+	rc, err := fetcher.FetchRange(ctx, desc, 0)
+	if err != nil {
+		return nil, err
+	}
+	rr := &resumableReader{
+		ctx:        ctx,
+		fetcher:    fetcher,
+		desc:       desc,
+		base:       rc,
+		maxRetries: maxRetries,
+	}
+	return &verifiedReadCloser{
+		vr:     NewVerifyReader(rr, desc),
+		closer: rr,
+	}, nil
+}
+
+// resumableReader reads the content and resumes the fetch on read failures.
+type resumableReader struct {
+	ctx        context.Context
+	fetcher    RangeFetcher
+	desc       ocispec.Descriptor
+	base       io.ReadCloser
+	offset     int64
+	retries    int
+	maxRetries int
+}
+
+// Read reads up to len(p) bytes into p, resuming the fetch on failures.
+func (r *resumableReader) Read(p []byte) (int, error) {
+	n, err := r.base.Read(p)
+	r.offset += int64(n)
+	if err == nil || err == io.EOF {
+		return n, err
+	}
+	if r.retries >= r.maxRetries || r.ctx.Err() != nil {
+		return n, err
+	}
+
+	// resume from the last read byte offset
+	r.retries++
+	r.base.Close()
+	rc, fetchErr := r.fetcher.FetchRange(r.ctx, r.desc, r.offset)
+	if fetchErr != nil {
+		r.base = io.NopCloser(eofReader{})
+		return n, fmt.Errorf("%s: %s: failed to resume fetch at offset %d: %w", r.desc.Digest, r.desc.MediaType, r.offset, fetchErr)
+	}
+	r.base = rc
+	if n > 0 {
+		return n, nil
+	}
+	return r.Read(p)
+}
+
+// Close closes the underlying reader.
+func (r *resumableReader) Close() error {
+	return r.base.Close()
+}
+
+// eofReader always returns io.EOF.
+type eofReader struct{}
+
+// Read returns io.EOF.
+func (eofReader) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+// verifiedReadCloser reads the content through a VerifyReader, and returns
+// the verification error instead of io.EOF on mismatch.
+type verifiedReadCloser struct {
+	vr     *VerifyReader
+	closer io.Closer
+}
+
+// Read reads up to len(p) bytes into p, and verifies the content on EOF.
+func (r *verifiedReadCloser) Read(p []byte) (int, error) {
+	n, err := r.vr.Read(p)
+	if err == io.EOF {
+		if verr := r.vr.Verify(); verr != nil {
+			return n, verr
+		}
+	}
+	return n, err
+}
+
+// Close closes the underlying reader.
+func (r *verifiedReadCloser) Close() error {
+	return r.closer.Close()
+}
@@ -0,0 +1,113 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var errFlakyRead = errors.New("flaky read")
+
+// flakyRangeFetcher serves content in ranges, where each returned reader
+// fails after reading failAfter bytes.
+type flakyRangeFetcher struct {
+	content   []byte
+	failAfter int
+	offsets   []int64
+}
+
+func (f *flakyRangeFetcher) FetchRange(_ context.Context, _ ocispec.Descriptor, offset int64) (io.ReadCloser, error) {
+	f.offsets = append(f.offsets, offset)
+	remaining := f.content[offset:]
+	if len(remaining) <= f.failAfter {
+		return io.NopCloser(bytes.NewReader(remaining)), nil
+	}
+	return io.NopCloser(io.MultiReader(
+		bytes.NewReader(remaining[:f.failAfter]),
+		errorReader{errFlakyRead},
+	)), nil
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestFetchResumable(t *testing.T) {
+	content := []byte("hello world")
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(content),
+		Size:      int64(len(content)),
+	}
+	ctx := context.Background()
+
+	fetcher := &flakyRangeFetcher{content: content, failAfter: 4}
+	rc, err := FetchResumable(ctx, fetcher, desc, 3)
+	if err != nil {
+		t.Fatalf("FetchResumable() error = %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("FetchResumable().Read() error = %v", err)
+	}
+	if err := rc.Close(); err != nil {
+		t.Errorf("FetchResumable().Close() error = %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("FetchResumable() = %q, want %q", got, content)
+	}
+	wantOffsets := []int64{0, 4, 8}
+	if len(fetcher.offsets) != len(wantOffsets) {
+		t.Fatalf("FetchRange() offsets = %v, want %v", fetcher.offsets, wantOffsets)
+	}
+	for i, offset := range wantOffsets {
+		if fetcher.offsets[i] != offset {
+			t.Errorf("FetchRange() offsets = %v, want %v", fetcher.offsets, wantOffsets)
+			break
+		}
+	}
+
+	// retries exhausted
+	fetcher = &flakyRangeFetcher{content: content, failAfter: 4}
+	rc, err = FetchResumable(ctx, fetcher, desc, 1)
+	if err != nil {
+		t.Fatalf("FetchResumable() error = %v", err)
+	}
+	if _, err := io.ReadAll(rc); !errors.Is(err, errFlakyRead) {
+		t.Errorf("FetchResumable().Read() error = %v, want %v", err, errFlakyRead)
+	}
+
+	// mismatched content
+	fetcher = &flakyRangeFetcher{content: []byte("hello w0rld"), failAfter: 4}
+	rc, err = FetchResumable(ctx, fetcher, desc, 3)
+	if err != nil {
+		t.Fatalf("FetchResumable() error = %v", err)
+	}
+	if _, err := io.ReadAll(rc); !errors.Is(err, ErrMismatchedDigest) {
+		t.Errorf("FetchResumable().Read() error = %v, want %v", err, ErrMismatchedDigest)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// NewSingleflight returns a storage wrapping s, which deduplicates the
+// concurrent fetches of the same content, e.g. in a caching proxy where many
+// clients request the same uncached blob simultaneously.
+// The first fetch of a digest reads the content from s into a temporary file
+// while verifying it, and the concurrent fetches of the same digest wait for
+// it and share the file, each with its own reader. The file is removed once
+// all the readers are closed. A fetch issued after the content is read starts
+// a new upstream read.
+// If the first fetch fails, e.g. its context is canceled, the concurrent
+// fetches waiting for it fail with the same error.
+func NewSingleflight(s ReadOnlyStorage) ReadOnlyStorage {
+	return &singleflightStorage{
+		ReadOnlyStorage: s,
+		flights:         make(map[digest.Digest]*fetchFlight),
+	}
+}
+
+// singleflightStorage is a storage deduplicating concurrent fetches.
+type singleflightStorage struct {
+	ReadOnlyStorage
+	lock    sync.Mutex
+	flights map[digest.Digest]*fetchFlight
+}
+
+// fetchFlight is an in-flight fetch of a content shared by the concurrent
+// fetches.
+type fetchFlight struct {
+	done chan struct{}
+	// path and err are set before done is closed.
+	path string
+	err  error
+	// refs counts the fetches referencing the temporary file, guarded by the
+	// lock of the storage.
+	refs int
+}
+
+// Fetch fetches the content identified by the descriptor, sharing the read
+// of the content from the underlying storage with the concurrent fetches.
+func (s *singleflightStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	s.lock.Lock()
+	f, ok := s.flights[target.Digest]
+	if ok {
+		f.refs++
+		s.lock.Unlock()
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			s.release(f)
+			return nil, ctx.Err()
+		}
+	} else {
+		f = &fetchFlight{
+			done: make(chan struct{}),
+			refs: 1,
+		}
+		s.flights[target.Digest] = f
+		s.lock.Unlock()
+
+		path, err := s.fetchToFile(ctx, target)
+		s.lock.Lock()
+		delete(s.flights, target.Digest)
+		f.path, f.err = path, err
+		close(f.done)
+		s.lock.Unlock()
+	}
+
+	if f.err != nil {
+		s.release(f)
+		return nil, f.err
+	}
+	fp, err := os.Open(f.path)
+	if err != nil {
+		s.release(f)
+		return nil, err
+	}
+	return &flightReader{
+		fp: fp,
+		release: func() {
+			s.release(f)
+		},
+	}, nil
+}
+
+// fetchToFile reads the content from the underlying storage into a temporary
+// file, and verifies it against the descriptor.
+func (s *singleflightStorage) fetchToFile(ctx context.Context, target ocispec.Descriptor) (path string, err error) {
+	rc, err := s.ReadOnlyStorage.Fetch(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	fp, err := os.CreateTemp("", "oras_singleflight_*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		closeErr := fp.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(fp.Name())
+		}
+	}()
+
+	vr := NewVerifyReader(rc, target)
+	if _, err := io.Copy(fp, vr); err != nil {
+		return "", fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, err)
+	}
+	if err := vr.Verify(); err != nil {
+		return "", fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, err)
+	}
+	return fp.Name(), nil
+}
+
+// release releases a reference to the flight, and removes the temporary file
+// when the last reference is released.
+func (s *singleflightStorage) release(f *fetchFlight) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f.refs--
+	if f.refs == 0 && f.path != "" {
+		os.Remove(f.path)
+	}
+}
+
+// flightReader reads the temporary file of a flight, and releases the
+// reference to the flight on close.
+type flightReader struct {
+	fp      *os.File
+	release func()
+	once    sync.Once
+}
+
+// Read reads up to len(p) bytes into p.
+func (r *flightReader) Read(p []byte) (int, error) {
+	return r.fp.Read(p)
+}
+
+// Seek sets the offset for the next Read.
+func (r *flightReader) Seek(offset int64, whence int) (int64, error) {
+	return r.fp.Seek(offset, whence)
+}
+
+// Close closes the file and releases the reference to the flight.
+func (r *flightReader) Close() error {
+	err := r.fp.Close()
+	r.once.Do(r.release)
+	return err
+}
@@ -0,0 +1,184 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/cas"
+)
+
+// gatedStorage blocks the fetches until the gate is opened, and counts them.
+type gatedStorage struct {
+	content.Storage
+	gate    chan struct{}
+	fetches int64
+}
+
+func (s *gatedStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	atomic.AddInt64(&s.fetches, 1)
+	<-s.gate
+	return s.Storage.Fetch(ctx, target)
+}
+
+func TestNewSingleflight(t *testing.T) {
+	ctx := context.Background()
+	base := &gatedStorage{
+		Storage: cas.NewMemory(),
+		gate:    make(chan struct{}),
+	}
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	if err := base.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatalf("Storage.Push() error = %v", err)
+	}
+	s := content.NewSingleflight(base)
+
+	// test sharing the upstream read among the concurrent fetches
+	const n = 5
+	var wg sync.WaitGroup
+	var started sync.WaitGroup
+	results := make([][]byte, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		started.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			started.Done()
+			rc, err := s.Fetch(ctx, desc)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer rc.Close()
+			results[i], errs[i] = io.ReadAll(rc)
+		}(i)
+	}
+	started.Wait()
+	for atomic.LoadInt64(&base.fetches) == 0 {
+		// wait for the first fetch to reach the upstream
+	}
+	close(base.gate)
+	wg.Wait()
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("Storage.Fetch() #%d error = %v", i, errs[i])
+		}
+		if !bytes.Equal(results[i], blob) {
+			t.Errorf("Storage.Fetch() #%d = %s, want %s", i, results[i], blob)
+		}
+	}
+	if got := atomic.LoadInt64(&base.fetches); got < 1 || got > n {
+		t.Errorf("count(upstream Fetch()) = %v, want between 1 and %d", got, n)
+	}
+
+	// test fetching again after the flight
+	got, err := content.FetchAll(ctx, s, desc)
+	if err != nil {
+		t.Fatalf("content.FetchAll() error = %v", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("content.FetchAll() = %s, want %s", got, blob)
+	}
+}
+
+func TestNewSingleflight_Dedup(t *testing.T) {
+	ctx := context.Background()
+	base := &gatedStorage{
+		Storage: cas.NewMemory(),
+		gate:    make(chan struct{}),
+	}
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	if err := base.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatalf("Storage.Push() error = %v", err)
+	}
+	s := content.NewSingleflight(base)
+
+	// the leader blocks on the gate, and the followers join its flight
+	leaderDone := make(chan error)
+	go func() {
+		_, err := content.FetchAll(ctx, s, desc)
+		leaderDone <- err
+	}()
+	for atomic.LoadInt64(&base.fetches) == 0 {
+		// wait for the leader to reach the upstream
+	}
+	followerCtx, cancel := context.WithCancel(ctx)
+	followerDone := make(chan error)
+	go func() {
+		_, err := s.Fetch(followerCtx, desc)
+		followerDone <- err
+	}()
+	follower2Done := make(chan error)
+	go func() {
+		_, err := content.FetchAll(ctx, s, desc)
+		follower2Done <- err
+	}()
+
+	// test canceling a waiting follower
+	cancel()
+	if err := <-followerDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("Storage.Fetch() error = %v, want %v", err, context.Canceled)
+	}
+
+	// give the follower time to join the flight
+	time.Sleep(100 * time.Millisecond)
+	close(base.gate)
+	if err := <-leaderDone; err != nil {
+		t.Errorf("leader content.FetchAll() error = %v", err)
+	}
+	if err := <-follower2Done; err != nil {
+		t.Errorf("follower content.FetchAll() error = %v", err)
+	}
+	if got := atomic.LoadInt64(&base.fetches); got != 1 {
+		t.Errorf("count(upstream Fetch()) = %v, want 1", got)
+	}
+}
+
+// tamperedStorage serves the same content for every fetch.
+type tamperedStorage struct {
+	content.ReadOnlyStorage
+	content []byte
+}
+
+func (s *tamperedStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.content)), nil
+}
+
+func TestNewSingleflight_Mismatch(t *testing.T) {
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	base := &tamperedStorage{
+		content: []byte("hello World"),
+	}
+	s := content.NewSingleflight(base)
+
+	if _, err := s.Fetch(ctx, desc); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Storage.Fetch() error = %v, want %v", err, content.ErrMismatchedDigest)
+	}
+}
@@ -16,10 +16,16 @@ limitations under the License.
 package content
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"os"
 
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
 )
 
 // Fetcher fetches content.
@@ -50,6 +56,9 @@ type ReadOnlyStorage interface {
 	Fetcher
 
 	// Exists returns true if the described content exists.
+	// Implementations should check the existence without fetching the
+	// content, so that callers like oras.CopyGraph can cheaply skip existing
+	// content.
 	Exists(ctx context.Context, target ocispec.Descriptor) (bool, error)
 }
 
@@ -60,6 +69,28 @@ type Deleter interface {
 	Delete(ctx context.Context, target ocispec.Descriptor) error
 }
 
+// Flusher persists the writes buffered by a storage.
+// Storages implementing Flusher may defer persisting the metadata, such as
+// the tags and the index, written by Push and Tag until Flush is called, so
+// that the writes can be batched. Callers must call Flush before exiting to
+// avoid losing the buffered writes.
+// The copy functions in the oras package call Flush on completion if the
+// destination implements Flusher.
+type Flusher interface {
+	// Flush persists all the buffered writes.
+	Flush(ctx context.Context) error
+}
+
+// RangeFetcher fetches content starting from a byte offset.
+// RangeFetcher is an extension of Fetcher, which enables resuming interrupted
+// fetches.
+// See also `FetchResumable()` in this package.
+type RangeFetcher interface {
+	// FetchRange fetches the content identified by the descriptor, starting
+	// from the given byte offset to the end of the content.
+	FetchRange(ctx context.Context, target ocispec.Descriptor, offset int64) (io.ReadCloser, error)
+}
+
 // FetchAll safely fetches the content described by the descriptor.
 // The fetched content is verified against the size and the digest.
 func FetchAll(ctx context.Context, fetcher Fetcher, desc ocispec.Descriptor) ([]byte, error) {
@@ -71,6 +102,56 @@ func FetchAll(ctx context.Context, fetcher Fetcher, desc ocispec.Descriptor) ([]
 	return ReadAll(rc, desc)
 }
 
+// PushEmptyJSON pushes the empty JSON blob described by DescriptorEmptyJSON.
+// No error is returned if the blob already exists.
+func PushEmptyJSON(ctx context.Context, pusher Pusher) error {
+	err := pusher.Push(ctx, DescriptorEmptyJSON, bytes.NewReader(EmptyJSON))
+	if err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
+// PushFromReader reads the content of unknown size from r, and pushes it to
+// pusher with the given media type. Returns the descriptor of the pushed
+// content.
+// Since the descriptor must be known before pushing, the content is spooled
+// to a temporary file while being digested, instead of being held in the
+// memory, and is pushed from the file.
+// If no media type is specified, "application/octet-stream" will be used.
+// No error is returned if the content already exists.
+func PushFromReader(ctx context.Context, pusher Pusher, mediaType string, r io.Reader) (ocispec.Descriptor, error) {
+	if mediaType == "" {
+		mediaType = defaultMediaType
+	}
+	fp, err := os.CreateTemp("", "oras_content_*")
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		fp.Close()
+		os.Remove(fp.Name())
+	}()
+
+	digester := digest.Canonical.Digester()
+	size, err := io.Copy(io.MultiWriter(fp, digester.Hash()), r)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digester.Digest(),
+		Size:      size,
+	}
+	if _, err := fp.Seek(0, io.SeekStart); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := pusher.Push(ctx, desc, fp); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
 // FetcherFunc is the basic Fetch method defined in Fetcher.
 type FetcherFunc func(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error)
 
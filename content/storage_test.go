@@ -0,0 +1,82 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content_test
+
+import (
+	"bytes"
+	"context"
+	_ "crypto/sha256"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestDescriptorEmptyJSON(t *testing.T) {
+	desc := content.DescriptorEmptyJSON
+	if want := digest.FromBytes(content.EmptyJSON); desc.Digest != want {
+		t.Errorf("DescriptorEmptyJSON.Digest = %v, want %v", desc.Digest, want)
+	}
+	if want := int64(len(content.EmptyJSON)); desc.Size != want {
+		t.Errorf("DescriptorEmptyJSON.Size = %v, want %v", desc.Size, want)
+	}
+}
+
+func TestPushEmptyJSON(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	// push twice to test idempotency
+	for i := 0; i < 2; i++ {
+		if err := content.PushEmptyJSON(ctx, s); err != nil {
+			t.Fatalf("PushEmptyJSON() error = %v", err)
+		}
+	}
+	got, err := content.FetchAll(ctx, s, content.DescriptorEmptyJSON)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	if !bytes.Equal(got, content.EmptyJSON) {
+		t.Errorf("FetchAll() = %v, want %v", got, content.EmptyJSON)
+	}
+}
+
+func TestPushFromReader(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+	blob := []byte("hello world")
+
+	// push twice to test idempotency
+	for i := 0; i < 2; i++ {
+		desc, err := content.PushFromReader(ctx, s, "", bytes.NewReader(blob))
+		if err != nil {
+			t.Fatalf("PushFromReader() error = %v", err)
+		}
+		want := content.NewDescriptorFromBytes("application/octet-stream", blob)
+		if !content.Equal(desc, want) {
+			t.Errorf("PushFromReader() = %v, want %v", desc, want)
+		}
+	}
+	desc := content.NewDescriptorFromBytes("", blob)
+	got, err := content.FetchAll(ctx, s, desc)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("FetchAll() = %v, want %v", got, blob)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// TeeStorage represents a storage mirroring every write to a secondary
+// storage, while reading from the primary storage only.
+type TeeStorage struct {
+	// Primary is the storage to read from and to write to.
+	Primary Storage
+	// Secondary is the storage to mirror the writes to.
+	Secondary Storage
+
+	// OnSecondaryError, if not nil, is called when a write to Secondary fails
+	// after the write to Primary succeeds. The write fails with the returned
+	// error, or succeeds if nil is returned, e.g. after logging err.
+	// If OnSecondaryError is nil, the write fails with err.
+	OnSecondaryError func(ctx context.Context, desc ocispec.Descriptor, err error) error
+}
+
+// NewTee returns a storage writing to both primary and secondary, and reading
+// from primary, so that secondary is kept in sync with the writes to primary.
+// Tag is propagated to both if they implement Tagger.
+// Note: contents already existing in primary are pushed again to secondary
+// only if they are pushed explicitly. Callers skipping the existing contents,
+// such as oras.Copy, do not mirror them to secondary.
+func NewTee(primary, secondary Storage) *TeeStorage {
+	return &TeeStorage{
+		Primary:   primary,
+		Secondary: secondary,
+	}
+}
+
+// Fetch fetches the content identified by the descriptor from the primary
+// storage.
+func (s *TeeStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	return s.Primary.Fetch(ctx, target)
+}
+
+// Exists returns true if the described content exists in the primary storage.
+func (s *TeeStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	return s.Primary.Exists(ctx, target)
+}
+
+// Push pushes the content, matching the expected descriptor, to both storages
+// in a single pass over the content.
+// If the content already exists in the primary storage, it is still pushed to
+// the secondary storage, and errdef.ErrAlreadyExists is returned.
+func (s *TeeStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	pr, pw := io.Pipe()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var secondaryErr error
+	go func() {
+		defer wg.Done()
+		secondaryErr = s.Secondary.Push(ctx, expected, pr)
+		// unblock the writes not consumed by the secondary storage
+		pr.CloseWithError(secondaryErr)
+	}()
+
+	w := &mirrorWriter{w: pw}
+	tr := io.TeeReader(content, w)
+	err := s.Primary.Push(ctx, expected, tr)
+	if err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		pw.CloseWithError(err)
+		wg.Wait()
+		return err
+	}
+	// mirror the content left unread by the primary storage
+	if _, copyErr := io.Copy(io.Discard, tr); copyErr != nil {
+		pw.CloseWithError(copyErr)
+		wg.Wait()
+		return copyErr
+	}
+	pw.Close()
+	wg.Wait()
+
+	if secondaryErr != nil && !errors.Is(secondaryErr, errdef.ErrAlreadyExists) {
+		if handleErr := s.handleSecondaryError(ctx, expected, secondaryErr); handleErr != nil {
+			return handleErr
+		}
+	}
+	return err
+}
+
+// Resolve resolves a reference to a descriptor from the primary storage.
+// Returns errdef.ErrUnsupported if the primary storage is not a Resolver.
+func (s *TeeStorage) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	resolver, ok := s.Primary.(Resolver)
+	if !ok {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: primary storage does not support resolving: %w", reference, errdef.ErrUnsupported)
+	}
+	return resolver.Resolve(ctx, reference)
+}
+
+// Tag tags a descriptor with a reference string in both storages.
+// Returns errdef.ErrUnsupported if the primary storage is not a Tagger.
+func (s *TeeStorage) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	tagger, ok := s.Primary.(Tagger)
+	if !ok {
+		return fmt.Errorf("%s: primary storage does not support tagging: %w", reference, errdef.ErrUnsupported)
+	}
+	if err := tagger.Tag(ctx, desc, reference); err != nil {
+		return err
+	}
+
+	var err error
+	if tagger, ok := s.Secondary.(Tagger); ok {
+		err = tagger.Tag(ctx, desc, reference)
+	} else {
+		err = fmt.Errorf("%s: secondary storage does not support tagging: %w", reference, errdef.ErrUnsupported)
+	}
+	if err != nil {
+		return s.handleSecondaryError(ctx, desc, err)
+	}
+	return nil
+}
+
+// Flush flushes both storages if they implement Flusher.
+func (s *TeeStorage) Flush(ctx context.Context) error {
+	if flusher, ok := s.Primary.(Flusher); ok {
+		if err := flusher.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := s.Secondary.(Flusher); ok {
+		if err := flusher.Flush(ctx); err != nil {
+			return s.handleSecondaryError(ctx, ocispec.Descriptor{}, err)
+		}
+	}
+	return nil
+}
+
+// handleSecondaryError handles the error of writing desc to the secondary
+// storage.
+func (s *TeeStorage) handleSecondaryError(ctx context.Context, desc ocispec.Descriptor, err error) error {
+	err = fmt.Errorf("failed to write to secondary storage: %w", err)
+	if s.OnSecondaryError == nil {
+		return err
+	}
+	return s.OnSecondaryError(ctx, desc, err)
+}
+
+// mirrorWriter writes to the secondary storage, and discards the writes after
+// the first failure so that the secondary storage never fails the read of the
+// primary storage.
+type mirrorWriter struct {
+	w      io.Writer
+	failed bool
+}
+
+// Write writes p unless a previous write has failed.
+func (w *mirrorWriter) Write(p []byte) (int, error) {
+	if !w.failed {
+		if _, err := w.w.Write(p); err != nil {
+			w.failed = true
+		}
+	}
+	return len(p), nil
+}
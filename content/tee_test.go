@@ -0,0 +1,173 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content_test
+
+import (
+	"bytes"
+	"context"
+	_ "crypto/sha256"
+	"errors"
+	"io"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+var errTeeTest = errors.New("test error")
+
+// failingStorage is a storage failing the writes without reading the content.
+type failingStorage struct {
+	*memory.Store
+}
+
+func (s *failingStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	return errTeeTest
+}
+
+func (s *failingStorage) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	return errTeeTest
+}
+
+func TestNewTee(t *testing.T) {
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	primary := memory.New()
+	secondary := memory.New()
+	s := content.NewTee(primary, secondary)
+	ctx := context.Background()
+
+	// test push
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatalf("TeeStorage.Push() error = %v", err)
+	}
+	for name, store := range map[string]*memory.Store{"primary": primary, "secondary": secondary} {
+		got, err := content.FetchAll(ctx, store, desc)
+		if err != nil {
+			t.Fatalf("%s: FetchAll() error = %v", name, err)
+		}
+		if !bytes.Equal(got, blob) {
+			t.Errorf("%s: FetchAll() = %v, want %v", name, got, blob)
+		}
+	}
+
+	// test tag
+	ref := "foobar"
+	if err := s.Tag(ctx, desc, ref); err != nil {
+		t.Fatalf("TeeStorage.Tag() error = %v", err)
+	}
+	for name, store := range map[string]*memory.Store{"primary": primary, "secondary": secondary} {
+		got, err := store.Resolve(ctx, ref)
+		if err != nil {
+			t.Fatalf("%s: Resolve() error = %v", name, err)
+		}
+		if !content.Equal(got, desc) {
+			t.Errorf("%s: Resolve() = %v, want %v", name, got, desc)
+		}
+	}
+	got, err := s.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatalf("TeeStorage.Resolve() error = %v", err)
+	}
+	if !content.Equal(got, desc) {
+		t.Errorf("TeeStorage.Resolve() = %v, want %v", got, desc)
+	}
+
+	// test reads from primary only
+	other := []byte("other")
+	otherDesc := content.NewDescriptorFromBytes("test", other)
+	if err := secondary.Push(ctx, otherDesc, bytes.NewReader(other)); err != nil {
+		t.Fatal(err)
+	}
+	exists, err := s.Exists(ctx, otherDesc)
+	if err != nil {
+		t.Fatalf("TeeStorage.Exists() error = %v", err)
+	}
+	if exists {
+		t.Error("TeeStorage.Exists() = true, want false")
+	}
+	if _, err := s.Fetch(ctx, otherDesc); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("TeeStorage.Fetch() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestTeeStorage_Push_ExistingInPrimary(t *testing.T) {
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	primary := memory.New()
+	secondary := memory.New()
+	ctx := context.Background()
+	if err := primary.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+
+	s := content.NewTee(primary, secondary)
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); !errors.Is(err, errdef.ErrAlreadyExists) {
+		t.Fatalf("TeeStorage.Push() error = %v, wantErr %v", err, errdef.ErrAlreadyExists)
+	}
+	got, err := content.FetchAll(ctx, secondary, desc)
+	if err != nil {
+		t.Fatalf("secondary: FetchAll() error = %v", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("secondary: FetchAll() = %v, want %v", got, blob)
+	}
+}
+
+func TestTeeStorage_SecondaryError(t *testing.T) {
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	ctx := context.Background()
+
+	t.Run("fail", func(t *testing.T) {
+		primary := memory.New()
+		s := content.NewTee(primary, &failingStorage{memory.New()})
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); !errors.Is(err, errTeeTest) {
+			t.Errorf("TeeStorage.Push() error = %v, wantErr %v", err, errTeeTest)
+		}
+		// the content is still written to the primary storage
+		if _, err := content.FetchAll(ctx, primary, desc); err != nil {
+			t.Errorf("primary: FetchAll() error = %v", err)
+		}
+		if err := s.Tag(ctx, desc, "foobar"); !errors.Is(err, errTeeTest) {
+			t.Errorf("TeeStorage.Tag() error = %v, wantErr %v", err, errTeeTest)
+		}
+	})
+
+	t.Run("log", func(t *testing.T) {
+		primary := memory.New()
+		s := content.NewTee(primary, &failingStorage{memory.New()})
+		var logged []error
+		s.OnSecondaryError = func(ctx context.Context, desc ocispec.Descriptor, err error) error {
+			logged = append(logged, err)
+			return nil
+		}
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Errorf("TeeStorage.Push() error = %v", err)
+		}
+		if err := s.Tag(ctx, desc, "foobar"); err != nil {
+			t.Errorf("TeeStorage.Tag() error = %v", err)
+		}
+		if len(logged) != 2 || !errors.Is(logged[0], errTeeTest) || !errors.Is(logged[1], errTeeTest) {
+			t.Errorf("OnSecondaryError errors = %v, want 2 errors of %v", logged, errTeeTest)
+		}
+		if _, err := primary.Resolve(ctx, "foobar"); err != nil {
+			t.Errorf("primary: Resolve() error = %v", err)
+		}
+	})
+}
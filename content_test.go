@@ -1562,6 +1562,94 @@ func TestFetchBytes_Repository(t *testing.T) {
 	}
 }
 
+func TestFetchImage_Memory(t *testing.T) {
+	target := memory.New()
+	ctx := context.Background()
+
+	push := func(desc ocispec.Descriptor, blob []byte) ocispec.Descriptor {
+		desc.Digest = digest.FromBytes(blob)
+		desc.Size = int64(len(blob))
+		if err := target.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	configBytes := []byte(`{"architecture":"test-arc-1","os":"test-os-1"}`)
+	config := push(ocispec.Descriptor{MediaType: ocispec.MediaTypeImageConfig}, configBytes)
+	layer := push(ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer}, []byte("foo"))
+	manifest := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{layer},
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDesc := push(ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Platform: &ocispec.Platform{
+			Architecture: "test-arc-1",
+			OS:           "test-os-1",
+		},
+	}, manifestJSON)
+	indexJSON, err := json.Marshal(ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{manifestDesc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := push(ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex}, indexJSON)
+	if err := target.Tag(ctx, manifestDesc, "image"); err != nil {
+		t.Fatal(err)
+	}
+	if err := target.Tag(ctx, index, "index"); err != nil {
+		t.Fatal(err)
+	}
+
+	// test fetching an image
+	gotManifest, gotConfig, err := oras.FetchImage(ctx, target, "image", oras.DefaultFetchImageOptions)
+	if err != nil {
+		t.Fatalf("oras.FetchImage() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotManifest, manifest) {
+		t.Errorf("oras.FetchImage() manifest = %v, want %v", gotManifest, manifest)
+	}
+	if !bytes.Equal(gotConfig, configBytes) {
+		t.Errorf("oras.FetchImage() config = %s, want %s", gotConfig, configBytes)
+	}
+
+	// test fetching an index
+	_, _, err = oras.FetchImage(ctx, target, "index", oras.DefaultFetchImageOptions)
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("oras.FetchImage() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+
+	// test fetching an index with a target platform
+	opts := oras.FetchImageOptions{}
+	opts.TargetPlatform = manifestDesc.Platform
+	gotManifest, gotConfig, err = oras.FetchImage(ctx, target, "index", opts)
+	if err != nil {
+		t.Fatalf("oras.FetchImage() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotManifest, manifest) {
+		t.Errorf("oras.FetchImage() manifest = %v, want %v", gotManifest, manifest)
+	}
+	if !bytes.Equal(gotConfig, configBytes) {
+		t.Errorf("oras.FetchImage() config = %s, want %s", gotConfig, configBytes)
+	}
+
+	// test the config size limit
+	opts = oras.FetchImageOptions{
+		MaxConfigBytes: config.Size - 1,
+	}
+	_, _, err = oras.FetchImage(ctx, target, "image", opts)
+	if !errors.Is(err, errdef.ErrSizeExceedsLimit) {
+		t.Errorf("oras.FetchImage() error = %v, want %v", err, errdef.ErrSizeExceedsLimit)
+	}
+}
+
 func TestPushBytes_Memory(t *testing.T) {
 	s := cas.NewMemory()
 
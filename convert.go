@@ -0,0 +1,635 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/registry"
+)
+
+// errBlobNotMapped is returned when a manifest references a blob not yet
+// transformed by MapBlob.
+var errBlobNotMapped = errors.New("blob not mapped")
+
+// dockerToOCIMediaTypes maps docker media types to their OCI equivalents.
+var dockerToOCIMediaTypes = map[string]string{
+	docker.MediaTypeManifest:     ocispec.MediaTypeImageManifest,
+	docker.MediaTypeManifestList: ocispec.MediaTypeImageIndex,
+	docker.MediaTypeConfig:       ocispec.MediaTypeImageConfig,
+	docker.MediaTypeLayer:        ocispec.MediaTypeImageLayerGzip,
+	docker.MediaTypeForeignLayer: ocispec.MediaTypeImageLayerNonDistributableGzip,
+}
+
+// convertedManifest is the result of converting a docker manifest or manifest
+// list to its OCI equivalent, or of mapping a manifest.
+type convertedManifest struct {
+	desc    ocispec.Descriptor
+	content []byte
+}
+
+// manifestConverter converts docker manifests and manifest lists to OCI image
+// manifests and indexes, and maps manifests with a user-provided hook.
+// Since the digests of the converted manifests change, the references of the
+// manifest lists and indexes, as well as the subjects of the manifests, are
+// remapped to the converted manifests accordingly.
+type manifestConverter struct {
+	// fetcher fetches the original manifests.
+	fetcher content.Fetcher
+	// convertDocker enables converting docker manifests.
+	convertDocker bool
+	// mapManifest maps the content of manifests if not nil.
+	mapManifest func(desc ocispec.Descriptor, content []byte) ([]byte, error)
+	// rewriteReferences enables remapping the references of all manifests.
+	rewriteReferences bool
+	// rewriteURLs rewrites the URLs of the referenced blobs if not nil.
+	rewriteURLs func(desc ocispec.Descriptor) ocispec.Descriptor
+	// mapBlob transforms the blobs on push if not nil.
+	mapBlob func(ctx context.Context, desc ocispec.Descriptor, content io.Reader) (ocispec.Descriptor, io.Reader, error)
+	// mappedBlobs records the descriptors of the transformed blobs.
+	mappedBlobs *sync.Map // map[descriptor.Descriptor]ocispec.Descriptor
+	// converted caches the conversion results.
+	converted sync.Map // map[descriptor.Descriptor]*conversion
+}
+
+// conversion is the conversion of a manifest, performed at most once on
+// success.
+type conversion struct {
+	lock   sync.Mutex
+	done   bool
+	result convertedManifest
+}
+
+// newManifestConverter creates a new manifest converter fetching the original
+// manifests from the given fetcher, configured by opts.ConvertManifest,
+// opts.MapManifest, opts.RewriteSubject, opts.RewriteURLs, and opts.MapBlob.
+// Returns nil if there is nothing to convert.
+func newManifestConverter(fetcher content.Fetcher, opts CopyGraphOptions) *manifestConverter {
+	if !opts.ConvertManifest && opts.MapManifest == nil && opts.RewriteURLs == nil && opts.MapBlob == nil {
+		return nil
+	}
+	return &manifestConverter{
+		fetcher:           fetcher,
+		convertDocker:     opts.ConvertManifest,
+		mapManifest:       opts.MapManifest,
+		rewriteReferences: opts.ConvertManifest && opts.RewriteSubject,
+		rewriteURLs:       opts.RewriteURLs,
+		mapBlob:           opts.MapBlob,
+		mappedBlobs:       &sync.Map{},
+	}
+}
+
+// shouldConvertManifest returns true if the manifest described by desc is to
+// be converted.
+func (c *manifestConverter) shouldConvertManifest(desc ocispec.Descriptor) bool {
+	if !descriptor.IsManifest(desc) {
+		return false
+	}
+	if c.mapManifest != nil || c.rewriteReferences || c.rewriteURLs != nil || c.mapBlob != nil {
+		return true
+	}
+	switch desc.MediaType {
+	case docker.MediaTypeManifest, docker.MediaTypeManifestList:
+		return c.convertDocker
+	}
+	return false
+}
+
+// ConvertDescriptor returns the converted descriptor of desc.
+// Annotations, platform and other fields of desc are preserved.
+// desc is returned as is if there is nothing to convert.
+func (c *manifestConverter) ConvertDescriptor(ctx context.Context, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	if c.shouldConvertManifest(desc) {
+		converted, err := c.convertManifest(ctx, desc)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		desc.MediaType = converted.desc.MediaType
+		desc.Digest = converted.desc.Digest
+		desc.Size = converted.desc.Size
+		return desc, nil
+	}
+	if c.mapBlob != nil && !descriptor.IsManifest(desc) {
+		mapped, ok := c.mappedBlobs.Load(descriptor.FromOCI(desc))
+		if !ok {
+			return ocispec.Descriptor{}, fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errBlobNotMapped)
+		}
+		return mapped.(ocispec.Descriptor), nil
+	}
+	if !c.convertDocker {
+		return desc, nil
+	}
+	if mediaType, ok := dockerToOCIMediaTypes[desc.MediaType]; ok {
+		desc.MediaType = mediaType
+	}
+	return desc, nil
+}
+
+// convertManifest converts the manifest described by desc.
+// The manifest is converted once on success, so that the same result is used
+// for pushing the manifest and remapping the references to it, even if
+// MapManifest is not deterministic.
+func (c *manifestConverter) convertManifest(ctx context.Context, desc ocispec.Descriptor) (convertedManifest, error) {
+	value, _ := c.converted.LoadOrStore(descriptor.FromOCI(desc), &conversion{})
+	conv := value.(*conversion)
+	conv.lock.Lock()
+	defer conv.lock.Unlock()
+	if conv.done {
+		return conv.result, nil
+	}
+	result, err := c.doConvertManifest(ctx, desc)
+	if err != nil {
+		return convertedManifest{}, err
+	}
+	conv.result = result
+	conv.done = true
+	return result, nil
+}
+
+// doConvertManifest fetches and converts the manifest described by desc.
+func (c *manifestConverter) doConvertManifest(ctx context.Context, desc ocispec.Descriptor) (convertedManifest, error) {
+	manifestJSON, err := content.FetchAll(ctx, c.fetcher, desc)
+	if err != nil {
+		return convertedManifest{}, err
+	}
+
+	mediaType := desc.MediaType
+	switch desc.MediaType {
+	case docker.MediaTypeManifest, docker.MediaTypeManifestList:
+		if c.convertDocker {
+			mediaType = dockerToOCIMediaTypes[desc.MediaType]
+			manifestJSON, err = c.convertDockerManifest(ctx, desc, manifestJSON)
+			break
+		}
+		fallthrough
+	default:
+		manifestJSON, err = c.remapReferences(ctx, manifestJSON)
+		if err == nil && c.mapBlob != nil {
+			manifestJSON, err = c.remapBlobs(ctx, manifestJSON)
+		}
+	}
+	if err != nil {
+		return convertedManifest{}, err
+	}
+
+	if c.rewriteURLs != nil {
+		manifestJSON, err = c.rewriteBlobURLs(manifestJSON)
+		if err != nil {
+			return convertedManifest{}, fmt.Errorf("%s: %s: failed to rewrite URLs: %w", desc.Digest, desc.MediaType, err)
+		}
+	}
+
+	if c.mapManifest != nil {
+		manifestJSON, err = c.mapManifest(desc, manifestJSON)
+		if err != nil {
+			return convertedManifest{}, fmt.Errorf("%s: %s: failed to map manifest: %w", desc.Digest, desc.MediaType, err)
+		}
+	}
+
+	return convertedManifest{
+		desc: ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    desc.Digest.Algorithm().FromBytes(manifestJSON),
+			Size:      int64(len(manifestJSON)),
+		},
+		content: manifestJSON,
+	}, nil
+}
+
+// convertDockerManifest converts the docker manifest or manifest list
+// described by desc to its OCI equivalent, preserving the unknown fields.
+func (c *manifestConverter) convertDockerManifest(ctx context.Context, desc ocispec.Descriptor, manifestJSON []byte) ([]byte, error) {
+	var err error
+	var converted interface{}
+	switch desc.MediaType {
+	case docker.MediaTypeManifest:
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+			return nil, err
+		}
+		manifest.MediaType = ocispec.MediaTypeImageManifest
+		if manifest.Config, err = c.ConvertDescriptor(ctx, manifest.Config); err != nil {
+			return nil, err
+		}
+		for i, layer := range manifest.Layers {
+			if manifest.Layers[i], err = c.ConvertDescriptor(ctx, layer); err != nil {
+				return nil, err
+			}
+		}
+		if manifest.Subject != nil {
+			subject, err := c.ConvertDescriptor(ctx, *manifest.Subject)
+			if err != nil {
+				return nil, err
+			}
+			manifest.Subject = &subject
+		}
+		converted = manifest
+	case docker.MediaTypeManifestList:
+		var index ocispec.Index
+		if err := json.Unmarshal(manifestJSON, &index); err != nil {
+			return nil, err
+		}
+		index.MediaType = ocispec.MediaTypeImageIndex
+		for i, manifest := range index.Manifests {
+			if index.Manifests[i], err = c.ConvertDescriptor(ctx, manifest); err != nil {
+				return nil, err
+			}
+		}
+		converted = index
+	default:
+		return nil, fmt.Errorf("%s: %s: not a docker manifest", desc.Digest, desc.MediaType)
+	}
+
+	convertedJSON, err := marshalPreservingUnknown(manifestJSON, converted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal converted manifest: %w", err)
+	}
+	return convertedJSON, nil
+}
+
+// remapReferences remaps the manifests referenced by the `manifests` and the
+// `subject` fields of the manifest to the converted ones.
+// Other fields, including unknown ones of the manifest and the remapped
+// descriptors, are preserved, and the content is returned as is if no
+// reference is remapped.
+func (c *manifestConverter) remapReferences(ctx context.Context, manifestJSON []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(manifestJSON, &fields); err != nil {
+		return nil, err
+	}
+	changed := false
+	if raw, ok := fields["manifests"]; ok {
+		var manifests []ocispec.Descriptor
+		if err := json.Unmarshal(raw, &manifests); err != nil {
+			return nil, err
+		}
+		remapped := false
+		for i, manifest := range manifests {
+			converted, err := c.ConvertDescriptor(ctx, manifest)
+			if err != nil {
+				return nil, err
+			}
+			if converted.Digest != manifest.Digest || converted.MediaType != manifest.MediaType {
+				manifests[i] = converted
+				remapped = true
+			}
+		}
+		if remapped {
+			raw, err := marshalPreservingUnknown(raw, manifests)
+			if err != nil {
+				return nil, err
+			}
+			fields["manifests"] = raw
+			changed = true
+		}
+	}
+	if raw, ok := fields["subject"]; ok && string(raw) != "null" {
+		var subject ocispec.Descriptor
+		if err := json.Unmarshal(raw, &subject); err != nil {
+			return nil, err
+		}
+		converted, err := c.ConvertDescriptor(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		if converted.Digest != subject.Digest || converted.MediaType != subject.MediaType {
+			raw, err := marshalPreservingUnknown(raw, converted)
+			if err != nil {
+				return nil, err
+			}
+			fields["subject"] = raw
+			changed = true
+		}
+	}
+	if !changed {
+		return manifestJSON, nil
+	}
+	remappedJSON, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal remapped manifest: %w", err)
+	}
+	return remappedJSON, nil
+}
+
+// remapBlobs remaps the blobs referenced by the `config`, the `layers`, and
+// the `blobs` fields of the manifest to the ones transformed by MapBlob.
+// Other fields, including unknown ones of the manifest and the remapped
+// descriptors, are preserved, and the content is returned as is if no blob is
+// remapped.
+func (c *manifestConverter) remapBlobs(ctx context.Context, manifestJSON []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(manifestJSON, &fields); err != nil {
+		return nil, err
+	}
+	// remap returns true if desc is remapped.
+	remap := func(desc *ocispec.Descriptor) (bool, error) {
+		mapped, err := c.ConvertDescriptor(ctx, *desc)
+		if err != nil {
+			return false, err
+		}
+		if reflect.DeepEqual(mapped, *desc) {
+			return false, nil
+		}
+		*desc = mapped
+		return true, nil
+	}
+	changed := false
+	if raw, ok := fields["config"]; ok && string(raw) != "null" {
+		var config ocispec.Descriptor
+		if err := json.Unmarshal(raw, &config); err != nil {
+			return nil, err
+		}
+		remapped, err := remap(&config)
+		if err != nil {
+			return nil, err
+		}
+		if remapped {
+			raw, err := marshalPreservingUnknown(raw, config)
+			if err != nil {
+				return nil, err
+			}
+			fields["config"] = raw
+			changed = true
+		}
+	}
+	for _, name := range []string{"layers", "blobs"} {
+		raw, ok := fields[name]
+		if !ok {
+			continue
+		}
+		var blobs []ocispec.Descriptor
+		if err := json.Unmarshal(raw, &blobs); err != nil {
+			return nil, err
+		}
+		remapped := false
+		for i := range blobs {
+			ok, err := remap(&blobs[i])
+			if err != nil {
+				return nil, err
+			}
+			remapped = remapped || ok
+		}
+		if remapped {
+			raw, err := marshalPreservingUnknown(raw, blobs)
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = raw
+			changed = true
+		}
+	}
+	if !changed {
+		return manifestJSON, nil
+	}
+	remappedJSON, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal remapped manifest: %w", err)
+	}
+	return remappedJSON, nil
+}
+
+// rewriteBlobURLs rewrites the URLs of the blobs referenced by the `config`,
+// the `layers`, and the `blobs` fields of the manifest.
+// Other fields, including unknown ones of the manifest and the rewritten
+// descriptors, are preserved, and the content is returned as is if no URL is
+// rewritten.
+func (c *manifestConverter) rewriteBlobURLs(manifestJSON []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(manifestJSON, &fields); err != nil {
+		return nil, err
+	}
+	// rewrite returns true if the URLs of desc are rewritten.
+	rewrite := func(desc *ocispec.Descriptor) bool {
+		// pass a copy of the URLs so that the hook can modify them in place
+		target := *desc
+		target.URLs = append([]string(nil), desc.URLs...)
+		urls := c.rewriteURLs(target).URLs
+		if reflect.DeepEqual(urls, desc.URLs) {
+			return false
+		}
+		desc.URLs = urls
+		return true
+	}
+	changed := false
+	if raw, ok := fields["config"]; ok && string(raw) != "null" {
+		var config ocispec.Descriptor
+		if err := json.Unmarshal(raw, &config); err != nil {
+			return nil, err
+		}
+		if rewrite(&config) {
+			raw, err := marshalPreservingUnknown(raw, config)
+			if err != nil {
+				return nil, err
+			}
+			fields["config"] = raw
+			changed = true
+		}
+	}
+	for _, name := range []string{"layers", "blobs"} {
+		raw, ok := fields[name]
+		if !ok {
+			continue
+		}
+		var blobs []ocispec.Descriptor
+		if err := json.Unmarshal(raw, &blobs); err != nil {
+			return nil, err
+		}
+		rewritten := false
+		for i := range blobs {
+			if rewrite(&blobs[i]) {
+				rewritten = true
+			}
+		}
+		if rewritten {
+			raw, err := marshalPreservingUnknown(raw, blobs)
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = raw
+			changed = true
+		}
+	}
+	if !changed {
+		return manifestJSON, nil
+	}
+	rewrittenJSON, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rewritten manifest: %w", err)
+	}
+	return rewrittenJSON, nil
+}
+
+// Storage wraps the storage s so that the content is converted before pushed
+// to or checked against s.
+func (c *manifestConverter) Storage(s content.Storage) content.Storage {
+	return &convertingStorage{
+		Storage:   s,
+		converter: c,
+	}
+}
+
+// Target wraps the target t so that the content is converted before pushed to,
+// checked against, or tagged in t.
+// The wrapped target implements registry.ReferencePusher if t implements it.
+func (c *manifestConverter) Target(t Target) Target {
+	ct := &convertingTarget{
+		convertingStorage: convertingStorage{
+			Storage:   t,
+			converter: c,
+		},
+		tagResolver: t,
+	}
+	if refPusher, ok := t.(registry.ReferencePusher); ok {
+		return &convertingReferenceTarget{
+			convertingTarget: ct,
+			refPusher:        refPusher,
+		}
+	}
+	return ct
+}
+
+// convertingStorage is a storage converting the content before pushing.
+type convertingStorage struct {
+	content.Storage
+	converter *manifestConverter
+}
+
+// Exists returns true if the converted content exists.
+// The blobs not yet transformed by MapBlob, as well as the manifests
+// referencing them, are reported as not existing.
+func (s *convertingStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	target, err := s.converter.ConvertDescriptor(ctx, target)
+	if err != nil {
+		if errors.Is(err, errBlobNotMapped) {
+			return false, nil
+		}
+		return false, err
+	}
+	return s.Storage.Exists(ctx, target)
+}
+
+// Push converts and pushes the content, matching the expected descriptor.
+func (s *convertingStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	if s.converter.mapBlob != nil && !descriptor.IsManifest(expected) {
+		return s.pushMappedBlob(ctx, expected, content)
+	}
+	expected, content, err := s.convert(ctx, expected, content)
+	if err != nil {
+		return err
+	}
+	return s.Storage.Push(ctx, expected, content)
+}
+
+// pushMappedBlob transforms the blob with MapBlob and pushes the transformed
+// blob, recording its descriptor for remapping the manifests.
+func (s *convertingStorage) pushMappedBlob(ctx context.Context, desc ocispec.Descriptor, r io.Reader) error {
+	mapped, mr, err := s.converter.mapBlob(ctx, desc, r)
+	if err != nil {
+		return fmt.Errorf("%s: %s: failed to map blob: %w", desc.Digest, desc.MediaType, err)
+	}
+	if err := s.Storage.Push(ctx, mapped, mr); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+	s.converter.mappedBlobs.Store(descriptor.FromOCI(desc), mapped)
+	return nil
+}
+
+// storage returns the underlying storage.
+func (s *convertingStorage) storage() content.Storage {
+	return s.Storage
+}
+
+// mounter returns the underlying storage if it implements registry.Mounter,
+// so that the blobs are mounted as is. The blobs are not mounted if they are
+// to be transformed by MapBlob.
+func (s *convertingStorage) mounter() (registry.Mounter, bool) {
+	if s.converter.mapBlob != nil {
+		return nil, false
+	}
+	mounter, ok := s.Storage.(registry.Mounter)
+	return mounter, ok
+}
+
+// Flush flushes the underlying storage if it implements content.Flusher.
+func (s *convertingStorage) Flush(ctx context.Context) error {
+	return flush(ctx, s.Storage)
+}
+
+// convert converts the descriptor as well as the content if the content is a
+// manifest to be converted.
+func (s *convertingStorage) convert(ctx context.Context, desc ocispec.Descriptor, r io.Reader) (ocispec.Descriptor, io.Reader, error) {
+	if s.converter.shouldConvertManifest(desc) {
+		converted, err := s.converter.convertManifest(ctx, desc)
+		if err != nil {
+			return ocispec.Descriptor{}, nil, err
+		}
+		return converted.desc, bytes.NewReader(converted.content), nil
+	}
+	desc, err := s.converter.ConvertDescriptor(ctx, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	return desc, r, nil
+}
+
+// convertingTarget is a target converting the content before pushing or
+// tagging.
+type convertingTarget struct {
+	convertingStorage
+	tagResolver content.TagResolver
+}
+
+// Resolve resolves a reference to a descriptor.
+func (t *convertingTarget) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	return t.tagResolver.Resolve(ctx, reference)
+}
+
+// Tag tags the converted descriptor with a reference string.
+func (t *convertingTarget) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	desc, err := t.converter.ConvertDescriptor(ctx, desc)
+	if err != nil {
+		return err
+	}
+	return t.tagResolver.Tag(ctx, desc, reference)
+}
+
+// convertingReferenceTarget is a convertingTarget supporting
+// registry.ReferencePusher.
+type convertingReferenceTarget struct {
+	*convertingTarget
+	refPusher registry.ReferencePusher
+}
+
+// PushReference converts and pushes the manifest with a reference tag.
+func (t *convertingReferenceTarget) PushReference(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string) error {
+	expected, content, err := t.convert(ctx, expected, content)
+	if err != nil {
+		return err
+	}
+	return t.refPusher.PushReference(ctx, expected, content, reference)
+}
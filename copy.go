@@ -17,25 +17,38 @@ package oras
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
 	"golang.org/x/sync/semaphore"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/cas"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/docker"
 	"oras.land/oras-go/v2/internal/graph"
+	"oras.land/oras-go/v2/internal/interfaces"
 	"oras.land/oras-go/v2/internal/platform"
 	"oras.land/oras-go/v2/internal/registryutil"
 	"oras.land/oras-go/v2/internal/status"
 	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
 )
 
 // defaultConcurrency is the default value of CopyGraphOptions.Concurrency.
 const defaultConcurrency = 3 // This value is consistent with dockerd and containerd.
 
+// defaultReferrersDepth is the default value of CopyOptions.ReferrersDepth.
+const defaultReferrersDepth = 8
+
 var (
 	// DefaultCopyOptions provides the default CopyOptions.
 	DefaultCopyOptions = CopyOptions{
@@ -53,6 +66,56 @@ type CopyOptions struct {
 	// reference will be passed to MapRoot, and the mapped descriptor will be
 	// used as the root node for copy.
 	MapRoot func(ctx context.Context, src content.ReadOnlyStorage, root ocispec.Descriptor) (ocispec.Descriptor, error)
+	// WithReferrers enables copying the referrers of the root node, such as
+	// signatures, SBOMs, and attestations, after the root node is copied.
+	// The referrers of the copied referrers are copied recursively up to
+	// ReferrersDepth levels. If the root node is an index, the referrers of
+	// the manifests in the index are copied as well.
+	// A referrer attached to multiple subjects is copied only once.
+	// Referrers are discovered via registry.ReferrerFinder if the source
+	// supports it, or via content.PredecessorFinder otherwise. No referrers
+	// are copied if the source supports neither.
+	WithReferrers bool
+	// ReferrersDepth limits the maximum levels of referrers to be copied when
+	// WithReferrers is set.
+	// If less than or equal to 0, a default (currently 8) is used.
+	ReferrersDepth int
+	// TagDigest, if set, additionally tags the root node in the destination
+	// with a tag derived from its digest, in the form of
+	// `<alg>-<encoded>.digest`, so that the root node is addressable by a tag
+	// pinned to its digest on registries not exposing digest references.
+	// The suffix keeps the tag apart from the referrers tag schema.
+	// The digest is the one of the root node as copied, which differs from
+	// the source if the manifests are converted or mapped.
+	TagDigest bool
+	// OnCopied, if provided, is called on successful copy with the root node
+	// and its references in the destination by tag and by digest.
+	// The references are fully qualified (e.g. `localhost:5000/hello:v1` and
+	// `localhost:5000/hello@sha256:...`) if the destination parses
+	// references, such as *remote.Repository. Otherwise, they are the bare
+	// tag and digest.
+	// tagRef is empty if the destination reference is a digest.
+	OnCopied func(ctx context.Context, root ocispec.Descriptor, tagRef, digestRef string) error
+	// AtomicTag, if set, keeps the destination tag untouched until the copy
+	// completes, so that the consumers of the tag observe either the previous
+	// root node or the new one along with all of its content, and never a
+	// partially copied one.
+	// The root node is tagged with a temporary tag in the form of
+	// `tmp_<alg>_<encoded>` during the copy instead, preventing registries
+	// from garbage collecting it as untagged. Once the graph, the referrers
+	// if WithReferrers is set, and the digest tag if TagDigest is set are all
+	// copied, the destination tag is moved to the root node with a single
+	// Tag operation, before the destination is flushed.
+	// On failure, the destination tag is left unchanged, while the content
+	// copied so far and the temporary tag may remain in the destination.
+	// NOTE: the temporary tag is left in the destination on success as well,
+	// and shows up in the tag listings, as deleting a tag deletes the tagged
+	// manifest on many registries. Callers are responsible for cleaning up
+	// the temporary tags if needed.
+	// The guarantee relies on the destination updating a tag atomically, as
+	// registries do on pushing a manifest by tag.
+	// It has no effect if the destination reference is a digest.
+	AtomicTag bool
 }
 
 // WithTargetPlatform configures opts.MapRoot to select the manifest whose
@@ -84,34 +147,292 @@ func (opts *CopyOptions) WithTargetPlatform(p *ocispec.Platform) {
 const defaultCopyMaxMetadataBytes int64 = 4 * 1024 * 1024 // 4 MiB
 
 // CopyGraphOptions contains parameters for oras.CopyGraph.
+// The manifests are copied byte for byte unless they are rewritten by
+// ConvertManifest, RewriteSubject, RewriteURLs, or MapBlob, which preserve the
+// fields unknown to oras, such as the extension fields, of the manifests and
+// their descriptors.
 type CopyGraphOptions struct {
 	// Concurrency limits the maximum number of concurrent copy tasks.
 	// If less than or equal to 0, a default (currently 3) is used.
 	Concurrency int64
 	// MaxMetadataBytes limits the maximum size of the metadata that can be
-	// cached in the memory.
+	// cached in the memory. Manifests and indexes larger than the limit are
+	// rejected with content.ErrManifestTooLarge, which matches
+	// errdef.ErrSizeExceedsLimit, before they are fetched. Blobs are limited
+	// separately by MaxBlobBytes.
 	// If less than or equal to 0, a default (currently 4 MiB) is used.
+	// Note: the manifests decoded by content.Successors, the default of
+	// FindSuccessors, are also limited by content.MaxManifestBytes.
 	MaxMetadataBytes int64
+	// MaxBlobBytes limits the maximum declared size of the blobs, such as
+	// configs and layers, to be copied. Blobs larger than the limit are
+	// rejected with errdef.ErrSizeExceedsLimit before they are fetched.
+	// If less than or equal to 0, the size of blobs is not limited.
+	// Regardless of the limits, the copy of any content is aborted with
+	// errdef.ErrSizeExceedsLimit if the fetched content exceeds the size
+	// declared by its descriptor.
+	MaxBlobBytes int64
 	// PreCopy handles the current descriptor before copying it.
 	PreCopy func(ctx context.Context, desc ocispec.Descriptor) error
 	// PostCopy handles the current descriptor after copying it.
 	PostCopy func(ctx context.Context, desc ocispec.Descriptor) error
 	// OnCopySkipped will be called when the sub-DAG rooted by the current node
-	// is skipped.
+	// is skipped, as the node already exists in the destination. It is called
+	// for the root node as well, allowing the callers to distinguish a no-op
+	// copy from a transfer. The nodes in the skipped sub-DAG are not visited,
+	// and thus not reported.
 	OnCopySkipped func(ctx context.Context, desc ocispec.Descriptor) error
 	// FindSuccessors finds the successors of the current node.
 	// fetcher provides cached access to the source storage, and is suitable
 	// for fetching non-leaf nodes like manifests. Since anything fetched from
 	// fetcher will be cached in the memory, it is recommended to use original
 	// source storage to fetch large blobs.
+	// It allows copying the graphs of custom manifests, of which the schema
+	// is unknown to content.Successors.
 	// If FindSuccessors is nil, content.Successors will be used.
 	FindSuccessors func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error)
+	// ConvertManifest converts docker manifests and manifest lists to OCI image
+	// manifests and indexes on copy. The media types of the referenced configs
+	// and layers are translated as well, and the references of the converted
+	// manifest lists are remapped to the converted manifests.
+	// Since the digests of the converted manifests change, the descriptor of
+	// the converted root node is returned by oras.Copy.
+	// Annotations and platforms of the descriptors are preserved.
+	// Note: PreCopy, PostCopy, and OnCopySkipped receive the descriptors of the
+	// original content.
+	ConvertManifest bool
+	// MapManifest, if not nil, rewrites the content of the manifests and
+	// indexes on copy. It is invoked with the descriptor of the original
+	// manifest and its content, where the references to the rewritten
+	// manifests are already updated, and returns the new content.
+	// The returned content is re-digested and pushed, and the references in
+	// the parent indexes as well as the subjects of the referrers are updated
+	// accordingly. If ConvertManifest is set, docker manifests are converted
+	// before mapping.
+	// Note: since rewriting a manifest changes its digest, MapManifest breaks
+	// the digest stability of the copied graph, and is intended for rewriting
+	// pipelines, such as stripping or injecting annotations when mirroring.
+	// The descriptor of the mapped root node is returned by oras.Copy and
+	// oras.ExtendedCopy.
+	MapManifest func(desc ocispec.Descriptor, content []byte) ([]byte, error)
+	// RewriteSubject enables rewriting the subjects of the copied referrers,
+	// which are not converted by ConvertManifest themselves, to the converted
+	// subject manifests. Otherwise, the referrers of the converted docker
+	// manifests keep pointing at the original digests, and become dangling in
+	// the destination.
+	// The rewritten referrers are re-digested and pushed. The references to
+	// the converted manifests in the OCI image indexes are rewritten as well.
+	// RewriteSubject is effective only if ConvertManifest is set, since
+	// MapManifest always rewrites the subjects.
+	// Note: as the digests of the rewritten referrers change, signatures
+	// covering the referrer manifests are invalidated.
+	RewriteSubject bool
+	// RewriteURLs, if not nil, rewrites the descriptors of the configs,
+	// layers and blobs referenced by the manifests on copy, so that the URLs
+	// of the foreign layers can be pointed at a mirror. Only the URLs of the
+	// returned descriptor are taken.
+	// The manifests with rewritten URLs are re-digested and pushed, and the
+	// references in the parent indexes as well as the subjects of the
+	// referrers are updated accordingly.
+	// Note: as the digests of the rewritten manifests change, signatures
+	// covering them are invalidated.
+	RewriteURLs func(desc ocispec.Descriptor) ocispec.Descriptor
+	// MapBlob, if not nil, transforms the blobs, such as the configs and the
+	// layers, on copy, e.g. to recompress or to re-encrypt the layers.
+	// MapBlob is called with the descriptor and the content of each blob read
+	// from the source, and returns the descriptor and the content of the
+	// transformed blob, which is pushed to the destination instead. Returning
+	// desc and content as is leaves the blob unchanged.
+	// The returned descriptor replaces the original one in the manifests
+	// referencing the blob, so it should preserve the fields to be kept, such
+	// as the annotations. The manifests are re-digested and pushed, and the
+	// references in the parent indexes as well as the subjects of the
+	// referrers are updated accordingly.
+	// The configs are not updated: the diffIDs, which are the digests of the
+	// uncompressed layers, remain valid as long as the transformation
+	// preserves the uncompressed content, as recompression and encryption do.
+	// Since the transformed blobs are not known before they are copied, the
+	// blobs are always copied, and never mounted with MountFrom.
+	// The blobs not copied, such as with SkipBlobs or BaseManifest, cannot be
+	// mapped, and fail the copy of the manifests referencing them.
+	// Note: as the digests of the manifests change, signatures covering them
+	// are invalidated.
+	MapBlob func(ctx context.Context, desc ocispec.Descriptor, content io.Reader) (ocispec.Descriptor, io.Reader, error)
+	// IncludeForeignLayers enables copying the nondistributable (foreign)
+	// layers, which are downloaded from the URLs of their descriptors, tried
+	// in order, and pushed to the destination. Foreign layers without URLs
+	// are fetched from the source.
+	// Otherwise, the foreign layers are not transferred, and are left as URL
+	// references in the copied manifests, since they are usually not served
+	// by the source registry.
+	IncludeForeignLayers bool
+	// MountFrom returns the candidate repositories that desc may be mounted
+	// from, in the order of preference.
+	// If the destination implements registry.Mounter and MountFrom returns
+	// any candidate, the blobs, such as configs and layers, are mounted
+	// instead of being fetched from the source and pushed to the destination,
+	// where the candidates are tried in order. The blob is copied from the
+	// source if it cannot be mounted from any candidate. Manifests are always
+	// copied.
+	// It is useful for copying content between repositories in the same
+	// registry, saving the bandwidth of the blobs.
+	// If MountFrom is nil, blobs are never mounted.
+	MountFrom func(ctx context.Context, desc ocispec.Descriptor) ([]string, error)
+	// OnMounted is called after a blob is mounted, instead of PreCopy and
+	// PostCopy.
+	OnMounted func(ctx context.Context, desc ocispec.Descriptor) error
+	// ContinueOnError enables copying as much content as possible instead of
+	// aborting on the first failure. The nodes failed to be copied are
+	// reported by a *PartialCopyError returned after the traversal.
+	// A node is not copied, and thus not tagged, if any of its successors
+	// failed to be copied, and is reported with ErrIncompleteSuccessors.
+	// Unrelated nodes are copied regardless of the failures.
+	ContinueOnError bool
+	// SkipBlobs enables shallow copy, where only the manifests and indexes are
+	// copied, and the configs and layers referenced by them are skipped.
+	// It is useful for mirroring metadata, such as building a local index of
+	// remote tags.
+	// Note: the copied manifests reference blobs absent in the destination,
+	// and thus the result is NOT a pullable image. Destinations validating
+	// the references of manifests, such as some remote registries, may reject
+	// the shallow copy.
+	SkipBlobs bool
+	// VerifyOnCopy enables verifying the size and the digest of the content
+	// fetched from the source against its descriptor while the content is
+	// being pushed to the destination.
+	// On mismatch, the copy of the node is aborted with an error wrapping
+	// content.ErrMismatchedDigest, content.ErrTrailingData, or
+	// io.ErrUnexpectedEOF, and naming the descriptor.
+	// It provides an integrity guarantee regardless of whether the
+	// destination verifies the pushed content.
+	VerifyOnCopy bool
+	// EmptyJSONTracker, if not nil, tracks the destinations where the
+	// well-known empty JSON blob exists, so that the blob is copied at most
+	// once per destination, and its existence probe is skipped thereafter.
+	// The empty JSON blob skipped is reported by OnCopySkipped.
+	EmptyJSONTracker *EmptyJSONTracker
+	// Ordered enables copying the nodes one at a time in a deterministic
+	// depth-first order, where the successors of a node are copied in the
+	// order returned by FindSuccessors before the node itself. For manifests,
+	// the config is copied first, followed by the layers in the order of the
+	// manifest.
+	// It trades the throughput for determinism, which is useful for
+	// debugging and for registries sensitive to the push order.
+	// Concurrency is ignored if Ordered is set.
+	Ordered bool
+	// Acquire, if not nil, is called before transferring each node, and the
+	// returned release function is called after the transfer completes.
+	// It allows multiple simultaneous copies to draw from a shared budget,
+	// e.g. a semaphore, bounding the total load on the registries regardless
+	// of how many copies are in flight. Concurrency still limits each copy.
+	// If Acquire returns an error, the copy of the node fails with the error.
+	Acquire func(ctx context.Context) (release func(), err error)
+	// MaxTotalBytes limits the total number of bytes transferred across the
+	// whole graph, as accounted by the declared sizes of the nodes copied or
+	// mounted. A node that would exceed the limit is not copied, and the copy
+	// is aborted with a *QuotaExceededError, reporting the bytes transferred
+	// before the abort, regardless of ContinueOnError. The copies in flight
+	// are canceled via the context.
+	// If less than or equal to 0, the total bytes are not limited.
+	MaxTotalBytes int64
+	// MaxGraphRetries is the maximum number of times the traversal of the
+	// graph is re-run when the copy fails with a retryable error, such as a
+	// network failure, a throttling response, or a server error. The nodes
+	// completed by the previous runs are skipped as they exist in the
+	// destination. Errors like authentication failures, missing content, and
+	// canceled contexts are not retried.
+	// If less than or equal to 0, the copy is not retried.
+	MaxGraphRetries int
+	// GraphRetryBackoff is the delay before the first retry of the graph,
+	// which is doubled on each subsequent retry.
+	// If less than or equal to 0, a default (currently 1 second) is used.
+	GraphRetryBackoff time.Duration
+	// MaxBytesPerSecond limits the throughput of the content read from the
+	// source, collectively across all the concurrent copies of the operation,
+	// e.g. to avoid saturating a shared uplink on background mirroring.
+	// The copies are throttled with a token bucket holding the bytes of one
+	// second, and are canceled promptly with the context while throttled.
+	// If less than or equal to 0, the throughput is not limited.
+	MaxBytesPerSecond int64
+	// ManifestFirst, if set, attempts to push each image manifest before its
+	// blobs. If the destination rejects the manifest reporting the unknown
+	// blobs, e.g. with the BLOB_UNKNOWN error of a remote registry, only the
+	// missing blobs are copied before the manifest is pushed again. It saves
+	// the existence checks of the blobs when most of them are already present
+	// in the destination. On any other error, the blobs are copied as usual.
+	// If the manifest is accepted, the blobs are still checked and copied if
+	// missing, as destinations such as memory.Store accept manifests
+	// referencing absent blobs.
+	// PreCopy is called once for the manifest before the first attempt.
+	// ManifestFirst is experimental and may be changed or removed.
+	ManifestFirst bool
+	// BaseManifest, if not nil, is the descriptor of the manifest of a base
+	// image existing in the destination. The blobs referenced by the base are
+	// considered existing in the destination without checking, so that only
+	// the layers changed from the base are copied. It saves the existence
+	// checks on the registries where they are slow.
+	// The base is fetched from the destination once before the copy.
+	BaseManifest *ocispec.Descriptor
+	// SourceCache, if not nil, is a read-through cache of the blobs fetched
+	// from the source: the blobs are fetched from the cache if present, and
+	// from the source otherwise, populating the cache as they are read.
+	// Sharing the cache across multiple copies, e.g. of several images built
+	// on the same base, fetches the shared layers from the source only once.
+	// The cache should be bounded, such as the one returned by
+	// memory.NewLRU. It is best effort: the content failing to be cached,
+	// e.g. exceeding the bound, is still copied.
+	SourceCache content.Storage
+	// OnOverallProgress, if not nil, is called with the overall progress of
+	// the copy as it progresses, e.g. to render "X of Y bytes, N of M blobs".
+	// The totals are computed by walking the graph once before the copy
+	// starts, where the nodes shared in the graph are counted once.
+	// The calls are serialized. The referrers copied by
+	// CopyOptions.WithReferrers are reported as separate graphs.
+	OnOverallProgress func(stats CopyStats)
+	// Logger, if not nil, logs the significant steps of the copy, such as
+	// resolving, copying, mounting, and retrying, as structured events.
+	Logger Logger
+	// LayerFilter, if not nil, selects the layers of the image manifests to
+	// be copied. The layers for which LayerFilter returns false are not
+	// copied, while the manifests and their configs are always copied.
+	// LayerFilter may be called more than once for the same layer.
+	// It is useful for mirroring the metadata with the selected layers only.
+	// Note: the copied manifests still reference the layers filtered out,
+	// which are absent in the destination unless copied otherwise, and thus
+	// the result is intentionally incomplete and may NOT be pullable.
+	// Destinations validating the references of manifests, such as some
+	// remote registries, may reject the manifests.
+	LayerFilter func(desc ocispec.Descriptor) bool
+	// AllowedMediaTypes, if not empty, lists the media types accepted by the
+	// destination, such as a registry rejecting artifact manifests.
+	// The graph is traversed before copying, and the copy fails early with
+	// errdef.ErrUnsupported if any node to be copied has a media type not in
+	// the list, instead of failing on pushing the node amid the copy.
+	// If ConvertManifest is set, the docker media types are checked against
+	// their OCI equivalents, so that docker manifests can be converted
+	// rather than rejected. The media types produced by MapManifest and
+	// MapBlob are not checked.
+	AllowedMediaTypes []string
+
+	// baseBlobs is the set of the blobs referenced by BaseManifest.
+	baseBlobs map[digest.Digest]bool
+	// quota tracks MaxTotalBytes, shared by the copies of the same operation.
+	quota *transferQuota
+	// bandwidth tracks MaxBytesPerSecond, shared by the copies of the same
+	// operation.
+	bandwidth *bandwidthLimiter
+	// progress tracks OnOverallProgress across the retries of the graph.
+	progress *copyProgress
+	// converter converts the manifests and maps the blobs, shared by the
+	// copies of the same operation so that each manifest is mapped once.
+	converter *manifestConverter
 }
 
 // Copy copies a rooted directed acyclic graph (DAG) with the tagged root node
 // in the source Target to the destination Target.
 // The destination reference will be the same as the source reference if the
 // destination reference is left blank.
+// If the destination implements content.Flusher, it is flushed on completion.
 // Returns the descriptor of the root node on successful copy.
 func Copy(ctx context.Context, src ReadOnlyTarget, srcRef string, dst Target, dstRef string, opts CopyOptions) (ocispec.Descriptor, error) {
 	if src == nil {
@@ -123,13 +444,22 @@ func Copy(ctx context.Context, src ReadOnlyTarget, srcRef string, dst Target, ds
 	if dstRef == "" {
 		dstRef = srcRef
 	}
+	ctx = withCopyScopeHints(ctx, src, srcRef, dst, dstRef)
 
 	// use caching proxy on non-leaf nodes
 	if opts.MaxMetadataBytes <= 0 {
 		opts.MaxMetadataBytes = defaultCopyMaxMetadataBytes
 	}
 	proxy := cas.NewProxyWithLimit(src, cas.NewMemory(), opts.MaxMetadataBytes)
-	root, err := resolveRoot(ctx, src, srcRef, proxy)
+	// share the quota and the bandwidth with the copies of the referrers
+	opts.quota = newTransferQuota(opts.MaxTotalBytes)
+	opts.bandwidth = newBandwidthLimiter(opts.MaxBytesPerSecond)
+	var start time.Time
+	if opts.Logger != nil {
+		start = time.Now()
+	}
+	root, err := resolveRoot(ctx, src, srcRef, proxy, opts.MaxMetadataBytes)
+	logNode(ctx, opts.Logger, "resolve", root, start, err, "reference", srcRef)
 	if err != nil {
 		return ocispec.Descriptor{}, err
 	}
@@ -143,7 +473,44 @@ func Copy(ctx context.Context, src ReadOnlyTarget, srcRef string, dst Target, ds
 		proxy.StopCaching = false
 	}
 
-	if err := prepareCopy(ctx, dst, dstRef, proxy, root, &opts); err != nil {
+	// keep the original options for copying referrers before the hooks for
+	// tagging the root node are installed
+	graphOpts := opts.CopyGraphOptions
+
+	target := dst
+	converter := newManifestConverter(proxy, opts.CopyGraphOptions)
+	if converter != nil {
+		dst = converter.Target(dst)
+	}
+
+	// convertRoot returns the descriptor of the root node as copied to the
+	// destination
+	convertRoot := func(ctx context.Context) (ocispec.Descriptor, error) {
+		if converter == nil {
+			return root, nil
+		}
+		return converter.ConvertDescriptor(ctx, root)
+	}
+
+	// tag the root node with the temporary tag until the copy completes if
+	// the destination tag is to be moved atomically, where the temporary tag
+	// is derived from the converted root node once it is copied
+	rootRef := staticReference(dstRef)
+	atomicTag := false
+	if opts.AtomicTag {
+		if tagRef, _ := copiedReferences(target, dstRef, root); tagRef != "" {
+			rootRef = func(ctx context.Context) (string, error) {
+				copied, err := convertRoot(ctx)
+				if err != nil {
+					return "", err
+				}
+				return atomicTempTag(copied.Digest), nil
+			}
+			atomicTag = true
+		}
+	}
+
+	if err := prepareCopy(ctx, dst, rootRef, proxy, root, &opts); err != nil {
 		return ocispec.Descriptor{}, err
 	}
 
@@ -151,29 +518,320 @@ func Copy(ctx context.Context, src ReadOnlyTarget, srcRef string, dst Target, ds
 		return ocispec.Descriptor{}, err
 	}
 
+	if opts.WithReferrers {
+		if err := copyReferrers(ctx, src, dst, proxy, root, opts.ReferrersDepth, graphOpts); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
+	copied, err := convertRoot(ctx)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	if opts.TagDigest {
+		if err := dst.Tag(ctx, root, digestTag(copied.Digest)); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
+	if atomicTag {
+		if err := dst.Tag(ctx, root, dstRef); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
+	if err := flush(ctx, dst); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	root = copied
+	if opts.OnCopied != nil {
+		tagRef, digestRef := copiedReferences(target, dstRef, root)
+		if err := opts.OnCopied(ctx, root, tagRef, digestRef); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
 	return root, nil
 }
 
+// digestTag returns the tag derived from dgst in the form of
+// `<alg>-<encoded>.digest`, where `<encoded>` is truncated to 64 characters to
+// fit in the maximum tag length.
+func digestTag(dgst digest.Digest) string {
+	return referrersTag(dgst) + ".digest"
+}
+
+// atomicTempTag returns the temporary tag of the root node with the digest
+// dgst for CopyOptions.AtomicTag, in the form of `tmp_<alg>_<encoded>`, where
+// `<encoded>` is truncated to 64 characters to fit in the maximum tag length.
+// Unlike the digest tag, it is not prefixed by the referrers tag of dgst so
+// that it is not mistaken for one.
+func atomicTempTag(dgst digest.Digest) string {
+	encoded := dgst.Encoded()
+	if len(encoded) > 64 {
+		encoded = encoded[:64]
+	}
+	return "tmp_" + dgst.Algorithm().String() + "_" + encoded
+}
+
+// copiedReferences returns the references of root in dst by tag and by digest,
+// which are fully qualified if dst parses references.
+func copiedReferences(dst content.Storage, dstRef string, root ocispec.Descriptor) (tagRef, digestRef string) {
+	if parser, ok := dst.(interfaces.ReferenceParser); ok {
+		if ref, err := parser.ParseReference(dstRef); err == nil {
+			if _, err := ref.Digest(); err != nil {
+				tagRef = ref.String()
+			}
+			ref.Reference = root.Digest.String()
+			return tagRef, ref.String()
+		}
+	}
+	if _, err := digest.Parse(dstRef); err != nil {
+		tagRef = dstRef
+	}
+	return tagRef, root.Digest.String()
+}
+
+// CopyFromDescriptor copies a rooted directed acyclic graph (DAG) with the root
+// node described by desc in the source CAS to the destination Target, and tags
+// the root node with dstRef in the destination if dstRef is not empty.
+// It is useful for copying content known by its descriptor, without requiring
+// the content to be tagged in the source.
+// If the destination implements content.Flusher, it is flushed on completion.
+// Returns the descriptor of the root node on successful copy.
+func CopyFromDescriptor(ctx context.Context, src content.ReadOnlyStorage, desc ocispec.Descriptor, dst Target, dstRef string, opts CopyGraphOptions) (ocispec.Descriptor, error) {
+	if src == nil {
+		return ocispec.Descriptor{}, errors.New("nil source storage")
+	}
+	if dst == nil {
+		return ocispec.Descriptor{}, errors.New("nil destination target")
+	}
+
+	// use caching proxy on non-leaf nodes
+	if opts.MaxMetadataBytes <= 0 {
+		opts.MaxMetadataBytes = defaultCopyMaxMetadataBytes
+	}
+	proxy := cas.NewProxyWithLimit(src, cas.NewMemory(), opts.MaxMetadataBytes)
+
+	converter := newManifestConverter(proxy, opts)
+	if converter != nil {
+		dst = converter.Target(dst)
+	}
+
+	copyOpts := CopyOptions{
+		CopyGraphOptions: opts,
+	}
+	if dstRef != "" {
+		if err := prepareCopy(ctx, dst, staticReference(dstRef), proxy, desc, &copyOpts); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
+	if err := copyGraph(ctx, src, dst, proxy, desc, copyOpts.CopyGraphOptions); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	if err := flush(ctx, dst); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	if converter != nil {
+		return converter.ConvertDescriptor(ctx, desc)
+	}
+	return desc, nil
+}
+
+// CopyBlobOptions contains parameters for oras.CopyBlob.
+type CopyBlobOptions struct {
+	// PreCopy handles the blob before it is copied.
+	PreCopy func(ctx context.Context, desc ocispec.Descriptor) error
+	// PostCopy handles the blob after it is copied.
+	PostCopy func(ctx context.Context, desc ocispec.Descriptor) error
+	// OnProgress, if provided, is called with the cumulative number of bytes
+	// copied as the blob is read from the source.
+	OnProgress func(desc ocispec.Descriptor, copied int64)
+}
+
+// CopyBlob copies a single blob identified by desc from src to dst, without
+// copying its successors.
+// The size and the digest of the blob are verified while it is copied.
+// No error is returned if the blob already exists in dst.
+func CopyBlob(ctx context.Context, src content.Fetcher, dst content.Pusher, desc ocispec.Descriptor, opts CopyBlobOptions) error {
+	if opts.PreCopy != nil {
+		if err := opts.PreCopy(ctx, desc); err != nil {
+			return err
+		}
+	}
+
+	var progress func(copied int64)
+	if opts.OnProgress != nil {
+		progress = func(copied int64) {
+			opts.OnProgress(desc, copied)
+		}
+	}
+	if err := doCopyNode(ctx, src, dst, desc, true, progress); err != nil {
+		return err
+	}
+
+	if opts.PostCopy != nil {
+		return opts.PostCopy(ctx, desc)
+	}
+	return nil
+}
+
 // CopyGraph copies a rooted directed acyclic graph (DAG) from the source CAS to
 // the destination CAS.
+// If the destination implements content.Flusher, it is flushed on completion.
 func CopyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, root ocispec.Descriptor, opts CopyGraphOptions) error {
 	// use caching proxy on non-leaf nodes
 	if opts.MaxMetadataBytes <= 0 {
 		opts.MaxMetadataBytes = defaultCopyMaxMetadataBytes
 	}
 	proxy := cas.NewProxyWithLimit(src, cas.NewMemory(), opts.MaxMetadataBytes)
-	return copyGraph(ctx, src, dst, proxy, root, opts)
+	converter := opts.converter
+	if converter == nil {
+		converter = newManifestConverter(proxy, opts)
+	}
+	if converter != nil {
+		dst = converter.Storage(dst)
+	}
+	if err := copyGraph(ctx, src, dst, proxy, root, opts); err != nil {
+		return err
+	}
+	return flush(ctx, dst)
+}
+
+// withCopyScopeHints adds the scope hints of copying from srcRef in src to
+// dstRef in dst to the context if they are remote repositories, i.e. pulling
+// from the source repository, and pulling and pushing to the destination
+// repository. With the union of the scopes hinted up front, the auth client
+// fetches a single token for all the requests to each registry, instead of
+// fetching a new token on the challenge of each new scope.
+func withCopyScopeHints(ctx context.Context, src content.ReadOnlyStorage, srcRef string, dst content.Storage, dstRef string) context.Context {
+	if parser, ok := src.(interfaces.ReferenceParser); ok {
+		if ref, err := parser.ParseReference(srcRef); err == nil {
+			ctx = registryutil.WithHostScopeHint(ctx, ref, auth.ActionPull)
+		}
+	}
+	if parser, ok := dst.(interfaces.ReferenceParser); ok {
+		if ref, err := parser.ParseReference(dstRef); err == nil {
+			ctx = registryutil.WithHostScopeHint(ctx, ref, auth.ActionPull, auth.ActionPush)
+		}
+	}
+	return ctx
+}
+
+// flush persists the writes buffered by dst if dst implements content.Flusher.
+func flush(ctx context.Context, dst content.Storage) error {
+	if flusher, ok := dst.(content.Flusher); ok {
+		return flusher.Flush(ctx)
+	}
+	return nil
 }
 
 // copyGraph copies a rooted directed acyclic graph (DAG) from the source CAS to
-// the destination CAS with specified caching.
+// the destination CAS with specified caching, retrying the traversal on
+// retryable errors up to opts.MaxGraphRetries times.
 func copyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, proxy *cas.Proxy, root ocispec.Descriptor, opts CopyGraphOptions) error {
+	if opts.quota == nil {
+		opts.quota = newTransferQuota(opts.MaxTotalBytes)
+	}
+	if opts.bandwidth == nil {
+		opts.bandwidth = newBandwidthLimiter(opts.MaxBytesPerSecond)
+	}
+	if len(opts.AllowedMediaTypes) > 0 {
+		if err := checkMediaTypes(ctx, proxy, root, graphSuccessors(opts), opts); err != nil {
+			return err
+		}
+	}
+	if opts.OnOverallProgress != nil && opts.progress == nil {
+		progress, err := newCopyProgress(ctx, proxy, root, graphSuccessors(opts), opts.OnOverallProgress)
+		if err != nil {
+			return err
+		}
+		opts.progress = progress
+	}
+	if opts.SourceCache != nil {
+		src = &sourceCacheStorage{
+			ReadOnlyStorage: src,
+			cache:           opts.SourceCache,
+		}
+	}
+	if opts.BaseManifest != nil && opts.baseBlobs == nil {
+		blobs, err := baseBlobs(ctx, storageOf(dst), *opts.BaseManifest)
+		if err != nil {
+			return err
+		}
+		opts.baseBlobs = blobs
+	}
+	backoff := opts.GraphRetryBackoff
+	if backoff <= 0 {
+		backoff = defaultGraphRetryBackoff
+	}
+	for retry := 0; ; retry++ {
+		err := copyGraphOnce(ctx, src, dst, proxy, root, opts)
+		if err == nil || retry >= opts.MaxGraphRetries || !isRetryable(err) {
+			return err
+		}
+		logNode(ctx, opts.Logger, "retry", root, time.Time{}, err, "attempt", retry+1, "backoff", backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// graphSuccessors returns the function finding the successors of the nodes to
+// be copied, honoring opts.FindSuccessors, opts.LayerFilter, opts.SkipBlobs,
+// and opts.IncludeForeignLayers.
+func graphSuccessors(opts CopyGraphOptions) func(context.Context, content.Fetcher, ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	// if FindSuccessors is not provided, use the default one
+	findSuccessors := opts.FindSuccessors
+	if findSuccessors == nil {
+		findSuccessors = content.Successors
+	}
+	if opts.LayerFilter != nil {
+		findSuccessors = filterLayers(findSuccessors, opts.LayerFilter)
+	}
+	if opts.SkipBlobs {
+		return skipBlobs(findSuccessors)
+	}
+	if opts.IncludeForeignLayers {
+		return findSuccessors
+	}
+	return skipForeignLayers(findSuccessors)
+}
+
+// copyGraphOnce traverses the graph once for copyGraph.
+func copyGraphOnce(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, proxy *cas.Proxy, root ocispec.Descriptor, opts CopyGraphOptions) error {
 	// track content status
 	tracker := status.NewTracker()
+	failures := newCopyFailures(opts.ContinueOnError)
+	// the empty JSON blob is tracked against the unwrapped destination, which
+	// is stable across copies.
+	trackedDst := storageOf(dst)
+	// pending maps the manifests rejected on ManifestFirst to their missing
+	// successors.
+	var pending sync.Map
+	// skip marks the descriptor as done on failure if failures are recorded,
+	// so that the copy continues.
+	skip := func(desc ocispec.Descriptor, done chan struct{}, err error) error {
+		if failures == nil {
+			return err
+		}
+		failures.record(desc, err)
+		close(done)
+		return graph.ErrSkipDesc
+	}
 
-	// if FindSuccessors is not provided, use the default one
-	if opts.FindSuccessors == nil {
-		opts.FindSuccessors = content.Successors
+	opts.FindSuccessors = graphSuccessors(opts)
+	if !opts.SkipBlobs && opts.IncludeForeignLayers {
+		src = &foreignLayerStorage{ReadOnlyStorage: src}
 	}
 
 	// prepare pre-handler
@@ -184,14 +842,25 @@ func copyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Sto
 			return nil, graph.ErrSkipDesc
 		}
 
-		// skip if a rooted sub-DAG exists
-		exists, err := dst.Exists(ctx, desc)
-		if err != nil {
-			return nil, err
+		// skip if a rooted sub-DAG exists, where the probes of the empty JSON
+		// blob and the blobs of the base known to exist are skipped
+		exists := opts.baseBlobs[desc.Digest] ||
+			content.IsEmptyJSON(desc) && opts.EmptyJSONTracker.exists(trackedDst)
+		if !exists {
+			var err error
+			exists, err = dst.Exists(ctx, desc)
+			if err != nil {
+				return nil, skip(desc, done, err)
+			}
+			if exists && content.IsEmptyJSON(desc) {
+				opts.EmptyJSONTracker.add(trackedDst)
+			}
 		}
 		if exists {
 			// mark the content as done
 			close(done)
+			opts.progress.skip(desc)
+			logNode(ctx, opts.Logger, "skip", desc, time.Time{}, nil)
 			if opts.OnCopySkipped != nil {
 				if err := opts.OnCopySkipped(ctx, desc); err != nil {
 					return nil, err
@@ -200,8 +869,29 @@ func copyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Sto
 			return nil, graph.ErrSkipDesc
 		}
 
+		// reject oversized content before fetching and decoding it
+		if err := checkSize(desc, opts); err != nil {
+			return nil, skip(desc, done, err)
+		}
+
 		// find successors while non-leaf nodes will be fetched and cached
-		return opts.FindSuccessors(ctx, proxy, desc)
+		successors, err := opts.FindSuccessors(ctx, proxy, desc)
+		if err != nil {
+			return nil, skip(desc, done, err)
+		}
+		if opts.ManifestFirst && len(successors) > 0 && isImageManifest(desc) {
+			p, err := pushManifestFirst(ctx, proxy, dst, desc, successors, opts)
+			if err != nil {
+				if errors.Is(err, ErrQuotaExceeded) {
+					// abort the copy
+					return nil, err
+				}
+				return nil, skip(desc, done, err)
+			}
+			pending.Store(descriptor.FromOCI(desc), p)
+			return p.successors, nil
+		}
+		return successors, nil
 	})
 
 	// prepare post-handler
@@ -211,24 +901,60 @@ func copyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Sto
 				// mark the content as done on success
 				done, _ := tracker.TryCommit(desc)
 				close(done)
+				if !failures.Failed(desc) {
+					opts.progress.complete(desc)
+				}
 			}
 		}()
 
 		// leaf nodes does not exist in the cache.
 		// copy them directly.
+		// on failure, the content is marked as done as well if failures
+		// are recorded.
 		exists, err := proxy.Cache.Exists(ctx, desc)
 		if err != nil {
-			return nil, err
+			return nil, failures.record(desc, err)
 		}
 		if !exists {
-			return nil, copyNode(ctx, src, dst, desc, opts)
+			if err := opts.quota.transfer(desc, func() error {
+				return withTransferSlot(ctx, opts, func() error {
+					return mountOrCopyNode(ctx, src, dst, desc, opts)
+				})
+			}); err != nil {
+				if errors.Is(err, ErrQuotaExceeded) {
+					// abort the copy
+					return nil, err
+				}
+				return nil, failures.record(desc, err)
+			}
+			if content.IsEmptyJSON(desc) {
+				opts.EmptyJSONTracker.add(trackedDst)
+			}
+			return nil, nil
 		}
 
 		// for non-leaf nodes, wait for its successors to complete
-		successors, err := opts.FindSuccessors(ctx, proxy, desc)
-		if err != nil {
-			return nil, err
+		nodeOpts := opts
+		var successors []ocispec.Descriptor
+		var pushed *pendingManifest
+		if value, ok := pending.Load(descriptor.FromOCI(desc)); ok {
+			// the manifest has been pushed once
+			p := value.(pendingManifest)
+			successors = p.successors
+			if p.pushed {
+				pushed = &p
+			}
+			if !p.retryPreCopy {
+				// PreCopy has been called
+				nodeOpts.PreCopy = nil
+			}
+		} else {
+			successors, err = opts.FindSuccessors(ctx, proxy, desc)
+			if err != nil {
+				return nil, failures.record(desc, err)
+			}
 		}
+		incomplete := false
 		for _, node := range successors {
 			done, committed := tracker.TryCommit(node)
 			if committed {
@@ -239,31 +965,326 @@ func copyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Sto
 			case <-ctx.Done():
 				return nil, ctx.Err()
 			}
+			if failures.Failed(node) {
+				incomplete = true
+			}
 		}
-		return nil, copyNode(ctx, proxy.Cache, dst, desc, opts)
+		if incomplete {
+			// do not copy the node referencing absent successors
+			return nil, failures.record(desc, ErrIncompleteSuccessors)
+		}
+		if pushed != nil {
+			// the manifest has been accepted on ManifestFirst
+			if pushed.postCopy && opts.PostCopy != nil {
+				if err := opts.PostCopy(ctx, desc); err != nil {
+					return nil, failures.record(desc, err)
+				}
+			}
+			return nil, nil
+		}
+		if err := opts.quota.transfer(desc, func() error {
+			return withTransferSlot(ctx, opts, func() error {
+				return copyNode(ctx, proxy.Cache, dst, desc, nodeOpts)
+			})
+		}); err != nil {
+			if errors.Is(err, ErrQuotaExceeded) {
+				// abort the copy
+				return nil, err
+			}
+			return nil, failures.record(desc, err)
+		}
+		return nil, nil
 	})
 
+	// traverse the graph
+	if opts.Ordered {
+		if err := graph.Walk(ctx, preHandler, postHandler, root); err != nil {
+			return err
+		}
+		return failures.Err()
+	}
 	if opts.Concurrency <= 0 {
 		opts.Concurrency = defaultConcurrency
 	}
-	// traverse the graph
-	return graph.Dispatch(ctx, preHandler, postHandler, semaphore.NewWeighted(opts.Concurrency), root)
+	if err := graph.Dispatch(ctx, preHandler, postHandler, semaphore.NewWeighted(opts.Concurrency), root); err != nil {
+		return err
+	}
+	return failures.Err()
+}
+
+// withTransferSlot calls transfer while holding a slot acquired by
+// opts.Acquire.
+func withTransferSlot(ctx context.Context, opts CopyGraphOptions, transfer func() error) error {
+	if opts.Acquire == nil {
+		return transfer()
+	}
+	release, err := opts.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return transfer()
+}
+
+// skipBlobs wraps findSuccessors so that only the manifest successors are
+// returned.
+func skipBlobs(findSuccessors func(context.Context, content.Fetcher, ocispec.Descriptor) ([]ocispec.Descriptor, error)) func(context.Context, content.Fetcher, ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	return func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		successors, err := findSuccessors(ctx, fetcher, desc)
+		if err != nil {
+			return nil, err
+		}
+		var manifests []ocispec.Descriptor
+		for _, successor := range successors {
+			if descriptor.IsManifest(successor) {
+				manifests = append(manifests, successor)
+			}
+		}
+		return manifests, nil
+	}
+}
+
+// fetchManifest safely fetches the manifest described by desc, which is
+// rejected if it exceeds content.MaxManifestBytes.
+func fetchManifest(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]byte, error) {
+	if err := content.CheckManifestSize(desc, content.MaxManifestBytes); err != nil {
+		return nil, err
+	}
+	return content.FetchAll(ctx, fetcher, desc)
+}
+
+// checkSize checks the declared size of the descriptor against the size
+// limits in the options before the content is fetched.
+func checkSize(desc ocispec.Descriptor, opts CopyGraphOptions) error {
+	if descriptor.IsManifest(desc) {
+		if desc.Size > opts.MaxMetadataBytes {
+			return fmt.Errorf("%s: %s: content size %v exceeds MaxMetadataBytes %v: %w",
+				desc.Digest, desc.MediaType, desc.Size, opts.MaxMetadataBytes, content.ErrManifestTooLarge)
+		}
+		return nil
+	}
+	if opts.MaxBlobBytes > 0 && desc.Size > opts.MaxBlobBytes {
+		return fmt.Errorf("%s: %s: content size %v exceeds MaxBlobBytes %v: %w",
+			desc.Digest, desc.MediaType, desc.Size, opts.MaxBlobBytes, errdef.ErrSizeExceedsLimit)
+	}
+	return nil
+}
+
+// copyReferrers copies the referrers of the root node, and of the manifests
+// in the root node if it is an index, as well as their sub-DAGs, level by
+// level, up to the given depth.
+// The referrers are deduplicated by digest across all the subjects, so that
+// each of them is copied once.
+func copyReferrers(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, proxy *cas.Proxy, root ocispec.Descriptor, depth int, opts CopyGraphOptions) error {
+	if depth <= 0 {
+		depth = defaultReferrersDepth
+	}
+	subjects := []ocispec.Descriptor{root}
+	switch root.MediaType {
+	case docker.MediaTypeManifestList, ocispec.MediaTypeImageIndex:
+		indexJSON, err := content.FetchAll(ctx, proxy, root)
+		if err != nil {
+			return err
+		}
+		var index ocispec.Index
+		if err := json.Unmarshal(indexJSON, &index); err != nil {
+			return err
+		}
+		subjects = append(subjects, index.Manifests...)
+	}
+	visited := make(map[digest.Digest]bool)
+	for _, subject := range subjects {
+		visited[subject.Digest] = true
+	}
+	for level := 0; level < depth && len(subjects) > 0; level++ {
+		var next []ocispec.Descriptor
+		for _, subject := range subjects {
+			referrers, err := findReferrers(ctx, src, proxy, subject)
+			if err != nil {
+				return err
+			}
+			for _, referrer := range referrers {
+				if visited[referrer.Digest] {
+					continue
+				}
+				visited[referrer.Digest] = true
+				if err := copyGraph(ctx, src, dst, proxy, referrer, opts); err != nil {
+					return err
+				}
+				next = append(next, referrer)
+			}
+		}
+		subjects = next
+	}
+	return nil
+}
+
+// findReferrers returns the manifests referencing the given subject in src.
+// Returns nil without error if src does not support finding referrers.
+func findReferrers(ctx context.Context, src content.ReadOnlyStorage, fetcher content.Fetcher, subject ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	if rf, ok := src.(registry.ReferrerFinder); ok {
+		var referrers []ocispec.Descriptor
+		err := rf.Referrers(ctx, subject, "", func(page []ocispec.Descriptor) error {
+			referrers = append(referrers, page...)
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, errdef.ErrNotFound) || errors.Is(err, errdef.ErrUnsupported) {
+				// the referrers API is not supported by the source
+				return nil, nil
+			}
+			return nil, err
+		}
+		return referrers, nil
+	}
+
+	pf, ok := src.(content.PredecessorFinder)
+	if !ok {
+		return nil, nil
+	}
+	predecessors, err := pf.Predecessors(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	// predecessors include the manifests and indexes pointing to the subject
+	// and thus should be filtered.
+	var referrers []ocispec.Descriptor
+	for _, predecessor := range predecessors {
+		switch predecessor.MediaType {
+		case ocispec.MediaTypeImageManifest, ocispec.MediaTypeArtifactManifest,
+			artifactspec.MediaTypeArtifactManifest:
+			manifestJSON, err := fetchManifest(ctx, fetcher, predecessor)
+			if err != nil {
+				return nil, err
+			}
+			var manifest struct {
+				Subject *ocispec.Descriptor `json:"subject,omitempty"`
+			}
+			if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+				return nil, err
+			}
+			if manifest.Subject != nil && manifest.Subject.Digest == subject.Digest {
+				referrers = append(referrers, predecessor)
+			}
+		}
+	}
+	return referrers, nil
 }
 
 // doCopyNode copies a single content from the source CAS to the destination CAS.
-func doCopyNode(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, desc ocispec.Descriptor) error {
+// If progress is not nil, it is called with the cumulative number of bytes
+// read from the source.
+func doCopyNode(ctx context.Context, src content.Fetcher, dst content.Pusher, desc ocispec.Descriptor, verify bool, progress func(copied int64)) error {
 	rc, err := src.Fetch(ctx, desc)
 	if err != nil {
 		return err
 	}
 	defer rc.Close()
-	err = dst.Push(ctx, desc, rc)
+	var fr io.Reader = rc
+	if progress != nil {
+		fr = content.NewProgressReader(rc, desc.Size, progress)
+	}
+	lr := newSizeLimitedReader(fr, desc)
+	var r io.Reader = lr
+	var vr *verifyingReader
+	if verify {
+		vr = newVerifyingReader(lr, desc)
+		r = vr
+	}
+	err = dst.Push(ctx, desc, r)
+	// report the verification failure or the oversized content regardless
+	// of how the destination wraps the read error.
+	if vr != nil && vr.err != nil {
+		return vr.err
+	}
+	if lr.err != nil {
+		return lr.err
+	}
 	if err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
 		return err
 	}
 	return nil
 }
 
+// verifyingReader reads the content and verifies it against the descriptor.
+// Instead of io.EOF, the verification error is returned on mismatch so that
+// the destination aborts the push before the content is committed.
+type verifyingReader struct {
+	vr   *content.VerifyReader
+	desc ocispec.Descriptor
+	err  error // verification error
+}
+
+// newVerifyingReader wraps r for reading content verified against desc.
+func newVerifyingReader(r io.Reader, desc ocispec.Descriptor) *verifyingReader {
+	return &verifyingReader{
+		vr:   content.NewVerifyReader(r, desc),
+		desc: desc,
+	}
+}
+
+// Read reads up to len(p) bytes into p, and verifies the content on EOF.
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.vr.Read(p)
+	switch {
+	case err == io.EOF:
+		if verr := r.vr.Verify(); verr != nil {
+			r.err = fmt.Errorf("%s: %s: %w", r.desc.Digest, r.desc.MediaType, verr)
+			return n, r.err
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		r.err = fmt.Errorf("%s: %s: %w", r.desc.Digest, r.desc.MediaType, err)
+		return n, r.err
+	}
+	return n, err
+}
+
+// sizeLimitedReader reads the content up to the size declared by the
+// descriptor, and fails with errdef.ErrSizeExceedsLimit if the content has
+// more data.
+type sizeLimitedReader struct {
+	r    io.Reader
+	n    int64 // remaining bytes
+	desc ocispec.Descriptor
+	err  error // size limit error
+}
+
+// newSizeLimitedReader wraps r for reading content limited by the size of desc.
+func newSizeLimitedReader(r io.Reader, desc ocispec.Descriptor) *sizeLimitedReader {
+	return &sizeLimitedReader{
+		r:    r,
+		n:    desc.Size,
+		desc: desc,
+	}
+}
+
+// Read reads up to len(p) bytes into p without exceeding the declared size.
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.n <= 0 {
+		// peek for trailing data
+		var peek [1]byte
+		n, err := r.r.Read(peek[:])
+		if n > 0 {
+			r.err = fmt.Errorf("%s: %s: content exceeds the declared size %v: %w",
+				r.desc.Digest, r.desc.MediaType, r.desc.Size, errdef.ErrSizeExceedsLimit)
+			return 0, r.err
+		}
+		return 0, err
+	}
+	if int64(len(p)) > r.n {
+		p = p[:r.n]
+	}
+	n, err := r.r.Read(p)
+	r.n -= int64(n)
+	return n, err
+}
+
 // copyNode copies a single content from the source CAS to the destination CAS,
 // and apply the given options.
 func copyNode(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, desc ocispec.Descriptor, opts CopyGraphOptions) error {
@@ -276,7 +1297,13 @@ func copyNode(ctx context.Context, src content.ReadOnlyStorage, dst content.Stor
 		}
 	}
 
-	if err := doCopyNode(ctx, src, dst, desc); err != nil {
+	var start time.Time
+	if opts.Logger != nil {
+		start = time.Now()
+	}
+	err := doCopyNode(ctx, opts.progress.fetcher(opts.bandwidth.fetcher(src)), dst, desc, opts.VerifyOnCopy, nil)
+	logNode(ctx, opts.Logger, "copy", desc, start, err)
+	if err != nil {
 		return err
 	}
 
@@ -286,6 +1313,100 @@ func copyNode(ctx context.Context, src content.ReadOnlyStorage, dst content.Stor
 	return nil
 }
 
+// mountOrCopyNode tries to mount a single blob from the candidate repositories
+// returned by opts.MountFrom, and copies it from the source CAS to the
+// destination CAS if it cannot be mounted.
+func mountOrCopyNode(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, desc ocispec.Descriptor, opts CopyGraphOptions) error {
+	mounter, ok := mounterOf(dst)
+	if !ok || opts.MountFrom == nil || descriptor.IsManifest(desc) {
+		return copyNode(ctx, src, dst, desc, opts)
+	}
+	sourceRepositories, err := opts.MountFrom(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if len(sourceRepositories) == 0 {
+		return copyNode(ctx, src, dst, desc, opts)
+	}
+
+	// errSkipSource is returned by getContent to try the next candidate.
+	errSkipSource := errors.New("skip source")
+	var start time.Time
+	if opts.Logger != nil {
+		start = time.Now()
+	}
+	for i, sourceRepository := range sourceRepositories {
+		// the invocation of getContent indicates that the mount has failed
+		mountFailed := false
+		getContent := func() (io.ReadCloser, error) {
+			mountFailed = true
+			if i < len(sourceRepositories)-1 {
+				return nil, errSkipSource
+			}
+
+			// copy the content from the source on the last candidate
+			if opts.PreCopy != nil {
+				if err := opts.PreCopy(ctx, desc); err != nil {
+					return nil, err
+				}
+			}
+			rc, err := opts.progress.fetcher(opts.bandwidth.fetcher(src)).Fetch(ctx, desc)
+			if err != nil {
+				return nil, err
+			}
+			var r io.Reader = newSizeLimitedReader(rc, desc)
+			if opts.VerifyOnCopy {
+				r = newVerifyingReader(r, desc)
+			}
+			return struct {
+				io.Reader
+				io.Closer
+			}{
+				Reader: r,
+				Closer: rc,
+			}, nil
+		}
+
+		if err := mounter.Mount(ctx, desc, sourceRepository, getContent); err != nil {
+			switch {
+			case errors.Is(err, errSkipSource):
+				continue
+			case errors.Is(err, graph.ErrSkipDesc):
+				return nil
+			case errors.Is(err, errdef.ErrAlreadyExists):
+			default:
+				return err
+			}
+		}
+		if !mountFailed {
+			logNode(ctx, opts.Logger, "mount", desc, start, nil, "from", sourceRepository)
+			if opts.OnMounted != nil {
+				return opts.OnMounted(ctx, desc)
+			}
+			return nil
+		}
+	}
+
+	// the content is copied from the source
+	logNode(ctx, opts.Logger, "copy", desc, start, nil)
+	if opts.PostCopy != nil {
+		return opts.PostCopy(ctx, desc)
+	}
+	return nil
+}
+
+// mounterOf returns dst as a registry.Mounter if dst supports mounting,
+// including the underlying storage of dst if dst is wrapped for copy.
+func mounterOf(dst content.Storage) (registry.Mounter, bool) {
+	if ms, ok := dst.(interface {
+		mounter() (registry.Mounter, bool)
+	}); ok {
+		return ms.mounter()
+	}
+	mounter, ok := dst.(registry.Mounter)
+	return mounter, ok
+}
+
 // copyCachedNodeWithReference copies a single content with a reference from the
 // source cache to the destination ReferencePusher.
 func copyCachedNodeWithReference(ctx context.Context, src *cas.Proxy, dst registry.ReferencePusher, desc ocispec.Descriptor, dstRef string) error {
@@ -303,7 +1424,11 @@ func copyCachedNodeWithReference(ctx context.Context, src *cas.Proxy, dst regist
 }
 
 // resolveRoot resolves the source reference to the root node.
-func resolveRoot(ctx context.Context, src ReadOnlyTarget, srcRef string, proxy *cas.Proxy) (ocispec.Descriptor, error) {
+// If src is a ReferenceFetcher, the root node is resolved and fetched in a
+// single call, and its content is verified against the returned descriptor
+// and cached in the proxy so that it is not fetched again on copying, as long
+// as its size does not exceed maxMetadataBytes.
+func resolveRoot(ctx context.Context, src ReadOnlyTarget, srcRef string, proxy *cas.Proxy, maxMetadataBytes int64) (ocispec.Descriptor, error) {
 	refFetcher, ok := src.(registry.ReferenceFetcher)
 	if !ok {
 		return src.Resolve(ctx, srcRef)
@@ -318,41 +1443,62 @@ func resolveRoot(ctx context.Context, src ReadOnlyTarget, srcRef string, proxy *
 	if err != nil {
 		return ocispec.Descriptor{}, err
 	}
-	defer rc.Close()
-	// cache root if it is a non-leaf node
-	fetcher := content.FetcherFunc(func(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
-		if content.Equal(target, root) {
-			return rc, nil
-		}
-		return nil, errors.New("fetching only root node expected")
-	})
-	if _, err = content.Successors(ctx, fetcher, root); err != nil {
+	if root.Size > maxMetadataBytes {
+		// large blobs are copied by streaming instead of being cached
+		rc.Close()
+		return root, nil
+	}
+	// cache root, leaf or not, reading through the proxy and verifying its
+	// content against the descriptor returned by the source
+	if _, err := content.ReadAll(rc, root); err != nil {
+		rc.Close()
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: %w", root.Digest, root.MediaType, err)
+	}
+	if err := rc.Close(); err != nil {
 		return ocispec.Descriptor{}, err
 	}
-
-	// TODO: optimize special case where root is a leaf node (i.e. a blob)
-	//       and dst is a ReferencePusher.
 	return root, nil
 }
 
-// prepareCopy prepares the hooks for copy.
-func prepareCopy(ctx context.Context, dst Target, dstRef string, proxy *cas.Proxy, root ocispec.Descriptor, opts *CopyOptions) error {
+// staticReference returns a function returning the given reference.
+func staticReference(reference string) func(ctx context.Context) (string, error) {
+	return func(context.Context) (string, error) {
+		return reference, nil
+	}
+}
+
+// prepareCopy prepares the hooks for copy, tagging the root node with the
+// reference returned by dstRef once the root node is copied.
+func prepareCopy(ctx context.Context, dst Target, dstRef func(ctx context.Context) (string, error), proxy *cas.Proxy, root ocispec.Descriptor, opts *CopyOptions) error {
 	if refPusher, ok := dst.(registry.ReferencePusher); ok {
 		// optimize performance for ReferencePusher targets
 		preCopy := opts.PreCopy
+		// rootRejected is set if the root is rejected on ManifestFirst, so
+		// that preCopy is not called again when the root is pushed again.
+		var rootRejected int32
 		opts.PreCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
-			if preCopy != nil {
+			isRoot := content.Equal(desc, root)
+			retry := isRoot && atomic.CompareAndSwapInt32(&rootRejected, 1, 0)
+			if preCopy != nil && !retry {
 				if err := preCopy(ctx, desc); err != nil {
 					return err
 				}
 			}
-			if !content.Equal(desc, root) {
+			if !isRoot {
 				// for non-root node, do nothing
 				return nil
 			}
 
 			// for root node, prepare optimized copy
-			if err := copyCachedNodeWithReference(ctx, proxy, refPusher, desc, dstRef); err != nil {
+			ref, err := dstRef(ctx)
+			if err != nil {
+				return err
+			}
+			if err := copyCachedNodeWithReference(ctx, proxy, refPusher, desc, ref); err != nil {
+				if opts.ManifestFirst && isUnknownBlobsError(err) {
+					// the root is pushed again after its missing blobs
+					atomic.StoreInt32(&rootRejected, 1)
+				}
 				return err
 			}
 			if opts.PostCopy != nil {
@@ -368,7 +1514,11 @@ func prepareCopy(ctx context.Context, dst Target, dstRef string, proxy *cas.Prox
 		opts.PostCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
 			if content.Equal(desc, root) {
 				// for root node, tag it after copying it
-				if err := dst.Tag(ctx, root, dstRef); err != nil {
+				ref, err := dstRef(ctx)
+				if err != nil {
+					return err
+				}
+				if err := dst.Tag(ctx, root, ref); err != nil {
 					return err
 				}
 			}
@@ -390,10 +1540,14 @@ func prepareCopy(ctx context.Context, dst Target, dstRef string, proxy *cas.Prox
 			return nil
 		}
 		// enforce tagging when root is skipped
+		ref, err := dstRef(ctx)
+		if err != nil {
+			return err
+		}
 		if refPusher, ok := dst.(registry.ReferencePusher); ok {
-			return copyCachedNodeWithReference(ctx, proxy, refPusher, desc, dstRef)
+			return copyCachedNodeWithReference(ctx, proxy, refPusher, desc, ref)
 		}
-		return dst.Tag(ctx, root, dstRef)
+		return dst.Tag(ctx, root, ref)
 	}
 
 	return nil
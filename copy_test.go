@@ -23,9 +23,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -36,6 +41,8 @@ import (
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/cas"
 	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
 )
 
 // storageTracker tracks storage API counts.
@@ -186,11 +193,14 @@ func TestCopy_ExistedRoot(t *testing.T) {
 		t.Fatal("fail to tag root node", err)
 	}
 
-	var skippedCount int64
+	var skipped []ocispec.Descriptor
+	var skippedLock sync.Mutex
 	copyOpts := oras.CopyOptions{
 		CopyGraphOptions: oras.CopyGraphOptions{
 			OnCopySkipped: func(ctx context.Context, desc ocispec.Descriptor) error {
-				atomic.AddInt64(&skippedCount, 1)
+				skippedLock.Lock()
+				defer skippedLock.Unlock()
+				skipped = append(skipped, desc)
 				return nil
 			},
 		},
@@ -240,9 +250,9 @@ func TestCopy_ExistedRoot(t *testing.T) {
 	if !reflect.DeepEqual(gotDesc, root) {
 		t.Errorf("dst.Resolve() = %v, want %v", gotDesc, root)
 	}
-	// verify invocation of onCopySkipped()
-	if got, want := skippedCount, int64(1); got != want {
-		t.Errorf("count(OnCopySkipped()) = %v, want %v", got, want)
+	// verify invocation of onCopySkipped() with the existing root
+	if want := []ocispec.Descriptor{root}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("OnCopySkipped() = %v, want %v", skipped, want)
 	}
 }
 
@@ -1413,3 +1423,3268 @@ func TestCopyGraph_WithOptions(t *testing.T) {
 		t.Fatalf("CopyGraph() error = %v, wantErr %v", err, errdef.ErrSizeExceedsLimit)
 	}
 }
+
+func TestCopy_ConvertManifest(t *testing.T) {
+	src := memory.New()
+	dst := memory.New()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			MediaType: docker.MediaTypeManifest,
+			Config:    config,
+			Layers:    layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(docker.MediaTypeManifest, manifestJSON)
+	}
+	generateIndex := func(manifests ...ocispec.Descriptor) {
+		index := ocispec.Index{
+			MediaType:   docker.MediaTypeManifestList,
+			Manifests:   manifests,
+			Annotations: map[string]string{"foo": "bar"},
+		}
+		indexJSON, err := json.Marshal(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(docker.MediaTypeManifestList, indexJSON)
+	}
+
+	appendBlob(docker.MediaTypeConfig, []byte("config")) // Blob 0
+	appendBlob(docker.MediaTypeLayer, []byte("foo"))     // Blob 1
+	appendBlob(docker.MediaTypeLayer, []byte("bar"))     // Blob 2
+	generateManifest(descs[0], descs[1:3]...)            // Blob 3
+	appendBlob(docker.MediaTypeLayer, []byte("hello"))   // Blob 4
+	generateManifest(descs[0], descs[4])                 // Blob 5
+	linux := &ocispec.Platform{Architecture: "amd64", OS: "linux"}
+	windows := &ocispec.Platform{Architecture: "amd64", OS: "windows"}
+	manifestAMD64 := descs[3]
+	manifestAMD64.Platform = linux
+	manifestWindows := descs[5]
+	manifestWindows.Platform = windows
+	manifestWindows.Annotations = map[string]string{"hello": "world"}
+	generateIndex(manifestAMD64, manifestWindows) // Blob 6
+
+	ctx := context.Background()
+	for i := range blobs {
+		err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+
+	root := descs[6]
+	ref := "foobar"
+	if err := src.Tag(ctx, root, ref); err != nil {
+		t.Fatal("fail to tag root node", err)
+	}
+
+	// test copy
+	opts := oras.CopyOptions{
+		CopyGraphOptions: oras.CopyGraphOptions{
+			ConvertManifest: true,
+		},
+	}
+	gotDesc, err := oras.Copy(ctx, src, ref, dst, "", opts)
+	if err != nil {
+		t.Fatalf("Copy() error = %v, wantErr %v", err, false)
+	}
+	if gotDesc.MediaType != ocispec.MediaTypeImageIndex {
+		t.Errorf("Copy() media type = %v, want %v", gotDesc.MediaType, ocispec.MediaTypeImageIndex)
+	}
+	if gotDesc.Digest == root.Digest {
+		t.Errorf("Copy() digest = %v, want converted digest", gotDesc.Digest)
+	}
+
+	// verify tag
+	tagged, err := dst.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatal("dst.Resolve() error =", err)
+	}
+	if !reflect.DeepEqual(tagged, gotDesc) {
+		t.Errorf("dst.Resolve() = %v, want %v", tagged, gotDesc)
+	}
+
+	// verify converted index
+	indexJSON, err := content.FetchAll(ctx, dst, gotDesc)
+	if err != nil {
+		t.Fatal("dst.Fetch() error =", err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	if got, want := index.MediaType, ocispec.MediaTypeImageIndex; got != want {
+		t.Errorf("index media type = %v, want %v", got, want)
+	}
+	if got, want := index.Annotations, map[string]string{"foo": "bar"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("index annotations = %v, want %v", got, want)
+	}
+	if got, want := len(index.Manifests), 2; got != want {
+		t.Fatalf("len(index.Manifests) = %v, want %v", got, want)
+	}
+	if got := index.Manifests[0].Platform; !reflect.DeepEqual(got, linux) {
+		t.Errorf("index.Manifests[0].Platform = %v, want %v", got, linux)
+	}
+	if got := index.Manifests[1].Platform; !reflect.DeepEqual(got, windows) {
+		t.Errorf("index.Manifests[1].Platform = %v, want %v", got, windows)
+	}
+	if got, want := index.Manifests[1].Annotations, manifestWindows.Annotations; !reflect.DeepEqual(got, want) {
+		t.Errorf("index.Manifests[1].Annotations = %v, want %v", got, want)
+	}
+
+	// verify converted manifests and blobs
+	for i, manifestDesc := range index.Manifests {
+		if got, want := manifestDesc.MediaType, ocispec.MediaTypeImageManifest; got != want {
+			t.Errorf("index.Manifests[%d] media type = %v, want %v", i, got, want)
+		}
+		manifestJSON, err := content.FetchAll(ctx, dst, manifestDesc)
+		if err != nil {
+			t.Fatalf("dst.Fetch(%d) error = %v", i, err)
+		}
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+			t.Fatal("json.Unmarshal() error =", err)
+		}
+		if got, want := manifest.MediaType, ocispec.MediaTypeImageManifest; got != want {
+			t.Errorf("manifest[%d] media type = %v, want %v", i, got, want)
+		}
+		if got, want := manifest.Config.MediaType, ocispec.MediaTypeImageConfig; got != want {
+			t.Errorf("manifest[%d] config media type = %v, want %v", i, got, want)
+		}
+		for _, desc := range append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...) {
+			if desc.MediaType != ocispec.MediaTypeImageConfig && desc.MediaType != ocispec.MediaTypeImageLayerGzip {
+				t.Errorf("manifest[%d] unexpected media type = %v", i, desc.MediaType)
+			}
+			exists, err := dst.Exists(ctx, desc)
+			if err != nil {
+				t.Fatalf("dst.Exists() error = %v", err)
+			}
+			if !exists {
+				t.Errorf("dst.Exists(%v) = %v, want %v", desc, exists, true)
+			}
+		}
+	}
+
+	// repeated copy should skip existing content
+	gotDesc2, err := oras.Copy(ctx, src, ref, dst, "", opts)
+	if err != nil {
+		t.Fatalf("Copy() error = %v, wantErr %v", err, false)
+	}
+	if !reflect.DeepEqual(gotDesc2, gotDesc) {
+		t.Errorf("Copy() = %v, want %v", gotDesc2, gotDesc)
+	}
+}
+
+func TestCopy_WithReferrers(t *testing.T) {
+	src := memory.New()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	generateIndex := func(manifests ...ocispec.Descriptor) {
+		index := ocispec.Index{
+			Manifests: manifests,
+		}
+		indexJSON, err := json.Marshal(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageIndex, indexJSON)
+	}
+	generateArtifact := func(subject *ocispec.Descriptor, blobs ...ocispec.Descriptor) {
+		artifact := ocispec.Artifact{
+			MediaType:    ocispec.MediaTypeArtifactManifest,
+			ArtifactType: "application/vnd.test",
+			Subject:      subject,
+			Blobs:        blobs,
+		}
+		artifactJSON, err := json.Marshal(artifact)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeArtifactManifest, artifactJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	generateManifest(descs[0], descs[1])                       // Blob 2
+	appendBlob("application/vnd.sig", []byte("sig"))           // Blob 3
+	generateArtifact(&descs[2], descs[3])                      // Blob 4
+	appendBlob("application/vnd.sig", []byte("sig of sig"))    // Blob 5
+	generateArtifact(&descs[4], descs[5])                      // Blob 6
+	generateIndex(descs[2])                                    // Blob 7
+
+	ctx := context.Background()
+	for i := range blobs {
+		err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+
+	root := descs[2]
+	ref := "foobar"
+	if err := src.Tag(ctx, root, ref); err != nil {
+		t.Fatal("fail to tag root node", err)
+	}
+
+	// test copy with referrers
+	dst := memory.New()
+	opts := oras.CopyOptions{
+		WithReferrers: true,
+	}
+	gotDesc, err := oras.Copy(ctx, src, ref, dst, "", opts)
+	if err != nil {
+		t.Fatalf("Copy() error = %v, wantErr %v", err, false)
+	}
+	if !reflect.DeepEqual(gotDesc, root) {
+		t.Errorf("Copy() = %v, want %v", gotDesc, root)
+	}
+	for i, desc := range descs {
+		exists, err := dst.Exists(ctx, desc)
+		if err != nil {
+			t.Fatalf("dst.Exists(%d) error = %v", i, err)
+		}
+		// the index pointing to the root is not a referrer
+		if want := i != 7; exists != want {
+			t.Errorf("dst.Exists(%d) = %v, want %v", i, exists, want)
+		}
+	}
+
+	// test copy with referrers depth
+	dst = memory.New()
+	opts.ReferrersDepth = 1
+	if _, err := oras.Copy(ctx, src, ref, dst, "", opts); err != nil {
+		t.Fatalf("Copy() error = %v, wantErr %v", err, false)
+	}
+	for i, desc := range descs {
+		exists, err := dst.Exists(ctx, desc)
+		if err != nil {
+			t.Fatalf("dst.Exists(%d) error = %v", i, err)
+		}
+		if want := i <= 4; exists != want {
+			t.Errorf("dst.Exists(%d) = %v, want %v", i, exists, want)
+		}
+	}
+
+	// test copy from a source not supporting referrers
+	dst = memory.New()
+	readOnlySrc := struct {
+		oras.ReadOnlyTarget
+	}{src}
+	if _, err := oras.Copy(ctx, readOnlySrc, ref, dst, "", opts); err != nil {
+		t.Fatalf("Copy() error = %v, wantErr %v", err, false)
+	}
+	for i, desc := range descs {
+		exists, err := dst.Exists(ctx, desc)
+		if err != nil {
+			t.Fatalf("dst.Exists(%d) error = %v", i, err)
+		}
+		if want := i <= 2; exists != want {
+			t.Errorf("dst.Exists(%d) = %v, want %v", i, exists, want)
+		}
+	}
+}
+
+func TestCopyGraph_SkipBlobs(t *testing.T) {
+	src := cas.NewMemory()
+	dst := cas.NewMemory()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	generateIndex := func(manifests ...ocispec.Descriptor) {
+		index := ocispec.Index{
+			Manifests: manifests,
+		}
+		indexJSON, err := json.Marshal(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageIndex, indexJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("bar"))     // Blob 2
+	generateManifest(descs[0], descs[1])                       // Blob 3
+	generateManifest(descs[0], descs[2])                       // Blob 4
+	generateIndex(descs[3:5]...)                               // Blob 5
+
+	ctx := context.Background()
+	for i := range blobs {
+		err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+
+	// test copy
+	root := descs[len(descs)-1]
+	opts := oras.CopyGraphOptions{
+		SkipBlobs: true,
+	}
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+	}
+
+	// verify contents
+	contents := dst.Map()
+	if got, want := len(contents), 3; got != want {
+		t.Errorf("len(dst) = %v, wantErr %v", got, want)
+	}
+	for i := range blobs {
+		exists, err := dst.Exists(ctx, descs[i])
+		if err != nil {
+			t.Fatalf("dst.Exists(%d) error = %v", i, err)
+		}
+		if want := i >= 3; exists != want {
+			t.Errorf("dst.Exists(%d) = %v, want %v", i, exists, want)
+		}
+	}
+}
+
+// tamperedStorage serves tampered content for the descriptors in tampered.
+type tamperedStorage struct {
+	content.Storage
+	tampered map[digest.Digest][]byte
+}
+
+func (s *tamperedStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if blob, ok := s.tampered[target.Digest]; ok {
+		return io.NopCloser(bytes.NewReader(blob)), nil
+	}
+	return s.Storage.Fetch(ctx, target)
+}
+
+// permissiveStorage stores the pushed content without verification.
+type permissiveStorage struct {
+	content.Storage
+	lock   sync.Mutex
+	pushed map[digest.Digest][]byte
+}
+
+func (s *permissiveStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	blob, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.pushed[expected.Digest] = blob
+	return nil
+}
+
+func TestCopyGraph_VerifyOnCopy(t *testing.T) {
+	src := cas.NewMemory()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	generateManifest(descs[0], descs[1])                       // Blob 2
+
+	ctx := context.Background()
+	for i := range blobs {
+		err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+	root := descs[2]
+
+	tests := []struct {
+		name              string
+		tampered          []byte
+		wantErr           error
+		wantUnverifiedErr error
+	}{
+		{
+			name:     "mismatched digest",
+			tampered: []byte("bar"),
+			wantErr:  content.ErrMismatchedDigest,
+		},
+		{
+			name:              "trailing data",
+			tampered:          []byte("foobar"),
+			wantErr:           content.ErrTrailingData,
+			wantUnverifiedErr: errdef.ErrSizeExceedsLimit,
+		},
+		{
+			name:     "unexpected EOF",
+			tampered: []byte("fo"),
+			wantErr:  io.ErrUnexpectedEOF,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tamperedSrc := &tamperedStorage{
+				Storage: src,
+				tampered: map[digest.Digest][]byte{
+					descs[1].Digest: tt.tampered,
+				},
+			}
+
+			// without verification, the tampered content is copied unless it
+			// exceeds the declared size.
+			dst := &permissiveStorage{
+				Storage: cas.NewMemory(),
+				pushed:  map[digest.Digest][]byte{},
+			}
+			err := oras.CopyGraph(ctx, tamperedSrc, dst, root, oras.CopyGraphOptions{})
+			if tt.wantUnverifiedErr != nil {
+				if !errors.Is(err, tt.wantUnverifiedErr) {
+					t.Fatalf("CopyGraph() error = %v, wantErr %v", err, tt.wantUnverifiedErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+				}
+				if got := dst.pushed[descs[1].Digest]; !bytes.Equal(got, tt.tampered) {
+					t.Errorf("dst content = %v, want %v", got, tt.tampered)
+				}
+			}
+
+			// with verification, the copy is aborted.
+			dst = &permissiveStorage{
+				Storage: cas.NewMemory(),
+				pushed:  map[digest.Digest][]byte{},
+			}
+			opts := oras.CopyGraphOptions{
+				VerifyOnCopy: true,
+			}
+			err = oras.CopyGraph(ctx, tamperedSrc, dst, root, opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CopyGraph() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if want := descs[1].Digest.String(); !strings.Contains(err.Error(), want) {
+				t.Errorf("CopyGraph() error = %v, want error naming %v", err, want)
+			}
+			if _, ok := dst.pushed[descs[1].Digest]; ok {
+				t.Errorf("tampered content is pushed to dst")
+			}
+			if _, ok := dst.pushed[root.Digest]; ok {
+				t.Errorf("root is pushed to dst")
+			}
+		})
+	}
+}
+
+func TestCopyGraph_SizeLimits(t *testing.T) {
+	src := cas.NewMemory()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foobar"))  // Blob 1
+	generateManifest(descs[0], descs[1])                       // Blob 2
+
+	ctx := context.Background()
+	for i := range blobs {
+		err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+	root := descs[2]
+
+	tests := []struct {
+		name            string
+		opts            oras.CopyGraphOptions
+		wantErr         bool
+		wantTooLargeErr bool
+	}{
+		{
+			name: "default limits",
+			opts: oras.CopyGraphOptions{},
+		},
+		{
+			name: "blobs within MaxBlobBytes",
+			opts: oras.CopyGraphOptions{
+				MaxBlobBytes: descs[1].Size,
+			},
+		},
+		{
+			name: "blob exceeds MaxBlobBytes",
+			opts: oras.CopyGraphOptions{
+				MaxBlobBytes: descs[1].Size - 1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "manifest exceeds MaxMetadataBytes",
+			opts: oras.CopyGraphOptions{
+				MaxMetadataBytes: root.Size - 1,
+			},
+			wantErr:         true,
+			wantTooLargeErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := cas.NewMemory()
+			err := oras.CopyGraph(ctx, src, dst, root, tt.opts)
+			if tt.wantErr {
+				if !errors.Is(err, errdef.ErrSizeExceedsLimit) {
+					t.Fatalf("CopyGraph() error = %v, wantErr %v", err, errdef.ErrSizeExceedsLimit)
+				}
+				if got := errors.Is(err, content.ErrManifestTooLarge); got != tt.wantTooLargeErr {
+					t.Errorf("errors.Is(CopyGraph() error, ErrManifestTooLarge) = %v, want %v", got, tt.wantTooLargeErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+			}
+			for i := range blobs {
+				exists, err := dst.Exists(ctx, descs[i])
+				if err != nil {
+					t.Fatalf("dst.Exists(%d) error = %v", i, err)
+				}
+				if !exists {
+					t.Errorf("dst.Exists(%d) = %v, want %v", i, exists, true)
+				}
+			}
+		})
+	}
+}
+
+func TestCopy_ContinueOnError(t *testing.T) {
+	src := memory.New()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	generateIndex := func(manifests ...ocispec.Descriptor) {
+		index := ocispec.Index{
+			Manifests: manifests,
+		}
+		indexJSON, err := json.Marshal(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageIndex, indexJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("bar"))     // Blob 2
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("missing")) // Blob 3
+	generateManifest(descs[0], descs[1:3]...)                  // Blob 4
+	generateManifest(descs[0], descs[3])                       // Blob 5
+	generateIndex(descs[4:6]...)                               // Blob 6
+
+	ctx := context.Background()
+	for i := range blobs {
+		if i == 3 {
+			// blob 3 is missing in the source
+			continue
+		}
+		err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+	root := descs[6]
+	ref := "foobar"
+	if err := src.Tag(ctx, root, ref); err != nil {
+		t.Fatal("fail to tag root node", err)
+	}
+
+	// without ContinueOnError, the copy is aborted on the first failure.
+	dst := memory.New()
+	_, err := oras.Copy(ctx, src, ref, dst, "", oras.CopyOptions{})
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("Copy() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+	var partialErr *oras.PartialCopyError
+	if errors.As(err, &partialErr) {
+		t.Fatalf("Copy() error = %v, want non-partial error", err)
+	}
+
+	// with ContinueOnError, unrelated content is copied.
+	dst = memory.New()
+	opts := oras.CopyOptions{}
+	opts.ContinueOnError = true
+	_, err = oras.Copy(ctx, src, ref, dst, "", opts)
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("Copy() error = %v, want %T", err, partialErr)
+	}
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Copy() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+	wantFailed := map[digest.Digest]error{
+		descs[3].Digest: errdef.ErrNotFound,
+		descs[5].Digest: oras.ErrIncompleteSuccessors,
+		descs[6].Digest: oras.ErrIncompleteSuccessors,
+	}
+	if len(partialErr.Errors) != len(wantFailed) {
+		t.Fatalf("PartialCopyError.Errors = %v, want %d errors", partialErr.Errors, len(wantFailed))
+	}
+	for _, copyErr := range partialErr.Errors {
+		wantErr, ok := wantFailed[copyErr.Descriptor.Digest]
+		if !ok {
+			t.Errorf("unexpected failure: %v", copyErr)
+			continue
+		}
+		if !errors.Is(copyErr, wantErr) {
+			t.Errorf("CopyError = %v, wantErr %v", copyErr, wantErr)
+		}
+	}
+
+	// verify contents
+	for i, desc := range descs {
+		exists, err := dst.Exists(ctx, desc)
+		if err != nil {
+			t.Fatalf("dst.Exists(%d) error = %v", i, err)
+		}
+		_, failed := wantFailed[desc.Digest]
+		if want := !failed; exists != want {
+			t.Errorf("dst.Exists(%d) = %v, want %v", i, exists, want)
+		}
+	}
+
+	// verify the root is not tagged
+	if _, err := dst.Resolve(ctx, ref); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("dst.Resolve() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestCopyFromDescriptor(t *testing.T) {
+	src := cas.NewMemory()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("bar"))     // Blob 2
+	generateManifest(descs[0], descs[1:3]...)                  // Blob 3
+
+	ctx := context.Background()
+	for i := range blobs {
+		err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+	root := descs[3]
+
+	// test copy with tagging
+	dst := memory.New()
+	ref := "foobar"
+	gotDesc, err := oras.CopyFromDescriptor(ctx, src, root, dst, ref, oras.CopyGraphOptions{})
+	if err != nil {
+		t.Fatalf("CopyFromDescriptor() error = %v, wantErr %v", err, false)
+	}
+	if !reflect.DeepEqual(gotDesc, root) {
+		t.Errorf("CopyFromDescriptor() = %v, want %v", gotDesc, root)
+	}
+	for i := range blobs {
+		got, err := content.FetchAll(ctx, dst, descs[i])
+		if err != nil {
+			t.Fatalf("content[%d] error = %v, wantErr %v", i, err, false)
+		}
+		if want := blobs[i]; !bytes.Equal(got, want) {
+			t.Errorf("content[%d] = %v, want %v", i, got, want)
+		}
+	}
+	gotDesc, err = dst.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatal("dst.Resolve() error =", err)
+	}
+	if !reflect.DeepEqual(gotDesc, root) {
+		t.Errorf("dst.Resolve() = %v, want %v", gotDesc, root)
+	}
+
+	// test copy without tagging
+	dst = memory.New()
+	if _, err := oras.CopyFromDescriptor(ctx, src, root, dst, "", oras.CopyGraphOptions{}); err != nil {
+		t.Fatalf("CopyFromDescriptor() error = %v, wantErr %v", err, false)
+	}
+	exists, err := dst.Exists(ctx, root)
+	if err != nil {
+		t.Fatal("dst.Exists() error =", err)
+	}
+	if !exists {
+		t.Errorf("dst.Exists() = %v, want %v", exists, true)
+	}
+	if _, err := dst.Resolve(ctx, ref); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("dst.Resolve() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestCopyGraph_EmptyJSONTracker(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+
+	// generate artifacts with the empty JSON config
+	var roots []ocispec.Descriptor
+	for i := 0; i < 3; i++ {
+		layer := []byte(fmt.Sprintf("layer %d", i))
+		layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+		if err := src.Push(ctx, layerDesc, bytes.NewReader(layer)); err != nil {
+			t.Fatal("failed to push test content to src:", err)
+		}
+		root, err := oras.Pack(ctx, src, []ocispec.Descriptor{layerDesc}, oras.PackOptions{
+			ArtifactType: "application/vnd.test",
+		})
+		if err != nil {
+			t.Fatal("oras.Pack() error =", err)
+		}
+		roots = append(roots, root)
+	}
+
+	copyAll := func(tracker *oras.EmptyJSONTracker) (*storageTracker, int64) {
+		dst := &storageTracker{Storage: memory.New()}
+		var skipped int64
+		opts := oras.CopyGraphOptions{
+			EmptyJSONTracker: tracker,
+			OnCopySkipped: func(ctx context.Context, desc ocispec.Descriptor) error {
+				atomic.AddInt64(&skipped, 1)
+				return nil
+			},
+		}
+		for _, root := range roots {
+			if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+				t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+			}
+		}
+		return dst, skipped
+	}
+
+	want, wantSkipped := copyAll(nil)
+	got, gotSkipped := copyAll(&oras.EmptyJSONTracker{})
+
+	// the empty JSON blob is probed only on the first copy
+	if diff := want.exists - got.exists; diff != int64(len(roots)-1) {
+		t.Errorf("count(Exists()) = %v, want %v", got.exists, want.exists-int64(len(roots)-1))
+	}
+	if got.push != want.push {
+		t.Errorf("count(Push()) = %v, want %v", got.push, want.push)
+	}
+	if gotSkipped != wantSkipped {
+		t.Errorf("count(OnCopySkipped()) = %v, want %v", gotSkipped, wantSkipped)
+	}
+	exists, err := got.Exists(ctx, content.DescriptorEmptyJSON)
+	if err != nil {
+		t.Fatal("dst.Exists() error =", err)
+	}
+	if !exists {
+		t.Errorf("dst.Exists() = %v, want %v", exists, true)
+	}
+}
+
+func TestCopyGraph_Ordered(t *testing.T) {
+	src := memory.New()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	generateIndex := func(manifests ...ocispec.Descriptor) {
+		index := ocispec.Index{
+			Manifests: manifests,
+		}
+		indexJSON, err := json.Marshal(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageIndex, indexJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("bar"))     // Blob 2
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("hello"))   // Blob 3
+	generateManifest(descs[0], descs[2], descs[1])             // Blob 4
+	generateManifest(descs[0], descs[3])                       // Blob 5
+	generateIndex(descs[4:6]...)                               // Blob 6
+
+	ctx := context.Background()
+	for i := range blobs {
+		err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+
+	// the nodes should be copied in the same order every time
+	want := []ocispec.Descriptor{descs[0], descs[2], descs[1], descs[4], descs[3], descs[5], descs[6]}
+	for i := 0; i < 5; i++ {
+		dst := memory.New()
+		var got []ocispec.Descriptor
+		opts := oras.CopyGraphOptions{
+			Ordered: true,
+			PreCopy: func(ctx context.Context, desc ocispec.Descriptor) error {
+				got = append(got, desc)
+				return nil
+			},
+		}
+		if err := oras.CopyGraph(ctx, src, dst, descs[6], opts); err != nil {
+			t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("copy order = %v, want %v", got, want)
+		}
+	}
+}
+
+// inflightStorage records the maximum number of concurrent pushes.
+type inflightStorage struct {
+	content.Storage
+	inflight    *int64
+	maxInflight *int64
+}
+
+func (s *inflightStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	n := atomic.AddInt64(s.inflight, 1)
+	defer atomic.AddInt64(s.inflight, -1)
+	for {
+		max := atomic.LoadInt64(s.maxInflight)
+		if n <= max || atomic.CompareAndSwapInt64(s.maxInflight, max, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return s.Storage.Push(ctx, expected, content)
+}
+
+func TestCopyGraph_SharedAcquire(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+
+	// generate test content
+	var layers []ocispec.Descriptor
+	for i := 0; i < 8; i++ {
+		layer := []byte(fmt.Sprintf("layer %d", i))
+		desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+		if err := src.Push(ctx, desc, bytes.NewReader(layer)); err != nil {
+			t.Fatal("failed to push test content to src:", err)
+		}
+		layers = append(layers, desc)
+	}
+	root, err := oras.Pack(ctx, src, layers, oras.PackOptions{})
+	if err != nil {
+		t.Fatal("oras.Pack() error =", err)
+	}
+
+	// share a budget of 2 transfers among the copies
+	const budget = 2
+	slots := make(chan struct{}, budget)
+	var acquired int64
+	opts := oras.CopyGraphOptions{
+		Concurrency: 5,
+		Acquire: func(ctx context.Context) (func(), error) {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			atomic.AddInt64(&acquired, 1)
+			return func() { <-slots }, nil
+		},
+	}
+
+	var inflight, maxInflight int64
+	const copies = 4
+	errs := make(chan error, copies)
+	for i := 0; i < copies; i++ {
+		go func() {
+			dst := &inflightStorage{
+				Storage:     memory.New(),
+				inflight:    &inflight,
+				maxInflight: &maxInflight,
+			}
+			errs <- oras.CopyGraph(ctx, src, dst, root, opts)
+		}()
+	}
+	for i := 0; i < copies; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+		}
+	}
+	if maxInflight > budget {
+		t.Errorf("max concurrent pushes = %v, want <= %v", maxInflight, budget)
+	}
+	// layers, config, and manifest are transferred by each copy
+	if want := int64(copies * (len(layers) + 2)); acquired != want {
+		t.Errorf("count(Acquire()) = %v, want %v", acquired, want)
+	}
+
+	// errors of Acquire fail the copy
+	errAcquire := errors.New("no budget")
+	opts.Acquire = func(ctx context.Context) (func(), error) {
+		return nil, errAcquire
+	}
+	if err := oras.CopyGraph(ctx, src, memory.New(), root, opts); !errors.Is(err, errAcquire) {
+		t.Errorf("CopyGraph() error = %v, wantErr %v", err, errAcquire)
+	}
+}
+
+func TestCopyBlob(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+
+	// test copy with hooks and progress
+	dst := memory.New()
+	var preCopied, postCopied []ocispec.Descriptor
+	var copied int64
+	opts := oras.CopyBlobOptions{
+		PreCopy: func(ctx context.Context, desc ocispec.Descriptor) error {
+			preCopied = append(preCopied, desc)
+			return nil
+		},
+		PostCopy: func(ctx context.Context, desc ocispec.Descriptor) error {
+			postCopied = append(postCopied, desc)
+			return nil
+		},
+		OnProgress: func(got ocispec.Descriptor, n int64) {
+			if !reflect.DeepEqual(got, desc) {
+				t.Errorf("OnProgress() desc = %v, want %v", got, desc)
+			}
+			copied = n
+		},
+	}
+	if err := oras.CopyBlob(ctx, src, dst, desc, opts); err != nil {
+		t.Fatalf("CopyBlob() error = %v", err)
+	}
+	got, err := content.FetchAll(ctx, dst, desc)
+	if err != nil {
+		t.Fatalf("FetchAll() error = %v", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("FetchAll() = %v, want %v", got, blob)
+	}
+	if copied != desc.Size {
+		t.Errorf("OnProgress() copied = %d, want %d", copied, desc.Size)
+	}
+	want := []ocispec.Descriptor{desc}
+	if !reflect.DeepEqual(preCopied, want) {
+		t.Errorf("PreCopy() = %v, want %v", preCopied, want)
+	}
+	if !reflect.DeepEqual(postCopied, want) {
+		t.Errorf("PostCopy() = %v, want %v", postCopied, want)
+	}
+
+	// test copy to a destination with the blob already existing
+	if err := oras.CopyBlob(ctx, src, dst, desc, oras.CopyBlobOptions{}); err != nil {
+		t.Errorf("CopyBlob() error = %v, wantErr %v", err, false)
+	}
+
+	// test copy of tampered content
+	tamperedSrc := &tamperedStorage{
+		Storage: src,
+		tampered: map[digest.Digest][]byte{
+			desc.Digest: []byte("hello wOrld"),
+		},
+	}
+	permissiveDst := &permissiveStorage{
+		Storage: memory.New(),
+		pushed:  make(map[digest.Digest][]byte),
+	}
+	err = oras.CopyBlob(ctx, tamperedSrc, permissiveDst, desc, oras.CopyBlobOptions{})
+	if !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("CopyBlob() error = %v, want %v", err, content.ErrMismatchedDigest)
+	}
+
+	// test copy of missing content
+	missing := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("missing"))
+	err = oras.CopyBlob(ctx, src, memory.New(), missing, oras.CopyBlobOptions{})
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("CopyBlob() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestCopy_RewriteURLs(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+
+	push := func(desc ocispec.Descriptor, blob []byte) {
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := []byte("config")
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config)
+	push(configDesc, config)
+	layer := []byte("layer")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	push(layerDesc, layer)
+	foreign := []byte("foreign")
+	foreignDesc := content.NewDescriptorFromBytes(docker.MediaTypeForeignLayer, foreign)
+	foreignDesc.URLs = []string{"https://upstream.example/foreign"}
+	push(foreignDesc, foreign)
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: configDesc,
+		Layers: []ocispec.Descriptor{layerDesc, foreignDesc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+	push(manifestDesc, manifestJSON)
+	indexJSON, err := json.Marshal(ocispec.Index{
+		Manifests: []ocispec.Descriptor{manifestDesc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, indexJSON)
+	push(indexDesc, indexJSON)
+	ref := "foobar"
+	if err := src.Tag(ctx, indexDesc, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := memory.New()
+	opts := oras.CopyOptions{
+		CopyGraphOptions: oras.CopyGraphOptions{
+			RewriteURLs: func(desc ocispec.Descriptor) ocispec.Descriptor {
+				for i, u := range desc.URLs {
+					desc.URLs[i] = strings.Replace(u, "upstream.example", "mirror.example", 1)
+				}
+				return desc
+			},
+		},
+	}
+	root, err := oras.Copy(ctx, src, ref, dst, ref, opts)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if root.Digest == indexDesc.Digest {
+		t.Errorf("Copy() = %v, want rewritten index", root)
+	}
+
+	// verify the rewritten index and manifest
+	gotIndexJSON, err := content.FetchAll(ctx, dst, root)
+	if err != nil {
+		t.Fatalf("FetchAll(index) error = %v", err)
+	}
+	var gotIndex ocispec.Index
+	if err := json.Unmarshal(gotIndexJSON, &gotIndex); err != nil {
+		t.Fatal(err)
+	}
+	if len(gotIndex.Manifests) != 1 {
+		t.Fatalf("len(index.Manifests) = %d, want 1", len(gotIndex.Manifests))
+	}
+	gotManifestJSON, err := content.FetchAll(ctx, dst, gotIndex.Manifests[0])
+	if err != nil {
+		t.Fatalf("FetchAll(manifest) error = %v", err)
+	}
+	var gotManifest ocispec.Manifest
+	if err := json.Unmarshal(gotManifestJSON, &gotManifest); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotManifest.Config, configDesc) {
+		t.Errorf("manifest.Config = %v, want %v", gotManifest.Config, configDesc)
+	}
+	wantForeign := foreignDesc
+	wantForeign.URLs = []string{"https://mirror.example/foreign"}
+	wantLayers := []ocispec.Descriptor{layerDesc, wantForeign}
+	if !reflect.DeepEqual(gotManifest.Layers, wantLayers) {
+		t.Errorf("manifest.Layers = %v, want %v", gotManifest.Layers, wantLayers)
+	}
+
+	// verify the foreign layer is not transferred
+	for _, tt := range []struct {
+		desc ocispec.Descriptor
+		want bool
+	}{
+		{configDesc, true},
+		{layerDesc, true},
+		{foreignDesc, false},
+		{manifestDesc, false},
+		{indexDesc, false},
+	} {
+		exists, err := dst.Exists(ctx, tt.desc)
+		if err != nil {
+			t.Fatalf("dst.Exists(%s) error = %v", tt.desc.Digest, err)
+		}
+		if exists != tt.want {
+			t.Errorf("dst.Exists(%s) = %v, want %v", tt.desc.Digest, exists, tt.want)
+		}
+	}
+	gotRoot, err := dst.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatalf("dst.Resolve() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotRoot, root) {
+		t.Errorf("dst.Resolve() = %v, want %v", gotRoot, root)
+	}
+}
+
+// scopeRecordingTarget is a target parsing references in a repository, and
+// recording the scopes hinted for its registry on each access.
+type scopeRecordingTarget struct {
+	oras.Target
+	registry   string
+	repository string
+	lock       sync.Mutex
+	scopes     map[string]bool
+}
+
+func (t *scopeRecordingTarget) ParseReference(reference string) (registry.Reference, error) {
+	return registry.Reference{
+		Registry:   t.registry,
+		Repository: t.repository,
+		Reference:  reference,
+	}, nil
+}
+
+func (t *scopeRecordingTarget) record(ctx context.Context) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.scopes[strings.Join(auth.GetAllScopesForHost(ctx, t.registry), " ")] = true
+}
+
+func (t *scopeRecordingTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	t.record(ctx)
+	return t.Target.Fetch(ctx, target)
+}
+
+func (t *scopeRecordingTarget) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	t.record(ctx)
+	return t.Target.Push(ctx, expected, content)
+}
+
+func (t *scopeRecordingTarget) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	t.record(ctx)
+	return t.Target.Exists(ctx, target)
+}
+
+func (t *scopeRecordingTarget) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	t.record(ctx)
+	return t.Target.Resolve(ctx, reference)
+}
+
+func (t *scopeRecordingTarget) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	t.record(ctx)
+	return t.Target.Tag(ctx, desc, reference)
+}
+
+func TestCopy_ScopeHints(t *testing.T) {
+	ctx := context.Background()
+	storage := memory.New()
+	layer := []byte("layer")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	if err := storage.Push(ctx, layerDesc, bytes.NewReader(layer)); err != nil {
+		t.Fatal(err)
+	}
+	manifestDesc, err := oras.Pack(ctx, storage, []ocispec.Descriptor{layerDesc}, oras.PackOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := "foobar"
+	if err := storage.Tag(ctx, manifestDesc, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		dstRegistry string
+		wantSrc     string
+		wantDst     string
+	}{
+		{
+			name:        "same registry",
+			dstRegistry: "registry.example",
+			wantSrc:     "repository:dst:pull,push repository:src:pull",
+			wantDst:     "repository:dst:pull,push repository:src:pull",
+		},
+		{
+			name:        "different registries",
+			dstRegistry: "mirror.example",
+			wantSrc:     "repository:src:pull",
+			wantDst:     "repository:dst:pull,push",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &scopeRecordingTarget{
+				Target:     storage,
+				registry:   "registry.example",
+				repository: "src",
+				scopes:     make(map[string]bool),
+			}
+			dst := &scopeRecordingTarget{
+				Target:     memory.New(),
+				registry:   tt.dstRegistry,
+				repository: "dst",
+				scopes:     make(map[string]bool),
+			}
+			if _, err := oras.Copy(ctx, src, ref, dst, "", oras.DefaultCopyOptions); err != nil {
+				t.Fatal("Copy() error =", err)
+			}
+			// all the accesses share the scopes hinted up front
+			if want := map[string]bool{tt.wantSrc: true}; !reflect.DeepEqual(src.scopes, want) {
+				t.Errorf("src scopes = %v, want %v", src.scopes, want)
+			}
+			if want := map[string]bool{tt.wantDst: true}; !reflect.DeepEqual(dst.scopes, want) {
+				t.Errorf("dst scopes = %v, want %v", dst.scopes, want)
+			}
+		})
+	}
+}
+
+// mountingStorage is a storage mounting the blobs from the source storage,
+// and recording the mounts.
+type mountingStorage struct {
+	content.Storage
+	source  content.Fetcher
+	lock    sync.Mutex
+	mounted []digest.Digest
+}
+
+func (s *mountingStorage) Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) error {
+	rc, err := s.source.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := s.Storage.Push(ctx, desc, rc); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.mounted = append(s.mounted, desc.Digest)
+	return nil
+}
+
+func TestCopyGraph_MountFrom_Converted(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("config"))
+	foo := push(ocispec.MediaTypeImageLayer, []byte("foo"))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{foo},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := push(ocispec.MediaTypeImageManifest, manifestJSON)
+
+	mapManifest := func(desc ocispec.Descriptor, content []byte) ([]byte, error) {
+		return append(content, ' '), nil
+	}
+	mapBlob := func(ctx context.Context, desc ocispec.Descriptor, r io.Reader) (ocispec.Descriptor, io.Reader, error) {
+		return desc, r, nil
+	}
+	tests := []struct {
+		name        string
+		opts        oras.CopyGraphOptions
+		wantMounted int
+	}{
+		{
+			name: "MapManifest mounts the blobs",
+			opts: oras.CopyGraphOptions{
+				MapManifest: mapManifest,
+			},
+			wantMounted: 2,
+		},
+		{
+			name: "MapBlob copies the blobs",
+			opts: oras.CopyGraphOptions{
+				MapBlob: mapBlob,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := &mountingStorage{
+				Storage: memory.New(),
+				source:  src,
+			}
+			opts := tt.opts
+			opts.MountFrom = func(ctx context.Context, desc ocispec.Descriptor) ([]string, error) {
+				return []string{"source"}, nil
+			}
+			if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+				t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+			}
+			if got := len(dst.mounted); got != tt.wantMounted {
+				t.Errorf("mounted = %v, want %d blobs", dst.mounted, tt.wantMounted)
+			}
+			for _, desc := range []ocispec.Descriptor{config, foo} {
+				exists, err := dst.Exists(ctx, desc)
+				if err != nil {
+					t.Fatalf("dst.Exists() error = %v", err)
+				}
+				if !exists {
+					t.Errorf("dst.Exists(%s) = %v, want %v", desc.Digest, exists, true)
+				}
+			}
+		})
+	}
+}
+
+func TestCopy_MapBlob(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+
+	push := func(desc ocispec.Descriptor, blob []byte) {
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pushManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) ocispec.Descriptor {
+		manifestJSON, err := json.Marshal(ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+		push(desc, manifestJSON)
+		return desc
+	}
+	config := []byte("config")
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config)
+	push(configDesc, config)
+	foo := []byte("foo")
+	fooDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, foo)
+	fooDesc.Annotations = map[string]string{"name": "foo"}
+	push(fooDesc, foo)
+	bar := []byte("bar")
+	barDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, bar)
+	push(barDesc, bar)
+	// the manifests share the layer foo
+	manifestDescs := []ocispec.Descriptor{
+		pushManifest(configDesc, fooDesc),
+		pushManifest(configDesc, fooDesc, barDesc),
+	}
+	indexJSON, err := json.Marshal(ocispec.Index{
+		Manifests: manifestDescs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, indexJSON)
+	push(indexDesc, indexJSON)
+	ref := "foobar"
+	if err := src.Tag(ctx, indexDesc, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	// map the layers to their upper case
+	const mediaTypeUpper = "application/vnd.test.layer.upper"
+	var mapped int64
+	mapLayer := func(desc ocispec.Descriptor) (ocispec.Descriptor, []byte) {
+		blob, err := content.FetchAll(ctx, src, desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blob = bytes.ToUpper(blob)
+		mappedDesc := content.NewDescriptorFromBytes(mediaTypeUpper, blob)
+		mappedDesc.Annotations = desc.Annotations
+		return mappedDesc, blob
+	}
+	dst := memory.New()
+	opts := oras.CopyOptions{
+		CopyGraphOptions: oras.CopyGraphOptions{
+			MapBlob: func(ctx context.Context, desc ocispec.Descriptor, r io.Reader) (ocispec.Descriptor, io.Reader, error) {
+				if desc.MediaType != ocispec.MediaTypeImageLayer {
+					return desc, r, nil
+				}
+				atomic.AddInt64(&mapped, 1)
+				blob, err := content.ReadAll(r, desc)
+				if err != nil {
+					return ocispec.Descriptor{}, nil, err
+				}
+				blob = bytes.ToUpper(blob)
+				mappedDesc := content.NewDescriptorFromBytes(mediaTypeUpper, blob)
+				mappedDesc.Annotations = desc.Annotations
+				return mappedDesc, bytes.NewReader(blob), nil
+			},
+		},
+	}
+	root, err := oras.Copy(ctx, src, ref, dst, ref, opts)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if root.Digest == indexDesc.Digest {
+		t.Errorf("Copy() = %v, want remapped index", root)
+	}
+	if got := atomic.LoadInt64(&mapped); got != 2 {
+		t.Errorf("count(MapBlob()) = %v, want %v", got, 2)
+	}
+
+	// verify the remapped index and manifests
+	gotIndexJSON, err := content.FetchAll(ctx, dst, root)
+	if err != nil {
+		t.Fatalf("FetchAll(index) error = %v", err)
+	}
+	var gotIndex ocispec.Index
+	if err := json.Unmarshal(gotIndexJSON, &gotIndex); err != nil {
+		t.Fatal(err)
+	}
+	if len(gotIndex.Manifests) != 2 {
+		t.Fatalf("len(index.Manifests) = %d, want 2", len(gotIndex.Manifests))
+	}
+	mappedFoo, upperFoo := mapLayer(fooDesc)
+	mappedBar, upperBar := mapLayer(barDesc)
+	wantLayers := [][]ocispec.Descriptor{
+		{mappedFoo},
+		{mappedFoo, mappedBar},
+	}
+	for i, manifestDesc := range gotIndex.Manifests {
+		gotManifestJSON, err := content.FetchAll(ctx, dst, manifestDesc)
+		if err != nil {
+			t.Fatalf("FetchAll(manifest) error = %v", err)
+		}
+		var gotManifest ocispec.Manifest
+		if err := json.Unmarshal(gotManifestJSON, &gotManifest); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(gotManifest.Config, configDesc) {
+			t.Errorf("manifest[%d].Config = %v, want %v", i, gotManifest.Config, configDesc)
+		}
+		if !reflect.DeepEqual(gotManifest.Layers, wantLayers[i]) {
+			t.Errorf("manifest[%d].Layers = %v, want %v", i, gotManifest.Layers, wantLayers[i])
+		}
+	}
+
+	// verify the transformed layers are pushed instead of the original ones
+	for _, tt := range []struct {
+		desc ocispec.Descriptor
+		want []byte
+	}{
+		{mappedFoo, upperFoo},
+		{mappedBar, upperBar},
+		{configDesc, config},
+	} {
+		got, err := content.FetchAll(ctx, dst, tt.desc)
+		if err != nil {
+			t.Fatalf("FetchAll(%s) error = %v", tt.desc.Digest, err)
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("FetchAll(%s) = %s, want %s", tt.desc.Digest, got, tt.want)
+		}
+	}
+	for _, desc := range []ocispec.Descriptor{fooDesc, barDesc, manifestDescs[0], manifestDescs[1], indexDesc} {
+		exists, err := dst.Exists(ctx, desc)
+		if err != nil {
+			t.Fatalf("dst.Exists(%s) error = %v", desc.Digest, err)
+		}
+		if exists {
+			t.Errorf("dst.Exists(%s) = %v, want %v", desc.Digest, exists, false)
+		}
+	}
+	gotRoot, err := dst.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatalf("dst.Resolve() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotRoot, root) {
+		t.Errorf("dst.Resolve() = %v, want %v", gotRoot, root)
+	}
+
+	// test failing the copy on mapping errors
+	errMap := errors.New("map error")
+	opts.MapBlob = func(ctx context.Context, desc ocispec.Descriptor, r io.Reader) (ocispec.Descriptor, io.Reader, error) {
+		return ocispec.Descriptor{}, nil, errMap
+	}
+	if _, err := oras.Copy(ctx, src, ref, memory.New(), ref, opts); !errors.Is(err, errMap) {
+		t.Errorf("Copy() error = %v, wantErr %v", err, errMap)
+	}
+}
+
+func TestCopyGraph_ForeignLayers(t *testing.T) {
+	foreign := []byte("foreign layer")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/foreign" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write(foreign); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	src := memory.New()
+	ctx := context.Background()
+	push := func(desc ocispec.Descriptor, blob []byte) {
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := []byte("config")
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config)
+	push(configDesc, config)
+	// the foreign layer is not served by the source
+	foreignDesc := content.NewDescriptorFromBytes(docker.MediaTypeForeignLayer, foreign)
+	foreignDesc.URLs = []string{ts.URL + "/missing", ts.URL + "/foreign"}
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: configDesc,
+		Layers: []ocispec.Descriptor{foreignDesc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+	push(root, manifestJSON)
+
+	// test copy skipping the foreign layer by default
+	dst := memory.New()
+	if err := oras.CopyGraph(ctx, src, dst, root, oras.CopyGraphOptions{}); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	for _, tt := range []struct {
+		desc ocispec.Descriptor
+		want bool
+	}{
+		{configDesc, true},
+		{foreignDesc, false},
+		{root, true},
+	} {
+		exists, err := dst.Exists(ctx, tt.desc)
+		if err != nil {
+			t.Fatalf("dst.Exists(%s) error = %v", tt.desc.Digest, err)
+		}
+		if exists != tt.want {
+			t.Errorf("dst.Exists(%s) = %v, want %v", tt.desc.Digest, exists, tt.want)
+		}
+	}
+
+	// test copy downloading the foreign layer from its URLs
+	dst = memory.New()
+	opts := oras.CopyGraphOptions{
+		IncludeForeignLayers: true,
+	}
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	got, err := content.FetchAll(ctx, dst, foreignDesc)
+	if err != nil {
+		t.Fatalf("FetchAll() error = %v", err)
+	}
+	if !bytes.Equal(got, foreign) {
+		t.Errorf("FetchAll() = %v, want %v", got, foreign)
+	}
+
+	// test copy with no URL serving the foreign layer
+	missingDesc := foreignDesc
+	missingDesc.URLs = []string{ts.URL + "/missing"}
+	manifestJSON, err = json.Marshal(ocispec.Manifest{
+		Config: configDesc,
+		Layers: []ocispec.Descriptor{missingDesc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root = content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+	push(root, manifestJSON)
+	err = oras.CopyGraph(ctx, src, memory.New(), root, opts)
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("CopyGraph() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestCopyGraph_MaxTotalBytes(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("config")) // 6 bytes
+	foo := push(ocispec.MediaTypeImageLayer, []byte("foo"))        // 3 bytes
+	bar := push(ocispec.MediaTypeImageLayer, []byte("bar"))        // 3 bytes
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{foo, bar},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := push(ocispec.MediaTypeImageManifest, manifestJSON)
+	total := config.Size + foo.Size + bar.Size + root.Size
+
+	// test copy within the quota
+	dst := memory.New()
+	opts := oras.CopyGraphOptions{
+		MaxTotalBytes: total,
+	}
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+	}
+
+	// test copy exceeding the quota, where the nodes are copied in order
+	for _, continueOnError := range []bool{false, true} {
+		dst := memory.New()
+		opts := oras.CopyGraphOptions{
+			MaxTotalBytes:   10,
+			Ordered:         true,
+			ContinueOnError: continueOnError,
+		}
+		err := oras.CopyGraph(ctx, src, dst, root, opts)
+		if !errors.Is(err, oras.ErrQuotaExceeded) {
+			t.Fatalf("CopyGraph() error = %v, want %v", err, oras.ErrQuotaExceeded)
+		}
+		var quotaErr *oras.QuotaExceededError
+		if !errors.As(err, &quotaErr) {
+			t.Fatalf("CopyGraph() error = %v, want *oras.QuotaExceededError", err)
+		}
+		want := &oras.QuotaExceededError{
+			Descriptor:  bar,
+			Limit:       10,
+			Transferred: config.Size + foo.Size,
+		}
+		if !reflect.DeepEqual(quotaErr, want) {
+			t.Errorf("CopyGraph() error = %v, want %v", quotaErr, want)
+		}
+		for _, desc := range []ocispec.Descriptor{bar, root} {
+			exists, err := dst.Exists(ctx, desc)
+			if err != nil {
+				t.Fatalf("dst.Exists() error = %v", err)
+			}
+			if exists {
+				t.Errorf("dst.Exists(%s) = %v, want %v", desc.Digest, exists, false)
+			}
+		}
+	}
+
+	// test concurrent copy exceeding the quota
+	opts = oras.CopyGraphOptions{
+		MaxTotalBytes: total - 1,
+	}
+	err = oras.CopyGraph(ctx, src, memory.New(), root, opts)
+	if !errors.Is(err, oras.ErrQuotaExceeded) {
+		t.Errorf("CopyGraph() error = %v, want %v", err, oras.ErrQuotaExceeded)
+	}
+}
+
+// flakyStorage fails the first pushes of the descriptors in failures.
+type flakyStorage struct {
+	content.Storage
+	lock     sync.Mutex
+	failures map[digest.Digest]int
+	pushes   map[digest.Digest]int
+}
+
+func (s *flakyStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	s.lock.Lock()
+	s.pushes[expected.Digest]++
+	fail := s.failures[expected.Digest] > 0
+	if fail {
+		s.failures[expected.Digest]--
+	}
+	s.lock.Unlock()
+	if fail {
+		return io.ErrUnexpectedEOF
+	}
+	return s.Storage.Push(ctx, expected, content)
+}
+
+func TestCopyGraph_MaxGraphRetries(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("config"))
+	foo := push(ocispec.MediaTypeImageLayer, []byte("foo"))
+	bar := push(ocispec.MediaTypeImageLayer, []byte("bar"))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{foo, bar},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := push(ocispec.MediaTypeImageManifest, manifestJSON)
+
+	// test retrying the graph on retryable errors
+	dst := &flakyStorage{
+		Storage:  memory.New(),
+		failures: map[digest.Digest]int{bar.Digest: 2},
+		pushes:   make(map[digest.Digest]int),
+	}
+	opts := oras.CopyGraphOptions{
+		MaxGraphRetries:   2,
+		GraphRetryBackoff: time.Millisecond,
+	}
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+	}
+	wantPushes := map[digest.Digest]int{
+		config.Digest: 1,
+		foo.Digest:    1,
+		bar.Digest:    3,
+		root.Digest:   1,
+	}
+	if !reflect.DeepEqual(dst.pushes, wantPushes) {
+		t.Errorf("pushes = %v, want %v", dst.pushes, wantPushes)
+	}
+
+	// test exhausting the retries
+	dst = &flakyStorage{
+		Storage:  memory.New(),
+		failures: map[digest.Digest]int{bar.Digest: 3},
+		pushes:   make(map[digest.Digest]int),
+	}
+	err = oras.CopyGraph(ctx, src, dst, root, opts)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("CopyGraph() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if got, want := dst.pushes[bar.Digest], 3; got != want {
+		t.Errorf("pushes[bar] = %v, want %v", got, want)
+	}
+
+	// test aborting on non-retryable errors
+	missing := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("missing"))
+	manifestJSON, err = json.Marshal(ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{missing},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root = push(ocispec.MediaTypeImageManifest, manifestJSON)
+	dst = &flakyStorage{
+		Storage: memory.New(),
+		pushes:  make(map[digest.Digest]int),
+	}
+	opts.GraphRetryBackoff = time.Hour
+	err = oras.CopyGraph(ctx, src, dst, root, opts)
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("CopyGraph() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+// strictStorage rejects the image manifests referencing absent blobs, and
+// records the existence checks and the pushes.
+type strictStorage struct {
+	content.Storage
+	lock   sync.Mutex
+	exists map[digest.Digest]int
+	pushes map[digest.Digest]int
+}
+
+func (s *strictStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	s.lock.Lock()
+	s.exists[target.Digest]++
+	s.lock.Unlock()
+	return s.Storage.Exists(ctx, target)
+}
+
+func (s *strictStorage) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	s.lock.Lock()
+	s.pushes[expected.Digest]++
+	s.lock.Unlock()
+	if expected.MediaType != ocispec.MediaTypeImageManifest {
+		return s.Storage.Push(ctx, expected, r)
+	}
+	manifestJSON, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return err
+	}
+	var unknown []digest.Digest
+	for _, blob := range append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...) {
+		exists, err := s.Storage.Exists(ctx, blob)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			unknown = append(unknown, blob.Digest)
+		}
+	}
+	if len(unknown) > 0 {
+		return &errdef.UnknownBlobsError{Blobs: unknown}
+	}
+	return s.Storage.Push(ctx, expected, bytes.NewReader(manifestJSON))
+}
+
+func TestCopyGraph_ManifestFirst(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("config"))
+	foo := push(ocispec.MediaTypeImageLayer, []byte("foo"))
+	bar := push(ocispec.MediaTypeImageLayer, []byte("bar"))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{foo, bar},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := push(ocispec.MediaTypeImageManifest, manifestJSON)
+
+	// test copying only the missing blobs
+	dst := &strictStorage{
+		Storage: memory.New(),
+		exists:  make(map[digest.Digest]int),
+		pushes:  make(map[digest.Digest]int),
+	}
+	for _, desc := range []ocispec.Descriptor{config, foo} {
+		rc, err := src.Fetch(ctx, desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = dst.Storage.Push(ctx, desc, rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var preCopied []digest.Digest
+	var lock sync.Mutex
+	opts := oras.CopyGraphOptions{
+		ManifestFirst: true,
+		PreCopy: func(ctx context.Context, desc ocispec.Descriptor) error {
+			lock.Lock()
+			defer lock.Unlock()
+			preCopied = append(preCopied, desc.Digest)
+			return nil
+		},
+	}
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+	}
+	wantPushes := map[digest.Digest]int{
+		bar.Digest:  1,
+		root.Digest: 2,
+	}
+	if !reflect.DeepEqual(dst.pushes, wantPushes) {
+		t.Errorf("pushes = %v, want %v", dst.pushes, wantPushes)
+	}
+	if got := dst.exists[config.Digest] + dst.exists[foo.Digest]; got != 0 {
+		t.Errorf("existence checks of the present blobs = %v, want 0", got)
+	}
+	if got, want := len(preCopied), 2; got != want {
+		t.Errorf("PreCopy calls = %v, want %v", got, want)
+	}
+	if exists, err := dst.Storage.Exists(ctx, root); err != nil || !exists {
+		t.Errorf("dst.Exists(root) = %v, %v, want %v", exists, err, true)
+	}
+
+	// test pushing the manifest once and checking the blobs if all blobs exist
+	manifestJSON, err = json.Marshal(ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{bar, foo},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root = push(ocispec.MediaTypeImageManifest, manifestJSON)
+	dst.pushes = make(map[digest.Digest]int)
+	dst.exists = make(map[digest.Digest]int)
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+	}
+	wantPushes = map[digest.Digest]int{
+		root.Digest: 1,
+	}
+	if !reflect.DeepEqual(dst.pushes, wantPushes) {
+		t.Errorf("pushes = %v, want %v", dst.pushes, wantPushes)
+	}
+	wantExists := map[digest.Digest]int{
+		root.Digest:   1,
+		config.Digest: 1,
+		bar.Digest:    1,
+		foo.Digest:    1,
+	}
+	if !reflect.DeepEqual(dst.exists, wantExists) {
+		t.Errorf("existence checks = %v, want %v", dst.exists, wantExists)
+	}
+}
+
+func TestCopyGraph_ManifestFirst_Accepted(t *testing.T) {
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("bar"))     // Blob 2
+	generateManifest(descs[0], descs[1:3]...)                  // Blob 3
+
+	ctx := context.Background()
+	src := memory.New()
+	for i := range blobs {
+		if err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i])); err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+
+	// memory.Store accepts the manifest referencing absent blobs
+	dst := memory.New()
+	var postCopied []ocispec.Descriptor
+	var lock sync.Mutex
+	opts := oras.CopyGraphOptions{
+		ManifestFirst: true,
+		PostCopy: func(ctx context.Context, desc ocispec.Descriptor) error {
+			lock.Lock()
+			defer lock.Unlock()
+			postCopied = append(postCopied, desc)
+			return nil
+		},
+	}
+	root := descs[3]
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+	}
+
+	// verify the whole graph is copied
+	for i, desc := range descs {
+		exists, err := dst.Exists(ctx, desc)
+		if err != nil {
+			t.Fatalf("dst.Exists(%d) error = %v", i, err)
+		}
+		if !exists {
+			t.Errorf("dst.Exists(%d) = %v, want %v", i, exists, true)
+		}
+	}
+	if got, want := len(postCopied), len(descs); got != want {
+		t.Errorf("PostCopy calls = %v, want %v", got, want)
+	}
+	if got := postCopied[len(postCopied)-1]; !content.Equal(got, root) {
+		t.Errorf("last PostCopy = %v, want %v", got, root)
+	}
+}
+
+// strictReferenceTarget is a strictStorage accepting the pushes by reference.
+type strictReferenceTarget struct {
+	*strictStorage
+	store *memory.Store
+}
+
+func (t *strictReferenceTarget) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	return t.store.Resolve(ctx, reference)
+}
+
+func (t *strictReferenceTarget) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	return t.store.Tag(ctx, desc, reference)
+}
+
+func (t *strictReferenceTarget) PushReference(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string) error {
+	if err := t.Push(ctx, expected, content); err != nil {
+		return err
+	}
+	return t.store.Tag(ctx, expected, reference)
+}
+
+func TestCopy_ManifestFirst_ReferencePusher(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("config"))
+	foo := push(ocispec.MediaTypeImageLayer, []byte("foo"))
+	bar := push(ocispec.MediaTypeImageLayer, []byte("bar"))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{foo, bar},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := push(ocispec.MediaTypeImageManifest, manifestJSON)
+	ref := "foobar"
+	if err := src.Tag(ctx, root, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	store := memory.New()
+	dst := &strictReferenceTarget{
+		strictStorage: &strictStorage{
+			Storage: store,
+			exists:  make(map[digest.Digest]int),
+			pushes:  make(map[digest.Digest]int),
+		},
+		store: store,
+	}
+	for _, desc := range []ocispec.Descriptor{config, foo} {
+		rc, err := src.Fetch(ctx, desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = store.Push(ctx, desc, rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	preCopied := make(map[digest.Digest]int)
+	postCopied := make(map[digest.Digest]int)
+	var lock sync.Mutex
+	opts := oras.CopyOptions{
+		CopyGraphOptions: oras.CopyGraphOptions{
+			ManifestFirst: true,
+			PreCopy: func(ctx context.Context, desc ocispec.Descriptor) error {
+				lock.Lock()
+				defer lock.Unlock()
+				preCopied[desc.Digest]++
+				return nil
+			},
+			PostCopy: func(ctx context.Context, desc ocispec.Descriptor) error {
+				lock.Lock()
+				defer lock.Unlock()
+				postCopied[desc.Digest]++
+				return nil
+			},
+		},
+	}
+	gotDesc, err := oras.Copy(ctx, src, ref, dst, ref, opts)
+	if err != nil {
+		t.Fatalf("Copy() error = %v, wantErr %v", err, false)
+	}
+	if !reflect.DeepEqual(gotDesc, root) {
+		t.Errorf("Copy() = %v, want %v", gotDesc, root)
+	}
+	wantPushes := map[digest.Digest]int{
+		bar.Digest:  1,
+		root.Digest: 2,
+	}
+	if !reflect.DeepEqual(dst.pushes, wantPushes) {
+		t.Errorf("pushes = %v, want %v", dst.pushes, wantPushes)
+	}
+	if got := dst.exists[config.Digest] + dst.exists[foo.Digest]; got != 0 {
+		t.Errorf("existence checks of the present blobs = %v, want 0", got)
+	}
+	wantCopied := map[digest.Digest]int{
+		bar.Digest:  1,
+		root.Digest: 1,
+	}
+	if !reflect.DeepEqual(preCopied, wantCopied) {
+		t.Errorf("PreCopy calls = %v, want %v", preCopied, wantCopied)
+	}
+	if !reflect.DeepEqual(postCopied, wantCopied) {
+		t.Errorf("PostCopy calls = %v, want %v", postCopied, wantCopied)
+	}
+	gotDesc, err = dst.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatal("dst.Resolve() error =", err)
+	}
+	if !reflect.DeepEqual(gotDesc, root) {
+		t.Errorf("dst.Resolve() = %v, want %v", gotDesc, root)
+	}
+}
+
+func TestCopyGraph_CustomSuccessors(t *testing.T) {
+	// a custom manifest listing its parts, unknown to content.Successors
+	const mediaTypeBundle = "application/vnd.example.bundle.v1+json"
+	type bundle struct {
+		Parts []ocispec.Descriptor `json:"parts"`
+	}
+	src := memory.New()
+	ctx := context.Background()
+
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	pushBundle := func(parts ...ocispec.Descriptor) ocispec.Descriptor {
+		bundleJSON, err := json.Marshal(bundle{Parts: parts})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return push(mediaTypeBundle, bundleJSON)
+	}
+	foo := push("application/octet-stream", []byte("foo"))
+	bar := push("application/octet-stream", []byte("bar"))
+	child := pushBundle(bar)
+	root := pushBundle(foo, child)
+
+	// test the default successors treating the custom manifest as a leaf
+	dst := memory.New()
+	if err := oras.CopyGraph(ctx, src, dst, root, oras.CopyGraphOptions{}); err != nil {
+		t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+	}
+	for _, desc := range []ocispec.Descriptor{foo, bar, child} {
+		if exists, _ := dst.Exists(ctx, desc); exists {
+			t.Errorf("dst.Exists(%s) = %v, want %v", desc.Digest, exists, false)
+		}
+	}
+
+	// test the custom successors
+	dst = memory.New()
+	opts := oras.CopyGraphOptions{
+		FindSuccessors: func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+			if desc.MediaType != mediaTypeBundle {
+				return content.Successors(ctx, fetcher, desc)
+			}
+			bundleJSON, err := content.FetchAll(ctx, fetcher, desc)
+			if err != nil {
+				return nil, err
+			}
+			var b bundle
+			if err := json.Unmarshal(bundleJSON, &b); err != nil {
+				return nil, err
+			}
+			return b.Parts, nil
+		},
+	}
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+	}
+	for _, desc := range []ocispec.Descriptor{foo, bar, child, root} {
+		if exists, err := dst.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("dst.Exists(%s) = %v, %v, want %v", desc.Digest, exists, err, true)
+		}
+	}
+}
+
+func TestCopyGraph_MaxBytesPerSecond(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("config"))
+	foo := push(ocispec.MediaTypeImageLayer, bytes.Repeat([]byte("foo"), 10000))
+	bar := push(ocispec.MediaTypeImageLayer, bytes.Repeat([]byte("bar"), 10000))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{foo, bar},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := push(ocispec.MediaTypeImageManifest, manifestJSON)
+
+	// test throttling the concurrent copies collectively:
+	// 60 KB at 40 KB/s takes at least 0.5s after the initial burst
+	dst := memory.New()
+	opts := oras.CopyGraphOptions{
+		MaxBytesPerSecond: 40000,
+	}
+	start := time.Now()
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("CopyGraph() took %v, want at least %v", elapsed, 400*time.Millisecond)
+	}
+	for _, desc := range []ocispec.Descriptor{config, foo, bar, root} {
+		if exists, err := dst.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("dst.Exists(%s) = %v, %v, want %v", desc.Digest, exists, err, true)
+		}
+	}
+
+	// test canceling the copy while throttled
+	dst = memory.New()
+	opts.MaxBytesPerSecond = 1000
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CopyGraph() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("CopyGraph() took %v after cancellation", elapsed)
+	}
+}
+
+func TestCopyGraphToAll(t *testing.T) {
+	base := memory.New()
+	ctx := context.Background()
+
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := base.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("config"))
+	foo := push(ocispec.MediaTypeImageLayer, []byte("foo"))
+	bar := push(ocispec.MediaTypeImageLayer, []byte("bar"))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{foo, bar},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := push(ocispec.MediaTypeImageManifest, manifestJSON)
+	src := &storageTracker{Storage: base}
+
+	// dsts[1] has foo already, and dsts[2] fails to push bar
+	dsts := make([]*flakyStorage, 3)
+	for i := range dsts {
+		dsts[i] = &flakyStorage{
+			Storage:  memory.New(),
+			failures: make(map[digest.Digest]int),
+			pushes:   make(map[digest.Digest]int),
+		}
+	}
+	if err := dsts[1].Storage.Push(ctx, foo, bytes.NewReader([]byte("foo"))); err != nil {
+		t.Fatal(err)
+	}
+	dsts[2].failures[bar.Digest] = 1
+	storages := make([]content.Storage, len(dsts))
+	for i, dst := range dsts {
+		storages[i] = dst
+	}
+
+	err = oras.CopyGraphToAll(ctx, src, storages, root, oras.CopyGraphOptions{})
+	var fanOutErr *oras.FanOutError
+	if !errors.As(err, &fanOutErr) {
+		t.Fatalf("CopyGraphToAll() error = %v, want %T", err, fanOutErr)
+	}
+	if got, want := len(fanOutErr.Errors), 1; got != want {
+		t.Fatalf("len(FanOutError.Errors) = %v, want %v", got, want)
+	}
+	if err := fanOutErr.Errors[2]; !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("FanOutError.Errors[2] = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	// test fetching each node from the source once
+	if got, want := src.fetch, int64(4); got != want {
+		t.Errorf("count(src.Fetch()) = %v, want %v", got, want)
+	}
+	wantPushes := []map[digest.Digest]int{
+		{config.Digest: 1, foo.Digest: 1, bar.Digest: 1, root.Digest: 1},
+		{config.Digest: 1, bar.Digest: 1, root.Digest: 1},
+	}
+	for i, want := range wantPushes {
+		if got := dsts[i].pushes; !reflect.DeepEqual(got, want) {
+			t.Errorf("dsts[%d].pushes = %v, want %v", i, got, want)
+		}
+		for _, desc := range []ocispec.Descriptor{config, foo, bar, root} {
+			if exists, err := dsts[i].Exists(ctx, desc); err != nil || !exists {
+				t.Errorf("dsts[%d].Exists(%s) = %v, %v, want %v", i, desc.Digest, exists, err, true)
+			}
+		}
+	}
+	if exists, _ := dsts[2].Exists(ctx, root); exists {
+		t.Errorf("dsts[2].Exists(root) = %v, want %v", exists, false)
+	}
+}
+
+func TestCopyGraph_BaseManifest(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+
+	push := func(s content.Storage, mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	pushManifest := func(s content.Storage, config ocispec.Descriptor, layers ...ocispec.Descriptor) ocispec.Descriptor {
+		manifestJSON, err := json.Marshal(ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return push(s, ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+
+	// the base image in dst
+	dst := &strictStorage{
+		Storage: memory.New(),
+		exists:  make(map[digest.Digest]int),
+		pushes:  make(map[digest.Digest]int),
+	}
+	baseConfig := push(dst.Storage, ocispec.MediaTypeImageConfig, []byte("base config"))
+	foo := push(dst.Storage, ocispec.MediaTypeImageLayer, []byte("foo"))
+	base := pushManifest(dst.Storage, baseConfig, foo)
+
+	// the new image in src
+	config := push(src, ocispec.MediaTypeImageConfig, []byte("config"))
+	push(src, ocispec.MediaTypeImageLayer, []byte("foo"))
+	bar := push(src, ocispec.MediaTypeImageLayer, []byte("bar"))
+	root := pushManifest(src, config, foo, bar)
+
+	opts := oras.CopyGraphOptions{
+		BaseManifest: &base,
+	}
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v, wantErr %v", err, false)
+	}
+	wantPushes := map[digest.Digest]int{
+		config.Digest: 1,
+		bar.Digest:    1,
+		root.Digest:   1,
+	}
+	if !reflect.DeepEqual(dst.pushes, wantPushes) {
+		t.Errorf("pushes = %v, want %v", dst.pushes, wantPushes)
+	}
+	if got := dst.exists[foo.Digest]; got != 0 {
+		t.Errorf("existence checks of the base blob = %v, want 0", got)
+	}
+
+	// test the absent base
+	absent := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte("{}"))
+	opts.BaseManifest = &absent
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("CopyGraph() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestCopy_TagDigest(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	layer := []byte("layer")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	if err := src.Push(ctx, layerDesc, bytes.NewReader(layer)); err != nil {
+		t.Fatal(err)
+	}
+	root, err := oras.Pack(ctx, src, []ocispec.Descriptor{layerDesc}, oras.PackOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := "foobar"
+	if err := src.Tag(ctx, root, ref); err != nil {
+		t.Fatal(err)
+	}
+	tag := "sha256-" + root.Digest.Encoded() + ".digest"
+
+	tests := []struct {
+		name          string
+		dst           func() oras.Target
+		dstRef        string
+		wantTagRef    string
+		wantDigestRef string
+	}{
+		{
+			name:          "bare references",
+			dst:           func() oras.Target { return memory.New() },
+			dstRef:        "v1",
+			wantTagRef:    "v1",
+			wantDigestRef: root.Digest.String(),
+		},
+		{
+			name: "fully qualified references",
+			dst: func() oras.Target {
+				return &scopeRecordingTarget{
+					Target:     memory.New(),
+					registry:   "registry.example",
+					repository: "hello",
+					scopes:     make(map[string]bool),
+				}
+			},
+			dstRef:        "v1",
+			wantTagRef:    "registry.example/hello:v1",
+			wantDigestRef: "registry.example/hello@" + root.Digest.String(),
+		},
+		{
+			name:          "digest destination reference",
+			dst:           func() oras.Target { return memory.New() },
+			dstRef:        root.Digest.String(),
+			wantTagRef:    "",
+			wantDigestRef: root.Digest.String(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := tt.dst()
+			var gotTagRef, gotDigestRef string
+			opts := oras.CopyOptions{
+				TagDigest: true,
+				OnCopied: func(ctx context.Context, desc ocispec.Descriptor, tagRef, digestRef string) error {
+					if !reflect.DeepEqual(desc, root) {
+						t.Errorf("OnCopied() desc = %v, want %v", desc, root)
+					}
+					gotTagRef, gotDigestRef = tagRef, digestRef
+					return nil
+				},
+			}
+			if _, err := oras.Copy(ctx, src, ref, dst, tt.dstRef, opts); err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+			if gotTagRef != tt.wantTagRef {
+				t.Errorf("OnCopied() tagRef = %q, want %q", gotTagRef, tt.wantTagRef)
+			}
+			if gotDigestRef != tt.wantDigestRef {
+				t.Errorf("OnCopied() digestRef = %q, want %q", gotDigestRef, tt.wantDigestRef)
+			}
+			got, err := dst.Resolve(ctx, tag)
+			if err != nil {
+				t.Fatalf("Resolve(%q) error = %v", tag, err)
+			}
+			if !reflect.DeepEqual(got, root) {
+				t.Errorf("Resolve(%q) = %v, want %v", tag, got, root)
+			}
+		})
+	}
+}
+
+func TestCopy_TagDigest_ConvertManifest(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	config := push(docker.MediaTypeConfig, []byte("config"))
+	layer := push(docker.MediaTypeLayer, []byte("layer"))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		MediaType: docker.MediaTypeManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{layer},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := push(docker.MediaTypeManifest, manifestJSON)
+	ref := "foobar"
+	if err := src.Tag(ctx, root, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := memory.New()
+	opts := oras.CopyOptions{
+		CopyGraphOptions: oras.CopyGraphOptions{
+			ConvertManifest: true,
+		},
+		TagDigest: true,
+		AtomicTag: true,
+	}
+	gotDesc, err := oras.Copy(ctx, src, ref, dst, ref, opts)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if gotDesc.Digest == root.Digest {
+		t.Fatalf("Copy() digest = %v, want converted digest", gotDesc.Digest)
+	}
+
+	// the tags derived from the digest name the converted root node
+	for _, tag := range []string{
+		"sha256-" + gotDesc.Digest.Encoded() + ".digest",
+		"tmp_sha256_" + gotDesc.Digest.Encoded(),
+	} {
+		got, err := dst.Resolve(ctx, tag)
+		if err != nil {
+			t.Fatalf("Resolve(%q) error = %v", tag, err)
+		}
+		if got.Digest != gotDesc.Digest {
+			t.Errorf("Resolve(%q) digest = %v, want %v", tag, got.Digest, gotDesc.Digest)
+		}
+	}
+	for _, tag := range []string{
+		"sha256-" + root.Digest.Encoded() + ".digest",
+		"tmp_sha256_" + root.Digest.Encoded(),
+	} {
+		if _, err := dst.Resolve(ctx, tag); !errors.Is(err, errdef.ErrNotFound) {
+			t.Errorf("Resolve(%q) error = %v, want %v", tag, err, errdef.ErrNotFound)
+		}
+	}
+}
+
+// referrerListingTarget is a target listing the referrers of the subjects
+// from a fixed table, like a registry supporting the Referrers API.
+type referrerListingTarget struct {
+	oras.Target
+	referrers map[digest.Digest][]ocispec.Descriptor
+}
+
+func (t *referrerListingTarget) Referrers(_ context.Context, desc ocispec.Descriptor, _ string, fn func(referrers []ocispec.Descriptor) error) error {
+	return fn(t.referrers[desc.Digest])
+}
+
+// pushCountingTarget counts the pushes of each content.
+type pushCountingTarget struct {
+	oras.Target
+	lock   sync.Mutex
+	pushes map[digest.Digest]int
+}
+
+func (t *pushCountingTarget) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	t.lock.Lock()
+	t.pushes[expected.Digest]++
+	t.lock.Unlock()
+	return t.Target.Push(ctx, expected, content)
+}
+
+func TestCopy_WithReferrers_Overlapping(t *testing.T) {
+	storage := memory.New()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	generateIndex := func(manifests ...ocispec.Descriptor) {
+		index := ocispec.Index{
+			Manifests: manifests,
+		}
+		indexJSON, err := json.Marshal(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageIndex, indexJSON)
+	}
+	generateArtifact := func(blobs ...ocispec.Descriptor) {
+		artifact := ocispec.Artifact{
+			MediaType:    ocispec.MediaTypeArtifactManifest,
+			ArtifactType: "application/vnd.test",
+			Blobs:        blobs,
+		}
+		artifactJSON, err := json.Marshal(artifact)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeArtifactManifest, artifactJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("bar"))     // Blob 2
+	generateManifest(descs[0], descs[1])                       // Blob 3
+	generateManifest(descs[0], descs[2])                       // Blob 4
+	generateIndex(descs[3], descs[4])                          // Blob 5
+	appendBlob("application/vnd.sbom", []byte("sbom"))         // Blob 6
+	generateArtifact(descs[6])                                 // Blob 7
+	appendBlob("application/vnd.sig", []byte("sig"))           // Blob 8
+	generateArtifact(descs[8])                                 // Blob 9
+	generateArtifact(descs[8], descs[6])                       // Blob 10
+
+	ctx := context.Background()
+	for i := range blobs {
+		err := storage.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+	root := descs[5]
+	ref := "foobar"
+	if err := storage.Tag(ctx, root, ref); err != nil {
+		t.Fatal("fail to tag root node", err)
+	}
+	// the SBOM is attached to the index and both of its manifests, and the
+	// signatures sharing a blob are attached to the manifests
+	src := &referrerListingTarget{
+		Target: storage,
+		referrers: map[digest.Digest][]ocispec.Descriptor{
+			descs[5].Digest: {descs[7]},
+			descs[3].Digest: {descs[7], descs[9]},
+			descs[4].Digest: {descs[7], descs[10]},
+		},
+	}
+
+	dst := &pushCountingTarget{
+		Target: memory.New(),
+		pushes: make(map[digest.Digest]int),
+	}
+	opts := oras.CopyOptions{
+		WithReferrers: true,
+	}
+	if _, err := oras.Copy(ctx, src, ref, dst, "", opts); err != nil {
+		t.Fatalf("Copy() error = %v, wantErr %v", err, false)
+	}
+	for i, desc := range descs {
+		if got := dst.pushes[desc.Digest]; got != 1 {
+			t.Errorf("count(Push(%d)) = %v, want %v", i, got, 1)
+		}
+	}
+}
+
+func TestCopy_PreserveUnknownFields(t *testing.T) {
+	ctx := context.Background()
+	layer := []byte("foo")
+	layerDesc := ocispec.Descriptor{
+		MediaType: docker.MediaTypeForeignLayer,
+		Digest:    digest.FromBytes(layer),
+		Size:      int64(len(layer)),
+		URLs:      []string{"https://example.com/foo"},
+	}
+	config := []byte("{}")
+	configDesc := ocispec.Descriptor{
+		MediaType: docker.MediaTypeConfig,
+		Digest:    digest.FromBytes(config),
+		Size:      int64(len(config)),
+	}
+	manifestJSON := []byte(fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,`+
+		`"config":{"mediaType":%q,"digest":%q,"size":%d},`+
+		`"layers":[{"mediaType":%q,"digest":%q,"size":%d,"urls":["https://example.com/foo"],"x-layer-extension":"bar"}],`+
+		`"x-extension":{"foo":"bar"}}`,
+		docker.MediaTypeManifest,
+		configDesc.MediaType, configDesc.Digest, configDesc.Size,
+		layerDesc.MediaType, layerDesc.Digest, layerDesc.Size))
+	manifestDesc := content.NewDescriptorFromBytes(docker.MediaTypeManifest, manifestJSON)
+
+	src := memory.New()
+	for _, blob := range []struct {
+		desc    ocispec.Descriptor
+		content []byte
+	}{
+		{configDesc, config},
+		{layerDesc, layer},
+		{manifestDesc, manifestJSON},
+	} {
+		if err := src.Push(ctx, blob.desc, bytes.NewReader(blob.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ref := "foobar"
+	if err := src.Tag(ctx, manifestDesc, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	// decode parses the manifest as generic JSON for comparison
+	decode := func(t *testing.T, manifestJSON []byte) map[string]interface{} {
+		var manifest map[string]interface{}
+		if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+			t.Fatal(err)
+		}
+		return manifest
+	}
+	tests := []struct {
+		name   string
+		opts   oras.CopyOptions
+		verify func(t *testing.T, got []byte)
+	}{
+		{
+			name: "verbatim",
+			verify: func(t *testing.T, got []byte) {
+				if !bytes.Equal(got, manifestJSON) {
+					t.Errorf("copied manifest = %s, want %s", got, manifestJSON)
+				}
+			},
+		},
+		{
+			name: "convert manifest",
+			opts: oras.CopyOptions{
+				CopyGraphOptions: oras.CopyGraphOptions{
+					ConvertManifest: true,
+				},
+			},
+			verify: func(t *testing.T, got []byte) {
+				manifest := decode(t, got)
+				if got, want := manifest["mediaType"], ocispec.MediaTypeImageManifest; got != want {
+					t.Errorf("mediaType = %v, want %v", got, want)
+				}
+				if got, want := manifest["x-extension"], map[string]interface{}{"foo": "bar"}; !reflect.DeepEqual(got, want) {
+					t.Errorf("x-extension = %v, want %v", got, want)
+				}
+				layer := manifest["layers"].([]interface{})[0].(map[string]interface{})
+				if got, want := layer["mediaType"], ocispec.MediaTypeImageLayerNonDistributableGzip; got != want {
+					t.Errorf("layer mediaType = %v, want %v", got, want)
+				}
+				if got, want := layer["x-layer-extension"], "bar"; got != want {
+					t.Errorf("layer x-layer-extension = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name: "rewrite URLs",
+			opts: oras.CopyOptions{
+				CopyGraphOptions: oras.CopyGraphOptions{
+					RewriteURLs: func(desc ocispec.Descriptor) ocispec.Descriptor {
+						desc.URLs = []string{"https://mirror.example.com/foo"}
+						return desc
+					},
+				},
+			},
+			verify: func(t *testing.T, got []byte) {
+				manifest := decode(t, got)
+				if got, want := manifest["x-extension"], map[string]interface{}{"foo": "bar"}; !reflect.DeepEqual(got, want) {
+					t.Errorf("x-extension = %v, want %v", got, want)
+				}
+				layer := manifest["layers"].([]interface{})[0].(map[string]interface{})
+				if got, want := layer["urls"], []interface{}{"https://mirror.example.com/foo"}; !reflect.DeepEqual(got, want) {
+					t.Errorf("layer urls = %v, want %v", got, want)
+				}
+				if got, want := layer["x-layer-extension"], "bar"; got != want {
+					t.Errorf("layer x-layer-extension = %v, want %v", got, want)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := memory.New()
+			root, err := oras.Copy(ctx, src, ref, dst, "", tt.opts)
+			if err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+			got, err := content.FetchAll(ctx, dst, root)
+			if err != nil {
+				t.Fatalf("FetchAll() error = %v", err)
+			}
+			tt.verify(t, got)
+		})
+	}
+}
+
+// referenceFetchingTarget is a fetchCountingTarget supporting
+// registry.ReferenceFetcher, which counts the resolves and optionally tampers
+// the fetched content.
+type referenceFetchingTarget struct {
+	*fetchCountingTarget
+	resolves int32
+	tamper   bool
+}
+
+func (t *referenceFetchingTarget) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	atomic.AddInt32(&t.resolves, 1)
+	return t.Target.Resolve(ctx, reference)
+}
+
+func (t *referenceFetchingTarget) FetchReference(ctx context.Context, reference string) (ocispec.Descriptor, io.ReadCloser, error) {
+	desc, err := t.Target.Resolve(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	if t.tamper {
+		return desc, io.NopCloser(strings.NewReader(strings.Repeat("x", int(desc.Size)))), nil
+	}
+	rc, err := t.Target.Fetch(ctx, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	return desc, rc, nil
+}
+
+func TestCopy_FetchReference(t *testing.T) {
+	ctx := context.Background()
+	storage := memory.New()
+	layer := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("foo"))
+	if err := storage.Push(ctx, layer, bytes.NewReader([]byte("foo"))); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := oras.Pack(ctx, storage, []ocispec.Descriptor{layer}, oras.PackOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Tag(ctx, manifest, "manifest"); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Tag(ctx, layer, "blob"); err != nil {
+		t.Fatal(err)
+	}
+
+	for ref, root := range map[string]ocispec.Descriptor{
+		"manifest": manifest,
+		"blob":     layer,
+	} {
+		t.Run(ref, func(t *testing.T) {
+			src := &referenceFetchingTarget{
+				fetchCountingTarget: &fetchCountingTarget{
+					Target:  storage,
+					fetches: make(map[digest.Digest]int),
+				},
+			}
+			dst := memory.New()
+			got, err := oras.Copy(ctx, src, ref, dst, "", oras.CopyOptions{})
+			if err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+			if !content.Equal(got, root) {
+				t.Errorf("Copy() = %v, want %v", got, root)
+			}
+			if n := atomic.LoadInt32(&src.resolves); n != 0 {
+				t.Errorf("Copy() resolves = %d, want 0", n)
+			}
+			if n := src.fetches[root.Digest]; n != 0 {
+				t.Errorf("Copy() fetches of the root = %d, want 0", n)
+			}
+			if _, err := dst.Resolve(ctx, ref); err != nil {
+				t.Errorf("dst.Resolve() error = %v", err)
+			}
+		})
+	}
+
+	t.Run("digest mismatch", func(t *testing.T) {
+		src := &referenceFetchingTarget{
+			fetchCountingTarget: &fetchCountingTarget{
+				Target:  storage,
+				fetches: make(map[digest.Digest]int),
+			},
+			tamper: true,
+		}
+		_, err := oras.Copy(ctx, src, "manifest", memory.New(), "", oras.CopyOptions{})
+		if !errors.Is(err, content.ErrMismatchedDigest) {
+			t.Errorf("Copy() error = %v, want %v", err, content.ErrMismatchedDigest)
+		}
+	})
+}
+
+func TestCopyGraph_LayerFilter(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	configDesc := push(ocispec.MediaTypeImageConfig, []byte("config"))
+	fooDesc := push("application/vnd.test.foo", []byte("foo"))
+	barDesc := push("application/vnd.test.bar", []byte("bar"))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: configDesc,
+		Layers: []ocispec.Descriptor{fooDesc, barDesc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDesc := push(ocispec.MediaTypeImageManifest, manifestJSON)
+	indexJSON, err := json.Marshal(ocispec.Index{
+		Manifests: []ocispec.Descriptor{manifestDesc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := push(ocispec.MediaTypeImageIndex, indexJSON)
+
+	dst := memory.New()
+	var filtered []ocispec.Descriptor
+	opts := oras.CopyGraphOptions{
+		LayerFilter: func(desc ocispec.Descriptor) bool {
+			filtered = append(filtered, desc)
+			return desc.MediaType == "application/vnd.test.foo"
+		},
+		Ordered: true,
+	}
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	for _, tt := range []struct {
+		desc ocispec.Descriptor
+		want bool
+	}{
+		{configDesc, true},
+		{fooDesc, true},
+		{barDesc, false},
+		{manifestDesc, true},
+		{root, true},
+	} {
+		exists, err := dst.Exists(ctx, tt.desc)
+		if err != nil {
+			t.Fatalf("dst.Exists(%s) error = %v", tt.desc.Digest, err)
+		}
+		if exists != tt.want {
+			t.Errorf("dst.Exists(%s) = %v, want %v", tt.desc.Digest, exists, tt.want)
+		}
+	}
+	// the filter is applied to the layers only
+	if len(filtered) == 0 {
+		t.Error("LayerFilter() is not called")
+	}
+	for _, desc := range filtered {
+		if !content.Equal(desc, fooDesc) && !content.Equal(desc, barDesc) {
+			t.Errorf("LayerFilter() called with non-layer %v", desc)
+		}
+	}
+}
+
+func TestCopyGraph_AllowedMediaTypes(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	configDesc := push(ocispec.MediaTypeImageConfig, []byte("config"))
+	layerDesc := push("application/vnd.test.layer", []byte("layer"))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: configDesc,
+		Layers: []ocispec.Descriptor{layerDesc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := push(ocispec.MediaTypeImageManifest, manifestJSON)
+
+	// the disallowed layer fails the copy before anything is pushed
+	dst := memory.New()
+	opts := oras.CopyGraphOptions{
+		AllowedMediaTypes: []string{ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageConfig},
+	}
+	err = oras.CopyGraph(ctx, src, dst, root, opts)
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Fatalf("CopyGraph() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	for _, desc := range []ocispec.Descriptor{configDesc, layerDesc, root} {
+		exists, err := dst.Exists(ctx, desc)
+		if err != nil {
+			t.Fatalf("dst.Exists(%s) error = %v", desc.Digest, err)
+		}
+		if exists {
+			t.Errorf("dst.Exists(%s) = %v, want %v", desc.Digest, exists, false)
+		}
+	}
+
+	// all media types allowed
+	opts.AllowedMediaTypes = append(opts.AllowedMediaTypes, layerDesc.MediaType)
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	for _, desc := range []ocispec.Descriptor{configDesc, layerDesc, root} {
+		exists, err := dst.Exists(ctx, desc)
+		if err != nil {
+			t.Fatalf("dst.Exists(%s) error = %v", desc.Digest, err)
+		}
+		if !exists {
+			t.Errorf("dst.Exists(%s) = %v, want %v", desc.Digest, exists, true)
+		}
+	}
+}
+
+// failingPushTarget fails the push of the content with the given digest.
+type failingPushTarget struct {
+	oras.Target
+	failDigest digest.Digest
+}
+
+func (t *failingPushTarget) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	if expected.Digest == t.failDigest {
+		return errors.New("push failed")
+	}
+	return t.Target.Push(ctx, expected, content)
+}
+
+func TestCopy_AtomicTag(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	push := func(s oras.Target, mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	pushManifest := func(s oras.Target, manifest ocispec.Manifest) ocispec.Descriptor {
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return push(s, ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	configDesc := push(src, ocispec.MediaTypeImageConfig, []byte("config"))
+	layerDesc := push(src, ocispec.MediaTypeImageLayer, []byte("new"))
+	root := pushManifest(src, ocispec.Manifest{
+		Config: configDesc,
+		Layers: []ocispec.Descriptor{layerDesc},
+	})
+	sigDesc := push(src, "application/vnd.test.sig", []byte("sig"))
+	pushManifest(src, ocispec.Manifest{
+		Config:  configDesc,
+		Layers:  []ocispec.Descriptor{sigDesc},
+		Subject: &root,
+	})
+	if err := src.Tag(ctx, root, "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	newDestination := func() (oras.Target, ocispec.Descriptor) {
+		dst := memory.New()
+		oldLayerDesc := push(dst, ocispec.MediaTypeImageLayer, []byte("old"))
+		oldConfigDesc := push(dst, ocispec.MediaTypeImageConfig, []byte("config"))
+		oldRoot := pushManifest(dst, ocispec.Manifest{
+			Config: oldConfigDesc,
+			Layers: []ocispec.Descriptor{oldLayerDesc},
+		})
+		if err := dst.Tag(ctx, oldRoot, "prod"); err != nil {
+			t.Fatal(err)
+		}
+		return dst, oldRoot
+	}
+
+	tests := []struct {
+		name      string
+		atomicTag bool
+		fail      bool
+		wantMoved bool
+	}{
+		{
+			name:      "mid-copy failure moves the tag without AtomicTag",
+			fail:      true,
+			wantMoved: true,
+		},
+		{
+			name:      "mid-copy failure keeps the tag with AtomicTag",
+			atomicTag: true,
+			fail:      true,
+		},
+		{
+			name:      "successful copy moves the tag with AtomicTag",
+			atomicTag: true,
+			wantMoved: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst, oldRoot := newDestination()
+			target := &failingPushTarget{Target: dst}
+			if tt.fail {
+				// fail copying the referrer after the root node is copied
+				target.failDigest = sigDesc.Digest
+			}
+			opts := oras.CopyOptions{
+				WithReferrers: true,
+				AtomicTag:     tt.atomicTag,
+			}
+			_, err := oras.Copy(ctx, src, "new", target, "prod", opts)
+			if (err != nil) != tt.fail {
+				t.Fatalf("Copy() error = %v, wantErr %v", err, tt.fail)
+			}
+
+			got, err := dst.Resolve(ctx, "prod")
+			if err != nil {
+				t.Fatalf("dst.Resolve() error = %v", err)
+			}
+			want := oldRoot
+			if tt.wantMoved {
+				want = root
+			}
+			if !content.Equal(got, want) {
+				t.Errorf("dst.Resolve() = %v, want %v", got, want)
+			}
+
+			if tt.atomicTag {
+				// the root node is kept by the temporary tag
+				tempTag := "tmp_sha256_" + root.Digest.Encoded()
+				got, err := dst.Resolve(ctx, tempTag)
+				if err != nil {
+					t.Fatalf("dst.Resolve(%s) error = %v", tempTag, err)
+				}
+				if !content.Equal(got, root) {
+					t.Errorf("dst.Resolve(%s) = %v, want %v", tempTag, got, root)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// ErrIncompleteSuccessors is reported in a CopyError when a node is not
+// copied since some of its successors failed to be copied.
+var ErrIncompleteSuccessors = errors.New("incomplete successors")
+
+// CopyError describes the failure of copying a node.
+type CopyError struct {
+	// Descriptor is the descriptor of the node failed to be copied.
+	Descriptor ocispec.Descriptor
+	// Err is the cause of the failure.
+	Err error
+}
+
+// Error returns the error message of the failure.
+func (e *CopyError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.Descriptor.Digest, e.Descriptor.MediaType, e.Err)
+}
+
+// Unwrap returns the cause of the failure.
+func (e *CopyError) Unwrap() error {
+	return e.Err
+}
+
+// PartialCopyError is returned by the copy functions when
+// CopyGraphOptions.ContinueOnError is set and some of the nodes failed to be
+// copied. The nodes not listed are copied successfully.
+type PartialCopyError struct {
+	// Errors lists the failures of the nodes failed to be copied.
+	Errors []*CopyError
+}
+
+// Error returns the error message listing all the failures.
+func (e *PartialCopyError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("failed to copy %d node(s): %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Is returns true if any of the failures matches target.
+func (e *PartialCopyError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// copyFailures records the failures of the nodes when copying with
+// CopyGraphOptions.ContinueOnError.
+// A nil *copyFailures does not record any failure, and returns the errors as
+// is.
+type copyFailures struct {
+	lock   sync.Mutex
+	errors []*CopyError
+	failed sync.Map // map[descriptor.Descriptor]struct{}
+}
+
+// newCopyFailures returns a recorder of failures if continueOnError is set.
+func newCopyFailures(continueOnError bool) *copyFailures {
+	if !continueOnError {
+		return nil
+	}
+	return &copyFailures{}
+}
+
+// record records the failure of desc if err is not nil, and returns nil
+// so that the copy continues. err is returned as is if f is nil.
+func (f *copyFailures) record(desc ocispec.Descriptor, err error) error {
+	if f == nil || err == nil {
+		return err
+	}
+	f.failed.Store(descriptor.FromOCI(desc), struct{}{})
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.errors = append(f.errors, &CopyError{
+		Descriptor: desc,
+		Err:        err,
+	})
+	return nil
+}
+
+// Failed returns true if desc failed to be copied.
+func (f *copyFailures) Failed(desc ocispec.Descriptor) bool {
+	if f == nil {
+		return false
+	}
+	_, failed := f.failed.Load(descriptor.FromOCI(desc))
+	return failed
+}
+
+// Err returns a *PartialCopyError listing the recorded failures, or nil if no
+// failure is recorded.
+func (f *copyFailures) Err() error {
+	if f == nil {
+		return nil
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if len(f.errors) == 0 {
+		return nil
+	}
+	return &PartialCopyError{
+		Errors: f.errors,
+	}
+}
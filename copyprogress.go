@@ -0,0 +1,189 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// CopyStats reports the overall progress of copying a graph.
+// The nodes, including the manifests, the configs, and the layers, are
+// deduplicated by digest.
+type CopyStats struct {
+	// TotalBlobs is the number of the nodes in the graph.
+	TotalBlobs int
+	// CompletedBlobs is the number of the nodes copied, mounted, or skipped
+	// as they exist in the destination.
+	CompletedBlobs int
+	// TotalBytes is the total size of the nodes in the graph.
+	TotalBytes int64
+	// CopiedBytes is the total size of the completed nodes, plus the bytes
+	// of the nodes being copied read from the source so far.
+	CopiedBytes int64
+}
+
+// copyProgress aggregates the progress of copying a graph.
+// A nil *copyProgress does not track the progress.
+type copyProgress struct {
+	lock       sync.Mutex
+	stats      CopyStats
+	fn         func(stats CopyStats)
+	successors map[digest.Digest][]ocispec.Descriptor
+	completed  map[digest.Digest]bool
+	inflight   map[digest.Digest]int64
+}
+
+// newCopyProgress walks the graph rooted at root once to compute the totals,
+// and reports the initial stats to fn.
+func newCopyProgress(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor, findSuccessors func(context.Context, content.Fetcher, ocispec.Descriptor) ([]ocispec.Descriptor, error), fn func(stats CopyStats)) (*copyProgress, error) {
+	p := &copyProgress{
+		fn:         fn,
+		successors: make(map[digest.Digest][]ocispec.Descriptor),
+		completed:  make(map[digest.Digest]bool),
+		inflight:   make(map[digest.Digest]int64),
+	}
+	stack := []ocispec.Descriptor{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, visited := p.successors[node.Digest]; visited {
+			continue
+		}
+		successors, err := findSuccessors(ctx, fetcher, node)
+		if err != nil {
+			return nil, err
+		}
+		if successors == nil {
+			successors = []ocispec.Descriptor{}
+		}
+		p.successors[node.Digest] = successors
+		p.stats.TotalBlobs++
+		p.stats.TotalBytes += node.Size
+		stack = append(stack, successors...)
+	}
+	p.fn(p.stats)
+	return p, nil
+}
+
+// complete marks the node desc completed.
+func (p *copyProgress) complete(desc ocispec.Descriptor) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.markCompleted(desc) {
+		p.fn(p.stats)
+	}
+}
+
+// skip marks the sub-DAG rooted at desc completed, as none of its nodes are
+// visited once desc exists in the destination.
+func (p *copyProgress) skip(desc ocispec.Descriptor) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	updated := false
+	stack := []ocispec.Descriptor{desc}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if p.markCompleted(node) {
+			updated = true
+			stack = append(stack, p.successors[node.Digest]...)
+		}
+	}
+	if updated {
+		p.fn(p.stats)
+	}
+}
+
+// markCompleted marks the node desc completed, and returns true if it was
+// not completed before. p.lock must be held.
+func (p *copyProgress) markCompleted(desc ocispec.Descriptor) bool {
+	if p.completed[desc.Digest] {
+		return false
+	}
+	p.completed[desc.Digest] = true
+	p.stats.CompletedBlobs++
+	p.stats.CopiedBytes += desc.Size - p.inflight[desc.Digest]
+	delete(p.inflight, desc.Digest)
+	return true
+}
+
+// restart discards the bytes of the node desc read by a previous attempt.
+func (p *copyProgress) restart(desc ocispec.Descriptor) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if n := p.inflight[desc.Digest]; n > 0 && !p.completed[desc.Digest] {
+		p.stats.CopiedBytes -= n
+		delete(p.inflight, desc.Digest)
+		p.fn(p.stats)
+	}
+}
+
+// read accounts n bytes of the node desc read from the source.
+func (p *copyProgress) read(desc ocispec.Descriptor, n int64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.completed[desc.Digest] {
+		return
+	}
+	p.inflight[desc.Digest] += n
+	p.stats.CopiedBytes += n
+	p.fn(p.stats)
+}
+
+// fetcher returns a fetcher accounting the bytes read from f.
+// If p is nil, f is returned as is.
+func (p *copyProgress) fetcher(f content.Fetcher) content.Fetcher {
+	if p == nil {
+		return f
+	}
+	return &progressFetcher{
+		Fetcher:  f,
+		progress: p,
+	}
+}
+
+// progressFetcher accounts the bytes read of the fetched content to a
+// copyProgress.
+type progressFetcher struct {
+	content.Fetcher
+	progress *copyProgress
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (f *progressFetcher) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := f.Fetcher.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	f.progress.restart(target)
+	var last int64
+	return content.NewProgressReader(rc, target.Size, func(copied int64) {
+		f.progress.read(target, copied-last)
+		last = copied
+	}), nil
+}
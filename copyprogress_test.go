@@ -0,0 +1,118 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestCopy_OnOverallProgress(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	base := push(ocispec.MediaTypeImageLayer, []byte("base layer"))
+	foo := push(ocispec.MediaTypeImageLayer, []byte("foo"))
+	bar := push(ocispec.MediaTypeImageLayer, []byte("bar"))
+	fooManifest, err := oras.Pack(ctx, src, []ocispec.Descriptor{base, foo}, oras.PackOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	barManifest, err := oras.Pack(ctx, src, []ocispec.Descriptor{base, bar}, oras.PackOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexJSON, err := json.Marshal(ocispec.Index{
+		Manifests: []ocispec.Descriptor{fooManifest, barManifest},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := push(ocispec.MediaTypeImageIndex, indexJSON)
+	ref := "foobar"
+	if err := src.Tag(ctx, root, ref); err != nil {
+		t.Fatal(err)
+	}
+	wantBytes, wantBlobs, err := oras.GraphSize(ctx, src, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		prepare func(dst oras.Target)
+	}{
+		{
+			name:    "copy all",
+			prepare: func(oras.Target) {},
+		},
+		{
+			name: "skip existing manifest",
+			prepare: func(dst oras.Target) {
+				if err := oras.CopyGraph(ctx, src, dst, fooManifest, oras.CopyGraphOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := memory.New()
+			tt.prepare(dst)
+			var reports []oras.CopyStats
+			opts := oras.CopyOptions{}
+			opts.OnOverallProgress = func(stats oras.CopyStats) {
+				reports = append(reports, stats)
+			}
+			if _, err := oras.Copy(ctx, src, ref, dst, "", opts); err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+			if len(reports) < 2 {
+				t.Fatalf("number of reports = %v, want at least 2", len(reports))
+			}
+			want := oras.CopyStats{
+				TotalBlobs: wantBlobs,
+				TotalBytes: wantBytes,
+			}
+			if got := reports[0]; got != want {
+				t.Errorf("first report = %+v, want %+v", got, want)
+			}
+			want.CompletedBlobs = wantBlobs
+			want.CopiedBytes = wantBytes
+			if got := reports[len(reports)-1]; got != want {
+				t.Errorf("last report = %+v, want %+v", got, want)
+			}
+			for i := 1; i < len(reports); i++ {
+				if reports[i].CompletedBlobs < reports[i-1].CompletedBlobs {
+					t.Errorf("report[%d].CompletedBlobs = %v, decreased from %v", i, reports[i].CompletedBlobs, reports[i-1].CompletedBlobs)
+				}
+			}
+		})
+	}
+}
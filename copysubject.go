@@ -0,0 +1,129 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/cas"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// DefaultCopyWithSubjectOptions provides the default CopyWithSubjectOptions.
+var DefaultCopyWithSubjectOptions = CopyWithSubjectOptions{
+	CopyGraphOptions: DefaultCopyGraphOptions,
+}
+
+// CopyWithSubjectOptions contains parameters for oras.CopyWithSubject.
+type CopyWithSubjectOptions struct {
+	CopyGraphOptions
+	// WithReferrers enables copying the other referrers of the subjects as
+	// well, such as the signatures and the SBOMs attached to the subject
+	// image besides the given referrer. The referrers are copied
+	// recursively up to ReferrersDepth levels from the top-most subject.
+	// See also `CopyOptions.WithReferrers`.
+	WithReferrers bool
+	// ReferrersDepth limits the maximum levels of referrers to be copied when
+	// WithReferrers is set.
+	// If less than or equal to 0, a default (currently 8) is used.
+	ReferrersDepth int
+}
+
+// CopyWithSubject copies the referrer manifest, such as a signature or an
+// SBOM, from src to dst together with its subject, so that the referrer is
+// not dangling in dst. If the subject is a referrer itself, its subject is
+// copied as well, up to the top-most subject, whose descriptor is returned.
+// The subjects are followed once each, so that interlinked subjects and
+// referrers never cause an infinite recursion.
+// If the referrer has no subject, only the referrer is copied, and its
+// descriptor is returned.
+func CopyWithSubject(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, referrer ocispec.Descriptor, opts CopyWithSubjectOptions) (ocispec.Descriptor, error) {
+	if opts.MaxMetadataBytes <= 0 {
+		opts.MaxMetadataBytes = defaultCopyMaxMetadataBytes
+	}
+	proxy := cas.NewProxyWithLimit(src, cas.NewMemory(), opts.MaxMetadataBytes)
+	// share the quota and the bandwidth across the copies of the graphs
+	opts.quota = newTransferQuota(opts.MaxTotalBytes)
+	opts.bandwidth = newBandwidthLimiter(opts.MaxBytesPerSecond)
+	if converter := newManifestConverter(proxy, opts.CopyGraphOptions); converter != nil {
+		dst = converter.Storage(dst)
+	}
+
+	subjects, err := findSubjects(ctx, proxy, referrer)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	top := referrer
+	if len(subjects) > 0 {
+		top = subjects[len(subjects)-1]
+	}
+
+	// copy the subjects top-down before the referrer, so that the referrers
+	// are never pushed before their subjects
+	for i := len(subjects) - 1; i >= 0; i-- {
+		if err := copyGraph(ctx, src, dst, proxy, subjects[i], opts.CopyGraphOptions); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	if err := copyGraph(ctx, src, dst, proxy, referrer, opts.CopyGraphOptions); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if opts.WithReferrers {
+		if err := copyReferrers(ctx, src, dst, proxy, top, opts.ReferrersDepth, opts.CopyGraphOptions); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	if err := flush(ctx, dst); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return top, nil
+}
+
+// findSubjects returns the chain of the subjects of the referrer, starting
+// from its direct subject to the top-most one. Each subject is followed once,
+// so that the chain is finite even if the subjects and the referrers are
+// interlinked.
+func findSubjects(ctx context.Context, fetcher content.Fetcher, referrer ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	visited := map[digest.Digest]bool{
+		referrer.Digest: true,
+	}
+	var subjects []ocispec.Descriptor
+	node := referrer
+	for descriptor.IsManifest(node) {
+		manifestJSON, err := fetchManifest(ctx, fetcher, node)
+		if err != nil {
+			return nil, err
+		}
+		var manifest struct {
+			Subject *ocispec.Descriptor `json:"subject,omitempty"`
+		}
+		if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+			return nil, fmt.Errorf("%s: %s: failed to decode manifest: %w", node.Digest, node.MediaType, err)
+		}
+		if manifest.Subject == nil || visited[manifest.Subject.Digest] {
+			break
+		}
+		node = *manifest.Subject
+		visited[node.Digest] = true
+		subjects = append(subjects, node)
+	}
+	return subjects, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestCopyWithSubject(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	pushManifest := func(config ocispec.Descriptor, subject *ocispec.Descriptor, layers ...ocispec.Descriptor) ocispec.Descriptor {
+		manifestJSON, err := json.Marshal(struct {
+			ocispec.Manifest
+			Subject *ocispec.Descriptor `json:"subject,omitempty"`
+		}{
+			Manifest: ocispec.Manifest{
+				Versioned: specs.Versioned{SchemaVersion: 2},
+				MediaType: ocispec.MediaTypeImageManifest,
+				Config:    config,
+				Layers:    layers,
+			},
+			Subject: subject,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return push(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+
+	config := push(ocispec.MediaTypeImageConfig, []byte("config"))
+	layer := push(ocispec.MediaTypeImageLayer, []byte("layer"))
+	image := pushManifest(config, nil, layer)
+	sbomConfig := push("application/vnd.test.sbom", []byte("sbom"))
+	sbom := pushManifest(sbomConfig, &image)
+	sigConfig := push("application/vnd.test.signature", []byte("signature"))
+	signature := pushManifest(sigConfig, &sbom)
+	attConfig := push("application/vnd.test.attestation", []byte("attestation"))
+	attestation := pushManifest(attConfig, &image)
+
+	tests := []struct {
+		name          string
+		referrer      ocispec.Descriptor
+		withReferrers bool
+		want          ocispec.Descriptor
+		wantCopied    []ocispec.Descriptor
+		wantNotCopied []ocispec.Descriptor
+	}{
+		{
+			name:          "subject chain",
+			referrer:      signature,
+			want:          image,
+			wantCopied:    []ocispec.Descriptor{config, layer, image, sbomConfig, sbom, sigConfig, signature},
+			wantNotCopied: []ocispec.Descriptor{attConfig, attestation},
+		},
+		{
+			name:          "subject chain with referrers",
+			referrer:      signature,
+			withReferrers: true,
+			want:          image,
+			wantCopied:    []ocispec.Descriptor{config, layer, image, sbom, signature, attConfig, attestation},
+		},
+		{
+			name:          "no subject",
+			referrer:      image,
+			want:          image,
+			wantCopied:    []ocispec.Descriptor{config, layer, image},
+			wantNotCopied: []ocispec.Descriptor{sbom, signature, attestation},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := memory.New()
+			opts := oras.DefaultCopyWithSubjectOptions
+			opts.WithReferrers = tt.withReferrers
+			got, err := oras.CopyWithSubject(ctx, src, dst, tt.referrer, opts)
+			if err != nil {
+				t.Fatalf("CopyWithSubject() error = %v", err)
+			}
+			if !content.Equal(got, tt.want) {
+				t.Errorf("CopyWithSubject() = %v, want %v", got, tt.want)
+			}
+			check := func(descs []ocispec.Descriptor, want bool) {
+				for _, desc := range descs {
+					exists, err := dst.Exists(ctx, desc)
+					if err != nil {
+						t.Fatalf("dst.Exists(%s) error = %v", desc.Digest, err)
+					}
+					if exists != want {
+						t.Errorf("dst.Exists(%s) = %v, want %v", desc.Digest, exists, want)
+					}
+				}
+			}
+			check(tt.wantCopied, true)
+			check(tt.wantNotCopied, false)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"reflect"
+	"sync"
+
+	"oras.land/oras-go/v2/content"
+)
+
+// EmptyJSONTracker tracks the targets where the well-known empty JSON blob
+// described by content.DescriptorEmptyJSON is known to exist, so that the
+// blob is pushed at most once per target, and its existence probe is skipped
+// thereafter.
+// It is intended to be shared by the Pack and the copy calls of
+// referrer-heavy workflows, which push many artifact manifests with the empty
+// config. The zero value is ready for use, and it is safe for concurrent use.
+// Note: the tracker assumes that the empty JSON blob is never deleted from the
+// tracked targets. Targets of non-comparable types are not tracked.
+type EmptyJSONTracker struct {
+	targets sync.Map // map[interface{}]struct{}
+}
+
+// exists returns true if the empty JSON blob is known to exist in target.
+func (t *EmptyJSONTracker) exists(target interface{}) bool {
+	if t == nil || !isComparable(target) {
+		return false
+	}
+	_, ok := t.targets.Load(target)
+	return ok
+}
+
+// add records that the empty JSON blob exists in target.
+func (t *EmptyJSONTracker) add(target interface{}) {
+	if t == nil || !isComparable(target) {
+		return
+	}
+	t.targets.Store(target, struct{}{})
+}
+
+// isComparable returns true if target can be used as a map key.
+func isComparable(target interface{}) bool {
+	return target != nil && reflect.TypeOf(target).Comparable()
+}
+
+// storageOf returns the underlying storage of dst if dst is wrapped for copy.
+func storageOf(dst content.Storage) content.Storage {
+	if cs, ok := dst.(interface{ storage() content.Storage }); ok {
+		return cs.storage()
+	}
+	return dst
+}
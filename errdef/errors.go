@@ -15,7 +15,13 @@ limitations under the License.
 
 package errdef
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
 
 // Common errors used in ORAS
 var (
@@ -27,4 +33,55 @@ var (
 	ErrUnsupportedVersion = errors.New("unsupported version")
 	ErrMissingReference   = errors.New("missing reference")
 	ErrSizeExceedsLimit   = errors.New("size exceeds limit")
+	ErrPreconditionFailed = errors.New("precondition failed")
 )
+
+// NotFoundError is returned when the content identified by a reference or a
+// descriptor is not found.
+// It matches ErrNotFound with errors.Is(), and the missing reference or
+// descriptor can be retrieved with errors.As().
+type NotFoundError struct {
+	// Reference is the reference not found. Empty if the content is
+	// identified by Descriptor.
+	Reference string
+	// Descriptor is the descriptor of the content not found.
+	Descriptor ocispec.Descriptor
+}
+
+// Error returns the error message naming the reference or the descriptor.
+func (e *NotFoundError) Error() string {
+	if e.Reference != "" {
+		return fmt.Sprintf("%s: %v", e.Reference, ErrNotFound)
+	}
+	return fmt.Sprintf("%s: %s: %v", e.Descriptor.Digest, e.Descriptor.MediaType, ErrNotFound)
+}
+
+// Unwrap returns ErrNotFound.
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// UnknownBlobsError is returned when a manifest is rejected for referencing
+// blobs unknown to the destination, e.g. on the BLOB_UNKNOWN error responses
+// of the remote registries.
+// The unknown blobs can be retrieved with errors.As().
+type UnknownBlobsError struct {
+	// Blobs are the digests of the unknown blobs.
+	Blobs []digest.Digest
+	// Err is the underlying error, if any.
+	Err error
+}
+
+// Error returns the error message of the underlying error, or the one naming
+// the unknown blobs.
+func (e *UnknownBlobsError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("blob unknown: %v", e.Blobs)
+}
+
+// Unwrap returns the underlying error.
+func (e *UnknownBlobsError) Unwrap() error {
+	return e.Err
+}
@@ -63,6 +63,7 @@ type ExtendedCopyGraphOptions struct {
 // the given tagged node from the source GraphTarget to the destination Target.
 // The destination reference will be the same as the source reference if the
 // destination reference is left blank.
+// If the destination implements content.Flusher, it is flushed on completion.
 // Returns the descriptor of the tagged node on successful copy.
 func ExtendedCopy(ctx context.Context, src ReadOnlyGraphTarget, srcRef string, dst Target, dstRef string, opts ExtendedCopyOptions) (ocispec.Descriptor, error) {
 	if src == nil {
@@ -74,32 +75,61 @@ func ExtendedCopy(ctx context.Context, src ReadOnlyGraphTarget, srcRef string, d
 	if dstRef == "" {
 		dstRef = srcRef
 	}
+	ctx = withCopyScopeHints(ctx, src, srcRef, dst, dstRef)
 
 	node, err := src.Resolve(ctx, srcRef)
 	if err != nil {
 		return ocispec.Descriptor{}, err
 	}
 
+	// share the converter with the conversion of the node below
+	opts.converter = newManifestConverter(src, opts.CopyGraphOptions)
 	if err := ExtendedCopyGraph(ctx, src, dst, node, opts.ExtendedCopyGraphOptions); err != nil {
 		return ocispec.Descriptor{}, err
 	}
 
+	// tag the converted node if manifests are converted or mapped
+	if opts.converter != nil {
+		node, err = opts.converter.ConvertDescriptor(ctx, node)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
 	if err := dst.Tag(ctx, node, dstRef); err != nil {
 		return ocispec.Descriptor{}, err
 	}
 
+	if err := flush(ctx, dst); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
 	return node, nil
 }
 
 // ExtendedCopyGraph copies the directed acyclic graph (DAG) that are reachable
 // from the given node from the source GraphStorage to the destination Storage.
+// If opts.MountFrom is set and the destination supports mounting, the blobs of
+// the node as well as the ones of its referrers are mounted instead of being
+// copied, while the manifests are still pushed.
+// If the destination implements content.Flusher, it is flushed on completion.
 func ExtendedCopyGraph(ctx context.Context, src content.ReadOnlyGraphStorage, dst content.Storage, node ocispec.Descriptor, opts ExtendedCopyGraphOptions) error {
 	roots, err := findRoots(ctx, src, node, opts)
 	if err != nil {
 		return err
 	}
 
-	// copy the sub-DAGs rooted by the root nodes
+	// copy the sub-DAGs rooted by the root nodes, sharing the quota, the
+	// bandwidth and the converter
+	if opts.quota == nil {
+		opts.quota = newTransferQuota(opts.MaxTotalBytes)
+	}
+	if opts.bandwidth == nil {
+		opts.bandwidth = newBandwidthLimiter(opts.MaxBytesPerSecond)
+	}
+	if opts.converter == nil {
+		opts.converter = newManifestConverter(src, opts.CopyGraphOptions)
+	}
 	for _, root := range roots {
 		if err := CopyGraph(ctx, src, dst, root, opts.CopyGraphOptions); err != nil {
 			return err
@@ -202,15 +232,14 @@ func (opts *ExtendedCopyGraphOptions) FilterAnnotation(key string, regex *regexp
 					docker.MediaTypeManifestList, ocispec.MediaTypeImageIndex,
 					artifactspec.MediaTypeArtifactManifest:
 					if err = func() error {
-						rc, err := src.Fetch(ctx, p)
+						manifestJSON, err := fetchManifest(ctx, src, p)
 						if err != nil {
 							return err
 						}
-						defer rc.Close()
 						var manifest struct {
 							Annotations map[string]string `json:"annotations"`
 						}
-						if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+						if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
 							return err
 						}
 						if manifest.Annotations == nil {
@@ -262,13 +291,12 @@ func (opts *ExtendedCopyGraphOptions) FilterArtifactType(regex *regexp.Regexp) {
 		for _, p := range predecessors {
 			if p.MediaType == artifactspec.MediaTypeArtifactManifest {
 				if err = func() error {
-					rc, err := src.Fetch(ctx, p)
+					manifestJSON, err := fetchManifest(ctx, src, p)
 					if err != nil {
 						return err
 					}
-					defer rc.Close()
 					var manifest artifactspec.Manifest
-					if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+					if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
 						return err
 					}
 					if regex.MatchString(manifest.ArtifactType) {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -27,6 +28,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/opencontainers/go-digest"
@@ -38,6 +41,7 @@ import (
 	"oras.land/oras-go/v2/content/memory"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/docker"
 	"oras.land/oras-go/v2/registry/remote"
 )
 
@@ -132,6 +136,607 @@ func TestExtendedCopy_FullCopy(t *testing.T) {
 	}
 }
 
+func TestExtendedCopy_MapManifest(t *testing.T) {
+	src := memory.New()
+	dst := memory.New()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	generateArtifactManifest := func(subject ocispec.Descriptor, blobs ...ocispec.Descriptor) {
+		var manifest ocispec.Artifact
+		manifest.Subject = &subject
+		manifest.Blobs = append(manifest.Blobs, blobs...)
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeArtifactManifest, manifestJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("bar"))     // Blob 2
+	generateManifest(descs[0], descs[1:3]...)                  // Blob 3
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("sig_1"))   // Blob 4
+	generateArtifactManifest(descs[3], descs[4])               // Blob 5
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("sig_2"))   // Blob 6
+	generateArtifactManifest(descs[5], descs[6])               // Blob 7
+
+	ctx := context.Background()
+	for i := range blobs {
+		err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+
+	manifest := descs[3]
+	ref := "foobar"
+	err := src.Tag(ctx, manifest, ref)
+	if err != nil {
+		t.Fatal("fail to tag root node", err)
+	}
+
+	// inject an annotation into every manifest
+	annotationKey := "org.example.mirrored"
+	opts := oras.ExtendedCopyOptions{}
+	opts.MapManifest = func(desc ocispec.Descriptor, content []byte) ([]byte, error) {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(content, &fields); err != nil {
+			return nil, err
+		}
+		fields["annotations"] = map[string]string{
+			annotationKey: "true",
+		}
+		return json.Marshal(fields)
+	}
+	gotDesc, err := oras.ExtendedCopy(ctx, src, ref, dst, "", opts)
+	if err != nil {
+		t.Fatalf("ExtendedCopy() error = %v, wantErr %v", err, false)
+	}
+	if gotDesc.Digest == manifest.Digest {
+		t.Errorf("ExtendedCopy() = %v, want mapped descriptor", gotDesc)
+	}
+
+	// verify blobs
+	for _, i := range []int{0, 1, 2, 4, 6} {
+		exists, err := dst.Exists(ctx, descs[i])
+		if err != nil {
+			t.Fatalf("dst.Exists(%d) error = %v", i, err)
+		}
+		if !exists {
+			t.Errorf("dst.Exists(%d) = %v, want %v", i, exists, true)
+		}
+	}
+
+	// verify tag
+	tagged, err := dst.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatal("dst.Resolve() error =", err)
+	}
+	if !reflect.DeepEqual(tagged, gotDesc) {
+		t.Errorf("dst.Resolve() = %v, want %v", tagged, gotDesc)
+	}
+
+	// verify the mapped manifests and the updated subjects
+	var subject ocispec.Descriptor
+	node := gotDesc
+	for i := 0; i < 3; i++ {
+		contentBytes, err := content.FetchAll(ctx, dst, node)
+		if err != nil {
+			t.Fatalf("content.FetchAll(%d) error = %v", i, err)
+		}
+		var got struct {
+			Subject     *ocispec.Descriptor `json:"subject,omitempty"`
+			Annotations map[string]string   `json:"annotations,omitempty"`
+		}
+		if err := json.Unmarshal(contentBytes, &got); err != nil {
+			t.Fatalf("json.Unmarshal(%d) error = %v", i, err)
+		}
+		if got.Annotations[annotationKey] != "true" {
+			t.Errorf("manifest %d annotations = %v, want %s", i, got.Annotations, annotationKey)
+		}
+		if i > 0 && (got.Subject == nil || got.Subject.Digest != subject.Digest) {
+			t.Errorf("manifest %d subject = %v, want %v", i, got.Subject, subject.Digest)
+		}
+		if i == 2 {
+			break
+		}
+		predecessors, err := dst.Predecessors(ctx, node)
+		if err != nil {
+			t.Fatalf("dst.Predecessors(%d) error = %v", i, err)
+		}
+		if len(predecessors) != 1 {
+			t.Fatalf("dst.Predecessors(%d) = %v, want 1 referrer", i, predecessors)
+		}
+		subject, node = node, predecessors[0]
+	}
+}
+
+func TestExtendedCopy_MapManifest_NonDeterministic(t *testing.T) {
+	src := memory.New()
+	dst := memory.New()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	generateArtifactManifest := func(subject ocispec.Descriptor, blobs ...ocispec.Descriptor) {
+		var manifest ocispec.Artifact
+		manifest.Subject = &subject
+		manifest.Blobs = append(manifest.Blobs, blobs...)
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeArtifactManifest, manifestJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("bar"))     // Blob 2
+	generateManifest(descs[0], descs[1:3]...)                  // Blob 3
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("sig_1"))   // Blob 4
+	generateArtifactManifest(descs[3], descs[4])               // Blob 5
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("sig_2"))   // Blob 6
+	generateArtifactManifest(descs[5], descs[6])               // Blob 7
+
+	ctx := context.Background()
+	for i := range blobs {
+		err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+
+	manifest := descs[3]
+	ref := "foobar"
+	err := src.Tag(ctx, manifest, ref)
+	if err != nil {
+		t.Fatal("fail to tag root node", err)
+	}
+
+	// inject a different build ID into every mapping
+	annotationKey := "org.example.build-id"
+	var buildID int64
+	opts := oras.ExtendedCopyOptions{}
+	opts.MapManifest = func(desc ocispec.Descriptor, content []byte) ([]byte, error) {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(content, &fields); err != nil {
+			return nil, err
+		}
+		fields["annotations"] = map[string]string{
+			annotationKey: strconv.FormatInt(atomic.AddInt64(&buildID, 1), 10),
+		}
+		return json.Marshal(fields)
+	}
+	gotDesc, err := oras.ExtendedCopy(ctx, src, ref, dst, "", opts)
+	if err != nil {
+		t.Fatalf("ExtendedCopy() error = %v, wantErr %v", err, false)
+	}
+	if got, want := atomic.LoadInt64(&buildID), int64(3); got != want {
+		t.Errorf("MapManifest calls = %v, want %v", got, want)
+	}
+
+	// verify tag
+	tagged, err := dst.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatal("dst.Resolve() error =", err)
+	}
+	if !reflect.DeepEqual(tagged, gotDesc) {
+		t.Errorf("dst.Resolve() = %v, want %v", tagged, gotDesc)
+	}
+
+	// verify the tagged manifest and the subjects of the referrers are pushed
+	node := gotDesc
+	for i := 0; i < 3; i++ {
+		exists, err := dst.Exists(ctx, node)
+		if err != nil {
+			t.Fatalf("dst.Exists(%d) error = %v", i, err)
+		}
+		if !exists {
+			t.Fatalf("dst.Exists(%d) = %v, want %v", i, exists, true)
+		}
+		if i == 2 {
+			break
+		}
+		predecessors, err := dst.Predecessors(ctx, node)
+		if err != nil {
+			t.Fatalf("dst.Predecessors(%d) error = %v", i, err)
+		}
+		if len(predecessors) != 1 {
+			t.Fatalf("dst.Predecessors(%d) = %v, want 1 referrer", i, predecessors)
+		}
+		node = predecessors[0]
+	}
+}
+
+func TestExtendedCopy_RewriteSubject(t *testing.T) {
+	src := memory.New()
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateDockerManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			MediaType: docker.MediaTypeManifest,
+			Config:    config,
+			Layers:    layers,
+		}
+		manifest.SchemaVersion = 2
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(docker.MediaTypeManifest, manifestJSON)
+	}
+	generateArtifactManifest := func(subject ocispec.Descriptor, blobs ...ocispec.Descriptor) {
+		var manifest ocispec.Artifact
+		manifest.MediaType = ocispec.MediaTypeArtifactManifest
+		manifest.Subject = &subject
+		manifest.Blobs = append(manifest.Blobs, blobs...)
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeArtifactManifest, manifestJSON)
+	}
+
+	appendBlob(docker.MediaTypeConfig, []byte("config"))   // Blob 0
+	appendBlob(docker.MediaTypeLayer, []byte("foo"))       // Blob 1
+	generateDockerManifest(descs[0], descs[1])             // Blob 2
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("sig")) // Blob 3
+	generateArtifactManifest(descs[2], descs[3])           // Blob 4
+
+	ctx := context.Background()
+	for i := range blobs {
+		err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+	ref := "foobar"
+	if err := src.Tag(ctx, descs[2], ref); err != nil {
+		t.Fatal("fail to tag root node", err)
+	}
+
+	for _, rewrite := range []bool{false, true} {
+		dst := memory.New()
+		opts := oras.ExtendedCopyOptions{}
+		opts.ConvertManifest = true
+		opts.RewriteSubject = rewrite
+		gotDesc, err := oras.ExtendedCopy(ctx, src, ref, dst, "", opts)
+		if err != nil {
+			t.Fatalf("ExtendedCopy() error = %v, wantErr %v", err, false)
+		}
+		if gotDesc.MediaType != ocispec.MediaTypeImageManifest || gotDesc.Digest == descs[2].Digest {
+			t.Errorf("ExtendedCopy() = %v, want converted descriptor", gotDesc)
+		}
+
+		// the referrer is kept as is without rewriting
+		exists, err := dst.Exists(ctx, descs[4])
+		if err != nil {
+			t.Fatal("dst.Exists() error =", err)
+		}
+		if exists == rewrite {
+			t.Errorf("dst.Exists(referrer) = %v, want %v (rewrite: %v)", exists, !rewrite, rewrite)
+		}
+
+		predecessors, err := dst.Predecessors(ctx, gotDesc)
+		if err != nil {
+			t.Fatal("dst.Predecessors() error =", err)
+		}
+		if !rewrite {
+			if len(predecessors) != 0 {
+				t.Errorf("dst.Predecessors() = %v, want none", predecessors)
+			}
+			continue
+		}
+		if len(predecessors) != 1 {
+			t.Fatalf("dst.Predecessors() = %v, want 1 referrer", predecessors)
+		}
+		contentBytes, err := content.FetchAll(ctx, dst, predecessors[0])
+		if err != nil {
+			t.Fatal("content.FetchAll() error =", err)
+		}
+		var got ocispec.Artifact
+		if err := json.Unmarshal(contentBytes, &got); err != nil {
+			t.Fatal("json.Unmarshal() error =", err)
+		}
+		if got.Subject == nil || !reflect.DeepEqual(*got.Subject, gotDesc) {
+			t.Errorf("referrer subject = %v, want %v", got.Subject, gotDesc)
+		}
+		if !reflect.DeepEqual(got.Blobs, descs[3:4]) {
+			t.Errorf("referrer blobs = %v, want %v", got.Blobs, descs[3:4])
+		}
+	}
+}
+
+// fetchRecordingTarget records the fetched content of the underlying store.
+type fetchRecordingTarget struct {
+	*memory.Store
+	lock    sync.Mutex
+	fetched map[digest.Digest]int
+}
+
+func (t *fetchRecordingTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	t.lock.Lock()
+	t.fetched[target.Digest]++
+	t.lock.Unlock()
+	return t.Store.Fetch(ctx, target)
+}
+
+func TestExtendedCopy_MountFrom(t *testing.T) {
+	src := &fetchRecordingTarget{
+		Store:   memory.New(),
+		fetched: map[digest.Digest]int{},
+	}
+
+	// generate test content
+	var blobs [][]byte
+	var descs []ocispec.Descriptor
+	appendBlob := func(mediaType string, blob []byte) {
+		blobs = append(blobs, blob)
+		descs = append(descs, ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		})
+	}
+	generateManifest := func(config ocispec.Descriptor, layers ...ocispec.Descriptor) {
+		manifest := ocispec.Manifest{
+			Config: config,
+			Layers: layers,
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	generateArtifactManifest := func(subject ocispec.Descriptor, blobs ...ocispec.Descriptor) {
+		var manifest ocispec.Artifact
+		manifest.Subject = &subject
+		manifest.Blobs = append(manifest.Blobs, blobs...)
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBlob(ocispec.MediaTypeArtifactManifest, manifestJSON)
+	}
+
+	appendBlob(ocispec.MediaTypeImageConfig, []byte("config")) // Blob 0
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("foo"))     // Blob 1
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("bar"))     // Blob 2
+	generateManifest(descs[0], descs[1:3]...)                  // Blob 3
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("sig_1"))   // Blob 4
+	generateArtifactManifest(descs[3], descs[4])               // Blob 5
+	appendBlob(ocispec.MediaTypeImageLayer, []byte("sig_2"))   // Blob 6
+	generateArtifactManifest(descs[3], descs[6])               // Blob 7
+
+	ctx := context.Background()
+	for i := range blobs {
+		err := src.Push(ctx, descs[i], bytes.NewReader(blobs[i]))
+		if err != nil {
+			t.Fatalf("failed to push test content to src: %d: %v", i, err)
+		}
+	}
+	manifest := descs[3]
+	ref := "foobar"
+	if err := src.Tag(ctx, manifest, ref); err != nil {
+		t.Fatal("fail to tag root node", err)
+	}
+
+	// the source repository in the registry holds all the blobs except
+	// blob 6, which is uploaded instead.
+	sourceBlobs := map[digest.Digest][]byte{}
+	for _, i := range []int{0, 1, 2, 4} {
+		sourceBlobs[descs[i].Digest] = blobs[i]
+	}
+
+	// set up a fake registry recording the mounts and the uploads
+	var lock sync.Mutex
+	targetBlobs := map[digest.Digest][]byte{}
+	targetManifests := map[string][]byte{}
+	targetMediaTypes := map[string]string{}
+	var mounted []digest.Digest
+	var uploadedBytes int
+	uuid := "4fd53bc9-565d-4527-ab80-3e051ac4880c"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		p := r.URL.Path
+		switch {
+		case r.Method == http.MethodPost && p == "/v2/target/blobs/uploads/":
+			q := r.URL.Query()
+			if from := q.Get("from"); from != "" {
+				if from != "source" {
+					t.Errorf("unexpected mount source: %s", from)
+				}
+				dgst := digest.Digest(q.Get("mount"))
+				if blob, ok := sourceBlobs[dgst]; ok {
+					targetBlobs[dgst] = blob
+					mounted = append(mounted, dgst)
+					w.Header().Set("Docker-Content-Digest", dgst.String())
+					w.WriteHeader(http.StatusCreated)
+					return
+				}
+			}
+			w.Header().Set("Location", "/v2/target/blobs/uploads/"+uuid)
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && p == "/v2/target/blobs/uploads/"+uuid:
+			blob, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("failed to read blob: %v", err)
+			}
+			dgst := digest.Digest(r.URL.Query().Get("digest"))
+			targetBlobs[dgst] = blob
+			uploadedBytes += len(blob)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodHead && strings.HasPrefix(p, "/v2/target/blobs/"):
+			blob, ok := targetBlobs[digest.Digest(strings.TrimPrefix(p, "/v2/target/blobs/"))]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(blob)))
+		case r.Method == http.MethodPut && strings.HasPrefix(p, "/v2/target/manifests/"):
+			manifest, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("failed to read manifest: %v", err)
+			}
+			reference := strings.TrimPrefix(p, "/v2/target/manifests/")
+			dgst := digest.FromBytes(manifest).String()
+			for _, key := range []string{reference, dgst} {
+				targetManifests[key] = manifest
+				targetMediaTypes[key] = r.Header.Get("Content-Type")
+			}
+			w.Header().Set("Docker-Content-Digest", dgst)
+			w.WriteHeader(http.StatusCreated)
+		case (r.Method == http.MethodHead || r.Method == http.MethodGet) && strings.HasPrefix(p, "/v2/target/manifests/"):
+			reference := strings.TrimPrefix(p, "/v2/target/manifests/")
+			manifest, ok := targetManifests[reference]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", targetMediaTypes[reference])
+			w.Header().Set("Docker-Content-Digest", digest.FromBytes(manifest).String())
+			w.Header().Set("Content-Length", strconv.Itoa(len(manifest)))
+			if r.Method == http.MethodGet {
+				w.Write(manifest)
+			}
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+	dst, err := remote.NewRepository(uri.Host + "/target")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	dst.PlainHTTP = true
+
+	// test extended copy with mounting
+	var onMounted int64
+	opts := oras.ExtendedCopyOptions{}
+	opts.MountFrom = func(ctx context.Context, desc ocispec.Descriptor) ([]string, error) {
+		return []string{"source"}, nil
+	}
+	opts.OnMounted = func(ctx context.Context, desc ocispec.Descriptor) error {
+		atomic.AddInt64(&onMounted, 1)
+		return nil
+	}
+	gotDesc, err := oras.ExtendedCopy(ctx, src, ref, dst, "", opts)
+	if err != nil {
+		t.Fatalf("ExtendedCopy() error = %v, wantErr %v", err, false)
+	}
+	if !reflect.DeepEqual(gotDesc, manifest) {
+		t.Errorf("ExtendedCopy() = %v, want %v", gotDesc, manifest)
+	}
+
+	// verify the copied content
+	for i, desc := range descs {
+		var got []byte
+		var ok bool
+		switch desc.MediaType {
+		case ocispec.MediaTypeImageManifest, ocispec.MediaTypeArtifactManifest:
+			got, ok = targetManifests[desc.Digest.String()]
+		default:
+			got, ok = targetBlobs[desc.Digest]
+		}
+		if !ok {
+			t.Errorf("content[%d] not copied", i)
+			continue
+		}
+		if !bytes.Equal(got, blobs[i]) {
+			t.Errorf("content[%d] = %v, want %v", i, got, blobs[i])
+		}
+	}
+	if _, ok := targetManifests[ref]; !ok {
+		t.Errorf("tag %q not copied", ref)
+	}
+
+	// the blobs of both the image and the referrers are mounted, and only
+	// the blob absent in the source repository is uploaded.
+	wantMounted := []digest.Digest{descs[0].Digest, descs[1].Digest, descs[2].Digest, descs[4].Digest}
+	if len(mounted) != len(wantMounted) {
+		t.Errorf("mounted = %v, want %v", mounted, wantMounted)
+	}
+	for _, dgst := range wantMounted {
+		if src.fetched[dgst] != 0 {
+			t.Errorf("mounted blob %s fetched %d times from src", dgst, src.fetched[dgst])
+		}
+	}
+	if got, want := onMounted, int64(len(wantMounted)); got != want {
+		t.Errorf("OnMounted count = %v, want %v", got, want)
+	}
+	if src.fetched[descs[6].Digest] != 1 {
+		t.Errorf("uploaded blob fetched %d times from src, want 1", src.fetched[descs[6].Digest])
+	}
+
+	// measure the bandwidth saved by mounting
+	var totalBlobBytes int
+	for _, i := range []int{0, 1, 2, 4, 6} {
+		totalBlobBytes += len(blobs[i])
+	}
+	if want := len(blobs[6]); uploadedBytes != want {
+		t.Errorf("uploaded %d bytes of blobs, want %d", uploadedBytes, want)
+	}
+	t.Logf("uploaded %d of %d bytes of blobs by mounting", uploadedBytes, totalBlobBytes)
+}
+
 func TestExtendedCopyGraph_FullCopy(t *testing.T) {
 	// generate test content
 	var blobs [][]byte
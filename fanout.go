@@ -0,0 +1,295 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// errAllDestinationsFailed is returned by fanOutStorage when no destination
+// is left to be written.
+var errAllDestinationsFailed = errors.New("all destinations failed")
+
+// FanOutError is returned by CopyGraphToAll when some of the destinations
+// failed. The destinations not listed are copied successfully.
+type FanOutError struct {
+	// Errors maps the indices of the failed destinations to the causes of
+	// the failures.
+	Errors map[int]error
+}
+
+// Error returns the error message listing all the failures.
+func (e *FanOutError) Error() string {
+	indices := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	messages := make([]string, 0, len(indices))
+	for _, i := range indices {
+		messages = append(messages, fmt.Sprintf("destination %d: %v", i, e.Errors[i]))
+	}
+	return fmt.Sprintf("failed to copy to %d destination(s): %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Is returns true if any of the failures matches target.
+func (e *FanOutError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// CopyGraphToAll copies a rooted directed acyclic graph (DAG) from the source
+// CAS to all the destination CASs in a single traversal, where each node is
+// fetched from the source once and written to the destinations lacking it.
+// A destination failing to be written is excluded from the rest of the copy,
+// without aborting the copy to the other destinations. In that case, a
+// *FanOutError reporting the failed destinations is returned.
+// opts.Concurrency limits the number of nodes copied concurrently, where each
+// node is written to the destinations concurrently.
+// The destinations implementing content.Flusher are flushed on completion.
+func CopyGraphToAll(ctx context.Context, src content.ReadOnlyStorage, dsts []content.Storage, root ocispec.Descriptor, opts CopyGraphOptions) error {
+	if len(dsts) == 0 {
+		return errors.New("no destination")
+	}
+	fanOut := newFanOutStorage(dsts)
+	if err := CopyGraph(ctx, src, fanOut, root, opts); err != nil && !errors.Is(err, errAllDestinationsFailed) {
+		return err
+	}
+	if errs := fanOut.errors(); len(errs) > 0 {
+		return &FanOutError{Errors: errs}
+	}
+	return nil
+}
+
+// fanOutStorage writes the content to multiple destinations, and tracks the
+// failed destinations.
+type fanOutStorage struct {
+	dsts []content.Storage
+	// present maps the descriptors to the destinations where the content
+	// exists, as known by Exists.
+	present sync.Map // map[descriptor.Descriptor][]bool
+
+	lock   sync.Mutex
+	failed map[int]error
+}
+
+// newFanOutStorage creates a new fanOutStorage writing to dsts.
+func newFanOutStorage(dsts []content.Storage) *fanOutStorage {
+	return &fanOutStorage{
+		dsts:   dsts,
+		failed: make(map[int]error),
+	}
+}
+
+// fail records the failure of the destination i.
+func (s *fanOutStorage) fail(i int, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.failed[i]; !ok {
+		s.failed[i] = err
+	}
+}
+
+// healthy returns the indices of the destinations not failed.
+func (s *fanOutStorage) healthy() []int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var indices []int
+	for i := range s.dsts {
+		if _, ok := s.failed[i]; !ok {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// errors returns the failures of the destinations.
+func (s *fanOutStorage) errors() map[int]error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.failed) == 0 {
+		return nil
+	}
+	errs := make(map[int]error, len(s.failed))
+	for i, err := range s.failed {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Fetch fetches the content identified by the descriptor from the first
+// healthy destination.
+func (s *fanOutStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	indices := s.healthy()
+	if len(indices) == 0 {
+		return nil, errAllDestinationsFailed
+	}
+	return s.dsts[indices[0]].Fetch(ctx, target)
+}
+
+// Exists returns true if the described content exists in all the healthy
+// destinations.
+func (s *fanOutStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	indices := s.healthy()
+	if len(indices) == 0 {
+		return false, errAllDestinationsFailed
+	}
+	present := make([]bool, len(s.dsts))
+	var wg sync.WaitGroup
+	for _, i := range indices {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			exists, err := s.dsts[i].Exists(ctx, target)
+			if err != nil {
+				s.fail(i, err)
+				return
+			}
+			present[i] = exists
+		}(i)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	s.present.Store(descriptor.FromOCI(target), present)
+
+	indices = s.healthy()
+	if len(indices) == 0 {
+		return false, errAllDestinationsFailed
+	}
+	for _, i := range indices {
+		if !present[i] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Push reads the content once, and pushes it to the healthy destinations
+// where the content does not exist.
+// The destinations failed to be written are excluded from then on. Push
+// fails only if the content cannot be read or no destination is left.
+func (s *fanOutStorage) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	var present []bool
+	if value, ok := s.present.Load(descriptor.FromOCI(expected)); ok {
+		present = value.([]bool)
+	}
+	var indices []int
+	for _, i := range s.healthy() {
+		if present == nil || !present[i] {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) == 0 {
+		if len(s.healthy()) == 0 {
+			return errAllDestinationsFailed
+		}
+		return nil
+	}
+
+	// write the content to the destinations via pipes
+	writers := make([]*io.PipeWriter, len(indices))
+	errs := make([]error, len(indices))
+	var wg sync.WaitGroup
+	for j, i := range indices {
+		pr, pw := io.Pipe()
+		writers[j] = pw
+		wg.Add(1)
+		go func(j, i int) {
+			defer wg.Done()
+			err := s.dsts[i].Push(ctx, expected, pr)
+			if err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+				errs[j] = err
+				pr.CloseWithError(err)
+				return
+			}
+			// stop receiving the content not read by the destination
+			pr.Close()
+		}(j, i)
+	}
+	err := fanOutCopy(writers, r)
+	for _, pw := range writers {
+		pw.CloseWithError(err)
+	}
+	wg.Wait()
+	if err != nil {
+		// the destinations are not to blame for the read failure
+		return err
+	}
+	for j, err := range errs {
+		if err != nil {
+			s.fail(indices[j], err)
+		}
+	}
+	if len(s.healthy()) == 0 {
+		return errAllDestinationsFailed
+	}
+	return nil
+}
+
+// Flush flushes the healthy destinations implementing content.Flusher.
+func (s *fanOutStorage) Flush(ctx context.Context) error {
+	for _, i := range s.healthy() {
+		if err := flush(ctx, s.dsts[i]); err != nil {
+			s.fail(i, err)
+		}
+	}
+	return nil
+}
+
+// fanOutCopy copies from r to all the writers, skipping the writers failed to
+// be written. Returns the read error other than io.EOF.
+func fanOutCopy(writers []*io.PipeWriter, r io.Reader) error {
+	active := make([]bool, len(writers))
+	for j := range active {
+		active[j] = true
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			for j, w := range writers {
+				if active[j] {
+					if _, werr := w.Write(buf[:n]); werr != nil {
+						active[j] = false
+					}
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+// isForeignLayer checks if the descriptor describes a nondistributable layer.
+func isForeignLayer(desc ocispec.Descriptor) bool {
+	switch desc.MediaType {
+	case docker.MediaTypeForeignLayer,
+		ocispec.MediaTypeImageLayerNonDistributable,
+		ocispec.MediaTypeImageLayerNonDistributableGzip,
+		ocispec.MediaTypeImageLayerNonDistributableZstd:
+		return true
+	}
+	return false
+}
+
+// skipForeignLayers wraps findSuccessors so that the nondistributable layers
+// are not returned.
+func skipForeignLayers(findSuccessors func(context.Context, content.Fetcher, ocispec.Descriptor) ([]ocispec.Descriptor, error)) func(context.Context, content.Fetcher, ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	return func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		successors, err := findSuccessors(ctx, fetcher, desc)
+		if err != nil {
+			return nil, err
+		}
+		var distributable []ocispec.Descriptor
+		for _, successor := range successors {
+			if !isForeignLayer(successor) {
+				distributable = append(distributable, successor)
+			}
+		}
+		return distributable, nil
+	}
+}
+
+// foreignLayerStorage is a read-only storage fetching the foreign layers from
+// the URLs of their descriptors.
+type foreignLayerStorage struct {
+	content.ReadOnlyStorage
+}
+
+// Fetch fetches the content identified by the descriptor.
+// Foreign layers are downloaded from the URLs of the descriptor, tried in
+// order, and other content is fetched from the underlying storage.
+func (s *foreignLayerStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if !isForeignLayer(target) || len(target.URLs) == 0 {
+		return s.ReadOnlyStorage.Fetch(ctx, target)
+	}
+	var err error
+	for _, url := range target.URLs {
+		var rc io.ReadCloser
+		if rc, err = fetchURL(ctx, url); err == nil {
+			return rc, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %s: failed to fetch foreign layer: %w", target.Digest, target.MediaType, err)
+}
+
+// fetchURL downloads the content at url.
+func fetchURL(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %q: %w", req.Method, url, errdef.ErrNotFound)
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %q: unexpected status code %d", req.Method, url, resp.StatusCode)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// GraphSize walks the directed acyclic graph (DAG) rooted at root, and
+// returns the total size and the number of the unique nodes in the graph,
+// including the root, the manifests, and the blobs, e.g. to report the size
+// of a copy before it starts.
+// The nodes are deduplicated by digest, so that the blobs shared by multiple
+// manifests, such as the common layers of the images in an index, are
+// counted once. Only the manifests are fetched from the fetcher.
+func GraphSize(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor) (int64, int, error) {
+	var size int64
+	visited := make(map[digest.Digest]bool)
+	stack := []ocispec.Descriptor{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[node.Digest] {
+			continue
+		}
+		visited[node.Digest] = true
+		size += node.Size
+
+		successors, err := content.Successors(ctx, fetcher, node)
+		if err != nil {
+			return 0, 0, err
+		}
+		stack = append(stack, successors...)
+	}
+	return size, len(visited), nil
+}
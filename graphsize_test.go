@@ -0,0 +1,112 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestGraphSize(t *testing.T) {
+	storage := memory.New()
+	ctx := context.Background()
+
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := storage.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	pushJSON := func(mediaType string, v interface{}) ocispec.Descriptor {
+		blob, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return push(mediaType, blob)
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("config"))
+	base := push(ocispec.MediaTypeImageLayer, []byte("base layer"))
+	amd64 := push(ocispec.MediaTypeImageLayer, []byte("amd64 layer"))
+	arm64 := push(ocispec.MediaTypeImageLayer, []byte("arm64 layer"))
+	// the manifests share the config and the base layer
+	manifestAMD64 := pushJSON(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{base, amd64},
+	})
+	manifestARM64 := pushJSON(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{base, arm64},
+	})
+	index := pushJSON(ocispec.MediaTypeImageIndex, ocispec.Index{
+		Manifests: []ocispec.Descriptor{manifestAMD64, manifestARM64},
+	})
+
+	tests := []struct {
+		name      string
+		root      ocispec.Descriptor
+		wantSize  int64
+		wantCount int
+	}{
+		{
+			name:      "index",
+			root:      index,
+			wantSize:  index.Size + manifestAMD64.Size + manifestARM64.Size + config.Size + base.Size + amd64.Size + arm64.Size,
+			wantCount: 7,
+		},
+		{
+			name:      "manifest",
+			root:      manifestAMD64,
+			wantSize:  manifestAMD64.Size + config.Size + base.Size + amd64.Size,
+			wantCount: 4,
+		},
+		{
+			name:      "blob",
+			root:      base,
+			wantSize:  base.Size,
+			wantCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, count, err := oras.GraphSize(ctx, storage, tt.root)
+			if err != nil {
+				t.Fatal("GraphSize() error =", err)
+			}
+			if size != tt.wantSize {
+				t.Errorf("GraphSize() size = %v, want %v", size, tt.wantSize)
+			}
+			if count != tt.wantCount {
+				t.Errorf("GraphSize() count = %v, want %v", count, tt.wantCount)
+			}
+		})
+	}
+
+	// test missing manifests
+	missing := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte(`{"layers":[]}`))
+	if _, _, err := oras.GraphSize(ctx, storage, missing); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("GraphSize() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/spec"
+)
+
+// ManifestInfo describes the content of a manifest, as returned by
+// InspectManifest.
+// Config and Layers are set for image manifests and artifact manifests, where
+// Config is nil for artifact manifests. Index is set for indexes and docker
+// manifest lists only.
+type ManifestInfo struct {
+	// Descriptor is the descriptor of the manifest.
+	Descriptor ocispec.Descriptor
+	// ArtifactType is the artifact type declared by the manifest, if any.
+	ArtifactType string
+	// Config is the config of the image manifest.
+	Config *ocispec.Descriptor
+	// Layers is the ordered list of the layers of the image manifest, or of
+	// the blobs of the artifact manifest.
+	Layers []ocispec.Descriptor
+	// Subject is the manifest referenced by the manifest, if any.
+	Subject *ocispec.Descriptor
+	// Annotations is the annotations of the manifest.
+	Annotations map[string]string
+	// Index describes the index, and is nil if the manifest is not an index.
+	Index *IndexInfo
+}
+
+// IndexInfo describes the content of an index or a docker manifest list.
+type IndexInfo struct {
+	// Manifests is the ordered list of the child manifests, along with their
+	// platforms if specified.
+	Manifests []ocispec.Descriptor
+}
+
+// InspectManifest fetches the manifest identified by the reference from the
+// target, and returns its content in a structured form.
+// Docker and OCI image manifests, OCI and ORAS artifact manifests, OCI image
+// indexes and docker manifest lists are supported. Returns
+// errdef.ErrUnsupported for other media types.
+func InspectManifest(ctx context.Context, target ReadOnlyTarget, reference string) (ManifestInfo, error) {
+	desc, manifestBytes, err := FetchBytes(ctx, target, reference, DefaultFetchBytesOptions)
+	if err != nil {
+		return ManifestInfo{}, err
+	}
+	info := ManifestInfo{
+		Descriptor: desc,
+	}
+	switch desc.MediaType {
+	case docker.MediaTypeManifest, ocispec.MediaTypeImageManifest:
+		var manifest spec.Manifest
+		if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+			return ManifestInfo{}, fmt.Errorf("%s: %s: failed to decode manifest: %w", desc.Digest, desc.MediaType, err)
+		}
+		info.ArtifactType = manifest.ArtifactType
+		info.Config = &manifest.Config
+		info.Layers = manifest.Layers
+		info.Subject = manifest.Subject
+		info.Annotations = manifest.Annotations
+	case docker.MediaTypeManifestList, ocispec.MediaTypeImageIndex:
+		var index spec.Index
+		if err := json.Unmarshal(manifestBytes, &index); err != nil {
+			return ManifestInfo{}, fmt.Errorf("%s: %s: failed to decode index: %w", desc.Digest, desc.MediaType, err)
+		}
+		info.ArtifactType = index.ArtifactType
+		info.Subject = index.Subject
+		info.Annotations = index.Annotations
+		info.Index = &IndexInfo{
+			Manifests: index.Manifests,
+		}
+	case ocispec.MediaTypeArtifactManifest:
+		var manifest ocispec.Artifact
+		if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+			return ManifestInfo{}, fmt.Errorf("%s: %s: failed to decode manifest: %w", desc.Digest, desc.MediaType, err)
+		}
+		info.ArtifactType = manifest.ArtifactType
+		info.Layers = manifest.Blobs
+		info.Subject = manifest.Subject
+		info.Annotations = manifest.Annotations
+	case artifactspec.MediaTypeArtifactManifest:
+		var manifest artifactspec.Manifest
+		if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+			return ManifestInfo{}, fmt.Errorf("%s: %s: failed to decode manifest: %w", desc.Digest, desc.MediaType, err)
+		}
+		info.ArtifactType = manifest.ArtifactType
+		for _, blob := range manifest.Blobs {
+			info.Layers = append(info.Layers, descriptor.ArtifactToOCI(blob))
+		}
+		if manifest.Subject != nil {
+			subject := descriptor.ArtifactToOCI(*manifest.Subject)
+			info.Subject = &subject
+		}
+		info.Annotations = manifest.Annotations
+	default:
+		return ManifestInfo{}, fmt.Errorf("%s: %s: not a manifest or an index: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+	return info, nil
+}
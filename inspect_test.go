@@ -0,0 +1,171 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/spec"
+)
+
+func TestInspectManifest(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	push := func(mediaType string, blob []byte, tag string) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		if tag != "" {
+			if err := s.Tag(ctx, desc, tag); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return desc
+	}
+	marshal := func(v interface{}) []byte {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	configDesc := push(ocispec.MediaTypeImageConfig, []byte("config"), "")
+	fooDesc := push("application/vnd.test.foo", []byte("foo"), "")
+	barDesc := push("application/vnd.test.bar", []byte("bar"), "")
+	imageDesc := push(ocispec.MediaTypeImageManifest, marshal(spec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    []ocispec.Descriptor{fooDesc, barDesc},
+	}), "image")
+	imageDesc.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	indexDesc := push(ocispec.MediaTypeImageIndex, marshal(ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{imageDesc},
+	}), "index")
+	annotations := map[string]string{"foo": "bar"}
+	signatureDesc := push(ocispec.MediaTypeImageManifest, marshal(spec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.test.signature",
+		Config:       configDesc,
+		Layers:       []ocispec.Descriptor{fooDesc},
+		Subject:      &indexDesc,
+		Annotations:  annotations,
+	}), "signature")
+	artifactDesc := push(ocispec.MediaTypeArtifactManifest, marshal(ocispec.Artifact{
+		MediaType:    ocispec.MediaTypeArtifactManifest,
+		ArtifactType: "application/vnd.test.sbom",
+		Blobs:        []ocispec.Descriptor{barDesc},
+		Subject:      &imageDesc,
+	}), "artifact")
+	imageDesc.Platform = nil
+
+	tests := []struct {
+		name      string
+		reference string
+		want      oras.ManifestInfo
+	}{
+		{
+			name:      "image manifest",
+			reference: "image",
+			want: oras.ManifestInfo{
+				Descriptor: imageDesc,
+				Config:     &configDesc,
+				Layers:     []ocispec.Descriptor{fooDesc, barDesc},
+			},
+		},
+		{
+			name:      "image manifest with subject",
+			reference: "signature",
+			want: oras.ManifestInfo{
+				Descriptor:   signatureDesc,
+				ArtifactType: "application/vnd.test.signature",
+				Config:       &configDesc,
+				Layers:       []ocispec.Descriptor{fooDesc},
+				Subject:      &indexDesc,
+				Annotations:  annotations,
+			},
+		},
+		{
+			name:      "artifact manifest",
+			reference: "artifact",
+			want: oras.ManifestInfo{
+				Descriptor:   artifactDesc,
+				ArtifactType: "application/vnd.test.sbom",
+				Layers:       []ocispec.Descriptor{barDesc},
+				Subject: &ocispec.Descriptor{
+					MediaType: imageDesc.MediaType,
+					Digest:    imageDesc.Digest,
+					Size:      imageDesc.Size,
+					Platform:  &ocispec.Platform{OS: "linux", Architecture: "amd64"},
+				},
+			},
+		},
+		{
+			name:      "index",
+			reference: "index",
+			want: oras.ManifestInfo{
+				Descriptor: indexDesc,
+				Index: &oras.IndexInfo{
+					Manifests: []ocispec.Descriptor{{
+						MediaType: imageDesc.MediaType,
+						Digest:    imageDesc.Digest,
+						Size:      imageDesc.Size,
+						Platform:  &ocispec.Platform{OS: "linux", Architecture: "amd64"},
+					}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := oras.InspectManifest(ctx, s, tt.reference)
+			if err != nil {
+				t.Fatalf("InspectManifest() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InspectManifest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInspectManifest_Unsupported(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	blob := []byte("config")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, blob)
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Tag(ctx, desc, "config"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := oras.InspectManifest(ctx, s, "config"); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("InspectManifest() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
@@ -43,7 +43,7 @@ func (m *Memory) Fetch(_ context.Context, target ocispec.Descriptor) (io.ReadClo
 	key := descriptor.FromOCI(target)
 	content, exists := m.content.Load(key)
 	if !exists {
-		return nil, fmt.Errorf("%s: %s: %w", key.Digest, key.MediaType, errdef.ErrNotFound)
+		return nil, &errdef.NotFoundError{Descriptor: target}
 	}
 	return io.NopCloser(bytes.NewReader(content.([]byte))), nil
 }
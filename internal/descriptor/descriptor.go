@@ -19,6 +19,7 @@ import (
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
+	"oras.land/oras-go/v2/internal/docker"
 )
 
 // Descriptor contains the minimun information to describe the disposition of
@@ -47,6 +48,18 @@ func FromOCI(desc ocispec.Descriptor) Descriptor {
 	}
 }
 
+// IsManifest checks if the descriptor describes a manifest or an index, of
+// which the successors are resolved by content.Successors.
+func IsManifest(desc ocispec.Descriptor) bool {
+	switch desc.MediaType {
+	case docker.MediaTypeManifest, docker.MediaTypeManifestList,
+		ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageIndex,
+		ocispec.MediaTypeArtifactManifest, artifactspec.MediaTypeArtifactManifest:
+		return true
+	}
+	return false
+}
+
 // ArtifactToOCI converts artifact descriptor to OCI descriptor.
 func ArtifactToOCI(desc artifactspec.Descriptor) ocispec.Descriptor {
 	return ocispec.Descriptor{
@@ -0,0 +1,123 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tarfs provides a read-only file system backed by a tar archive.
+package tarfs
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+)
+
+// entry is a regular file in the tar archive.
+type entry struct {
+	header *tar.Header
+	pos    int64 // the offset of the file content in the tar archive
+}
+
+// TarFS represents a read-only file system backed by a tar archive.
+// Only regular files are accessible. The file content is served directly from
+// the archive via random access without extraction.
+type TarFS struct {
+	r       io.ReaderAt
+	entries map[string]*entry
+}
+
+// New indexes the entries of the tar archive of the given size read from r,
+// and returns a file system serving the regular files in the archive.
+func New(r io.ReaderAt, size int64) (*TarFS, error) {
+	sr := io.NewSectionReader(r, 0, size)
+	tr := tar.NewReader(sr)
+	entries := make(map[string]*entry)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to read tar archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		// the reader is positioned at the start of the file content after
+		// reading the header.
+		pos, err := sr.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		entries[path.Clean(header.Name)] = &entry{
+			header: header,
+			pos:    pos,
+		}
+	}
+	return &TarFS{
+		r:       r,
+		entries: entries,
+	}, nil
+}
+
+// Open opens the named file.
+func (tfs *TarFS) Open(name string) (fs.File, error) {
+	e, err := tfs.getEntry("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return &file{
+		SectionReader: io.NewSectionReader(tfs.r, e.pos, e.header.Size),
+		info:          e.header.FileInfo(),
+	}, nil
+}
+
+// Stat returns a fs.FileInfo describing the named file.
+func (tfs *TarFS) Stat(name string) (fs.FileInfo, error) {
+	e, err := tfs.getEntry("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return e.header.FileInfo(), nil
+}
+
+// getEntry returns the entry of the named file.
+func (tfs *TarFS) getEntry(op, name string) (*entry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	e, ok := tfs.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return e, nil
+}
+
+// file is a regular file in the tar archive.
+type file struct {
+	*io.SectionReader
+	info fs.FileInfo
+}
+
+// Stat returns a fs.FileInfo describing the file.
+func (f *file) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+// Close closes the file. It is a no-op since the underlying archive is owned
+// by the caller.
+func (f *file) Close() error {
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tarfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+)
+
+func TestTarFS(t *testing.T) {
+	files := map[string][]byte{
+		"foo":         []byte("hello world"),
+		"dir/bar":     []byte("goodbye world"),
+		"dir/sub/baz": {},
+	}
+	buf := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buf)
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     "dir/",
+		Mode:     0755,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// entry names are cleaned on indexing
+	for name, path := range map[string]string{
+		"foo":         "foo",
+		"./dir/bar":   "dir/bar",
+		"dir/sub/baz": "dir/sub/baz",
+	} {
+		content := files[path]
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     "link",
+		Linkname: "foo",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tfs, err := New(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for name, want := range files {
+		f, err := tfs.Open(name)
+		if err != nil {
+			t.Fatalf("TarFS.Open(%s) error = %v", name, err)
+		}
+		got, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if err := f.Close(); err != nil {
+			t.Errorf("failed to close %s: %v", name, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("TarFS.Open(%s) = %v, want %v", name, got, want)
+		}
+
+		info, err := tfs.Stat(name)
+		if err != nil {
+			t.Fatalf("TarFS.Stat(%s) error = %v", name, err)
+		}
+		if info.Size() != int64(len(want)) {
+			t.Errorf("TarFS.Stat(%s).Size() = %v, want %v", name, info.Size(), len(want))
+		}
+	}
+
+	// test non-regular files and invalid paths
+	for _, name := range []string{"dir", "link", "unknown"} {
+		if _, err := tfs.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("TarFS.Open(%s) error = %v, wantErr %v", name, err, fs.ErrNotExist)
+		}
+	}
+	if _, err := tfs.Open("/foo"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("TarFS.Open() error = %v, wantErr %v", err, fs.ErrInvalid)
+	}
+}
+
+func TestTarFS_BadArchive(t *testing.T) {
+	content := []byte("not a tar archive")
+	if _, err := New(bytes.NewReader(content), int64(len(content))); err == nil {
+		t.Errorf("New() error = %v, wantErr %v", err, true)
+	}
+}
@@ -98,6 +98,47 @@ func Dispatch(ctx context.Context, preHandler, postHandler Handler, limiter *sem
 	return eg.Wait()
 }
 
+// Walk traverses a graph sequentially in a depth-first order, visiting the
+// successors in the order returned by preHandler. Therefore, the traversal is
+// deterministic given deterministic handlers.
+// On visiting a node,
+// - `preHandler` is called before traversing the successors.
+// - `postHandler` is called after traversing the successors.
+// A handler may return `ErrSkipDesc` to signal not traversing descendants.
+// If any handler returns an error, the walk is stopped.
+// WARNING:
+// - This function does not detect circles. It is possible running into an
+//   infinite loop. The caller is required to make sure the graph is a DAG.
+// - This function does not record walk history. Nodes might be visited multiple
+//   times if they are directly pointed by multiple nodes.
+func Walk(ctx context.Context, preHandler, postHandler Handler, roots ...ocispec.Descriptor) error {
+	for _, root := range roots {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// pre-handle
+		nodes, err := preHandler.Handle(ctx, root)
+		if err != nil {
+			if errors.Is(err, ErrSkipDesc) {
+				continue
+			}
+			return err
+		}
+
+		// handle successors
+		if err := Walk(ctx, preHandler, postHandler, nodes...); err != nil {
+			return err
+		}
+
+		// post-handle
+		if _, err := postHandler.Handle(ctx, root); err != nil && !errors.Is(err, ErrSkipDesc) {
+			return err
+		}
+	}
+	return nil
+}
+
 func startLimitRegion(ctx context.Context, limiter *semaphore.Weighted) error {
 	if limiter == nil {
 		return nil
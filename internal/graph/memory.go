@@ -88,6 +88,20 @@ func (m *Memory) IndexAll(ctx context.Context, fetcher content.Fetcher, node oci
 	return Dispatch(ctx, preHandler, postHandler, nil, node)
 }
 
+// IndexSuccessors indexes predecessors for each of the given direct successors
+// of the node.
+// It is useful for restoring the index from a persisted state without
+// fetching the node.
+func (m *Memory) IndexSuccessors(ctx context.Context, node ocispec.Descriptor, successors []ocispec.Descriptor) error {
+	return m.index(ctx, node, successors)
+}
+
+// Indexed returns true if the given node has been indexed.
+func (m *Memory) Indexed(node ocispec.Descriptor) bool {
+	_, exists := m.indexed.Load(descriptor.FromOCI(node))
+	return exists
+}
+
 // Predecessors returns the nodes directly pointing to the current node.
 // Predecessors returns nil without error if the node does not exists in the
 // store.
@@ -27,3 +27,10 @@ func WithScopeHint(ctx context.Context, ref registry.Reference, actions ...strin
 	scope := auth.ScopeRepository(ref.Repository, actions...)
 	return auth.AppendScopes(ctx, scope)
 }
+
+// WithHostScopeHint adds a hinted scope for the host of the reference to the
+// context.
+func WithHostScopeHint(ctx context.Context, ref registry.Reference, actions ...string) context.Context {
+	scope := auth.ScopeRepository(ref.Repository, actions...)
+	return auth.AppendScopesForHost(ctx, ref.Host(), scope)
+}
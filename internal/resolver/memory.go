@@ -37,7 +37,7 @@ func NewMemory() *Memory {
 func (m *Memory) Resolve(_ context.Context, reference string) (ocispec.Descriptor, error) {
 	desc, ok := m.index.Load(reference)
 	if !ok {
-		return ocispec.Descriptor{}, errdef.ErrNotFound
+		return ocispec.Descriptor{}, &errdef.NotFoundError{Reference: reference}
 	}
 	return desc.(ocispec.Descriptor), nil
 }
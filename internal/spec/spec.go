@@ -0,0 +1,86 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package spec defines the OCI 1.1 structures and media types, which are not
+// yet available in the image-spec version in use.
+package spec
+
+import (
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// MediaTypeEmptyJSON is the media type of the empty JSON object `{}`.
+// It is used as the config media type of artifacts packed as OCI image
+// manifests.
+// Reference: https://github.com/opencontainers/image-spec/blob/main/manifest.md#guidance-for-an-empty-descriptor
+const MediaTypeEmptyJSON = "application/vnd.oci.empty.v1+json"
+
+// Manifest provides `application/vnd.oci.image.manifest.v1+json` mediatype
+// structure when marshalled to JSON, with the OCI 1.1 `artifactType` field.
+// Reference: https://github.com/opencontainers/image-spec/blob/main/manifest.md
+type Manifest struct {
+	specs.Versioned
+
+	// MediaType specifies the type of this document data structure e.g.
+	// `application/vnd.oci.image.manifest.v1+json`
+	MediaType string `json:"mediaType,omitempty"`
+
+	// ArtifactType specifies the IANA media type of artifact when the manifest
+	// is used for an artifact.
+	ArtifactType string `json:"artifactType,omitempty"`
+
+	// Config references a configuration object for a container, by digest.
+	// The referenced configuration object is a JSON blob that the runtime uses
+	// to set up the container.
+	Config ocispec.Descriptor `json:"config"`
+
+	// Layers is an indexed list of layers referenced by the manifest.
+	Layers []ocispec.Descriptor `json:"layers"`
+
+	// Subject is an optional link from the image manifest to another manifest
+	// forming an association between the image manifest and the other
+	// manifest.
+	Subject *ocispec.Descriptor `json:"subject,omitempty"`
+
+	// Annotations contains arbitrary metadata for the image manifest.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Index provides `application/vnd.oci.image.index.v1+json` mediatype structure
+// when marshalled to JSON, with the OCI 1.1 `artifactType` and `subject`
+// fields.
+// Reference: https://github.com/opencontainers/image-spec/blob/main/image-index.md
+type Index struct {
+	specs.Versioned
+
+	// MediaType specifies the type of this document data structure e.g.
+	// `application/vnd.oci.image.index.v1+json`
+	MediaType string `json:"mediaType,omitempty"`
+
+	// ArtifactType specifies the IANA media type of artifact when the index
+	// is used for an artifact.
+	ArtifactType string `json:"artifactType,omitempty"`
+
+	// Manifests references platform specific manifests.
+	Manifests []ocispec.Descriptor `json:"manifests"`
+
+	// Subject is an optional link from the index to another manifest forming
+	// an association between the index and the other manifest.
+	Subject *ocispec.Descriptor `json:"subject,omitempty"`
+
+	// Annotations contains arbitrary metadata for the image index.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
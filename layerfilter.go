@@ -0,0 +1,71 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// filterLayers wraps findSuccessors so that the layers of the image manifests
+// rejected by filter are not returned. The layers are identified by decoding
+// the manifests, so that the configs, the subjects, and the successors of
+// other nodes are never filtered out.
+func filterLayers(findSuccessors func(context.Context, content.Fetcher, ocispec.Descriptor) ([]ocispec.Descriptor, error), filter func(desc ocispec.Descriptor) bool) func(context.Context, content.Fetcher, ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	return func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		successors, err := findSuccessors(ctx, fetcher, desc)
+		if err != nil || !isImageManifest(desc) {
+			return successors, err
+		}
+
+		// the manifest is fetched from the cache populated by findSuccessors
+		manifestJSON, err := content.FetchAll(ctx, fetcher, desc)
+		if err != nil {
+			return nil, err
+		}
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+			return nil, fmt.Errorf("%s: %s: failed to decode manifest: %w", desc.Digest, desc.MediaType, err)
+		}
+		rejected := make(map[digest.Digest]bool)
+		for _, layer := range manifest.Layers {
+			if !filter(layer) {
+				rejected[layer.Digest] = true
+			}
+		}
+		if len(rejected) == 0 {
+			return successors, nil
+		}
+		// never filter out the config, even if it is also a rejected layer
+		delete(rejected, manifest.Config.Digest)
+		if manifest.Subject != nil {
+			delete(rejected, manifest.Subject.Digest)
+		}
+
+		var selected []ocispec.Descriptor
+		for _, successor := range successors {
+			if !rejected[successor.Digest] {
+				selected = append(selected, successor)
+			}
+		}
+		return selected, nil
+	}
+}
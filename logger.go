@@ -0,0 +1,63 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Logger logs the significant steps of the copy operations as structured
+// events, which are
+//   - "resolve": the source reference is resolved to the root node.
+//   - "skip": a node is skipped as it exists in the destination.
+//   - "copy": a node is fetched from the source and pushed to the destination.
+//   - "mount": a blob is mounted from another repository.
+//   - "retry": the traversal of the graph is retried.
+//
+// The attributes are given as alternating keys and values, where the keys
+// are strings, such as "digest", "mediaType", "size", "duration", and
+// "error". Logger can be adapted to a logging library, e.g. *slog.Logger:
+//
+//	func (l slogLogger) Log(ctx context.Context, event string, keyvals ...interface{}) {
+//		l.Logger.InfoContext(ctx, event, keyvals...)
+//	}
+type Logger interface {
+	// Log logs an event with its attributes.
+	Log(ctx context.Context, event string, keyvals ...interface{})
+}
+
+// logNode logs an event on the node desc with logger, started at start, if
+// logger is not nil.
+func logNode(ctx context.Context, logger Logger, event string, desc ocispec.Descriptor, start time.Time, err error, keyvals ...interface{}) {
+	if logger == nil {
+		return
+	}
+	keyvals = append([]interface{}{
+		"digest", desc.Digest,
+		"mediaType", desc.MediaType,
+		"size", desc.Size,
+	}, keyvals...)
+	if !start.IsZero() {
+		keyvals = append(keyvals, "duration", time.Since(start))
+	}
+	if err != nil {
+		keyvals = append(keyvals, "error", err)
+	}
+	logger.Log(ctx, event, keyvals...)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// recordingLogger records the logged events with their attributes.
+type recordingLogger struct {
+	lock   sync.Mutex
+	events map[string][]map[string]interface{}
+}
+
+func (l *recordingLogger) Log(_ context.Context, event string, keyvals ...interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	attrs := make(map[string]interface{})
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		attrs[keyvals[i].(string)] = keyvals[i+1]
+	}
+	l.events[event] = append(l.events[event], attrs)
+}
+
+func TestCopy_Logger(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	layer := []byte("layer")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	if err := src.Push(ctx, layerDesc, bytes.NewReader(layer)); err != nil {
+		t.Fatal(err)
+	}
+	root, err := oras.Pack(ctx, src, []ocispec.Descriptor{layerDesc}, oras.PackOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := "foobar"
+	if err := src.Tag(ctx, root, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := memory.New()
+	logger := &recordingLogger{events: make(map[string][]map[string]interface{})}
+	opts := oras.CopyOptions{}
+	opts.Logger = logger
+	if _, err := oras.Copy(ctx, src, ref, dst, "", opts); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	resolved := logger.events["resolve"]
+	if len(resolved) != 1 {
+		t.Fatalf("number of resolve events = %v, want %v", len(resolved), 1)
+	}
+	if got := resolved[0]["digest"]; got != root.Digest {
+		t.Errorf("resolve digest = %v, want %v", got, root.Digest)
+	}
+	if got := resolved[0]["reference"]; got != ref {
+		t.Errorf("resolve reference = %v, want %v", got, ref)
+	}
+	// the config, the layer, and the manifest are copied
+	copied := logger.events["copy"]
+	if len(copied) != 3 {
+		t.Fatalf("number of copy events = %v, want %v", len(copied), 3)
+	}
+	for _, attrs := range copied {
+		if _, ok := attrs["duration"]; !ok {
+			t.Errorf("copy event of %v has no duration", attrs["digest"])
+		}
+		if _, ok := attrs["error"]; ok {
+			t.Errorf("copy event of %v has error %v", attrs["digest"], attrs["error"])
+		}
+	}
+	if got := copied[len(copied)-1]["digest"]; got != root.Digest {
+		t.Errorf("last copy digest = %v, want %v", got, root.Digest)
+	}
+
+	// copy again, where the root is skipped
+	logger.events = make(map[string][]map[string]interface{})
+	if _, err := oras.Copy(ctx, src, ref, dst, "", opts); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if got := len(logger.events["copy"]); got != 0 {
+		t.Errorf("number of copy events = %v, want %v", got, 0)
+	}
+	skipped := logger.events["skip"]
+	if len(skipped) != 1 || skipped[0]["digest"] != root.Digest {
+		t.Errorf("skip events = %v, want the root %v", skipped, root.Digest)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/graph"
+)
+
+// pendingManifest is a manifest attempted on ManifestFirst, of which the
+// successors are to be copied if missing in the destination.
+type pendingManifest struct {
+	// successors are the successors to be copied before the manifest is
+	// completed.
+	successors []ocispec.Descriptor
+	// pushed is set if the manifest is accepted or skipped, and is not to be
+	// pushed again.
+	pushed bool
+	// postCopy is set if PostCopy is to be called once the successors are
+	// copied.
+	postCopy bool
+	// retryPreCopy is set if PreCopy is to be called again on the retry.
+	retryPreCopy bool
+}
+
+// isImageManifest checks if the descriptor describes an image manifest, of
+// which the successors are blobs and the subject.
+func isImageManifest(desc ocispec.Descriptor) bool {
+	switch desc.MediaType {
+	case docker.MediaTypeManifest, ocispec.MediaTypeImageManifest:
+		return true
+	}
+	return false
+}
+
+// pushManifestFirst pushes the manifest desc to dst before its successors.
+// If the manifest is accepted or skipped by PreCopy, all the successors are to
+// be checked and copied if missing, as the destination may accept manifests
+// referencing absent blobs. Otherwise, the successors to be copied before the
+// manifest is pushed again are the missing blobs if reported by dst, or all
+// the successors.
+// If PreCopy rejects the manifest reporting the unknown blobs, e.g. when the
+// manifest is pushed by reference in PreCopy, PreCopy is to be called again on
+// the retry.
+func pushManifestFirst(ctx context.Context, src content.Fetcher, dst content.Storage, desc ocispec.Descriptor, successors []ocispec.Descriptor, opts CopyGraphOptions) (pendingManifest, error) {
+	if opts.PreCopy != nil {
+		if err := opts.PreCopy(ctx, desc); err != nil {
+			if err == graph.ErrSkipDesc {
+				return pendingManifest{
+					successors: successors,
+					pushed:     true,
+				}, nil
+			}
+			if isUnknownBlobsError(err) {
+				return pendingManifest{
+					successors:   missingSuccessors(err, successors),
+					retryPreCopy: true,
+				}, nil
+			}
+			return pendingManifest{}, err
+		}
+	}
+
+	err := opts.quota.transfer(desc, func() error {
+		return withTransferSlot(ctx, opts, func() error {
+			return doCopyNode(ctx, opts.bandwidth.fetcher(src), dst, desc, opts.VerifyOnCopy, nil)
+		})
+	})
+	if err == nil {
+		return pendingManifest{
+			successors: successors,
+			pushed:     true,
+			postCopy:   true,
+		}, nil
+	}
+	if errors.Is(err, ErrQuotaExceeded) {
+		return pendingManifest{}, err
+	}
+	return pendingManifest{
+		successors: missingSuccessors(err, successors),
+	}, nil
+}
+
+// isUnknownBlobsError checks if err reports the blobs unknown to the
+// destination.
+func isUnknownBlobsError(err error) bool {
+	var unknownErr *errdef.UnknownBlobsError
+	return errors.As(err, &unknownErr)
+}
+
+// missingSuccessors returns the successors reported unknown by err, or all the
+// successors if err does not report any of them.
+func missingSuccessors(err error, successors []ocispec.Descriptor) []ocispec.Descriptor {
+	var unknownErr *errdef.UnknownBlobsError
+	if !errors.As(err, &unknownErr) {
+		return successors
+	}
+	unknown := make(map[digest.Digest]bool)
+	for _, dgst := range unknownErr.Blobs {
+		unknown[dgst] = true
+	}
+	var missing []ocispec.Descriptor
+	for _, successor := range successors {
+		if unknown[successor.Digest] {
+			missing = append(missing, successor)
+		}
+	}
+	if len(missing) == 0 {
+		// the reported blobs are not referenced by the manifest
+		return successors
+	}
+	return missing
+}
@@ -0,0 +1,66 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// checkMediaTypes traverses the graph rooted at root, as found by
+// findSuccessors, and returns an error if the media type of any node to be
+// pushed is not in opts.AllowedMediaTypes.
+// The docker media types are checked against their OCI equivalents if
+// opts.ConvertManifest is set, as they are converted before pushed.
+func checkMediaTypes(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor, findSuccessors func(context.Context, content.Fetcher, ocispec.Descriptor) ([]ocispec.Descriptor, error), opts CopyGraphOptions) error {
+	allowed := make(map[string]bool, len(opts.AllowedMediaTypes))
+	for _, mediaType := range opts.AllowedMediaTypes {
+		allowed[mediaType] = true
+	}
+
+	visited := make(map[digest.Digest]bool)
+	stack := []ocispec.Descriptor{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[node.Digest] {
+			continue
+		}
+		visited[node.Digest] = true
+
+		mediaType := node.MediaType
+		if opts.ConvertManifest {
+			if converted, ok := dockerToOCIMediaTypes[mediaType]; ok {
+				mediaType = converted
+			}
+		}
+		if !allowed[mediaType] {
+			return fmt.Errorf("%s: %s: media type not allowed in the destination: %w", node.Digest, mediaType, errdef.ErrUnsupported)
+		}
+
+		successors, err := findSuccessors(ctx, fetcher, node)
+		if err != nil {
+			return err
+		}
+		stack = append(stack, successors...)
+	}
+	return nil
+}
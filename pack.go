@@ -29,6 +29,8 @@ import (
 	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/spec"
 )
 
 // MediaTypeUnknownConfig is the default mediaType used when no
@@ -44,6 +46,11 @@ var (
 	// format.
 	// Reference: https://datatracker.ietf.org/doc/html/rfc3339#section-5.6
 	ErrInvalidDateTimeFormat = errors.New("invalid date and time format")
+	// ErrInvalidDescriptor is returned by Pack(), PackIndex(), and
+	// PackArtifact() when any of the given descriptors is malformed, or when
+	// the layers or the blobs are duplicated without being allowed. The error
+	// message identifies the offending descriptor.
+	ErrInvalidDescriptor = errors.New("invalid descriptor")
 )
 
 // PackOptions contains parameters for oras.Pack.
@@ -51,12 +58,61 @@ type PackOptions struct {
 	// ConfigDescriptor is a pointer to the descriptor of the config blob.
 	ConfigDescriptor *ocispec.Descriptor
 	// ConfigMediaType is the media type of the config blob.
-	// If not specified, MediaTypeUnknownConfig will be used.
+	// If not specified, MediaTypeUnknownConfig will be used, or
+	// content.DescriptorEmptyJSON will be used as the config descriptor if
+	// ArtifactType is specified.
 	ConfigMediaType string
 	// ConfigAnnotations is the annotation map of the config descriptor.
 	ConfigAnnotations map[string]string
 	// ManifestAnnotations is the annotation map of the manifest.
 	ManifestAnnotations map[string]string
+	// AnnotateManifest, if not nil, is called just before the manifest is
+	// marshaled, and returns the annotation map of the manifest computed from
+	// the layers, e.g. the titles of the layers collected into a manifest
+	// annotation. existing is a copy of ManifestAnnotations, which can be
+	// modified and returned. layers must not be modified.
+	AnnotateManifest func(layers []ocispec.Descriptor, existing map[string]string) map[string]string
+	// Subject is the subject of the manifest.
+	// A manifest with a subject is a referrer of the subject as specified by
+	// the OCI image-spec v1.1.
+	Subject *ocispec.Descriptor
+	// ArtifactType is the artifact type of the manifest.
+	// Reference: https://github.com/opencontainers/image-spec/blob/main/manifest.md#guidelines-for-artifact-usage
+	ArtifactType string
+	// DigestAlgorithm is the algorithm used to calculate the digests of the
+	// generated config and manifest.
+	// The hash function of the algorithm must be available, e.g. by importing
+	// "crypto/sha512" for digest.SHA512. Note that the pusher may not support
+	// algorithms other than digest.Canonical, in which case the push fails.
+	// If not specified, digest.Canonical (sha256) is used.
+	DigestAlgorithm digest.Algorithm
+	// EmptyJSONTracker, if not nil, tracks the pushers where the well-known
+	// empty JSON config is pushed, so that it is pushed at most once per
+	// pusher.
+	EmptyJSONTracker *EmptyJSONTracker
+	// AllowDuplicateLayers allows the layers with the same digest to be
+	// packed more than once.
+	AllowDuplicateLayers bool
+}
+
+// PackIndexOptions contains parameters for oras.PackIndex.
+type PackIndexOptions struct {
+	// IndexAnnotations is the annotation map of the index.
+	IndexAnnotations map[string]string
+	// Subject is the subject of the index.
+	// An index with a subject is a referrer of the subject as specified by
+	// the OCI image-spec v1.1.
+	Subject *ocispec.Descriptor
+	// ArtifactType is the artifact type of the index.
+	// Reference: https://github.com/opencontainers/image-spec/blob/main/image-index.md
+	ArtifactType string
+	// DigestAlgorithm is the algorithm used to calculate the digest of the
+	// generated index.
+	// The hash function of the algorithm must be available, e.g. by importing
+	// "crypto/sha512" for digest.SHA512. Note that the pusher may not support
+	// algorithms other than digest.Canonical, in which case the push fails.
+	// If not specified, digest.Canonical (sha256) is used.
+	DigestAlgorithm digest.Algorithm
 }
 
 // PackArtifactOptions contains parameters for oras.PackArtifact.
@@ -64,21 +120,65 @@ type PackArtifactOptions struct {
 	// Subject is the subject of the ORAS Artifact Manifest.
 	Subject *artifactspec.Descriptor
 	// ManifestAnnotations is the annotation map of the manifest.
+	// A fixed creation time can be specified by the annotation
+	// AnnotationArtifactCreated for reproducible manifests.
 	ManifestAnnotations map[string]string
+	// ExcludeTimestamp controls if the creation time annotation
+	// AnnotationArtifactCreated is omitted when it is not specified in
+	// ManifestAnnotations.
+	// If set, the generated manifest is reproducible given identical inputs.
+	ExcludeTimestamp bool
+	// DigestAlgorithm is the algorithm used to calculate the digest of the
+	// generated manifest.
+	// The hash function of the algorithm must be available, e.g. by importing
+	// "crypto/sha512" for digest.SHA512. Note that the pusher may not support
+	// algorithms other than digest.Canonical, in which case the push fails.
+	// If not specified, digest.Canonical (sha256) is used.
+	DigestAlgorithm digest.Algorithm
+	// AllowDuplicateBlobs allows the blobs with the same digest to be packed
+	// more than once.
+	AllowDuplicateBlobs bool
 }
 
 // Pack packs the given layers, generates a manifest for the pack,
 // and pushes it to a content storage.
+// If opts.Subject or opts.ArtifactType is specified, the generated manifest is
+// an OCI image-spec v1.1 manifest, which can be used as a referrer.
 // If succeeded, returns a descriptor of the manifest.
+// Returns ErrInvalidDescriptor if any of the given descriptors is malformed.
 func Pack(ctx context.Context, pusher content.Pusher, layers []ocispec.Descriptor, opts PackOptions) (ocispec.Descriptor, error) {
-	if opts.ConfigMediaType == "" {
-		opts.ConfigMediaType = MediaTypeUnknownConfig
+	alg, err := digestAlgorithm(opts.DigestAlgorithm)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := validatePackDescriptors(layers, opts); err != nil {
+		return ocispec.Descriptor{}, err
 	}
 
 	var configDesc ocispec.Descriptor
 	if opts.ConfigDescriptor != nil {
 		configDesc = *opts.ConfigDescriptor
+	} else if opts.ConfigMediaType == "" && opts.ArtifactType != "" && alg == digest.Canonical {
+		// use the well-known empty JSON blob as the config of artifacts
+		configDesc = content.DescriptorEmptyJSON
+		configDesc.Annotations = opts.ConfigAnnotations
+		if !opts.EmptyJSONTracker.exists(pusher) {
+			if err := content.PushEmptyJSON(ctx, pusher); err != nil {
+				return ocispec.Descriptor{}, fmt.Errorf("failed to push config: %w", err)
+			}
+			opts.EmptyJSONTracker.add(pusher)
+		}
+	} else if opts.ConfigMediaType == "" && opts.ArtifactType != "" {
+		// use the empty JSON blob digested by the specified algorithm
+		configDesc = content.NewDescriptorFromBytesWithAlgorithm(spec.MediaTypeEmptyJSON, content.EmptyJSON, alg)
+		configDesc.Annotations = opts.ConfigAnnotations
+		if err := pusher.Push(ctx, configDesc, bytes.NewReader(content.EmptyJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to push config: %w", err)
+		}
 	} else {
+		if opts.ConfigMediaType == "" {
+			opts.ConfigMediaType = MediaTypeUnknownConfig
+		}
 		// Use an empty JSON object here, because some registries may not accept
 		// empty config blob.
 		// As of September 2022, GAR is known to return 400 on empty blob upload.
@@ -86,7 +186,7 @@ func Pack(ctx context.Context, pusher content.Pusher, layers []ocispec.Descripto
 		configBytes := []byte("{}")
 		configDesc = ocispec.Descriptor{
 			MediaType:   opts.ConfigMediaType,
-			Digest:      digest.FromBytes(configBytes),
+			Digest:      alg.FromBytes(configBytes),
 			Size:        int64(len(configBytes)),
 			Annotations: opts.ConfigAnnotations,
 		}
@@ -100,15 +200,25 @@ func Pack(ctx context.Context, pusher content.Pusher, layers []ocispec.Descripto
 	if layers == nil {
 		layers = []ocispec.Descriptor{} // make it an empty array to prevent potential server-side bugs
 	}
+	annotations := opts.ManifestAnnotations
+	if opts.AnnotateManifest != nil {
+		existing := make(map[string]string, len(opts.ManifestAnnotations))
+		for k, v := range opts.ManifestAnnotations {
+			existing[k] = v
+		}
+		annotations = opts.AnnotateManifest(layers, existing)
+	}
 
-	manifest := ocispec.Manifest{
+	manifest := spec.Manifest{
 		Versioned: specs.Versioned{
 			SchemaVersion: 2, // historical value. does not pertain to OCI or docker version
 		},
-		Config:      configDesc,
-		MediaType:   ocispec.MediaTypeImageManifest,
-		Layers:      layers,
-		Annotations: opts.ManifestAnnotations,
+		Config:       configDesc,
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: opts.ArtifactType,
+		Layers:       layers,
+		Subject:      opts.Subject,
+		Annotations:  annotations,
 	}
 	manifestBytes, err := json.Marshal(manifest)
 	if err != nil {
@@ -116,7 +226,7 @@ func Pack(ctx context.Context, pusher content.Pusher, layers []ocispec.Descripto
 	}
 	manifestDesc := ocispec.Descriptor{
 		MediaType: ocispec.MediaTypeImageManifest,
-		Digest:    digest.FromBytes(manifestBytes),
+		Digest:    alg.FromBytes(manifestBytes),
 		Size:      int64(len(manifestBytes)),
 	}
 
@@ -128,23 +238,78 @@ func Pack(ctx context.Context, pusher content.Pusher, layers []ocispec.Descripto
 	return manifestDesc, nil
 }
 
+// PackIndex generates an OCI image index referencing the given manifests, and
+// pushes it to a content storage.
+// If opts.Subject or opts.ArtifactType is specified, the generated index is an
+// OCI image-spec v1.1 index, which can be used as a referrer.
+// If succeeded, returns a descriptor of the index.
+// Returns ErrInvalidDescriptor if any of the given descriptors is malformed.
+func PackIndex(ctx context.Context, pusher content.Pusher, manifests []ocispec.Descriptor, opts PackIndexOptions) (ocispec.Descriptor, error) {
+	alg, err := digestAlgorithm(opts.DigestAlgorithm)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := validatePackIndexDescriptors(manifests, opts); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	if manifests == nil {
+		manifests = []ocispec.Descriptor{} // make it an empty array to prevent potential server-side bugs
+	}
+
+	index := spec.Index{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2, // historical value. does not pertain to OCI or docker version
+		},
+		MediaType:    ocispec.MediaTypeImageIndex,
+		ArtifactType: opts.ArtifactType,
+		Manifests:    manifests,
+		Subject:      opts.Subject,
+		Annotations:  opts.IndexAnnotations,
+	}
+	indexBytes, err := json.Marshal(index)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to marshal index: %w", err)
+	}
+	indexDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    alg.FromBytes(indexBytes),
+		Size:      int64(len(indexBytes)),
+	}
+
+	// push index
+	if err := pusher.Push(ctx, indexDesc, bytes.NewReader(indexBytes)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push index: %w", err)
+	}
+
+	return indexDesc, nil
+}
+
 // PackArtifact packs the given blobs, generates an ORAS Artifact Manifest for
 // the pack, and pushes it to a content storage.
 // If succeeded, returns a descriptor of the manifest.
-// Returns ErrMissingArtifactType if artifactType is empty.
+// Returns ErrMissingArtifactType if artifactType is empty, and
+// ErrInvalidDescriptor if any of the given descriptors is malformed.
 // Reference: https://github.com/oras-project/artifacts-spec/blob/main/artifact-manifest.md
 func PackArtifact(ctx context.Context, pusher content.Pusher, artifactType string, blobs []artifactspec.Descriptor, opts PackArtifactOptions) (ocispec.Descriptor, error) {
 	if artifactType == "" {
 		// artifactType is required for ORAS Artifact Manifest
 		return ocispec.Descriptor{}, ErrMissingArtifactType
 	}
+	alg, err := digestAlgorithm(opts.DigestAlgorithm)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := validatePackArtifactDescriptors(blobs, opts); err != nil {
+		return ocispec.Descriptor{}, err
+	}
 
 	if createdTime, ok := opts.ManifestAnnotations[artifactspec.AnnotationArtifactCreated]; ok {
 		// if AnnotationArtifactCreated is provided, validate its format
 		if _, err := time.Parse(time.RFC3339, createdTime); err != nil {
 			return ocispec.Descriptor{}, fmt.Errorf("%w: %v", ErrInvalidDateTimeFormat, err)
 		}
-	} else {
+	} else if !opts.ExcludeTimestamp {
 		// copy the original annotation map
 		annotations := make(map[string]string, len(opts.ManifestAnnotations)+1)
 		for k, v := range opts.ManifestAnnotations {
@@ -176,7 +341,7 @@ func PackArtifact(ctx context.Context, pusher content.Pusher, artifactType strin
 
 	manifestDesc := ocispec.Descriptor{
 		MediaType: artifactspec.MediaTypeArtifactManifest,
-		Digest:    digest.FromBytes(manifestBytes),
+		Digest:    alg.FromBytes(manifestBytes),
 		Size:      int64(len(manifestBytes)),
 	}
 
@@ -187,3 +352,95 @@ func PackArtifact(ctx context.Context, pusher content.Pusher, artifactType strin
 
 	return manifestDesc, nil
 }
+
+// digestAlgorithm returns the digest algorithm to be used, defaulting to
+// digest.Canonical, and validates that its hash function is available.
+func digestAlgorithm(alg digest.Algorithm) (digest.Algorithm, error) {
+	if alg == "" {
+		return digest.Canonical, nil
+	}
+	if !alg.Available() {
+		return "", fmt.Errorf("digest algorithm %q: %w", alg, errdef.ErrUnsupported)
+	}
+	return alg, nil
+}
+
+// validatePackDescriptors validates the layers, the config, and the subject to
+// be packed by Pack().
+func validatePackDescriptors(layers []ocispec.Descriptor, opts PackOptions) error {
+	if opts.ConfigDescriptor != nil {
+		if err := validateDescriptor("config", *opts.ConfigDescriptor); err != nil {
+			return err
+		}
+	}
+	if opts.Subject != nil {
+		if err := validateDescriptor("subject", *opts.Subject); err != nil {
+			return err
+		}
+	}
+	seen := make(map[digest.Digest]int, len(layers))
+	for i, layer := range layers {
+		name := fmt.Sprintf("layers[%d]", i)
+		if err := validateDescriptor(name, layer); err != nil {
+			return err
+		}
+		if j, ok := seen[layer.Digest]; ok && !opts.AllowDuplicateLayers {
+			return fmt.Errorf("%w: %s: %s: duplicate of layers[%d]", ErrInvalidDescriptor, name, layer.Digest, j)
+		}
+		seen[layer.Digest] = i
+	}
+	return nil
+}
+
+// validatePackIndexDescriptors validates the manifests and the subject to be
+// packed by PackIndex().
+func validatePackIndexDescriptors(manifests []ocispec.Descriptor, opts PackIndexOptions) error {
+	if opts.Subject != nil {
+		if err := validateDescriptor("subject", *opts.Subject); err != nil {
+			return err
+		}
+	}
+	for i, manifest := range manifests {
+		if err := validateDescriptor(fmt.Sprintf("manifests[%d]", i), manifest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePackArtifactDescriptors validates the blobs and the subject to be
+// packed by PackArtifact().
+func validatePackArtifactDescriptors(blobs []artifactspec.Descriptor, opts PackArtifactOptions) error {
+	if opts.Subject != nil {
+		if err := validateDescriptor("subject", descriptor.ArtifactToOCI(*opts.Subject)); err != nil {
+			return err
+		}
+	}
+	seen := make(map[digest.Digest]int, len(blobs))
+	for i, blob := range blobs {
+		name := fmt.Sprintf("blobs[%d]", i)
+		if err := validateDescriptor(name, descriptor.ArtifactToOCI(blob)); err != nil {
+			return err
+		}
+		if j, ok := seen[blob.Digest]; ok && !opts.AllowDuplicateBlobs {
+			return fmt.Errorf("%w: %s: %s: duplicate of blobs[%d]", ErrInvalidDescriptor, name, blob.Digest, j)
+		}
+		seen[blob.Digest] = i
+	}
+	return nil
+}
+
+// validateDescriptor checks that desc has a media type, a well-formed digest,
+// and a non-negative size.
+func validateDescriptor(name string, desc ocispec.Descriptor) error {
+	if desc.MediaType == "" {
+		return fmt.Errorf("%w: %s: %s: missing media type", ErrInvalidDescriptor, name, desc.Digest)
+	}
+	if err := desc.Digest.Validate(); err != nil {
+		return fmt.Errorf("%w: %s: invalid digest %q: %v", ErrInvalidDescriptor, name, desc.Digest, err)
+	}
+	if desc.Size < 0 {
+		return fmt.Errorf("%w: %s: %s: negative size %d", ErrInvalidDescriptor, name, desc.Digest, desc.Size)
+	}
+	return nil
+}
@@ -18,8 +18,10 @@ package oras
 import (
 	"bytes"
 	"context"
+	_ "crypto/sha512"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"reflect"
 	"testing"
@@ -30,7 +32,11 @@ import (
 	"github.com/opencontainers/go-digest"
 	specs "github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/spec"
 )
 
 func Test_Pack_Default(t *testing.T) {
@@ -218,6 +224,489 @@ func Test_Pack_NoLayer(t *testing.T) {
 	}
 }
 
+func Test_Pack_AnnotateManifest(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	layers := []ocispec.Descriptor{
+		{
+			MediaType:   ocispec.MediaTypeImageLayer,
+			Digest:      digest.FromString("foo"),
+			Size:        3,
+			Annotations: map[string]string{ocispec.AnnotationTitle: "foo.txt"},
+		},
+		{
+			MediaType:   ocispec.MediaTypeImageLayer,
+			Digest:      digest.FromString("bar"),
+			Size:        3,
+			Annotations: map[string]string{ocispec.AnnotationTitle: "bar.txt"},
+		},
+	}
+	manifestAnnotations := map[string]string{"foo": "bar"}
+	opts := PackOptions{
+		ManifestAnnotations: manifestAnnotations,
+		AnnotateManifest: func(layers []ocispec.Descriptor, existing map[string]string) map[string]string {
+			var titles []string
+			for _, layer := range layers {
+				titles = append(titles, layer.Annotations[ocispec.AnnotationTitle])
+			}
+			existing["titles"] = fmt.Sprint(titles)
+			return existing
+		},
+	}
+	manifestDesc, err := Pack(ctx, s, layers, opts)
+	if err != nil {
+		t.Fatal("Oras.Pack() error =", err)
+	}
+
+	rc, err := s.Fetch(ctx, manifestDesc)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+		t.Fatal("error decoding manifest, error =", err)
+	}
+	if err := rc.Close(); err != nil {
+		t.Error("Store.Fetch().Close() error =", err)
+	}
+	want := map[string]string{
+		"foo":    "bar",
+		"titles": "[foo.txt bar.txt]",
+	}
+	if !reflect.DeepEqual(manifest.Annotations, want) {
+		t.Errorf("Manifest.Annotations = %v, want %v", manifest.Annotations, want)
+	}
+	if want := map[string]string{"foo": "bar"}; !reflect.DeepEqual(manifestAnnotations, want) {
+		t.Errorf("PackOptions.ManifestAnnotations = %v, want %v", manifestAnnotations, want)
+	}
+}
+
+func Test_Pack_WithSubjectAndArtifactType(t *testing.T) {
+	s := memory.New()
+
+	// prepare test content
+	subjectManifest := []byte(`{"layers":[]}`)
+	subjectDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(subjectManifest),
+		Size:      int64(len(subjectManifest)),
+	}
+	layer := []byte("hello world")
+	layerDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(layer),
+		Size:      int64(len(layer)),
+	}
+	artifactType := "application/vnd.test"
+
+	// test Pack
+	ctx := context.Background()
+	opts := PackOptions{
+		Subject:      &subjectDesc,
+		ArtifactType: artifactType,
+	}
+	manifestDesc, err := Pack(ctx, s, []ocispec.Descriptor{layerDesc}, opts)
+	if err != nil {
+		t.Fatal("Oras.Pack() error =", err)
+	}
+
+	expectedConfigBytes := []byte("{}")
+	expectedConfig := ocispec.Descriptor{
+		MediaType: spec.MediaTypeEmptyJSON,
+		Digest:    digest.FromBytes(expectedConfigBytes),
+		Size:      int64(len(expectedConfigBytes)),
+	}
+	expectedManifest := spec.Manifest{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2, // historical value. does not pertain to OCI or docker version
+		},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Config:       expectedConfig,
+		Layers:       []ocispec.Descriptor{layerDesc},
+		Subject:      &subjectDesc,
+	}
+	expectedManifestBytes, err := json.Marshal(expectedManifest)
+	if err != nil {
+		t.Fatal("failed to marshal manifest:", err)
+	}
+
+	// test manifest
+	rc, err := s.Fetch(ctx, manifestDesc)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal("Store.Fetch().Read() error =", err)
+	}
+	err = rc.Close()
+	if err != nil {
+		t.Error("Store.Fetch().Close() error =", err)
+	}
+	if !bytes.Equal(got, expectedManifestBytes) {
+		t.Errorf("Store.Fetch() = %v, want %v", got, expectedManifestBytes)
+	}
+
+	// test config
+	exists, err := s.Exists(ctx, expectedConfig)
+	if err != nil {
+		t.Fatal("Store.Exists() error =", err)
+	}
+	if !exists {
+		t.Errorf("Store.Exists() = %v, want %v", exists, true)
+	}
+
+	// test subject
+	predecessors, err := s.Predecessors(ctx, subjectDesc)
+	if err != nil {
+		t.Fatal("Store.Predecessors() error =", err)
+	}
+	if want := []ocispec.Descriptor{manifestDesc}; !reflect.DeepEqual(predecessors, want) {
+		t.Errorf("Store.Predecessors() = %v, want %v", predecessors, want)
+	}
+}
+
+func Test_Pack_DigestAlgorithm(t *testing.T) {
+	s := memory.New()
+
+	// prepare test content
+	layer := []byte("hello world")
+	layerDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.SHA512.FromBytes(layer),
+		Size:      int64(len(layer)),
+	}
+	artifactType := "application/vnd.test"
+
+	// test Pack
+	ctx := context.Background()
+	opts := PackOptions{
+		ArtifactType:    artifactType,
+		DigestAlgorithm: digest.SHA512,
+	}
+	manifestDesc, err := Pack(ctx, s, []ocispec.Descriptor{layerDesc}, opts)
+	if err != nil {
+		t.Fatal("Oras.Pack() error =", err)
+	}
+	if got := manifestDesc.Digest.Algorithm(); got != digest.SHA512 {
+		t.Errorf("Oras.Pack() digest algorithm = %v, want %v", got, digest.SHA512)
+	}
+
+	expectedConfigBytes := []byte("{}")
+	expectedConfig := ocispec.Descriptor{
+		MediaType: spec.MediaTypeEmptyJSON,
+		Digest:    digest.SHA512.FromBytes(expectedConfigBytes),
+		Size:      int64(len(expectedConfigBytes)),
+	}
+	expectedManifest := spec.Manifest{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2, // historical value. does not pertain to OCI or docker version
+		},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Config:       expectedConfig,
+		Layers:       []ocispec.Descriptor{layerDesc},
+	}
+	expectedManifestBytes, err := json.Marshal(expectedManifest)
+	if err != nil {
+		t.Fatal("failed to marshal manifest:", err)
+	}
+
+	// test manifest
+	got, err := content.FetchAll(ctx, s, manifestDesc)
+	if err != nil {
+		t.Fatal("content.FetchAll() error =", err)
+	}
+	if !bytes.Equal(got, expectedManifestBytes) {
+		t.Errorf("content.FetchAll() = %v, want %v", got, expectedManifestBytes)
+	}
+
+	// test config
+	exists, err := s.Exists(ctx, expectedConfig)
+	if err != nil {
+		t.Fatal("Store.Exists() error =", err)
+	}
+	if !exists {
+		t.Errorf("Store.Exists() = %v, want %v", exists, true)
+	}
+
+	// test PackArtifact
+	artifactDesc, err := PackArtifact(ctx, s, artifactType, nil, PackArtifactOptions{
+		DigestAlgorithm: digest.SHA512,
+	})
+	if err != nil {
+		t.Fatal("Oras.PackArtifact() error =", err)
+	}
+	if got := artifactDesc.Digest.Algorithm(); got != digest.SHA512 {
+		t.Errorf("Oras.PackArtifact() digest algorithm = %v, want %v", got, digest.SHA512)
+	}
+	if _, err := content.FetchAll(ctx, s, artifactDesc); err != nil {
+		t.Fatal("content.FetchAll() error =", err)
+	}
+}
+
+func Test_Pack_UnsupportedDigestAlgorithm(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	opts := PackOptions{
+		DigestAlgorithm: digest.Algorithm("unknown"),
+	}
+	_, err := Pack(ctx, s, nil, opts)
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Oras.Pack() error = %v, wantErr %v", err, errdef.ErrUnsupported)
+	}
+
+	_, err = PackArtifact(ctx, s, "application/vnd.test", nil, PackArtifactOptions{
+		DigestAlgorithm: digest.Algorithm("unknown"),
+	})
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Oras.PackArtifact() error = %v, wantErr %v", err, errdef.ErrUnsupported)
+	}
+}
+
+func Test_Pack_InvalidDescriptor(t *testing.T) {
+	layer := content.NewDescriptorFromBytes("test", []byte("hello world"))
+	tests := []struct {
+		name   string
+		layers []ocispec.Descriptor
+		opts   PackOptions
+	}{
+		{
+			name:   "missing media type",
+			layers: []ocispec.Descriptor{{Digest: layer.Digest, Size: layer.Size}},
+		},
+		{
+			name:   "missing digest",
+			layers: []ocispec.Descriptor{{MediaType: "test", Size: layer.Size}},
+		},
+		{
+			name:   "malformed digest",
+			layers: []ocispec.Descriptor{{MediaType: "test", Digest: "sha256:abc", Size: layer.Size}},
+		},
+		{
+			name:   "negative size",
+			layers: []ocispec.Descriptor{{MediaType: "test", Digest: layer.Digest, Size: -1}},
+		},
+		{
+			name:   "duplicate layers",
+			layers: []ocispec.Descriptor{layer, layer},
+		},
+		{
+			name: "invalid config",
+			opts: PackOptions{
+				ConfigDescriptor: &ocispec.Descriptor{MediaType: "test"},
+			},
+		},
+		{
+			name: "invalid subject",
+			opts: PackOptions{
+				Subject: &ocispec.Descriptor{Digest: layer.Digest, Size: layer.Size},
+			},
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := memory.New()
+			_, err := Pack(ctx, s, tt.layers, tt.opts)
+			if !errors.Is(err, ErrInvalidDescriptor) {
+				t.Errorf("Oras.Pack() error = %v, wantErr %v", err, ErrInvalidDescriptor)
+			}
+		})
+	}
+
+	// duplicate layers are allowed if specified
+	opts := PackOptions{
+		AllowDuplicateLayers: true,
+	}
+	if _, err := Pack(ctx, memory.New(), []ocispec.Descriptor{layer, layer}, opts); err != nil {
+		t.Errorf("Oras.Pack() error = %v, wantErr %v", err, false)
+	}
+}
+
+func Test_PackArtifact_InvalidDescriptor(t *testing.T) {
+	blob := descriptor.OCIToArtifact(content.NewDescriptorFromBytes("test", []byte("hello world")))
+	tests := []struct {
+		name  string
+		blobs []artifactspec.Descriptor
+		opts  PackArtifactOptions
+	}{
+		{
+			name:  "missing media type",
+			blobs: []artifactspec.Descriptor{{Digest: blob.Digest, Size: blob.Size}},
+		},
+		{
+			name:  "missing digest",
+			blobs: []artifactspec.Descriptor{{MediaType: "test", Size: blob.Size}},
+		},
+		{
+			name:  "negative size",
+			blobs: []artifactspec.Descriptor{{MediaType: "test", Digest: blob.Digest, Size: -1}},
+		},
+		{
+			name:  "duplicate blobs",
+			blobs: []artifactspec.Descriptor{blob, blob},
+		},
+		{
+			name: "invalid subject",
+			opts: PackArtifactOptions{
+				Subject: &artifactspec.Descriptor{MediaType: "test", Size: blob.Size},
+			},
+		},
+	}
+	ctx := context.Background()
+	artifactType := "application/vnd.test"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := memory.New()
+			_, err := PackArtifact(ctx, s, artifactType, tt.blobs, tt.opts)
+			if !errors.Is(err, ErrInvalidDescriptor) {
+				t.Errorf("Oras.PackArtifact() error = %v, wantErr %v", err, ErrInvalidDescriptor)
+			}
+		})
+	}
+
+	// duplicate blobs are allowed if specified
+	opts := PackArtifactOptions{
+		AllowDuplicateBlobs: true,
+	}
+	if _, err := PackArtifact(ctx, memory.New(), artifactType, []artifactspec.Descriptor{blob, blob}, opts); err != nil {
+		t.Errorf("Oras.PackArtifact() error = %v, wantErr %v", err, false)
+	}
+}
+
+func Test_PackIndex_Default(t *testing.T) {
+	s := memory.New()
+
+	// prepare test content
+	manifest := []byte(`{"layers":[]}`)
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+
+	// test PackIndex
+	ctx := context.Background()
+	indexDesc, err := PackIndex(ctx, s, []ocispec.Descriptor{manifestDesc}, PackIndexOptions{})
+	if err != nil {
+		t.Fatal("Oras.PackIndex() error =", err)
+	}
+
+	expectedIndex := ocispec.Index{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2, // historical value. does not pertain to OCI or docker version
+		},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{manifestDesc},
+	}
+	expectedIndexBytes, err := json.Marshal(expectedIndex)
+	if err != nil {
+		t.Fatal("failed to marshal index:", err)
+	}
+	got, err := content.FetchAll(ctx, s, indexDesc)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	if !bytes.Equal(got, expectedIndexBytes) {
+		t.Errorf("Store.Fetch() = %s, want %s", got, expectedIndexBytes)
+	}
+	if want := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, expectedIndexBytes); !reflect.DeepEqual(indexDesc, want) {
+		t.Errorf("Oras.PackIndex() = %v, want %v", indexDesc, want)
+	}
+}
+
+func Test_PackIndex_WithSubjectAndArtifactType(t *testing.T) {
+	s := memory.New()
+
+	// prepare test content
+	subjectManifest := []byte(`{"layers":[]}`)
+	subjectDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(subjectManifest),
+		Size:      int64(len(subjectManifest)),
+	}
+	attestation := []byte(`{"layers":[{"mediaType":"test","digest":"sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9","size":11}]}`)
+	attestationDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(attestation),
+		Size:      int64(len(attestation)),
+	}
+	artifactType := "application/vnd.test.bundle"
+	annotations := map[string]string{"foo": "bar"}
+
+	// test PackIndex
+	ctx := context.Background()
+	opts := PackIndexOptions{
+		IndexAnnotations: annotations,
+		Subject:          &subjectDesc,
+		ArtifactType:     artifactType,
+	}
+	indexDesc, err := PackIndex(ctx, s, []ocispec.Descriptor{attestationDesc}, opts)
+	if err != nil {
+		t.Fatal("Oras.PackIndex() error =", err)
+	}
+
+	expectedIndex := spec.Index{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2, // historical value. does not pertain to OCI or docker version
+		},
+		MediaType:    ocispec.MediaTypeImageIndex,
+		ArtifactType: artifactType,
+		Manifests:    []ocispec.Descriptor{attestationDesc},
+		Subject:      &subjectDesc,
+		Annotations:  annotations,
+	}
+	expectedIndexBytes, err := json.Marshal(expectedIndex)
+	if err != nil {
+		t.Fatal("failed to marshal index:", err)
+	}
+	got, err := content.FetchAll(ctx, s, indexDesc)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	if !bytes.Equal(got, expectedIndexBytes) {
+		t.Errorf("Store.Fetch() = %s, want %s", got, expectedIndexBytes)
+	}
+
+	// test successors
+	successors, err := content.Successors(ctx, s, indexDesc)
+	if err != nil {
+		t.Fatal("content.Successors() error =", err)
+	}
+	if want := []ocispec.Descriptor{attestationDesc, subjectDesc}; !reflect.DeepEqual(successors, want) {
+		t.Errorf("content.Successors() = %v, want %v", successors, want)
+	}
+
+	// test subject
+	predecessors, err := s.Predecessors(ctx, subjectDesc)
+	if err != nil {
+		t.Fatal("Store.Predecessors() error =", err)
+	}
+	if want := []ocispec.Descriptor{indexDesc}; !reflect.DeepEqual(predecessors, want) {
+		t.Errorf("Store.Predecessors() = %v, want %v", predecessors, want)
+	}
+}
+
+func Test_PackIndex_InvalidDescriptor(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+	manifests := []ocispec.Descriptor{
+		{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Digest:    "sha256:invalid",
+			Size:      2,
+		},
+	}
+	_, err := PackIndex(ctx, s, manifests, PackIndexOptions{})
+	if !errors.Is(err, ErrInvalidDescriptor) {
+		t.Errorf("Oras.PackIndex() error = %v, wantErr %v", err, ErrInvalidDescriptor)
+	}
+}
+
 func Test_PackArtifact_Default(t *testing.T) {
 	s := memory.New()
 
@@ -432,3 +921,96 @@ func Test_PackArtifact_InvalidDateTimeFormat(t *testing.T) {
 		t.Errorf("Oras.Pack() error = %v, wantErr = %v", err, ErrInvalidDateTimeFormat)
 	}
 }
+
+func Test_PackArtifact_ExcludeTimestamp(t *testing.T) {
+	blob := []byte("hello world")
+	blobs := []artifactspec.Descriptor{
+		{
+			MediaType: "test",
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		},
+	}
+	artifactType := "application/vnd.test"
+	opts := PackArtifactOptions{
+		ManifestAnnotations: map[string]string{"foo": "bar"},
+		ExcludeTimestamp:    true,
+	}
+
+	ctx := context.Background()
+	s := memory.New()
+	manifestDesc, err := PackArtifact(ctx, s, artifactType, blobs, opts)
+	if err != nil {
+		t.Fatal("Oras.PackArtifact() error =", err)
+	}
+	manifestBytes, err := content.FetchAll(ctx, s, manifestDesc)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	var manifest artifactspec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		t.Fatal("error decoding manifest, error =", err)
+	}
+	if _, ok := manifest.Annotations[artifactspec.AnnotationArtifactCreated]; ok {
+		t.Errorf("Annotation %s = %v, want none", artifactspec.AnnotationArtifactCreated, manifest.Annotations)
+	}
+
+	// packing again should generate an identical manifest
+	gotDesc, err := PackArtifact(ctx, memory.New(), artifactType, blobs, opts)
+	if err != nil {
+		t.Fatal("Oras.PackArtifact() error =", err)
+	}
+	if !reflect.DeepEqual(gotDesc, manifestDesc) {
+		t.Errorf("Oras.PackArtifact() = %v, want %v", gotDesc, manifestDesc)
+	}
+}
+
+// emptyJSONPushCounter counts the pushes of the empty JSON blob.
+type emptyJSONPushCounter struct {
+	content.Storage
+	pushed int
+}
+
+func (c *emptyJSONPushCounter) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	if expected.Digest == digest.FromBytes([]byte("{}")) {
+		c.pushed++
+	}
+	return c.Storage.Push(ctx, expected, content)
+}
+
+func Test_Pack_EmptyJSONTracker(t *testing.T) {
+	ctx := context.Background()
+	tracker := &EmptyJSONTracker{}
+	opts := PackOptions{
+		ArtifactType:     "application/vnd.test",
+		EmptyJSONTracker: tracker,
+	}
+
+	s := &emptyJSONPushCounter{Storage: memory.New()}
+	for i := 0; i < 3; i++ {
+		layer := []byte(fmt.Sprintf("layer %d", i))
+		layerDesc := content.NewDescriptorFromBytes("test", layer)
+		if _, err := Pack(ctx, s, []ocispec.Descriptor{layerDesc}, opts); err != nil {
+			t.Fatal("Oras.Pack() error =", err)
+		}
+	}
+	if want := 1; s.pushed != want {
+		t.Errorf("empty JSON pushed %d times, want %d", s.pushed, want)
+	}
+
+	// the blob should be pushed to other targets
+	other := &emptyJSONPushCounter{Storage: memory.New()}
+	if _, err := Pack(ctx, other, nil, opts); err != nil {
+		t.Fatal("Oras.Pack() error =", err)
+	}
+	if want := 1; other.pushed != want {
+		t.Errorf("empty JSON pushed %d times, want %d", other.pushed, want)
+	}
+	exists, err := other.Exists(ctx, content.DescriptorEmptyJSON)
+	if err != nil {
+		t.Fatal("Store.Exists() error =", err)
+	}
+	if !exists {
+		t.Errorf("Store.Exists() = %v, want %v", exists, true)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ErrQuotaExceeded is returned when copying a node would exceed
+// CopyGraphOptions.MaxTotalBytes.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// QuotaExceededError describes the abort of a copy exceeding
+// CopyGraphOptions.MaxTotalBytes. It matches ErrQuotaExceeded.
+type QuotaExceededError struct {
+	// Descriptor is the descriptor of the node that would exceed the quota.
+	Descriptor ocispec.Descriptor
+	// Limit is the quota in bytes.
+	Limit int64
+	// Transferred is the number of bytes transferred before the abort.
+	Transferred int64
+}
+
+// Error returns the error message of the abort.
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s: %s: %v: %d of %d bytes transferred", e.Descriptor.Digest, e.Descriptor.MediaType, ErrQuotaExceeded, e.Transferred, e.Limit)
+}
+
+// Unwrap returns ErrQuotaExceeded.
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// transferQuota tracks the bytes transferred against a limit.
+// A nil *transferQuota does not limit the transfer.
+type transferQuota struct {
+	limit       int64
+	reserved    int64 // accessed atomically
+	transferred int64 // accessed atomically
+}
+
+// newTransferQuota returns a quota of limit bytes, or nil if limit is not
+// positive.
+func newTransferQuota(limit int64) *transferQuota {
+	if limit <= 0 {
+		return nil
+	}
+	return &transferQuota{limit: limit}
+}
+
+// transfer reserves the size of desc before calling fn, and accounts the size
+// as transferred if fn succeeds.
+// Returns a *QuotaExceededError without calling fn if the reservation would
+// exceed the limit.
+func (q *transferQuota) transfer(desc ocispec.Descriptor, fn func() error) error {
+	if q == nil {
+		return fn()
+	}
+	for {
+		reserved := atomic.LoadInt64(&q.reserved)
+		if reserved+desc.Size > q.limit {
+			return &QuotaExceededError{
+				Descriptor:  desc,
+				Limit:       q.limit,
+				Transferred: atomic.LoadInt64(&q.transferred),
+			}
+		}
+		if atomic.CompareAndSwapInt64(&q.reserved, reserved, reserved+desc.Size) {
+			break
+		}
+	}
+	if err := fn(); err != nil {
+		atomic.AddInt64(&q.reserved, -desc.Size)
+		return err
+	}
+	atomic.AddInt64(&q.transferred, desc.Size)
+	return nil
+}
@@ -0,0 +1,234 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/registry"
+)
+
+// ReadOnlyReferrerStorage represents a read-only storage that supports the
+// Referrers API.
+type ReadOnlyReferrerStorage interface {
+	content.ReadOnlyStorage
+	registry.ReferrerFinder
+}
+
+// FindReferrersRecursive lists the referrers of the given index and of each
+// of its child manifests, with the artifact type if specified.
+// Nested indexes are resolved recursively. If desc is not an index, only the
+// referrers of desc itself are listed.
+//
+// The result is keyed by the digest of the subject each referrer attaches to.
+// A referrer listed under multiple subjects is reported only once, under the
+// first subject it is found for, where the index is visited before its
+// children and children are visited in the order of the index.
+func FindReferrersRecursive(ctx context.Context, finder ReadOnlyReferrerStorage, desc ocispec.Descriptor, artifactType string) (map[digest.Digest][]ocispec.Descriptor, error) {
+	res := make(map[digest.Digest][]ocispec.Descriptor)
+	visitedSubjects := make(map[descriptor.Descriptor]bool)
+	visitedReferrers := make(map[descriptor.Descriptor]bool)
+
+	var find func(subject ocispec.Descriptor) error
+	find = func(subject ocispec.Descriptor) error {
+		key := descriptor.FromOCI(subject)
+		if visitedSubjects[key] {
+			return nil
+		}
+		visitedSubjects[key] = true
+
+		if err := finder.Referrers(ctx, subject, artifactType, func(referrers []ocispec.Descriptor) error {
+			for _, referrer := range referrers {
+				key := descriptor.FromOCI(referrer)
+				if visitedReferrers[key] {
+					continue
+				}
+				visitedReferrers[key] = true
+				res[subject.Digest] = append(res[subject.Digest], referrer)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		switch subject.MediaType {
+		case docker.MediaTypeManifestList, ocispec.MediaTypeImageIndex:
+			indexJSON, err := fetchManifest(ctx, finder, subject)
+			if err != nil {
+				return err
+			}
+			// the subject of the index, if any, is not a child
+			var index ocispec.Index
+			if err := json.Unmarshal(indexJSON, &index); err != nil {
+				return fmt.Errorf("%s: %s: failed to decode index: %w", subject.Digest, subject.MediaType, err)
+			}
+			for _, manifest := range index.Manifests {
+				if err := find(manifest); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := find(desc); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// PushReferrerOptions contains parameters for oras.PushReferrer.
+type PushReferrerOptions struct {
+	// ForceReferrersTag updates the referrers tag of the subject even if the
+	// target does not report the lack of the Referrers API, e.g. for storages
+	// which are not registries such as OCI layouts.
+	ForceReferrersTag bool
+}
+
+// capabilityPinger probes the capabilities of a registry.
+type capabilityPinger interface {
+	Ping(ctx context.Context) (registry.Capabilities, error)
+}
+
+// PushReferrer pushes the manifest described by desc and read from r to the
+// target.
+// If the manifest has a subject and the target reports that it does not
+// support the Referrers API, the referrers tag of the subject is updated to an
+// index listing the existing referrers along with the pushed manifest, so that
+// the referrer can be discovered on the registries without the Referrers API.
+// The previous index of the referrers tag is left untagged in the target.
+//
+// Updating the referrers tag is not atomic: concurrent pushes of referrers of
+// the same subject may drop each other from the index.
+// Reference: https://github.com/opencontainers/distribution-spec/blob/main/spec.md#referrers-tag-schema
+func PushReferrer(ctx context.Context, target Target, desc ocispec.Descriptor, r io.Reader, opts PushReferrerOptions) error {
+	manifestJSON, err := content.ReadAll(r, desc)
+	if err != nil {
+		return err
+	}
+	if err := target.Push(ctx, desc, bytes.NewReader(manifestJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+
+	// the fields of interest are shared by the image manifests and indexes
+	var manifest struct {
+		ArtifactType string              `json:"artifactType,omitempty"`
+		Config       *ocispec.Descriptor `json:"config,omitempty"`
+		Subject      *ocispec.Descriptor `json:"subject,omitempty"`
+		Annotations  map[string]string   `json:"annotations,omitempty"`
+	}
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageIndex:
+		if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+			return fmt.Errorf("%s: %s: failed to decode manifest: %w", desc.Digest, desc.MediaType, err)
+		}
+	}
+	if manifest.Subject == nil {
+		return nil
+	}
+
+	if !opts.ForceReferrersTag {
+		pinger, ok := target.(capabilityPinger)
+		if !ok {
+			return nil
+		}
+		caps, err := pinger.Ping(ctx)
+		if err != nil {
+			return err
+		}
+		if caps.ReferrersAPI {
+			return nil
+		}
+	}
+
+	referrer := ocispec.Descriptor{
+		MediaType:    desc.MediaType,
+		Digest:       desc.Digest,
+		Size:         desc.Size,
+		ArtifactType: manifest.ArtifactType,
+		Annotations:  manifest.Annotations,
+	}
+	if referrer.ArtifactType == "" && manifest.Config != nil {
+		referrer.ArtifactType = manifest.Config.MediaType
+	}
+	return addToReferrersTag(ctx, target, *manifest.Subject, referrer)
+}
+
+// addToReferrersTag adds the referrer to the index tagged by the referrers tag
+// of the subject.
+func addToReferrersTag(ctx context.Context, target Target, subject ocispec.Descriptor, referrer ocispec.Descriptor) error {
+	tag := referrersTag(subject.Digest)
+	index := ocispec.Index{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2, // historical value. does not pertain to OCI or docker version
+		},
+		MediaType: ocispec.MediaTypeImageIndex,
+	}
+	existing, err := target.Resolve(ctx, tag)
+	switch {
+	case err == nil:
+		indexJSON, err := fetchManifest(ctx, target, existing)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(indexJSON, &index); err != nil {
+			return fmt.Errorf("%s: %s: failed to decode referrers index: %w", existing.Digest, existing.MediaType, err)
+		}
+		for _, m := range index.Manifests {
+			if m.Digest == referrer.Digest {
+				return nil
+			}
+		}
+	case errors.Is(err, errdef.ErrNotFound):
+	default:
+		return err
+	}
+
+	index.Manifests = append(index.Manifests, referrer)
+	indexJSON, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal referrers index: %w", err)
+	}
+	indexDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, indexJSON)
+	if err := target.Push(ctx, indexDesc, bytes.NewReader(indexJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+	return target.Tag(ctx, indexDesc, tag)
+}
+
+// referrersTag returns the referrers tag of the subject in the form of
+// `<alg>-<ref>`, where `<ref>` is the encoded digest truncated to 64
+// characters.
+// Reference: https://github.com/opencontainers/distribution-spec/blob/main/spec.md#referrers-tag-schema
+func referrersTag(dgst digest.Digest) string {
+	ref := dgst.Encoded()
+	if len(ref) > 64 {
+		ref = ref[:64]
+	}
+	return dgst.Algorithm().String() + "-" + ref
+}
@@ -0,0 +1,289 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/spec"
+	"oras.land/oras-go/v2/registry"
+)
+
+// referrerStorage is a storage serving the referrers of each subject one
+// per page.
+type referrerStorage struct {
+	content.Storage
+	referrers map[digest.Digest][]ocispec.Descriptor
+}
+
+func (s *referrerStorage) Referrers(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func(referrers []ocispec.Descriptor) error) error {
+	for _, referrer := range s.referrers[desc.Digest] {
+		if artifactType != "" && referrer.ArtifactType != artifactType {
+			continue
+		}
+		if err := fn([]ocispec.Descriptor{referrer}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestFindReferrersRecursive(t *testing.T) {
+	storage := &referrerStorage{
+		Storage:   memory.New(),
+		referrers: make(map[digest.Digest][]ocispec.Descriptor),
+	}
+	ctx := context.Background()
+
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := storage.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	pushIndex := func(manifests ...ocispec.Descriptor) ocispec.Descriptor {
+		indexJSON, err := json.Marshal(ocispec.Index{Manifests: manifests})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return push(ocispec.MediaTypeImageIndex, indexJSON)
+	}
+	pushManifest := func(platform string) ocispec.Descriptor {
+		config := push(ocispec.MediaTypeImageConfig, []byte(platform))
+		manifestJSON, err := json.Marshal(ocispec.Manifest{Config: config})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return push(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	referrer := func(name, artifactType string) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(ocispec.MediaTypeArtifactManifest, []byte(name))
+		desc.ArtifactType = artifactType
+		return desc
+	}
+
+	amd64 := pushManifest("amd64")
+	arm64 := pushManifest("arm64")
+	s390x := pushManifest("s390x")
+	nested := pushIndex(s390x)
+	index := pushIndex(amd64, arm64, nested)
+
+	indexSig := referrer("index sig", "application/vnd.sig")
+	amd64Sig := referrer("amd64 sig", "application/vnd.sig")
+	amd64SBOM := referrer("amd64 sbom", "application/vnd.sbom")
+	s390xSig := referrer("s390x sig", "application/vnd.sig")
+	storage.referrers[index.Digest] = []ocispec.Descriptor{indexSig}
+	// indexSig is also listed under amd64 and must be reported only once
+	storage.referrers[amd64.Digest] = []ocispec.Descriptor{amd64Sig, indexSig, amd64SBOM}
+	storage.referrers[s390x.Digest] = []ocispec.Descriptor{s390xSig}
+
+	tests := []struct {
+		name         string
+		desc         ocispec.Descriptor
+		artifactType string
+		want         map[digest.Digest][]ocispec.Descriptor
+	}{
+		{
+			name: "all referrers",
+			desc: index,
+			want: map[digest.Digest][]ocispec.Descriptor{
+				index.Digest: {indexSig},
+				amd64.Digest: {amd64Sig, amd64SBOM},
+				s390x.Digest: {s390xSig},
+			},
+		},
+		{
+			name:         "filter by artifact type",
+			desc:         index,
+			artifactType: "application/vnd.sbom",
+			want: map[digest.Digest][]ocispec.Descriptor{
+				amd64.Digest: {amd64SBOM},
+			},
+		},
+		{
+			name: "non-index subject",
+			desc: amd64,
+			want: map[digest.Digest][]ocispec.Descriptor{
+				amd64.Digest: {amd64Sig, indexSig, amd64SBOM},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := oras.FindReferrersRecursive(ctx, storage, tt.desc, tt.artifactType)
+			if err != nil {
+				t.Fatalf("FindReferrersRecursive() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindReferrersRecursive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// test missing index
+	missing := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, []byte("missing"))
+	if _, err := oras.FindReferrersRecursive(ctx, storage, missing, ""); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("FindReferrersRecursive() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+// pingTarget is a target reporting the given capabilities.
+type pingTarget struct {
+	oras.Target
+	caps registry.Capabilities
+}
+
+func (t *pingTarget) Ping(ctx context.Context) (registry.Capabilities, error) {
+	return t.caps, nil
+}
+
+func TestPushReferrer(t *testing.T) {
+	ctx := context.Background()
+	subjectJSON := []byte(`{"layers":[]}`)
+	subject := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, subjectJSON)
+	generateReferrer := func(artifactType string) (ocispec.Descriptor, []byte) {
+		manifest := spec.Manifest{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: artifactType,
+			Config:       content.DescriptorEmptyJSON,
+			Layers:       []ocispec.Descriptor{},
+			Subject:      &subject,
+			Annotations: map[string]string{
+				"foo": artifactType,
+			},
+		}
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON), manifestJSON
+	}
+	sbomDesc, sbomJSON := generateReferrer("application/vnd.test.sbom")
+	sigDesc, sigJSON := generateReferrer("application/vnd.test.signature")
+	tag := "sha256-" + subject.Digest.Encoded()
+
+	// test updating the referrers tag without the Referrers API
+	target := &pingTarget{Target: memory.New()}
+	if err := target.Push(ctx, subject, bytes.NewReader(subjectJSON)); err != nil {
+		t.Fatal("Target.Push() error =", err)
+	}
+	if err := oras.PushReferrer(ctx, target, sbomDesc, bytes.NewReader(sbomJSON), oras.PushReferrerOptions{}); err != nil {
+		t.Fatal("oras.PushReferrer() error =", err)
+	}
+	if err := oras.PushReferrer(ctx, target, sigDesc, bytes.NewReader(sigJSON), oras.PushReferrerOptions{}); err != nil {
+		t.Fatal("oras.PushReferrer() error =", err)
+	}
+	// pushing again does not duplicate the referrer
+	if err := oras.PushReferrer(ctx, target, sbomDesc, bytes.NewReader(sbomJSON), oras.PushReferrerOptions{}); err != nil {
+		t.Fatal("oras.PushReferrer() error =", err)
+	}
+	exists, err := target.Exists(ctx, sigDesc)
+	if err != nil {
+		t.Fatal("Target.Exists() error =", err)
+	}
+	if !exists {
+		t.Errorf("Target.Exists() = %v, want %v", exists, true)
+	}
+	_, indexJSON, err := oras.FetchBytes(ctx, target, tag, oras.DefaultFetchBytesOptions)
+	if err != nil {
+		t.Fatal("oras.FetchBytes() error =", err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		t.Fatal(err)
+	}
+	if want := ocispec.MediaTypeImageIndex; index.MediaType != want {
+		t.Errorf("index.MediaType = %v, want %v", index.MediaType, want)
+	}
+	want := []ocispec.Descriptor{
+		{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			Digest:       sbomDesc.Digest,
+			Size:         sbomDesc.Size,
+			ArtifactType: "application/vnd.test.sbom",
+			Annotations:  map[string]string{"foo": "application/vnd.test.sbom"},
+		},
+		{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			Digest:       sigDesc.Digest,
+			Size:         sigDesc.Size,
+			ArtifactType: "application/vnd.test.signature",
+			Annotations:  map[string]string{"foo": "application/vnd.test.signature"},
+		},
+	}
+	if !reflect.DeepEqual(index.Manifests, want) {
+		t.Errorf("index.Manifests = %v, want %v", index.Manifests, want)
+	}
+
+	// test skipping the referrers tag with the Referrers API
+	target = &pingTarget{
+		Target: memory.New(),
+		caps:   registry.Capabilities{ReferrersAPI: true},
+	}
+	if err := oras.PushReferrer(ctx, target, sbomDesc, bytes.NewReader(sbomJSON), oras.PushReferrerOptions{}); err != nil {
+		t.Fatal("oras.PushReferrer() error =", err)
+	}
+	if _, err := target.Resolve(ctx, tag); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Target.Resolve() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+
+	// test skipping the referrers tag for targets without capabilities
+	store := memory.New()
+	if err := oras.PushReferrer(ctx, store, sbomDesc, bytes.NewReader(sbomJSON), oras.PushReferrerOptions{}); err != nil {
+		t.Fatal("oras.PushReferrer() error =", err)
+	}
+	if _, err := store.Resolve(ctx, tag); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Target.Resolve() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+
+	// test forcing the referrers tag
+	opts := oras.PushReferrerOptions{
+		ForceReferrersTag: true,
+	}
+	if err := oras.PushReferrer(ctx, store, sigDesc, bytes.NewReader(sigJSON), opts); err != nil {
+		t.Fatal("oras.PushReferrer() error =", err)
+	}
+	_, indexJSON, err = oras.FetchBytes(ctx, store, tag, oras.DefaultFetchBytesOptions)
+	if err != nil {
+		t.Fatal("oras.FetchBytes() error =", err)
+	}
+	index = ocispec.Index{}
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(index.Manifests, want[1:]) {
+		t.Errorf("index.Manifests = %v, want %v", index.Manifests, want[1:])
+	}
+
+	// test pushing a manifest without subject
+	manifestJSON := []byte(`{"layers":[]}`)
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+	if err := oras.PushReferrer(ctx, store, manifestDesc, bytes.NewReader(manifestJSON), opts); err != nil {
+		t.Fatal("oras.PushReferrer() error =", err)
+	}
+}
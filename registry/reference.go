@@ -42,6 +42,22 @@ var (
 	tagRegexp = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
 )
 
+// Errors of the classes of the violations found in validating references.
+// All of them wrap errdef.ErrInvalidReference.
+var (
+	// ErrInvalidRegistry is returned for a registry not being a host with an
+	// optional port.
+	ErrInvalidRegistry = fmt.Errorf("%w: invalid registry", errdef.ErrInvalidReference)
+	// ErrInvalidRepository is returned for a repository missing or not
+	// matching the repository grammar.
+	ErrInvalidRepository = fmt.Errorf("%w: invalid repository", errdef.ErrInvalidReference)
+	// ErrInvalidTag is returned for a tag not matching the tag grammar.
+	ErrInvalidTag = fmt.Errorf("%w: invalid tag", errdef.ErrInvalidReference)
+	// ErrInvalidDigest is returned for a malformed digest, or a digest of an
+	// unavailable algorithm.
+	ErrInvalidDigest = fmt.Errorf("%w: invalid digest", errdef.ErrInvalidReference)
+)
+
 // Reference references to a descriptor in the registry.
 type Reference struct {
 	// Registry is the name of the registry.
@@ -94,7 +110,7 @@ func ParseReference(artifact string) (Reference, error) {
 	parts := strings.SplitN(artifact, "/", 2)
 	if len(parts) == 1 {
 		// Invalid Form
-		return Reference{}, fmt.Errorf("%w: missing repository", errdef.ErrInvalidReference)
+		return Reference{}, fmt.Errorf("%w: missing repository", ErrInvalidRepository)
 	}
 	registry, path := parts[0], parts[1]
 
@@ -129,6 +145,75 @@ func ParseReference(artifact string) (Reference, error) {
 	return res, nil
 }
 
+// ParseReferenceOptions contains parameters for ParseReferenceWithOptions.
+type ParseReferenceOptions struct {
+	// DockerHubConventions applies the Docker Hub conventions on parsing:
+	// if the first component of the artifact is not a registry host, i.e. it
+	// contains neither "." nor ":" and is not "localhost", "docker.io" is used
+	// as the registry and the first component is treated as a part of the
+	// repository. Besides, repositories of a single component on "docker.io"
+	// are prefixed with "library/".
+	// If DockerHubConventions is not set, the first component is always
+	// treated as the registry, as ParseReference does.
+	DockerHubConventions bool
+	// Strict requires the first component of the artifact to be a registry
+	// host, i.e. it contains "." or ":", or is "localhost". Otherwise, an
+	// error wrapping errdef.ErrInvalidReference is returned for the ambiguous
+	// registry, instead of treating the component as the registry or
+	// defaulting to Docker Hub.
+	// Strict takes precedence over DockerHubConventions.
+	Strict bool
+}
+
+// ParseReferenceWithOptions parses a string (artifact) into an `artifact
+// reference` as ParseReference does, with the given options controlling how
+// the registry is determined.
+func ParseReferenceWithOptions(artifact string, opts ParseReferenceOptions) (Reference, error) {
+	registry, path, found := strings.Cut(artifact, "/")
+	switch {
+	case opts.Strict:
+		if !found || !isRegistryHost(registry) {
+			return Reference{}, fmt.Errorf("%w: ambiguous registry %q", errdef.ErrInvalidReference, registry)
+		}
+	case opts.DockerHubConventions:
+		if !found || !isRegistryHost(registry) {
+			artifact = dockerHubRegistry + "/" + artifact
+			registry, path, _ = strings.Cut(artifact, "/")
+		}
+		if registry == dockerHubRegistry && !strings.Contains(repositoryOf(path), "/") {
+			artifact = registry + "/library/" + path
+		}
+	}
+	return ParseReference(artifact)
+}
+
+// ValidateReference validates the syntax of the reference string, including
+// the registry, the repository, and the tag or the digest, without resolving
+// it. The returned error is one of ErrInvalidRegistry, ErrInvalidRepository,
+// ErrInvalidTag, and ErrInvalidDigest, or wraps one of them.
+func ValidateReference(artifact string) error {
+	_, err := ParseReference(artifact)
+	return err
+}
+
+// dockerHubRegistry is the registry name of Docker Hub.
+const dockerHubRegistry = "docker.io"
+
+// isRegistryHost returns true if the component is recognized as a registry
+// host rather than a part of the repository.
+func isRegistryHost(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
+
+// repositoryOf returns the repository part of the path by stripping the tag
+// or the digest.
+func repositoryOf(path string) string {
+	if index := strings.IndexAny(path, ":@"); index != -1 {
+		return path[:index]
+	}
+	return path
+}
+
 // Validate validates the entire reference.
 func (r Reference) Validate() error {
 	err := r.ValidateRegistry()
@@ -146,7 +231,7 @@ func (r Reference) Validate() error {
 func (r Reference) ValidateRegistry() error {
 	uri, err := url.ParseRequestURI("dummy://" + r.Registry)
 	if err != nil || uri.Host != r.Registry {
-		return fmt.Errorf("%w: invalid registry", errdef.ErrInvalidReference)
+		return ErrInvalidRegistry
 	}
 	return nil
 }
@@ -154,7 +239,7 @@ func (r Reference) ValidateRegistry() error {
 // ValidateRepository validates the repository.
 func (r Reference) ValidateRepository() error {
 	if !repositoryRegexp.MatchString(r.Repository) {
-		return fmt.Errorf("%w: invalid repository", errdef.ErrInvalidReference)
+		return ErrInvalidRepository
 	}
 	return nil
 }
@@ -164,11 +249,15 @@ func (r Reference) ValidateReference() error {
 	if r.Reference == "" {
 		return nil
 	}
-	if _, err := r.Digest(); err == nil {
+	if strings.Contains(r.Reference, ":") {
+		// tags never contain colons, and thus the reference is a digest
+		if _, err := r.Digest(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDigest, err)
+		}
 		return nil
 	}
 	if !tagRegexp.MatchString(r.Reference) {
-		return fmt.Errorf("%w: invalid tag", errdef.ErrInvalidReference)
+		return ErrInvalidTag
 	}
 	return nil
 }
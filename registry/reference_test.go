@@ -17,9 +17,12 @@ package registry
 
 import (
 	_ "crypto/sha256"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
+
+	"oras.land/oras-go/v2/errdef"
 )
 
 // For a definition of what a "valid form [ABCD]" means, see reference.go.
@@ -121,3 +124,149 @@ func TestParseReferenceUglies(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateReference(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr error
+	}{
+		{
+			name: "tag reference",
+			raw:  "localhost:5000/hello-world:v1",
+		},
+		{
+			name: "digest reference",
+			raw:  "localhost:5000/hello-world@sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+		},
+		{
+			name:    "invalid registry",
+			raw:     "localhost:v1/hello-world",
+			wantErr: ErrInvalidRegistry,
+		},
+		{
+			name:    "missing repository",
+			raw:     "localhost",
+			wantErr: ErrInvalidRepository,
+		},
+		{
+			name:    "invalid repository",
+			raw:     "localhost/UPPERCASE/test",
+			wantErr: ErrInvalidRepository,
+		},
+		{
+			name:    "invalid tag",
+			raw:     "localhost/hello-world:.v1",
+			wantErr: ErrInvalidTag,
+		},
+		{
+			name:    "truncated digest",
+			raw:     "localhost/hello-world@sha256:b94d27b9934d3e08",
+			wantErr: ErrInvalidDigest,
+		},
+		{
+			name:    "unknown digest algorithm",
+			raw:     "localhost/hello-world@foo:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+			wantErr: ErrInvalidDigest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReference(tt.raw)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("ValidateReference() error = %v, wantErr nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateReference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !errors.Is(err, errdef.ErrInvalidReference) {
+				t.Errorf("ValidateReference() error = %v, wantErr %v", err, errdef.ErrInvalidReference)
+			}
+		})
+	}
+}
+
+func TestParseReferenceWithOptions(t *testing.T) {
+	dgst := "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	tests := []struct {
+		name     string
+		artifact string
+		opts     ParseReferenceOptions
+		want     Reference
+		wantErr  bool
+	}{
+		{
+			name:     "no options",
+			artifact: "hello/world:v1",
+			want:     Reference{Registry: "hello", Repository: "world", Reference: "v1"},
+		},
+		{
+			name:     "docker hub conventions with registry host",
+			artifact: "localhost:5000/hello:v1",
+			opts:     ParseReferenceOptions{DockerHubConventions: true},
+			want:     Reference{Registry: "localhost:5000", Repository: "hello", Reference: "v1"},
+		},
+		{
+			name:     "docker hub conventions with localhost",
+			artifact: "localhost/hello",
+			opts:     ParseReferenceOptions{DockerHubConventions: true},
+			want:     Reference{Registry: "localhost", Repository: "hello"},
+		},
+		{
+			name:     "docker hub conventions with user repository",
+			artifact: "hello/world:v1",
+			opts:     ParseReferenceOptions{DockerHubConventions: true},
+			want:     Reference{Registry: "docker.io", Repository: "hello/world", Reference: "v1"},
+		},
+		{
+			name:     "docker hub conventions with official repository",
+			artifact: "hello-world@" + dgst,
+			opts:     ParseReferenceOptions{DockerHubConventions: true},
+			want:     Reference{Registry: "docker.io", Repository: "library/hello-world", Reference: dgst},
+		},
+		{
+			name:     "docker hub conventions with explicit docker.io",
+			artifact: "docker.io/hello-world:latest",
+			opts:     ParseReferenceOptions{DockerHubConventions: true},
+			want:     Reference{Registry: "docker.io", Repository: "library/hello-world", Reference: "latest"},
+		},
+		{
+			name:     "strict with registry host",
+			artifact: "registry.example:8443/hello/world:v1",
+			opts:     ParseReferenceOptions{Strict: true},
+			want:     Reference{Registry: "registry.example:8443", Repository: "hello/world", Reference: "v1"},
+		},
+		{
+			name:     "strict with ambiguous registry",
+			artifact: "hello/world:v1",
+			opts:     ParseReferenceOptions{Strict: true, DockerHubConventions: true},
+			wantErr:  true,
+		},
+		{
+			name:     "strict without registry",
+			artifact: "hello-world",
+			opts:     ParseReferenceOptions{Strict: true},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseReferenceWithOptions(tt.artifact, tt.opts)
+			if tt.wantErr {
+				if !errors.Is(err, errdef.ErrInvalidReference) {
+					t.Errorf("ParseReferenceWithOptions() error = %v, wantErr %v", err, errdef.ErrInvalidReference)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseReferenceWithOptions() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseReferenceWithOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
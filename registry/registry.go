@@ -39,6 +39,18 @@ type Registry interface {
 	Repository(ctx context.Context, name string) (Repository, error)
 }
 
+// Capabilities describes the features supported by a remote registry, as
+// discovered by probing its APIs.
+type Capabilities struct {
+	// APIVersion is the API version reported by the registry in the
+	// `Docker-Distribution-API-Version` header, e.g. "registry/2.0".
+	// It is empty if the header is absent.
+	APIVersion string
+	// ReferrersAPI indicates whether the Referrers API is supported.
+	// Reference: https://github.com/oras-project/artifacts-spec/blob/main/manifest-referrers-api.md
+	ReferrersAPI bool
+}
+
 // Repositories lists the name of repositories available in the registry.
 func Repositories(ctx context.Context, reg Registry) ([]string, error) {
 	var res []string
@@ -18,6 +18,8 @@ package auth
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -25,7 +27,9 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"runtime/debug"
 	"strings"
+	"sync"
 
 	"oras.land/oras-go/v2/registry/remote/internal/errutil"
 )
@@ -33,11 +37,41 @@ import (
 // DefaultClient is the default auth-decorated client.
 var DefaultClient = &Client{
 	Header: http.Header{
-		"User-Agent": {"oras-go"},
+		"User-Agent": {defaultUserAgent},
 	},
 	Cache: DefaultCache,
 }
 
+// orasModulePath is the module path of oras-go.
+const orasModulePath = "oras.land/oras-go/v2"
+
+// defaultUserAgent specifies the default user agent, identifying oras-go and
+// its version if known from the build information.
+// See also SetUserAgent and AppendUserAgent.
+var defaultUserAgent = buildUserAgent()
+
+// buildUserAgent returns the user agent identifying oras-go and its version.
+func buildUserAgent() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "oras-go"
+	}
+	modules := append([]*debug.Module{&info.Main}, info.Deps...)
+	for _, module := range modules {
+		if module.Path != orasModulePath {
+			continue
+		}
+		if module.Replace != nil {
+			module = module.Replace
+		}
+		if module.Version == "" || module.Version == "(devel)" {
+			break
+		}
+		return "oras-go/" + module.Version
+	}
+	return "oras-go"
+}
+
 // maxResponseBytes specifies the default limit on how many response bytes are
 // allowed in the server's response from authorization service servers.
 // A typical response message from authorization service servers is around 1 to
@@ -67,9 +101,33 @@ func StaticCredential(registry string, cred Credential) func(context.Context, st
 type Client struct {
 	// Client is the underlying HTTP client used to access the remote
 	// server.
-	// If nil, http.DefaultClient is used.
+	// If nil, http.DefaultClient is used, or a client with the TLS
+	// configuration of InsecureSkipVerify and RootCAs if any is set.
+	// A custom Client takes precedence over InsecureSkipVerify and RootCAs,
+	// which are ignored in that case.
 	Client *http.Client
 
+	// InsecureSkipVerify controls whether to skip verifying the certificate
+	// chain and the host name of the TLS certificates presented by the
+	// remote servers, e.g. the self-signed certificates of development
+	// registries.
+	// WARNING: it accepts any certificate presented by the servers, leaving
+	// the connections, and thus the credentials and the contents sent over
+	// them, open to man-in-the-middle attacks. It should be used for testing
+	// only. For registries with private certificate authorities, set RootCAs
+	// instead.
+	// It is effective only if Client is nil, and should be set before the
+	// first request.
+	InsecureSkipVerify bool
+
+	// RootCAs specifies the set of root certificate authorities used to
+	// verify the TLS certificates presented by the remote servers, e.g. the
+	// private certificate authorities of internal registries.
+	// If nil, the root certificate authorities of the host are used.
+	// It is effective only if Client is nil, and should be set before the
+	// first request.
+	RootCAs *x509.CertPool
+
 	// Header contains the custom headers to be added to each request.
 	Header http.Header
 
@@ -96,15 +154,47 @@ type Client struct {
 	// - https://docs.docker.com/registry/spec/auth/jwt/
 	// - https://docs.docker.com/registry/spec/auth/oauth/
 	ForceAttemptOAuth2 bool
+
+	// PrefetchToken controls whether to fetch the bearer token of the hinted
+	// scopes before sending a request, if no token of the scopes is cached,
+	// with the parameters of the last bearer challenge of the registry.
+	// It saves the round trip of the challenge for each new set of scopes,
+	// e.g. when the scopes of an operation are hinted up front by WithScopes
+	// or WithScopesForHost. However, an extra token is fetched if the hinted
+	// scopes do not cover the scope challenged by the registry.
+	// It is effective only if Cache is set, and the scheme of the registry is
+	// known to be bearer.
+	PrefetchToken bool
+
+	// challenges remembers the parameters of the last bearer challenge of
+	// each registry, so that the tokens of new scopes are fetched before
+	// sending the requests.
+	challenges sync.Map // map[string]map[string]string
+
+	// tlsClient is the HTTP client configured by InsecureSkipVerify and
+	// RootCAs, created on the first request.
+	tlsClient     *http.Client
+	tlsClientOnce sync.Once
 }
 
 // client returns an HTTP client used to access the remote registry.
 // http.DefaultClient is return if the client is not configured.
 func (c *Client) client() *http.Client {
-	if c.Client == nil {
+	if c.Client != nil {
+		return c.Client
+	}
+	if !c.InsecureSkipVerify && c.RootCAs == nil {
 		return http.DefaultClient
 	}
-	return c.Client
+	c.tlsClientOnce.Do(func() {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: c.InsecureSkipVerify,
+			RootCAs:            c.RootCAs,
+		}
+		c.tlsClient = &http.Client{Transport: transport}
+	})
+	return c.tlsClient
 }
 
 // send adds headers to the request and sends the request to the remote server.
@@ -112,6 +202,9 @@ func (c *Client) send(req *http.Request) (*http.Response, error) {
 	for key, values := range c.Header {
 		req.Header[key] = append(req.Header[key], values...)
 	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", defaultUserAgent)
+	}
 	return c.client().Do(req)
 }
 
@@ -133,6 +226,8 @@ func (c *Client) cache() Cache {
 }
 
 // SetUserAgent sets the user agent for all out-going requests.
+// If not set, a default user agent identifying oras-go and its version is
+// sent.
 func (c *Client) SetUserAgent(userAgent string) {
 	if c.Header == nil {
 		c.Header = http.Header{}
@@ -140,6 +235,19 @@ func (c *Client) SetUserAgent(userAgent string) {
 	c.Header.Set("User-Agent", userAgent)
 }
 
+// AppendUserAgent appends the product token, e.g. "my-tool/1.0", to the user
+// agent for all out-going requests, following the user agent set by
+// SetUserAgent, or the default one if not set.
+// Note: since DefaultClient is shared, callers should append the product
+// token to their own Client instead of DefaultClient.
+func (c *Client) AppendUserAgent(product string) {
+	userAgent := defaultUserAgent
+	if ua := c.Header.Get("User-Agent"); ua != "" {
+		userAgent = ua
+	}
+	c.SetUserAgent(userAgent + " " + product)
+}
+
 // Do sends the request to the remote server, attempting to resolve
 // authentication if 'Authorization' header is not set.
 // On authentication failure due to bad credential,
@@ -166,9 +274,14 @@ func (c *Client) Do(originalReq *http.Request) (*http.Response, error) {
 				req.Header.Set("Authorization", "Basic "+token)
 			}
 		case SchemeBearer:
-			scopes := GetScopes(ctx)
+			scopes := GetAllScopesForHost(ctx, registry)
 			attemptedKey = strings.Join(scopes, " ")
 			token, err := cache.GetToken(ctx, registry, SchemeBearer, attemptedKey)
+			if err != nil && c.PrefetchToken {
+				// fetch the token of the new scopes in advance to save the
+				// round trip of the challenge.
+				token, err = c.prefetchBearerToken(ctx, registry, attemptedKey, scopes)
+			}
 			if err == nil {
 				req.Header.Set("Authorization", "Bearer "+token)
 			}
@@ -202,8 +315,10 @@ func (c *Client) Do(originalReq *http.Request) (*http.Response, error) {
 	case SchemeBearer:
 		resp.Body.Close()
 
+		c.challenges.Store(registry, params)
+
 		// merge hinted scopes with challenged scopes
-		scopes := GetScopes(ctx)
+		scopes := GetAllScopesForHost(ctx, registry)
 		if scope := params["scope"]; scope != "" {
 			scopes = append(scopes, strings.Split(scope, " ")...)
 			scopes = CleanScopes(scopes)
@@ -252,6 +367,20 @@ func (c *Client) Do(originalReq *http.Request) (*http.Response, error) {
 	return c.send(req)
 }
 
+// prefetchBearerToken fetches the bearer token of the scopes for the registry
+// with the parameters of the last bearer challenge of the registry, and caches
+// it with the key.
+func (c *Client) prefetchBearerToken(ctx context.Context, registry, key string, scopes []string) (string, error) {
+	value, ok := c.challenges.Load(registry)
+	if !ok {
+		return "", errors.New("no bearer challenge")
+	}
+	params := value.(map[string]string)
+	return c.cache().Set(ctx, registry, SchemeBearer, key, func(ctx context.Context) (string, error) {
+		return c.fetchBearerToken(ctx, registry, params["realm"], params["service"], scopes)
+	})
+}
+
 // fetchBasicAuth fetches a basic auth token for the basic challenge.
 func (c *Client) fetchBasicAuth(ctx context.Context, registry string) (string, error) {
 	cred, err := c.credential(ctx, registry)
@@ -17,6 +17,7 @@ package auth
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -72,6 +73,58 @@ func TestClient_SetUserAgent(t *testing.T) {
 	}
 }
 
+func TestClient_Do_TLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ts.Certificate())
+
+	tests := []struct {
+		name    string
+		client  *Client
+		wantErr bool
+	}{
+		{
+			name:   "insecure skip verify",
+			client: &Client{InsecureSkipVerify: true},
+		},
+		{
+			name:   "custom root CAs",
+			client: &Client{RootCAs: rootCAs},
+		},
+		{
+			name: "custom client takes precedence",
+			client: &Client{
+				Client:             &http.Client{},
+				InsecureSkipVerify: true,
+				RootCAs:            rootCAs,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+			if err != nil {
+				t.Fatalf("failed to create test request: %v", err)
+			}
+			resp, err := tt.client.Do(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Client.Do() = %v, want %v", resp.StatusCode, http.StatusOK)
+			}
+		})
+	}
+}
+
 func TestClient_Do_Basic_Auth(t *testing.T) {
 	username := "test_user"
 	password := "test_password"
@@ -725,6 +778,103 @@ func TestClient_Do_Bearer_Auth_Cached(t *testing.T) {
 	}
 }
 
+func TestClient_Do_Bearer_PrefetchToken(t *testing.T) {
+	var requestCount, wantRequestCount int64
+	var challengeCount, wantChallengeCount int64
+	var authCount, wantAuthCount int64
+	var service string
+	as := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/" {
+			t.Error("unexecuted attempt of authorization service")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if got := r.URL.Query().Get("service"); got != service {
+			t.Errorf("unexpected service: got %s, want %s", got, service)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		atomic.AddInt64(&authCount, 1)
+		// issue the token of the requested scopes
+		token := strings.Join(r.URL.Query()["scope"], " ")
+		if _, err := fmt.Fprintf(w, `{"access_token":%q}`, token); err != nil {
+			t.Errorf("failed to write %q: %v", r.URL, err)
+		}
+	}))
+	defer as.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requestCount, 1)
+		scope := r.URL.Query().Get("scope")
+		if auth := r.Header.Get("Authorization"); auth != "Bearer "+scope {
+			atomic.AddInt64(&challengeCount, 1)
+			challenge := fmt.Sprintf("Bearer realm=%q,service=%q,scope=%q", as.URL, service, scope)
+			w.Header().Set("Www-Authenticate", challenge)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+	service = uri.Host
+
+	client := &Client{
+		Cache:         NewCache(),
+		PrefetchToken: true,
+	}
+	do := func(scope string) {
+		t.Helper()
+		ctx := WithScopesForHost(context.Background(), uri.Host, scope)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"?scope="+url.QueryEscape(scope), nil)
+		if err != nil {
+			t.Fatalf("failed to create test request: %v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Client.Do() error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Client.Do() = %v, want %v", resp.StatusCode, http.StatusOK)
+		}
+	}
+
+	// first request to the registry gets challenged
+	do("repository:foo:pull")
+	if wantRequestCount += 2; requestCount != wantRequestCount {
+		t.Errorf("unexpected number of requests: %d, want %d", requestCount, wantRequestCount)
+	}
+	if wantChallengeCount++; challengeCount != wantChallengeCount {
+		t.Errorf("unexpected number of challenges: %d, want %d", challengeCount, wantChallengeCount)
+	}
+	if wantAuthCount++; authCount != wantAuthCount {
+		t.Errorf("unexpected number of auth requests: %d, want %d", authCount, wantAuthCount)
+	}
+
+	// the token of a new scope is fetched before the request
+	do("repository:bar:pull,push")
+	if wantRequestCount++; requestCount != wantRequestCount {
+		t.Errorf("unexpected number of requests: %d, want %d", requestCount, wantRequestCount)
+	}
+	if challengeCount != wantChallengeCount {
+		t.Errorf("unexpected number of challenges: %d, want %d", challengeCount, wantChallengeCount)
+	}
+	if wantAuthCount++; authCount != wantAuthCount {
+		t.Errorf("unexpected number of auth requests: %d, want %d", authCount, wantAuthCount)
+	}
+
+	// the cached token is reused
+	do("repository:foo:pull")
+	if wantRequestCount++; requestCount != wantRequestCount {
+		t.Errorf("unexpected number of requests: %d, want %d", requestCount, wantRequestCount)
+	}
+	if authCount != wantAuthCount {
+		t.Errorf("unexpected number of auth requests: %d, want %d", authCount, wantAuthCount)
+	}
+}
+
 func TestClient_Do_Bearer_OAuth2_Password(t *testing.T) {
 	username := "test_user"
 	password := "test_password"
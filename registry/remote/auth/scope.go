@@ -95,6 +95,48 @@ func GetScopes(ctx context.Context) []string {
 	return nil
 }
 
+// scopesForHostContextKey is the context key for the scopes of a host.
+type scopesForHostContextKey string
+
+// WithScopesForHost returns a context with scopes added for the host, which
+// are used as hints only for the requests to the host, in addition to the
+// scopes added by WithScopes. Scopes are de-duplicated.
+// It allows hinting the scopes of the repositories on different registries
+// in the same context, e.g. pulling from one registry and pushing to another,
+// without requesting tokens for the repositories unknown to each registry.
+// Passing an empty list of scopes will virtually remove the scope hints of the
+// host in the context.
+func WithScopesForHost(ctx context.Context, host string, scopes ...string) context.Context {
+	scopes = CleanScopes(scopes)
+	return context.WithValue(ctx, scopesForHostContextKey(host), scopes)
+}
+
+// AppendScopesForHost appends additional scopes to the existing scopes of the
+// host in the context and returns a new context. The resulted scopes are
+// de-duplicated.
+func AppendScopesForHost(ctx context.Context, host string, scopes ...string) context.Context {
+	if len(scopes) == 0 {
+		return ctx
+	}
+	return WithScopesForHost(ctx, host, append(GetScopesForHost(ctx, host), scopes...)...)
+}
+
+// GetScopesForHost returns the scopes of the host in the context, excluding
+// the ones added by WithScopes.
+func GetScopesForHost(ctx context.Context, host string) []string {
+	if scopes, ok := ctx.Value(scopesForHostContextKey(host)).([]string); ok {
+		return append([]string(nil), scopes...)
+	}
+	return nil
+}
+
+// GetAllScopesForHost returns the scopes in the context applying to the
+// requests to the host, which are the scopes added by WithScopes merged with
+// the ones added for the host.
+func GetAllScopesForHost(ctx context.Context, host string) []string {
+	return CleanScopes(append(GetScopes(ctx), GetScopesForHost(ctx, host)...))
+}
+
 // CleanScopes merges and sort the actions in ascending order if the scopes have
 // the same resource type and name. The final scopes are sorted in ascending
 // order. In other words, the scopes passed in are de-duplicated and sorted.
@@ -184,6 +184,55 @@ func TestAppendScopes(t *testing.T) {
 	}
 }
 
+func TestWithScopesForHost(t *testing.T) {
+	ctx := context.Background()
+	ctx = WithScopes(ctx, "repository:foo:pull")
+
+	// add scopes for hosts
+	ctx = WithScopesForHost(ctx, "registry.example", "repository:bar:push", "repository:bar:pull")
+	ctx = AppendScopesForHost(ctx, "registry.example", "repository:baz:pull")
+	ctx = AppendScopesForHost(ctx, "other.example", "repository:qux:pull")
+	want := []string{
+		"repository:bar:pull,push",
+		"repository:baz:pull",
+	}
+	if got := GetScopesForHost(ctx, "registry.example"); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetScopesForHost() = %v, want %v", got, want)
+	}
+	want = []string{
+		"repository:bar:pull,push",
+		"repository:baz:pull",
+		"repository:foo:pull",
+	}
+	if got := GetAllScopesForHost(ctx, "registry.example"); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAllScopesForHost() = %v, want %v", got, want)
+	}
+	want = []string{
+		"repository:foo:pull",
+		"repository:qux:pull",
+	}
+	if got := GetAllScopesForHost(ctx, "other.example"); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAllScopesForHost() = %v, want %v", got, want)
+	}
+
+	// the scopes for hosts are not global scopes
+	want = []string{
+		"repository:foo:pull",
+	}
+	if got := GetScopes(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetScopes() = %v, want %v", got, want)
+	}
+	if got := GetAllScopesForHost(ctx, "unknown.example"); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAllScopesForHost() = %v, want %v", got, want)
+	}
+
+	// remove the scopes for a host
+	ctx = WithScopesForHost(ctx, "registry.example")
+	if got := GetScopesForHost(ctx, "registry.example"); len(got) != 0 {
+		t.Errorf("GetScopesForHost() = %v, want empty", got)
+	}
+}
+
 func TestCleanScopes(t *testing.T) {
 	tests := []struct {
 		name   string
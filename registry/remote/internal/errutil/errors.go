@@ -23,6 +23,8 @@ import (
 	"net/url"
 	"strings"
 	"unicode"
+
+	"github.com/opencontainers/go-digest"
 )
 
 // maxErrorBytes specifies the default limit on how many response bytes are
@@ -33,8 +35,37 @@ var maxErrorBytes int64 = 8 * 1024 // 8 KiB
 
 // requestError contains a single error.
 type requestError struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Detail  json.RawMessage `json:"detail,omitempty"`
+}
+
+// unknownBlob returns the digest of the unknown blob reported by the error,
+// if any.
+// The detail of the BLOB_UNKNOWN and MANIFEST_BLOB_UNKNOWN errors is either
+// the digest string or an object with a digest field, depending on the
+// registry.
+func (e requestError) unknownBlob() (digest.Digest, bool) {
+	if e.Code != "BLOB_UNKNOWN" && e.Code != "MANIFEST_BLOB_UNKNOWN" {
+		return "", false
+	}
+	if len(e.Detail) == 0 {
+		return "", false
+	}
+	var dgst digest.Digest
+	if err := json.Unmarshal(e.Detail, &dgst); err != nil {
+		var detail struct {
+			Digest digest.Digest `json:"digest"`
+		}
+		if err := json.Unmarshal(e.Detail, &detail); err != nil {
+			return "", false
+		}
+		dgst = detail.Digest
+	}
+	if dgst.Validate() != nil {
+		return "", false
+	}
+	return dgst, true
 }
 
 // Error returns a error string describing the error.
@@ -74,12 +105,38 @@ type UnexpectedStatusCodeError struct {
 	URL        *url.URL
 	StatusCode int
 	Message    string
+
+	// errs is the list of errors in the error response, if any.
+	errs requestErrors
 }
 
 func (err *UnexpectedStatusCodeError) Error() string {
 	return fmt.Sprintf("%s %q: unexpected status code %d: %s", err.Method, err.URL, err.StatusCode, err.Message)
 }
 
+// Temporary returns true if the request may succeed on retry, i.e. the status
+// code indicates a timeout, throttling, or a server error.
+func (err *UnexpectedStatusCodeError) Temporary() bool {
+	switch err.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return err.StatusCode >= http.StatusInternalServerError
+}
+
+// UnknownBlobs returns the digests of the blobs reported as unknown by the
+// registry, e.g. the blobs referenced by a manifest but missing in the
+// repository on manifest push.
+func (err *UnexpectedStatusCodeError) UnknownBlobs() []digest.Digest {
+	var dgsts []digest.Digest
+	for _, e := range err.errs {
+		if dgst, ok := e.unknownBlob(); ok {
+			dgsts = append(dgsts, dgst)
+		}
+	}
+	return dgsts
+}
+
 // ParseErrorResponse parses the error returned by the remote registry.
 func ParseErrorResponse(resp *http.Response) error {
 	var errmsg string
@@ -98,5 +155,6 @@ func ParseErrorResponse(resp *http.Response) error {
 		URL:        resp.Request.URL,
 		StatusCode: resp.StatusCode,
 		Message:    errmsg,
+		errs:       body.Errors,
 	}
 }
@@ -16,10 +16,14 @@ limitations under the License.
 package errutil
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/opencontainers/go-digest"
 )
 
 func Test_ParseErrorResponse(t *testing.T) {
@@ -74,3 +78,60 @@ func Test_ParseErrorResponse_plain(t *testing.T) {
 		t.Errorf("ParseErrorResponse() error = %v, want err message %v", err, want)
 	}
 }
+
+func TestUnexpectedStatusCodeError_Temporary(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		want       bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusUnauthorized, false},
+		{http.StatusForbidden, false},
+		{http.StatusNotFound, false},
+		{http.StatusRequestTimeout, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.statusCode), func(t *testing.T) {
+			err := &UnexpectedStatusCodeError{StatusCode: tt.statusCode}
+			if got := err.Temporary(); got != tt.want {
+				t.Errorf("UnexpectedStatusCodeError.Temporary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnexpectedStatusCodeError_UnknownBlobs(t *testing.T) {
+	config := digest.FromString("config")
+	layer := digest.FromString("layer")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := `{"errors":[` +
+			`{"code":"MANIFEST_BLOB_UNKNOWN","message":"blob unknown to registry","detail":"` + config.String() + `"},` +
+			`{"code":"BLOB_UNKNOWN","message":"blob unknown to registry","detail":{"digest":"` + layer.String() + `"}},` +
+			`{"code":"BLOB_UNKNOWN","message":"blob unknown to registry"},` +
+			`{"code":"MANIFEST_INVALID","message":"manifest invalid","detail":"` + layer.String() + `"}` +
+			`]}`
+		w.WriteHeader(http.StatusBadRequest)
+		if _, err := w.Write([]byte(msg)); err != nil {
+			t.Errorf("failed to write %q: %v", r.URL, err)
+		}
+	}))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("failed to do request: %v", err)
+	}
+	err = ParseErrorResponse(resp)
+	var errResp *UnexpectedStatusCodeError
+	if !errors.As(err, &errResp) {
+		t.Fatalf("ParseErrorResponse() error = %v, want %T", err, errResp)
+	}
+	want := []digest.Digest{config, layer}
+	if got := errResp.UnknownBlobs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnexpectedStatusCodeError.UnknownBlobs() = %v, want %v", got, want)
+	}
+}
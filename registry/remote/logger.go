@@ -0,0 +1,67 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Logger logs the requests sent to the remote registry as structured events
+// named "request", with the attributes given as alternating keys and values:
+// "method", "url", "status", "duration", and "error" on failure.
+type Logger interface {
+	// Log logs an event with its attributes.
+	Log(ctx context.Context, event string, keyvals ...interface{})
+}
+
+// loggingClient is a Client logging the requests with a Logger.
+type loggingClient struct {
+	Client
+	logger Logger
+}
+
+// Do sends the request and logs it with its response.
+func (c *loggingClient) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.Client.Do(req)
+	keyvals := []interface{}{
+		"method", req.Method,
+		"url", req.URL.Redacted(),
+	}
+	if resp != nil {
+		keyvals = append(keyvals, "status", resp.StatusCode)
+	}
+	keyvals = append(keyvals, "duration", time.Since(start))
+	if err != nil {
+		keyvals = append(keyvals, "error", err)
+	}
+	c.logger.Log(req.Context(), "request", keyvals...)
+	return resp, err
+}
+
+// logClient returns a client logging the requests with logger.
+// If logger is nil, client is returned as is.
+func logClient(client Client, logger Logger) Client {
+	if logger == nil {
+		return client
+	}
+	return &loggingClient{
+		Client: client,
+		logger: logger,
+	}
+}
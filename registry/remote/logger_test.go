@@ -0,0 +1,123 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// testLogger records the logged events with their attributes.
+type testLogger struct {
+	lock   sync.Mutex
+	events []string
+	attrs  []map[string]interface{}
+}
+
+func (l *testLogger) Log(_ context.Context, event string, keyvals ...interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	attrs := make(map[string]interface{})
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		attrs[keyvals[i].(string)] = keyvals[i+1]
+	}
+	l.events = append(l.events, event)
+	l.attrs = append(l.attrs, attrs)
+}
+
+func TestRepository_Logger(t *testing.T) {
+	blob := []byte("hello world")
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	missing := []byte("missing")
+	missingDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(missing),
+		Size:      int64(len(missing)),
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/test/blobs/" + blobDesc.Digest.String():
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Docker-Content-Digest", blobDesc.Digest.String())
+			w.Header().Set("Content-Length", "11")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	logger := &testLogger{}
+	repo.Logger = logger
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		desc ocispec.Descriptor
+		want bool
+	}{
+		{blobDesc, true},
+		{missingDesc, false},
+	} {
+		exists, err := repo.Exists(ctx, tt.desc)
+		if err != nil {
+			t.Fatalf("Repository.Exists() error = %v", err)
+		}
+		if exists != tt.want {
+			t.Errorf("Repository.Exists() = %v, want %v", exists, tt.want)
+		}
+	}
+
+	if got := len(logger.events); got != 2 {
+		t.Fatalf("number of logged events = %v, want %v", got, 2)
+	}
+	for i, wantStatus := range []int{http.StatusOK, http.StatusNotFound} {
+		if got := logger.events[i]; got != "request" {
+			t.Errorf("event[%d] = %v, want %v", i, got, "request")
+		}
+		attrs := logger.attrs[i]
+		if got := attrs["method"]; got != http.MethodHead {
+			t.Errorf("event[%d] method = %v, want %v", i, got, http.MethodHead)
+		}
+		if got := attrs["status"]; got != wantStatus {
+			t.Errorf("event[%d] status = %v, want %v", i, got, wantStatus)
+		}
+		if _, ok := attrs["duration"]; !ok {
+			t.Errorf("event[%d] duration is missing", i)
+		}
+		if _, ok := attrs["error"]; ok {
+			t.Errorf("event[%d] error = %v, want none", i, attrs["error"])
+		}
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"net/http"
+)
+
+// RateLimiter limits the rate of the outgoing requests.
+// *rate.Limiter in the package golang.org/x/time/rate implements this
+// interface.
+type RateLimiter interface {
+	// Wait blocks until the limiter permits an event to happen.
+	// It returns an error if the context is canceled, or the expected wait time
+	// exceeds the context's deadline.
+	Wait(ctx context.Context) error
+}
+
+// rateLimitedClient is a Client throttling the requests by a RateLimiter.
+type rateLimitedClient struct {
+	Client
+	limiter RateLimiter
+}
+
+// Do waits for the rate limiter before sending the request.
+func (c *rateLimitedClient) Do(req *http.Request) (*http.Response, error) {
+	if err := c.limiter.Wait(req.Context()); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// limitClient returns a client throttled by the limiter.
+// If limiter is nil, client is returned as is.
+func limitClient(client Client, limiter RateLimiter) Client {
+	if limiter == nil {
+		return client
+	}
+	return &rateLimitedClient{
+		Client:  client,
+		limiter: limiter,
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// testRateLimiter counts the number of waits, and fails the waits if err is
+// set.
+type testRateLimiter struct {
+	count int64
+	err   error
+}
+
+func (l *testRateLimiter) Wait(ctx context.Context) error {
+	atomic.AddInt64(&l.count, 1)
+	if l.err != nil {
+		return l.err
+	}
+	return ctx.Err()
+}
+
+func TestRepository_RateLimiter(t *testing.T) {
+	blob := []byte("hello world")
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	index := []byte(`{"manifests":[]}`)
+	indexDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(index),
+		Size:      int64(len(index)),
+	}
+	var requestCount int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requestCount, 1)
+		switch r.URL.Path {
+		case "/v2/test/blobs/" + blobDesc.Digest.String():
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Docker-Content-Digest", blobDesc.Digest.String())
+			w.Header().Set("Content-Length", "11")
+		case "/v2/test/manifests/" + indexDesc.Digest.String():
+			w.Header().Set("Content-Type", indexDesc.MediaType)
+			w.Header().Set("Docker-Content-Digest", indexDesc.Digest.String())
+			w.Header().Set("Content-Length", "16")
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	limiter := &testRateLimiter{}
+	repo.RateLimiter = limiter
+	ctx := context.Background()
+
+	for _, desc := range []ocispec.Descriptor{blobDesc, indexDesc} {
+		exists, err := repo.Exists(ctx, desc)
+		if err != nil {
+			t.Fatalf("Repository.Exists() error = %v", err)
+		}
+		if !exists {
+			t.Errorf("Repository.Exists() = %v, want %v", exists, true)
+		}
+	}
+	if got, want := limiter.count, requestCount; got != want || got != 2 {
+		t.Errorf("RateLimiter.Wait() count = %v, want %v", got, want)
+	}
+
+	// test limiter error
+	errLimited := errors.New("limited")
+	limiter.err = errLimited
+	if _, err := repo.Exists(ctx, blobDesc); !errors.Is(err, errLimited) {
+		t.Errorf("Repository.Exists() error = %v, wantErr %v", err, errLimited)
+	}
+	if requestCount != 2 {
+		t.Errorf("request count = %v, want %v", requestCount, 2)
+	}
+
+	// test context cancellation
+	limiter.err = nil
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := repo.Exists(ctx, blobDesc); !errors.Is(err, context.Canceled) {
+		t.Errorf("Repository.Exists() error = %v, wantErr %v", err, context.Canceled)
+	}
+	if requestCount != 2 {
+		t.Errorf("request count = %v, want %v", requestCount, 2)
+	}
+}
@@ -67,10 +67,11 @@ func NewRegistry(name string) (*Registry, error) {
 // client returns an HTTP client used to access the remote registry.
 // A default HTTP client is return if the client is not configured.
 func (r *Registry) client() Client {
-	if r.Client == nil {
-		return auth.DefaultClient
+	client := r.Client
+	if client == nil {
+		client = auth.DefaultClient
 	}
-	return r.Client
+	return limitClient(logClient(timeoutRequests(client, r.RequestTimeout), r.Logger), r.RateLimiter)
 }
 
 // Ping checks whether or not the registry implement Docker Registry API V2 or
@@ -107,6 +108,8 @@ func (r *Registry) Ping(ctx context.Context) error {
 // If `last` is NOT empty, the entries in the response start after the
 // repo specified by `last`. Otherwise, the response starts from the top
 // of the Repositories list.
+// If the registry disables the catalog endpoint, errdef.ErrUnsupported is
+// returned.
 // Reference: https://docs.docker.com/registry/spec/api/#catalog
 func (r *Registry) Repositories(ctx context.Context, last string, fn func(repos []string) error) error {
 	ctx = auth.AppendScopes(ctx, auth.ScopeRegistryCatalog)
@@ -145,7 +148,12 @@ func (r *Registry) repositories(ctx context.Context, last string, fn func(repos
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		// the catalog endpoint is disabled or not implemented by the registry
+		return "", fmt.Errorf("%s %q: repository listing: %w", resp.Request.Method, resp.Request.URL, errdef.ErrUnsupported)
+	default:
 		return "", errutil.ParseErrorResponse(resp)
 	}
 	var page struct {
@@ -18,6 +18,7 @@ package remote
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -172,6 +173,52 @@ func TestRegistry_Repositories(t *testing.T) {
 	}
 }
 
+func TestRegistry_Repositories_Unsupported(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			}))
+			defer ts.Close()
+			uri, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatalf("invalid test http server: %v", err)
+			}
+
+			reg, err := NewRegistry(uri.Host)
+			if err != nil {
+				t.Fatalf("NewRegistry() error = %v", err)
+			}
+			reg.PlainHTTP = true
+
+			ctx := context.Background()
+			_, err = registry.Repositories(ctx, reg)
+			if !errors.Is(err, errdef.ErrUnsupported) {
+				t.Errorf("Registry.Repositories() error = %v, wantErr %v", err, errdef.ErrUnsupported)
+			}
+		})
+	}
+
+	// authorization failures are not reported as unsupported
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+	reg, err := NewRegistry(uri.Host)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	reg.PlainHTTP = true
+	_, err = registry.Repositories(context.Background(), reg)
+	if err == nil || errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Registry.Repositories() error = %v, want a non-ErrUnsupported error", err)
+	}
+}
+
 func TestRegistry_Repository(t *testing.T) {
 	reg, err := NewRegistry("localhost:5000")
 	if err != nil {
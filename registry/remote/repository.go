@@ -27,10 +27,13 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/opencontainers/distribution-spec/specs-go/v1/extensions"
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/cas"
 	"oras.land/oras-go/v2/internal/httputil"
@@ -47,6 +50,11 @@ import (
 // See https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pull
 const dockerContentDigestHeader = "Docker-Content-Digest"
 
+// headerOCIFiltersApplied is the header in the referrers API response, which
+// indicates the filters applied by the server.
+// Reference: https://github.com/opencontainers/distribution-spec/blob/main/spec.md#listing-referrers
+const headerOCIFiltersApplied = "OCI-Filters-Applied"
+
 // referrersApiRegex checks referrers API version.
 // Reference: https://github.com/oras-project/artifacts-spec/blob/main/manifest-referrers-api.md#versioning
 var referrersApiRegex = regexp.MustCompile(`^oras/1\.(0|[1-9]\d*)$`)
@@ -68,6 +76,17 @@ type Client interface {
 type Repository struct {
 	// Client is the underlying HTTP client used to access the remote registry.
 	// If nil, auth.DefaultClient is used.
+	// Every request is created with the context passed to the operation, so
+	// that context values, such as tracing spans and correlation IDs, reach
+	// the client. Requests can be instrumented by plugging an
+	// http.RoundTripper, e.g. a tracing transport, into the client:
+	//
+	//	repo.Client = &auth.Client{
+	//		Client: &http.Client{Transport: tracingTransport},
+	//	}
+	//
+	// where the token requests of auth.Client are sent with the context of
+	// the originating requests as well.
 	Client Client
 
 	// Reference references the remote repository.
@@ -83,6 +102,16 @@ type Repository struct {
 	// are used.
 	ManifestMediaTypes []string
 
+	// AcceptManifestMediaTypes overrides the manifest media types in the
+	// `Accept` header for resolving and fetching manifests by references, in
+	// the given order, without affecting how manifests are identified from
+	// descriptors.
+	// It is useful for registries negotiating the manifest format, for
+	// example, to receive an OCI image index rather than a docker manifest
+	// list.
+	// If empty, ManifestMediaTypes is used.
+	AcceptManifestMediaTypes []string
+
 	// TagListPageSize specifies the page size when invoking the tag list API.
 	// If zero, the page size is determined by the remote registry.
 	// Reference: https://docs.docker.com/registry/spec/api/#tags
@@ -94,11 +123,46 @@ type Repository struct {
 	// Reference: https://github.com/oras-project/artifacts-spec/blob/main/manifest-referrers-api.md
 	ReferrerListPageSize int
 
+	// SkipReferrersAPI forces the referrers tag schema for the registries
+	// advertising a broken Referrers API. If true, Referrers() never requests
+	// the Referrers API and lists the referrers from the index tagged by the
+	// referrers tag of the subject, i.e. `sha256-<digest>`, instead. Ping()
+	// does not probe the Referrers API and always reports it as unsupported,
+	// so that oras.PushReferrer updates the referrers tag on pushing
+	// referrers.
+	// If false, the support of the Referrers API is detected automatically.
+	// Reference: https://github.com/opencontainers/distribution-spec/blob/main/spec.md#referrers-tag-schema
+	SkipReferrersAPI bool
+
 	// MaxMetadataBytes specifies a limit on how many response bytes are allowed
 	// in the server's response to the metadata APIs, such as catalog list, tag
 	// list, and referrers list.
 	// If less than or equal to zero, a default (currently 4MiB) is used.
 	MaxMetadataBytes int64
+
+	// RateLimiter limits the rate of the requests sent to the remote registry,
+	// including blob and manifest operations. Waiting for the limiter respects
+	// the cancellation of the request context.
+	// If nil, the requests are not throttled.
+	RateLimiter RateLimiter
+
+	// RequestTimeout, if positive, limits the time waiting for the response
+	// of each request sent to the remote registry, independent of the
+	// deadline of the context of the operation. The response bodies, such as
+	// the blobs being streamed, are aborted only if no byte is received for
+	// RequestTimeout, so that large blobs can take as long as they need.
+	// The timed out requests fail with a net.Error reporting a timeout and
+	// wrapping os.ErrDeadlineExceeded, which is retried by oras.Copy with
+	// CopyGraphOptions.MaxGraphRetries.
+	// If not positive, the requests are limited only by the context.
+	RequestTimeout time.Duration
+
+	// Logger, if not nil, logs the requests sent to the remote registry,
+	// including blob and manifest operations, as structured events.
+	Logger Logger
+
+	// capabilities caches the registry.Capabilities probed by Ping().
+	capabilities atomic.Value
 }
 
 // NewRepository creates a client to the remote repository identified by a
@@ -117,10 +181,20 @@ func NewRepository(reference string) (*Repository, error) {
 // client returns an HTTP client used to access the remote repository.
 // A default HTTP client is return if the client is not configured.
 func (r *Repository) client() Client {
-	if r.Client == nil {
-		return auth.DefaultClient
+	client := r.Client
+	if client == nil {
+		client = auth.DefaultClient
 	}
-	return r.Client
+	return limitClient(logClient(timeoutRequests(client, r.RequestTimeout), r.Logger), r.RateLimiter)
+}
+
+// manifestAcceptHeader returns the `Accept` header for resolving and fetching
+// manifests by references.
+func (r *Repository) manifestAcceptHeader() string {
+	if len(r.AcceptManifestMediaTypes) > 0 {
+		return manifestAcceptHeader(r.AcceptManifestMediaTypes)
+	}
+	return manifestAcceptHeader(r.ManifestMediaTypes)
 }
 
 // blobStore detects the blob store for the given descriptor.
@@ -136,7 +210,32 @@ func (r *Repository) Fetch(ctx context.Context, target ocispec.Descriptor) (io.R
 	return r.blobStore(target).Fetch(ctx, target)
 }
 
+// FetchRange fetches the content identified by the descriptor, starting from
+// the given byte offset to the end of the content.
+// Manifests are fetched entirely with the bytes before the offset discarded.
+// See also blobStore.FetchRange.
+func (r *Repository) FetchRange(ctx context.Context, target ocispec.Descriptor, offset int64) (io.ReadCloser, error) {
+	if isManifest(r.ManifestMediaTypes, target) {
+		if offset < 0 || offset > target.Size {
+			return nil, fmt.Errorf("%s: invalid offset %d for size %d", target.Digest, offset, target.Size)
+		}
+		rc, err := r.Manifests().Fetch(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.CopyN(io.Discard, rc, offset); err != nil {
+			rc.Close()
+			return nil, err
+		}
+		return rc, nil
+	}
+	return (&blobStore{repo: r}).FetchRange(ctx, target, offset)
+}
+
 // Push pushes the content, matching the expected descriptor.
+// If a manifest is rejected for referencing blobs missing in the repository,
+// the returned error is an *errdef.UnknownBlobsError, reporting the digests of
+// the missing blobs parsed from the error response.
 func (r *Repository) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
 	return r.blobStore(expected).Push(ctx, expected, content)
 }
@@ -151,6 +250,13 @@ func (r *Repository) Delete(ctx context.Context, target ocispec.Descriptor) erro
 	return r.blobStore(target).Delete(ctx, target)
 }
 
+// Mount makes the blob with the given descriptor in fromRepo available in the
+// repository signified by the receiver.
+// See also blobStore.Mount.
+func (r *Repository) Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) error {
+	return (&blobStore{repo: r}).Mount(ctx, desc, fromRepo, getContent)
+}
+
 // Blobs provides access to the blob CAS only, which contains config blobs,
 // layers, and other generic blobs.
 func (r *Repository) Blobs() registry.BlobStore {
@@ -174,16 +280,90 @@ func (r *Repository) Tag(ctx context.Context, desc ocispec.Descriptor, reference
 }
 
 // PushReference pushes the manifest with a reference tag.
+// The reference can be a tag or digest. If the reference is a digest, it must
+// match the digest of the expected descriptor.
 func (r *Repository) PushReference(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string) error {
 	return r.Manifests().PushReference(ctx, expected, content, reference)
 }
 
+// PushReferenceOptions contains parameters for
+// Repository.PushReferenceWithOptions.
+type PushReferenceOptions struct {
+	// IfMatch, if not empty, pushes the manifest only if the reference
+	// currently resolves to the manifest of the given digest, by sending the
+	// `If-Match` header.
+	IfMatch digest.Digest
+	// IfNoneMatch pushes the manifest only if the reference does not exist,
+	// by sending the `If-None-Match: *` header.
+	IfNoneMatch bool
+	// ContentType, if not empty, overrides the `Content-Type` header of the
+	// manifest push, which is the media type of the expected descriptor by
+	// default. It is useful for registries picky about the exact header.
+	// ContentType must be one of the manifest media types of the repository,
+	// or errdef.ErrUnsupported is returned.
+	// See also `Repository.ManifestMediaTypes`.
+	ContentType string
+}
+
+// PushReferenceWithOptions pushes the manifest with a reference tag
+// conditionally for optimistic concurrency control of tag updates.
+// If the precondition is not met, a wrapped error of
+// errdef.ErrPreconditionFailed is returned. If the remote registry rejects
+// the conditional request as not implemented, a wrapped error of
+// errdef.ErrUnsupported is returned.
+// Note: registries ignoring the conditional headers push the manifest
+// unconditionally, which cannot be detected by the client.
+func (r *Repository) PushReferenceWithOptions(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string, opts PushReferenceOptions) error {
+	ms := &manifestStore{repo: r}
+	return ms.PushReferenceWithOptions(ctx, expected, content, reference, opts)
+}
+
 // FetchReference fetches the manifest identified by the reference.
 // The reference can be a tag or digest.
 func (r *Repository) FetchReference(ctx context.Context, reference string) (ocispec.Descriptor, io.ReadCloser, error) {
 	return r.Manifests().FetchReference(ctx, reference)
 }
 
+// ResolveWithAnnotations resolves a reference to a manifest descriptor, and
+// populates the annotations and the artifact type of the manifest into the
+// returned descriptor.
+// Unlike Resolve, the manifest is fetched with a single GET request, and its
+// size is limited by MaxMetadataBytes.
+// See also `ManifestMediaTypes`.
+func (r *Repository) ResolveWithAnnotations(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	desc, rc, err := r.FetchReference(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer rc.Close()
+
+	maxMetadataBytes := r.MaxMetadataBytes
+	if maxMetadataBytes <= 0 {
+		maxMetadataBytes = defaultMaxMetadataBytes
+	}
+	if desc.Size > maxMetadataBytes {
+		return ocispec.Descriptor{}, fmt.Errorf(
+			"content size %v exceeds MaxMetadataBytes %v: %w",
+			desc.Size,
+			maxMetadataBytes,
+			errdef.ErrSizeExceedsLimit)
+	}
+	manifestJSON, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	var manifest struct {
+		ArtifactType string            `json:"artifactType"`
+		Annotations  map[string]string `json:"annotations"`
+	}
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: failed to decode manifest: %w", desc.Digest, err)
+	}
+	desc.ArtifactType = manifest.ArtifactType
+	desc.Annotations = manifest.Annotations
+	return desc, nil
+}
+
 // ParseReference resolves a tag or a digest reference to a fully qualified
 // reference from a base reference r.Reference.
 // Tag, digest, or fully qualified references are accepted as input.
@@ -239,43 +419,106 @@ func (r *Repository) Tags(ctx context.Context, last string, fn func(tags []strin
 	return nil
 }
 
+// TagsPageOptions contains parameters for Repository.TagsPage.
+type TagsPageOptions struct {
+	// Last is the cursor of the page. If NOT empty, the entries in the page
+	// start after the tag specified by Last. Otherwise, the page starts from
+	// the top of the tag list.
+	Last string
+	// PageSize specifies the page size requested by the `n` query parameter.
+	// If less than or equal to zero, TagListPageSize of the repository is
+	// used, or the page size is determined by the remote registry if
+	// TagListPageSize is not set.
+	// Registries may ignore the requested page size.
+	PageSize int
+}
+
+// TagsPage lists a single page of the tags available in the repository, and
+// returns the cursor of the next page, which can be passed as opts.Last for
+// requesting the next page.
+// An empty cursor is returned if there are no more pages.
+// References:
+// - https://github.com/opencontainers/distribution-spec/blob/main/spec.md#content-discovery
+// - https://docs.docker.com/registry/spec/api/#tags
+func (r *Repository) TagsPage(ctx context.Context, opts TagsPageOptions) (tags []string, next string, err error) {
+	ctx = registryutil.WithScopeHint(ctx, r.Reference, auth.ActionPull)
+	url := buildRepositoryTagListURL(r.PlainHTTP, r.Reference)
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = r.TagListPageSize
+	}
+	tags, resp, err := r.tagsPage(ctx, opts.Last, pageSize, url)
+	if err != nil {
+		return nil, "", err
+	}
+	link, err := parseLink(resp)
+	if err != nil {
+		if err == errNoLink {
+			return tags, "", nil
+		}
+		return nil, "", err
+	}
+	if len(tags) == 0 {
+		return tags, "", nil
+	}
+
+	// prefer the cursor provided by the registry, and fall back to the last
+	// tag of the page for opaque links.
+	if last := parseLinkLast(link); last != "" {
+		return tags, last, nil
+	}
+	return tags, tags[len(tags)-1], nil
+}
+
 // tags returns a single page of tag list with the next link.
 func (r *Repository) tags(ctx context.Context, last string, fn func(tags []string) error, url string) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	tags, resp, err := r.tagsPage(ctx, last, r.TagListPageSize, url)
 	if err != nil {
 		return "", err
 	}
-	if r.TagListPageSize > 0 || last != "" {
+	if err := fn(tags); err != nil {
+		return "", err
+	}
+
+	return parseLink(resp)
+}
+
+// tagsPage requests a single page of tag list with the given page size, and
+// returns the tags in the page with the response for parsing the next link.
+func (r *Repository) tagsPage(ctx context.Context, last string, pageSize int, url string) ([]string, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pageSize > 0 || last != "" {
 		q := req.URL.Query()
-		if r.TagListPageSize > 0 {
-			q.Set("n", strconv.Itoa(r.TagListPageSize))
+		if pageSize > 0 {
+			q.Set("n", strconv.Itoa(pageSize))
 		}
 		if last != "" {
 			q.Set("last", last)
 		}
 		req.URL.RawQuery = q.Encode()
 	}
+	acceptGzip(req)
 	resp, err := r.client().Do(req)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
+	decodeGzip(resp)
 
 	if resp.StatusCode != http.StatusOK {
-		return "", errutil.ParseErrorResponse(resp)
+		return nil, nil, errutil.ParseErrorResponse(resp)
 	}
 	var page struct {
 		Tags []string `json:"tags"`
 	}
 	lr := limitReader(resp.Body, r.MaxMetadataBytes)
 	if err := json.NewDecoder(lr).Decode(&page); err != nil {
-		return "", fmt.Errorf("%s %q: failed to decode response: %w", resp.Request.Method, resp.Request.URL, err)
+		return nil, nil, fmt.Errorf("%s %q: failed to decode response: %w", resp.Request.Method, resp.Request.URL, err)
 	}
-	if err := fn(page.Tags); err != nil {
-		return "", err
-	}
-
-	return parseLink(resp)
+	return page.Tags, resp, nil
 }
 
 // Predecessors returns the descriptors of ORAS Artifact manifests directly
@@ -300,8 +543,14 @@ func (r *Repository) Predecessors(ctx context.Context, desc ocispec.Descriptor)
 // referencing the given manifest descriptor. fn is called for each page of
 // the referrers result. If artifactType is not empty, only referrers of the
 // same artifact type are fed to fn.
+// If SkipReferrersAPI is set, the referrers are listed from the referrers tag
+// of desc instead.
 // Reference: https://github.com/oras-project/artifacts-spec/blob/main/manifest-referrers-api.md
 func (r *Repository) Referrers(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func(referrers []ocispec.Descriptor) error) error {
+	if r.SkipReferrersAPI {
+		return r.referrersByTagSchema(ctx, desc, artifactType, fn)
+	}
+
 	ref := r.Reference
 	ref.Reference = desc.Digest.String()
 	ctx = registryutil.WithScopeHint(ctx, ref, auth.ActionPull)
@@ -309,7 +558,12 @@ func (r *Repository) Referrers(ctx context.Context, desc ocispec.Descriptor, art
 	var err error
 
 	var legacyAPI bool
-	url, err = r.referrers(ctx, artifactType, fn, url, legacyAPI)
+	if caps, ok := r.cachedCapabilities(); ok && !caps.ReferrersAPI {
+		// skip the Referrers API known to be unsupported
+		err = errdef.ErrNotFound
+	} else {
+		url, err = r.referrers(ctx, artifactType, fn, url, legacyAPI)
+	}
 	// Fallback to legacy url
 	if errors.Is(err, errdef.ErrNotFound) {
 		url = buildArtifactReferrerURLLegacy(r.PlainHTTP, ref, artifactType)
@@ -371,9 +625,11 @@ func (r *Repository) referrers(ctx context.Context, artifactType string, fn func
 	} else {
 		refs = page.Referrers
 	}
-	// Server may not support filtering. We still need to filter on client side
-	// for sure.
-	refs = filterReferrers(refs, artifactType)
+	// Server may not support filtering. Filter on client side if the server
+	// does not signal that the artifactType filter is applied.
+	if !isReferrersFilterApplied(resp, "artifactType") {
+		refs = filterReferrers(refs, artifactType)
+	}
 	if len(refs) > 0 {
 		if err := fn(refs); err != nil {
 			return "", err
@@ -383,6 +639,67 @@ func (r *Repository) referrers(ctx context.Context, artifactType string, fn func
 	return parseLink(resp)
 }
 
+// referrersByTagSchema lists the referrers of desc from the index tagged by
+// the referrers tag of desc. No referrers are listed if the tag does not
+// exist.
+// Reference: https://github.com/opencontainers/distribution-spec/blob/main/spec.md#referrers-tag-schema
+func (r *Repository) referrersByTagSchema(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func(referrers []ocispec.Descriptor) error) error {
+	tag := buildReferrersTag(desc.Digest)
+	indexDesc, rc, err := r.FetchReference(ctx, tag)
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	defer rc.Close()
+	if indexDesc.MediaType != ocispec.MediaTypeImageIndex {
+		return fmt.Errorf("%s: %s: unexpected referrers index media type: %w", indexDesc.Digest, indexDesc.MediaType, errdef.ErrUnsupported)
+	}
+	indexJSON, err := content.ReadAll(rc, indexDesc)
+	if err != nil {
+		return err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		return fmt.Errorf("%s: %s: failed to decode referrers index: %w", indexDesc.Digest, indexDesc.MediaType, err)
+	}
+	refs := filterReferrers(index.Manifests, artifactType)
+	if len(refs) == 0 {
+		return nil
+	}
+	return fn(refs)
+}
+
+// buildReferrersTag returns the referrers tag of the subject in the form of
+// `<alg>-<ref>`, where `<ref>` is the encoded digest truncated to 64
+// characters.
+// Reference: https://github.com/opencontainers/distribution-spec/blob/main/spec.md#referrers-tag-schema
+func buildReferrersTag(dgst digest.Digest) string {
+	ref := dgst.Encoded()
+	if len(ref) > 64 {
+		ref = ref[:64]
+	}
+	return dgst.Algorithm().String() + "-" + ref
+}
+
+// isReferrersFilterApplied checks the `OCI-Filters-Applied` header of the
+// referrers API response to see if the requested filter is applied by the
+// server.
+// Reference: https://github.com/opencontainers/distribution-spec/blob/main/spec.md#listing-referrers
+func isReferrersFilterApplied(resp *http.Response, requested string) bool {
+	applied := resp.Header.Get(headerOCIFiltersApplied)
+	if applied == "" {
+		return false
+	}
+	for _, filter := range strings.Split(applied, ",") {
+		if strings.TrimSpace(filter) == requested {
+			return true
+		}
+	}
+	return false
+}
+
 // filterReferrers filters a slice of referrers by artifactType in place.
 // The returned slice contains matching referrers.
 func filterReferrers(refs []ocispec.Descriptor, artifactType string) []ocispec.Descriptor {
@@ -401,6 +718,75 @@ func filterReferrers(refs []ocispec.Descriptor, artifactType string) []ocispec.D
 	return refs[:j]
 }
 
+// Ping checks whether the remote registry supports the distribution API, and
+// probes the capabilities of the repository, such as the support of the
+// Referrers API.
+// The capabilities are cached on success, so that the subsequent operations,
+// such as Referrers(), skip the requests known to fail.
+// If SkipReferrersAPI is set, the Referrers API is not probed and is reported
+// as unsupported.
+func (r *Repository) Ping(ctx context.Context) (registry.Capabilities, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, buildRegistryBaseURL(r.PlainHTTP, r.Reference), nil)
+	if err != nil {
+		return registry.Capabilities{}, err
+	}
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return registry.Capabilities{}, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return registry.Capabilities{}, fmt.Errorf("%s %q: %w", resp.Request.Method, resp.Request.URL, errdef.ErrNotFound)
+	default:
+		return registry.Capabilities{}, errutil.ParseErrorResponse(resp)
+	}
+	caps := registry.Capabilities{
+		APIVersion: resp.Header.Get("Docker-Distribution-API-Version"),
+	}
+
+	if !r.SkipReferrersAPI {
+		if caps.ReferrersAPI, err = r.pingReferrers(ctx); err != nil {
+			return registry.Capabilities{}, err
+		}
+	}
+	r.capabilities.Store(caps)
+	return caps, nil
+}
+
+// pingReferrers probes the Referrers API by listing the referrers of a
+// non-existent manifest.
+func (r *Repository) pingReferrers(ctx context.Context) (bool, error) {
+	ref := r.Reference
+	ref.Reference = digest.FromBytes(nil).String()
+	ctx = registryutil.WithScopeHint(ctx, ref, auth.ActionPull)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, buildArtifactReferrerURL(r.PlainHTTP, ref, ""), nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return verifyOrasApiVersion(resp) == nil, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, errutil.ParseErrorResponse(resp)
+	}
+}
+
+// cachedCapabilities returns the capabilities cached by Ping().
+func (r *Repository) cachedCapabilities() (registry.Capabilities, bool) {
+	caps, ok := r.capabilities.Load().(registry.Capabilities)
+	return caps, ok
+}
+
 // DiscoverExtensions lists all supported extensions in current repository.
 // Reference: https://github.com/oras-project/artifacts-spec/blob/main/manifest-referrers-api.md#api-discovery
 func (r *Repository) DiscoverExtensions(ctx context.Context) ([]extensions.Extension, error) {
@@ -453,7 +839,7 @@ func (r *Repository) delete(ctx context.Context, target ocispec.Descriptor, isMa
 	case http.StatusAccepted:
 		return verifyContentDigest(resp, target.Digest)
 	case http.StatusNotFound:
-		return fmt.Errorf("%s: %w", target.Digest, errdef.ErrNotFound)
+		return &errdef.NotFoundError{Descriptor: target}
 	default:
 		return errutil.ParseErrorResponse(resp)
 	}
@@ -482,6 +868,9 @@ func (s *blobStore) Fetch(ctx context.Context, target ocispec.Descriptor) (rc io
 	if target.Size > 0 {
 		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", target.Size-1))
 	}
+	// blobs are usually compressed already. Request them as is so that
+	// http.Transport does not negotiate gzip on its own.
+	req.Header.Set("Accept-Encoding", "identity")
 
 	resp, err := s.repo.client().Do(req)
 	if err != nil {
@@ -502,7 +891,66 @@ func (s *blobStore) Fetch(ctx context.Context, target ocispec.Descriptor) (rc io
 	case http.StatusPartialContent:
 		return httputil.NewReadSeekCloser(s.repo.client(), req, resp.Body, target.Size), nil
 	case http.StatusNotFound:
-		return nil, fmt.Errorf("%s: %w", target.Digest, errdef.ErrNotFound)
+		return nil, &errdef.NotFoundError{Descriptor: target}
+	default:
+		return nil, errutil.ParseErrorResponse(resp)
+	}
+}
+
+// FetchRange fetches the content identified by the descriptor, starting from
+// the given byte offset to the end of the content, by a `Range` request.
+// If the remote registry does not support range requests, the content is
+// fetched entirely and the bytes before the offset are discarded.
+// Reference: https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pulling-blobs
+func (s *blobStore) FetchRange(ctx context.Context, target ocispec.Descriptor, offset int64) (rc io.ReadCloser, err error) {
+	if offset < 0 || offset > target.Size {
+		return nil, fmt.Errorf("%s: invalid offset %d for size %d", target.Digest, offset, target.Size)
+	}
+	if offset == 0 {
+		return s.Fetch(ctx, target)
+	}
+	if offset == target.Size {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	ref := s.repo.Reference
+	ref.Reference = target.Digest.String()
+	ctx = registryutil.WithScopeHint(ctx, ref, auth.ActionPull)
+	url := buildRepositoryBlobURL(s.repo.PlainHTTP, ref)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+
+	resp, err := s.repo.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			resp.Body.Close()
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if size := resp.ContentLength; size != -1 && size != target.Size-offset {
+			return nil, fmt.Errorf("%s %q: mismatch Content-Length", resp.Request.Method, resp.Request.URL)
+		}
+		return resp.Body, nil
+	case http.StatusOK: // server does not support range requests as `Range` was ignored.
+		if size := resp.ContentLength; size != -1 && size != target.Size {
+			return nil, fmt.Errorf("%s %q: mismatch Content-Length", resp.Request.Method, resp.Request.URL)
+		}
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			return nil, fmt.Errorf("%s %q: failed to skip to offset %d: %w", resp.Request.Method, resp.Request.URL, offset, err)
+		}
+		return resp.Body, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		return nil, fmt.Errorf("%s %q: invalid range: %w", resp.Request.Method, resp.Request.URL, errutil.ParseErrorResponse(resp))
+	case http.StatusNotFound:
+		return nil, &errdef.NotFoundError{Descriptor: target}
 	default:
 		return nil, errutil.ParseErrorResponse(resp)
 	}
@@ -528,11 +976,7 @@ func (s *blobStore) Push(ctx context.Context, expected ocispec.Descriptor, conte
 	if err != nil {
 		return err
 	}
-	reqHostname := req.URL.Hostname()
-	reqPort := req.URL.Port()
-
-	client := s.repo.client()
-	resp, err := client.Do(req)
+	resp, err := s.repo.client().Do(req)
 	if err != nil {
 		return err
 	}
@@ -542,24 +986,18 @@ func (s *blobStore) Push(ctx context.Context, expected ocispec.Descriptor, conte
 		return errutil.ParseErrorResponse(resp)
 	}
 	resp.Body.Close()
+	return s.completePushAfterInitialPost(ctx, req, resp, expected, content)
+}
 
+// completePushAfterInitialPost implements step 2 of the push protocol. This
+// can be invoked either by Push or by Mount when the receiving repository
+// does not implement the mount endpoint.
+func (s *blobStore) completePushAfterInitialPost(ctx context.Context, req *http.Request, resp *http.Response, expected ocispec.Descriptor, content io.Reader) error {
 	// monolithic upload
-	location, err := resp.Location()
+	url, err := uploadLocation(req, resp)
 	if err != nil {
 		return err
 	}
-	// work-around solution for https://github.com/oras-project/oras-go/issues/177
-	// For some registries, if the port 443 is explicitly set to the hostname
-	// like registry.wabbit-networks.io:443/myrepo, blob push will fail since
-	// the hostname of the Location header in the response is set to
-	// registry.wabbit-networks.io instead of registry.wabbit-networks.io:443.
-	locationHostname := location.Hostname()
-	locationPort := location.Port()
-	// if location port 443 is missing, add it back
-	if reqPort == "443" && locationHostname == reqHostname && locationPort == "" {
-		location.Host = locationHostname + ":" + reqPort
-	}
-	url = location.String()
 	req, err = http.NewRequestWithContext(ctx, http.MethodPut, url, content)
 	if err != nil {
 		return err
@@ -579,7 +1017,7 @@ func (s *blobStore) Push(ctx context.Context, expected ocispec.Descriptor, conte
 	if auth := resp.Request.Header.Get("Authorization"); auth != "" {
 		req.Header.Set("Authorization", auth)
 	}
-	resp, err = client.Do(req)
+	resp, err = s.repo.client().Do(req)
 	if err != nil {
 		return err
 	}
@@ -591,6 +1029,131 @@ func (s *blobStore) Push(ctx context.Context, expected ocispec.Descriptor, conte
 	return nil
 }
 
+// cancelUploadAfterInitialPost cancels the upload session started by the
+// initial POST request on a best-effort basis, so that the registry does not
+// keep it until it expires.
+func (s *blobStore) cancelUploadAfterInitialPost(ctx context.Context, req *http.Request, resp *http.Response) {
+	url, err := uploadLocation(req, resp)
+	if err != nil {
+		return
+	}
+	req, err = http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return
+	}
+	// reuse credential from previous POST request
+	if auth := resp.Request.Header.Get("Authorization"); auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	resp, err = s.repo.client().Do(req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+// uploadLocation returns the URL of the upload session in the Location header
+// of the response to the initial POST request req.
+func uploadLocation(req *http.Request, resp *http.Response) (string, error) {
+	location, err := resp.Location()
+	if err != nil {
+		return "", err
+	}
+	// work-around solution for https://github.com/oras-project/oras-go/issues/177
+	// For some registries, if the port 443 is explicitly set to the hostname
+	// like registry.wabbit-networks.io:443/myrepo, blob push will fail since
+	// the hostname of the Location header in the response is set to
+	// registry.wabbit-networks.io instead of registry.wabbit-networks.io:443.
+	reqHostname := req.URL.Hostname()
+	reqPort := req.URL.Port()
+	locationHostname := location.Hostname()
+	locationPort := location.Port()
+	// if location port 443 is missing, add it back
+	if reqPort == "443" && locationHostname == reqHostname && locationPort == "" {
+		location.Host = locationHostname + ":" + reqPort
+	}
+	return location.String(), nil
+}
+
+// Mount makes the blob with the given descriptor in fromRepo available in the
+// repository signified by the receiver.
+//
+// This avoids the need to pull content down from fromRepo only to push it to
+// r.
+//
+// If the registry does not implement mounting, getContent will be used to get
+// the content to push. If getContent is nil, the content will be pulled from
+// the source repository. If the content cannot be obtained, the upload session
+// started by the registry is cancelled.
+// Reference: https://docs.docker.com/registry/spec/api/#cross-repository-blob-mount
+func (s *blobStore) Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) error {
+	// pushing usually requires both pull and push actions.
+	// Reference: https://github.com/distribution/distribution/blob/v2.7.1/registry/handlers/app.go#L921-L930
+	ctx = registryutil.WithScopeHint(ctx, s.repo.Reference, auth.ActionPull, auth.ActionPush)
+
+	// We also need pull access to the source repo.
+	fromRef := s.repo.Reference
+	fromRef.Repository = fromRepo
+	ctx = registryutil.WithScopeHint(ctx, fromRef, auth.ActionPull)
+
+	url := buildRepositoryBlobMountURL(s.repo.PlainHTTP, s.repo.Reference, desc.Digest, fromRepo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.repo.client().Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusCreated {
+		defer resp.Body.Close()
+		// Check the server seems to be behaving.
+		return verifyContentDigest(resp, desc.Digest)
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		defer resp.Body.Close()
+		return errutil.ParseErrorResponse(resp)
+	}
+	resp.Body.Close()
+	// From the [spec]:
+	//
+	// "If a registry does not support cross-repository mounting
+	// or is unable to mount the requested blob,
+	// it SHOULD return a 202.
+	// This indicates that the upload session has begun
+	// and that the client MAY proceed with the upload."
+	//
+	// So we need to get the content from somewhere in order to
+	// push it. If the caller has provided a getContent function, we
+	// can use that, otherwise pull the content from the source repository.
+	//
+	// [spec]: https://github.com/opencontainers/distribution-spec/blob/main/spec.md#mounting-a-blob-from-another-repository
+
+	var r io.ReadCloser
+	if getContent != nil {
+		r, err = getContent()
+	} else {
+		r, err = s.sibling(fromRepo).Fetch(ctx, desc)
+	}
+	if err != nil {
+		// the upload session is not used
+		s.cancelUploadAfterInitialPost(ctx, req, resp)
+		return fmt.Errorf("cannot read source blob: %w", err)
+	}
+	defer r.Close()
+	return s.completePushAfterInitialPost(ctx, req, resp, desc, r)
+}
+
+// sibling returns a blob store for another repository in the same
+// registry.
+func (s *blobStore) sibling(otherRepoName string) *blobStore {
+	otherRepo := *s.repo
+	otherRepo.Reference.Repository = otherRepoName
+	return &blobStore{
+		repo: &otherRepo,
+	}
+}
+
 // Exists returns true if the described content exists.
 func (s *blobStore) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
 	_, err := s.Resolve(ctx, target.Digest.String())
@@ -635,7 +1198,7 @@ func (s *blobStore) Resolve(ctx context.Context, reference string) (ocispec.Desc
 	case http.StatusOK:
 		return generateBlobDescriptor(resp, refDigest)
 	case http.StatusNotFound:
-		return ocispec.Descriptor{}, fmt.Errorf("%s: %w", ref, errdef.ErrNotFound)
+		return ocispec.Descriptor{}, &errdef.NotFoundError{Reference: ref.String()}
 	default:
 		return ocispec.Descriptor{}, errutil.ParseErrorResponse(resp)
 	}
@@ -691,7 +1254,7 @@ func (s *blobStore) FetchReference(ctx context.Context, reference string) (desc
 		}
 		return desc, httputil.NewReadSeekCloser(s.repo.client(), req, resp.Body, desc.Size), nil
 	case http.StatusNotFound:
-		return ocispec.Descriptor{}, nil, fmt.Errorf("%s: %w", ref, errdef.ErrNotFound)
+		return ocispec.Descriptor{}, nil, &errdef.NotFoundError{Reference: ref.String()}
 	default:
 		return ocispec.Descriptor{}, nil, errutil.ParseErrorResponse(resp)
 	}
@@ -736,6 +1299,7 @@ func (s *manifestStore) Fetch(ctx context.Context, target ocispec.Descriptor) (r
 		return nil, err
 	}
 	req.Header.Set("Accept", target.MediaType)
+	acceptGzip(req)
 
 	resp, err := s.repo.client().Do(req)
 	if err != nil {
@@ -746,12 +1310,13 @@ func (s *manifestStore) Fetch(ctx context.Context, target ocispec.Descriptor) (r
 			resp.Body.Close()
 		}
 	}()
+	decodeGzip(resp)
 
 	switch resp.StatusCode {
 	case http.StatusOK:
 		// no-op
 	case http.StatusNotFound:
-		return nil, fmt.Errorf("%s: %w", target.Digest, errdef.ErrNotFound)
+		return nil, &errdef.NotFoundError{Descriptor: target}
 	default:
 		return nil, errutil.ParseErrorResponse(resp)
 	}
@@ -768,12 +1333,22 @@ func (s *manifestStore) Fetch(ctx context.Context, target ocispec.Descriptor) (r
 	if err := verifyContentDigest(resp, target.Digest); err != nil {
 		return nil, err
 	}
+	if resp.Uncompressed {
+		// verify the decompressed manifest as the size is unknown
+		defer resp.Body.Close()
+		manifest, err := content.ReadAll(resp.Body, target)
+		if err != nil {
+			return nil, fmt.Errorf("%s %q: %w", resp.Request.Method, resp.Request.URL, err)
+		}
+		return io.NopCloser(bytes.NewReader(manifest)), nil
+	}
 	return resp.Body, nil
 }
 
 // Push pushes the content, matching the expected descriptor.
+// See also `Repository.Push`.
 func (s *manifestStore) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
-	return s.push(ctx, expected, content, expected.Digest.String())
+	return s.push(ctx, expected, content, expected.Digest.String(), PushReferenceOptions{})
 }
 
 // Exists returns true if the described content exists.
@@ -794,6 +1369,9 @@ func (s *manifestStore) Delete(ctx context.Context, target ocispec.Descriptor) e
 }
 
 // Resolve resolves a reference to a descriptor.
+// The descriptor is resolved by a HEAD request without downloading the
+// manifest, and the request falls back to GET only if the registry does not
+// return the `Docker-Content-Digest` header for a tag.
 // See also `ManifestMediaTypes`.
 func (s *manifestStore) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
 	ref, err := s.repo.ParseReference(reference)
@@ -806,7 +1384,7 @@ func (s *manifestStore) Resolve(ctx context.Context, reference string) (ocispec.
 	if err != nil {
 		return ocispec.Descriptor{}, err
 	}
-	req.Header.Set("Accept", manifestAcceptHeader(s.repo.ManifestMediaTypes))
+	req.Header.Set("Accept", s.repo.manifestAcceptHeader())
 
 	resp, err := s.repo.client().Do(req)
 	if err != nil {
@@ -816,16 +1394,39 @@ func (s *manifestStore) Resolve(ctx context.Context, reference string) (ocispec.
 
 	switch resp.StatusCode {
 	case http.StatusOK:
+		if _, err := ref.Digest(); err != nil && resp.Header.Get(dockerContentDigestHeader) == "" {
+			// the digest of a tag cannot be determined from a HEAD response
+			// without the `Docker-Content-Digest` header, fall back to GET.
+			return s.resolveByFetch(ctx, reference)
+		}
 		return s.generateDescriptor(resp, ref, req.Method)
 	case http.StatusNotFound:
-		return ocispec.Descriptor{}, fmt.Errorf("%s: %w", ref, errdef.ErrNotFound)
+		return ocispec.Descriptor{}, &errdef.NotFoundError{Reference: ref.String()}
 	default:
 		return ocispec.Descriptor{}, errutil.ParseErrorResponse(resp)
 	}
 }
 
+// resolveByFetch resolves a reference to a descriptor by fetching the
+// manifest, where the digest is calculated from the response body if the
+// registry does not return the `Docker-Content-Digest` header.
+func (s *manifestStore) resolveByFetch(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	desc, rc, err := s.FetchReference(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := rc.Close(); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
 // FetchReference fetches the manifest identified by the reference.
 // The reference can be a tag or digest.
+// The received manifest is verified against the digest in the
+// `Docker-Content-Digest` header if present, or the digest reference, while
+// it is read from the returned reader, which fails with an error wrapping
+// content.ErrMismatchedDigest on mismatch instead of returning io.EOF.
 func (s *manifestStore) FetchReference(ctx context.Context, reference string) (desc ocispec.Descriptor, rc io.ReadCloser, err error) {
 	ref, err := s.repo.ParseReference(reference)
 	if err != nil {
@@ -838,7 +1439,8 @@ func (s *manifestStore) FetchReference(ctx context.Context, reference string) (d
 	if err != nil {
 		return ocispec.Descriptor{}, nil, err
 	}
-	req.Header.Set("Accept", manifestAcceptHeader(s.repo.ManifestMediaTypes))
+	req.Header.Set("Accept", s.repo.manifestAcceptHeader())
+	acceptGzip(req)
 
 	resp, err := s.repo.client().Do(req)
 	if err != nil {
@@ -849,6 +1451,7 @@ func (s *manifestStore) FetchReference(ctx context.Context, reference string) (d
 			resp.Body.Close()
 		}
 	}()
+	decodeGzip(resp)
 
 	switch resp.StatusCode {
 	case http.StatusOK:
@@ -856,9 +1459,11 @@ func (s *manifestStore) FetchReference(ctx context.Context, reference string) (d
 		if err != nil {
 			return ocispec.Descriptor{}, nil, err
 		}
-		return desc, resp.Body, nil
+		// verify the manifest while streaming, so that a manifest not
+		// matching its claimed digest is never read to the end
+		return desc, newVerifyReadCloser(resp, desc), nil
 	case http.StatusNotFound:
-		return ocispec.Descriptor{}, nil, fmt.Errorf("%s: %w", ref, errdef.ErrNotFound)
+		return ocispec.Descriptor{}, nil, &errdef.NotFoundError{Reference: ref.String()}
 	default:
 		return ocispec.Descriptor{}, nil, errutil.ParseErrorResponse(resp)
 	}
@@ -878,20 +1483,35 @@ func (s *manifestStore) Tag(ctx context.Context, desc ocispec.Descriptor, refere
 	}
 	defer rc.Close()
 
-	return s.push(ctx, desc, rc, ref.Reference)
+	return s.push(ctx, desc, rc, ref.Reference, PushReferenceOptions{})
 }
 
 // PushReference pushes the manifest with a reference tag.
+// The reference can be a tag or digest. If the reference is a digest, it must
+// match the digest of the expected descriptor.
 func (s *manifestStore) PushReference(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string) error {
+	return s.PushReferenceWithOptions(ctx, expected, content, reference, PushReferenceOptions{})
+}
+
+// PushReferenceWithOptions pushes the manifest with a reference tag
+// conditionally.
+// See also `PushReferenceOptions`.
+func (s *manifestStore) PushReferenceWithOptions(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string, opts PushReferenceOptions) error {
 	ref, err := s.repo.ParseReference(reference)
 	if err != nil {
 		return err
 	}
-	return s.push(ctx, expected, content, ref.Reference)
+	if dgst, err := ref.Digest(); err == nil && dgst != expected.Digest {
+		return fmt.Errorf("%s: mismatch digest reference: expect %s: %w", dgst, expected.Digest, errdef.ErrInvalidDigest)
+	}
+	if opts.ContentType != "" && !isManifest(s.repo.ManifestMediaTypes, ocispec.Descriptor{MediaType: opts.ContentType}) {
+		return fmt.Errorf("%s: content type %q is not a manifest media type: %w", ref, opts.ContentType, errdef.ErrUnsupported)
+	}
+	return s.push(ctx, expected, content, ref.Reference, opts)
 }
 
 // push pushes the manifest content, matching the expected descriptor.
-func (s *manifestStore) push(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string) error {
+func (s *manifestStore) push(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string, opts PushReferenceOptions) error {
 	ref := s.repo.Reference
 	ref.Reference = reference
 	// pushing usually requires both pull and push actions.
@@ -913,7 +1533,18 @@ func (s *manifestStore) push(ctx context.Context, expected ocispec.Descriptor, c
 		return fmt.Errorf("mismatch content length %d: expect %d", req.ContentLength, expected.Size)
 	}
 	req.ContentLength = expected.Size
-	req.Header.Set("Content-Type", expected.MediaType)
+	contentType := expected.MediaType
+	if opts.ContentType != "" {
+		contentType = opts.ContentType
+	}
+	req.Header.Set("Content-Type", contentType)
+	conditional := opts.IfMatch != "" || opts.IfNoneMatch
+	if opts.IfMatch != "" {
+		req.Header.Set("If-Match", strconv.Quote(opts.IfMatch.String()))
+	}
+	if opts.IfNoneMatch {
+		req.Header.Set("If-None-Match", "*")
+	}
 
 	// if the underlying client is an auth client, the content might be read
 	// more than once for obtaining the auth challenge and the actual request.
@@ -940,10 +1571,35 @@ func (s *manifestStore) push(ctx context.Context, expected ocispec.Descriptor, c
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		return errutil.ParseErrorResponse(resp)
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return verifyContentDigest(resp, expected.Digest)
+	case http.StatusPreconditionFailed:
+		return fmt.Errorf("%s: %w", ref, errdef.ErrPreconditionFailed)
+	case http.StatusNotImplemented:
+		if conditional {
+			return fmt.Errorf("%s: conditional push: %w", ref, errdef.ErrUnsupported)
+		}
 	}
-	return verifyContentDigest(resp, expected.Digest)
+	err = errutil.ParseErrorResponse(resp)
+	var errResp *errutil.UnexpectedStatusCodeError
+	if errors.As(err, &errResp) {
+		if blobs := errResp.UnknownBlobs(); len(blobs) > 0 {
+			return &errdef.UnknownBlobsError{
+				Blobs: blobs,
+				Err:   err,
+			}
+		}
+	}
+	return err
+}
+
+// Manifests lists all the manifests in the repository.
+// Since neither the distribution spec nor the extensions discoverable by
+// DiscoverExtensions() provide manifest listing, errdef.ErrUnsupported is
+// always returned for now.
+func (s *manifestStore) Manifests(ctx context.Context, fn func(manifests []ocispec.Descriptor) error) error {
+	return fmt.Errorf("%s: manifest listing: %w", s.repo.Reference, errdef.ErrUnsupported)
 }
 
 // ParseReference parses a reference to a fully qualified reference.
@@ -966,6 +1622,12 @@ func (s *manifestStore) generateDescriptor(resp *http.Response, ref registry.Ref
 	}
 
 	// 2. Validate Size
+	if resp.Uncompressed && resp.ContentLength == -1 && httpMethod == http.MethodGet {
+		// the size of the decompressed content is known only after reading
+		if err := bufferResponse(resp, s.repo.MaxMetadataBytes); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
 	if resp.ContentLength == -1 {
 		return ocispec.Descriptor{}, fmt.Errorf(
 			"%s %q: unknown response Content-Length",
@@ -1014,7 +1676,11 @@ func (s *manifestStore) generateDescriptor(resp *http.Response, ref registry.Ref
 			// GET without server `Docker-Content-Digest` header forces the
 			// expensive calculation
 			var calculatedDigest digest.Digest
-			if calculatedDigest, err = calculateDigestFromResponse(resp, s.repo.MaxMetadataBytes); err != nil {
+			alg := digest.Canonical
+			if len(refDigest) > 0 && refDigest.Algorithm().Available() {
+				alg = refDigest.Algorithm()
+			}
+			if calculatedDigest, err = calculateDigestFromResponse(resp, s.repo.MaxMetadataBytes, alg); err != nil {
 				return ocispec.Descriptor{}, fmt.Errorf("failed to calculate digest on response body; %w", err)
 			}
 			contentDigest = calculatedDigest
@@ -1025,10 +1691,10 @@ func (s *manifestStore) generateDescriptor(resp *http.Response, ref registry.Ref
 
 	if len(refDigest) > 0 && refDigest != contentDigest {
 		return ocispec.Descriptor{}, fmt.Errorf(
-			"%s %q: invalid response; digest mismatch: `%s: %s` vs expected `%s`",
+			"%s %q: invalid response; digest mismatch: `%s: %s` vs expected `%s`: %w",
 			resp.Request.Method, resp.Request.URL,
 			dockerContentDigestHeader, contentDigest,
-			refDigest,
+			refDigest, content.ErrMismatchedDigest,
 		)
 	}
 
@@ -1040,9 +1706,24 @@ func (s *manifestStore) generateDescriptor(resp *http.Response, ref registry.Ref
 	}, nil
 }
 
+// bufferResponse reads the response body into the memory, and sets the
+// Content-Length of the response accordingly.
+func bufferResponse(resp *http.Response, maxMetadataBytes int64) error {
+	defer resp.Body.Close()
+
+	body := limitReader(resp.Body, maxMetadataBytes)
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("%s %q: failed to read response body: %w", resp.Request.Method, resp.Request.URL, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(content))
+	resp.ContentLength = int64(len(content))
+	return nil
+}
+
 // calculateDigestFromResponse calculates the actual digest of the response body
-// taking care not to destroy it in the process.
-func calculateDigestFromResponse(resp *http.Response, maxMetadataBytes int64) (digest.Digest, error) {
+// using the given algorithm, taking care not to destroy it in the process.
+func calculateDigestFromResponse(resp *http.Response, maxMetadataBytes int64, alg digest.Algorithm) (digest.Digest, error) {
 	defer resp.Body.Close()
 
 	body := limitReader(resp.Body, maxMetadataBytes)
@@ -1052,7 +1733,7 @@ func calculateDigestFromResponse(resp *http.Response, maxMetadataBytes int64) (d
 	}
 	resp.Body = io.NopCloser(bytes.NewReader(content))
 
-	return digest.FromBytes(content), nil
+	return alg.FromBytes(content), nil
 }
 
 // verifyContentDigest verifies "Docker-Content-Digest" header if present.
@@ -1076,10 +1757,10 @@ func verifyContentDigest(resp *http.Response, expected digest.Digest) error {
 
 	if contentDigest != expected {
 		return fmt.Errorf(
-			"%s %q: invalid response; digest mismatch: `%s: %s` vs expected `%s`",
+			"%s %q: invalid response; digest mismatch: `%s: %s` vs expected `%s`: %w",
 			resp.Request.Method, resp.Request.URL,
 			dockerContentDigestHeader, contentDigest,
-			expected,
+			expected, content.ErrMismatchedDigest,
 		)
 	}
 
@@ -17,6 +17,7 @@ package remote
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -30,12 +31,17 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/opencontainers/distribution-spec/specs-go/v1/extensions"
 	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
 	"oras.land/oras-go/v2/internal/interfaces"
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote/auth"
@@ -202,6 +208,266 @@ func TestRepository_Fetch(t *testing.T) {
 	}
 }
 
+// contextKey is the type of the context keys in tests.
+type contextKey string
+
+// contextRecordingTransport records the values of the request contexts.
+type contextRecordingTransport struct {
+	key    contextKey
+	lock   sync.Mutex
+	values []interface{}
+}
+
+func (t *contextRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.lock.Lock()
+	t.values = append(t.values, req.Context().Value(t.key))
+	t.lock.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRepository_ContextPropagation(t *testing.T) {
+	blob := []byte("hello world")
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	index := []byte(`{"manifests":[]}`)
+	indexDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(index),
+		Size:      int64(len(index)),
+	}
+	uuid := "4fd53bc9-565d-4527-ab80-3e051ac4880c"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/test/blobs/uploads/":
+			w.Header().Set("Location", "/v2/test/blobs/uploads/"+uuid)
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/test/blobs/uploads/"+uuid:
+			if _, err := io.Copy(io.Discard, r.Body); err != nil {
+				t.Errorf("fail to read: %v", err)
+			}
+			w.Header().Set("Docker-Content-Digest", blobDesc.Digest.String())
+			w.WriteHeader(http.StatusCreated)
+		case (r.Method == http.MethodGet || r.Method == http.MethodHead) && r.URL.Path == "/v2/test/blobs/"+blobDesc.Digest.String():
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Length", strconv.Itoa(len(blob)))
+			w.Header().Set("Docker-Content-Digest", blobDesc.Digest.String())
+			if r.Method == http.MethodGet {
+				w.Write(blob)
+			}
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/test/manifests/"+indexDesc.Digest.String():
+			if _, err := io.Copy(io.Discard, r.Body); err != nil {
+				t.Errorf("fail to read: %v", err)
+			}
+			w.Header().Set("Docker-Content-Digest", indexDesc.Digest.String())
+			w.WriteHeader(http.StatusCreated)
+		case (r.Method == http.MethodGet || r.Method == http.MethodHead) && r.URL.Path == "/v2/test/manifests/"+indexDesc.Digest.String():
+			w.Header().Set("Content-Type", indexDesc.MediaType)
+			w.Header().Set("Content-Length", strconv.Itoa(len(index)))
+			w.Header().Set("Docker-Content-Digest", indexDesc.Digest.String())
+			if r.Method == http.MethodGet {
+				w.Write(index)
+			}
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	key := contextKey("correlation-id")
+	transport := &contextRecordingTransport{key: key}
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	repo.Client = &auth.Client{
+		Client: &http.Client{Transport: transport},
+	}
+
+	operations := map[string]func(ctx context.Context) error{
+		"blob push": func(ctx context.Context) error {
+			return repo.Blobs().Push(ctx, blobDesc, bytes.NewReader(blob))
+		},
+		"blob exists": func(ctx context.Context) error {
+			_, err := repo.Blobs().Exists(ctx, blobDesc)
+			return err
+		},
+		"blob fetch": func(ctx context.Context) error {
+			rc, err := repo.Blobs().Fetch(ctx, blobDesc)
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+			_, err = io.ReadAll(rc)
+			return err
+		},
+		"manifest push": func(ctx context.Context) error {
+			return repo.Manifests().Push(ctx, indexDesc, bytes.NewReader(index))
+		},
+		"manifest resolve": func(ctx context.Context) error {
+			_, err := repo.Manifests().Resolve(ctx, indexDesc.Digest.String())
+			return err
+		},
+		"manifest fetch": func(ctx context.Context) error {
+			rc, err := repo.Manifests().Fetch(ctx, indexDesc)
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+			_, err = io.ReadAll(rc)
+			return err
+		},
+	}
+	for name, operation := range operations {
+		t.Run(name, func(t *testing.T) {
+			transport.values = nil
+			ctx := context.WithValue(context.Background(), key, name)
+			if err := operation(ctx); err != nil {
+				t.Fatalf("%s error = %v", name, err)
+			}
+			if len(transport.values) == 0 {
+				t.Fatal("no request reached the transport")
+			}
+			for i, value := range transport.values {
+				if value != name {
+					t.Errorf("request %d context value = %v, want %v", i, value, name)
+				}
+			}
+		})
+	}
+}
+
+func TestRepository_UserAgent(t *testing.T) {
+	blob := []byte("hello world")
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	index := []byte(`{"manifests":[]}`)
+	indexDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(index),
+		Size:      int64(len(index)),
+	}
+	uuid := "4fd53bc9-565d-4527-ab80-3e051ac4880c"
+	var lock sync.Mutex
+	userAgents := make(map[string]string)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		userAgents[r.Method+" "+r.URL.Path] = r.UserAgent()
+		lock.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/test/blobs/uploads/":
+			w.Header().Set("Location", "/v2/test/blobs/uploads/"+uuid)
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/test/blobs/uploads/"+uuid:
+			if _, err := io.Copy(io.Discard, r.Body); err != nil {
+				t.Errorf("fail to read: %v", err)
+			}
+			w.Header().Set("Docker-Content-Digest", blobDesc.Digest.String())
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/test/manifests/"+indexDesc.Digest.String():
+			if _, err := io.Copy(io.Discard, r.Body); err != nil {
+				t.Errorf("fail to read: %v", err)
+			}
+			w.Header().Set("Docker-Content-Digest", indexDesc.Digest.String())
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/test/_oras/artifacts/referrers":
+			w.Header().Set("ORAS-Api-Version", "oras/1.0")
+			if err := json.NewEncoder(w).Encode(struct {
+				Referrers []ocispec.Descriptor `json:"referrers"`
+			}{}); err != nil {
+				t.Errorf("failed to write response: %v", err)
+			}
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		client        func() *auth.Client
+		wantUserAgent string
+	}{
+		{
+			name: "default user agent",
+			client: func() *auth.Client {
+				return &auth.Client{}
+			},
+			wantUserAgent: auth.DefaultClient.Header.Get("User-Agent"),
+		},
+		{
+			name: "appended product token",
+			client: func() *auth.Client {
+				client := &auth.Client{}
+				client.AppendUserAgent("test-tool/1.0")
+				return client
+			},
+			wantUserAgent: auth.DefaultClient.Header.Get("User-Agent") + " test-tool/1.0",
+		},
+		{
+			name: "custom user agent",
+			client: func() *auth.Client {
+				client := &auth.Client{}
+				client.SetUserAgent("test agent")
+				return client
+			},
+			wantUserAgent: "test agent",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userAgents = make(map[string]string)
+			repo, err := NewRepository(uri.Host + "/test")
+			if err != nil {
+				t.Fatalf("NewRepository() error = %v", err)
+			}
+			repo.PlainHTTP = true
+			repo.Client = tt.client()
+
+			ctx := context.Background()
+			if err := repo.Blobs().Push(ctx, blobDesc, bytes.NewReader(blob)); err != nil {
+				t.Fatalf("Blobs.Push() error = %v", err)
+			}
+			if err := repo.Manifests().Push(ctx, indexDesc, bytes.NewReader(index)); err != nil {
+				t.Fatalf("Manifests.Push() error = %v", err)
+			}
+			if err := repo.Referrers(ctx, indexDesc, "", func([]ocispec.Descriptor) error {
+				return nil
+			}); err != nil {
+				t.Fatalf("Repository.Referrers() error = %v", err)
+			}
+
+			if len(userAgents) != 4 {
+				t.Errorf("requests = %v, want 4 requests", userAgents)
+			}
+			for request, userAgent := range userAgents {
+				if userAgent != tt.wantUserAgent {
+					t.Errorf("%s: User-Agent = %q, want %q", request, userAgent, tt.wantUserAgent)
+				}
+			}
+		})
+	}
+	if !strings.HasPrefix(auth.DefaultClient.Header.Get("User-Agent"), "oras-go") {
+		t.Errorf("default User-Agent = %q, want oras-go", auth.DefaultClient.Header.Get("User-Agent"))
+	}
+}
+
 func TestRepository_Push(t *testing.T) {
 	blob := []byte("hello world")
 	blobDesc := ocispec.Descriptor{
@@ -478,6 +744,13 @@ func TestRepository_Resolve(t *testing.T) {
 	if !errors.Is(err, errdef.ErrNotFound) {
 		t.Errorf("Repository.Resolve() error = %v, wantErr %v", err, errdef.ErrNotFound)
 	}
+	var notFound *errdef.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Repository.Resolve() error = %v, want *errdef.NotFoundError", err)
+	}
+	if want := repoName + "@" + blobDesc.Digest.String(); notFound.Reference != want {
+		t.Errorf("NotFoundError.Reference = %v, want %v", notFound.Reference, want)
+	}
 
 	got, err := repo.Resolve(ctx, indexDesc.Digest.String())
 	if err != nil {
@@ -649,6 +922,198 @@ func TestRepository_PushReference(t *testing.T) {
 	}
 }
 
+func TestRepository_PushReferenceWithOptions(t *testing.T) {
+	index := []byte(`{"manifests":[]}`)
+	indexDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(index),
+		Size:      int64(len(index)),
+	}
+	currentDigest := digest.FromBytes([]byte("current"))
+	ref := "foobar"
+	tagExists := true
+	supported := true
+	var gotContentType string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/v2/test/manifests/"+ref {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		ifMatch := r.Header.Get("If-Match")
+		ifNoneMatch := r.Header.Get("If-None-Match")
+		if !supported && (ifMatch != "" || ifNoneMatch != "") {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		if (ifMatch != "" && (!tagExists || ifMatch != `"`+currentDigest.String()+`"`)) ||
+			(ifNoneMatch == "*" && tagExists) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		gotContentType = r.Header.Get("Content-Type")
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			t.Errorf("fail to read: %v", err)
+		}
+		w.Header().Set("Docker-Content-Digest", indexDesc.Digest.String())
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		tagExists bool
+		supported bool
+		opts      PushReferenceOptions
+		wantErr   error
+		// wantContentType is the expected Content-Type header on success
+		wantContentType string
+	}{
+		{
+			name:            "no condition",
+			tagExists:       true,
+			supported:       true,
+			wantContentType: ocispec.MediaTypeImageIndex,
+		},
+		{
+			name:      "if match",
+			tagExists: true,
+			supported: true,
+			opts:      PushReferenceOptions{IfMatch: currentDigest},
+		},
+		{
+			name:      "if match failed",
+			tagExists: true,
+			supported: true,
+			opts:      PushReferenceOptions{IfMatch: indexDesc.Digest},
+			wantErr:   errdef.ErrPreconditionFailed,
+		},
+		{
+			name:      "if none match",
+			tagExists: false,
+			supported: true,
+			opts:      PushReferenceOptions{IfNoneMatch: true},
+		},
+		{
+			name:      "if none match failed",
+			tagExists: true,
+			supported: true,
+			opts:      PushReferenceOptions{IfNoneMatch: true},
+			wantErr:   errdef.ErrPreconditionFailed,
+		},
+		{
+			name:      "unsupported",
+			tagExists: true,
+			supported: false,
+			opts:      PushReferenceOptions{IfNoneMatch: true},
+			wantErr:   errdef.ErrUnsupported,
+		},
+		{
+			name:            "content type",
+			tagExists:       true,
+			supported:       true,
+			opts:            PushReferenceOptions{ContentType: docker.MediaTypeManifestList},
+			wantContentType: docker.MediaTypeManifestList,
+		},
+		{
+			name:      "invalid content type",
+			tagExists: true,
+			supported: true,
+			opts:      PushReferenceOptions{ContentType: "application/json"},
+			wantErr:   errdef.ErrUnsupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tagExists = tt.tagExists
+			supported = tt.supported
+			err := repo.PushReferenceWithOptions(ctx, indexDesc, bytes.NewReader(index), ref, tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Repository.PushReferenceWithOptions() error = %v", err)
+				}
+				if gotContentType != tt.wantContentType && tt.wantContentType != "" {
+					t.Errorf("Repository.PushReferenceWithOptions() Content-Type = %v, want %v", gotContentType, tt.wantContentType)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Repository.PushReferenceWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRepository_PushReference_Digest(t *testing.T) {
+	index := []byte(`{"manifests":[]}`)
+	indexDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(index),
+		Size:      int64(len(index)),
+	}
+	var gotIndex []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/test/manifests/"+indexDesc.Digest.String():
+			buf := bytes.NewBuffer(nil)
+			if _, err := buf.ReadFrom(r.Body); err != nil {
+				t.Errorf("fail to read: %v", err)
+			}
+			gotIndex = buf.Bytes()
+			w.Header().Set("Docker-Content-Digest", indexDesc.Digest.String())
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	ctx := context.Background()
+
+	// test with digest and fully qualified digest references
+	for _, ref := range []string{
+		indexDesc.Digest.String(),
+		uri.Host + "/test@" + indexDesc.Digest.String(),
+	} {
+		gotIndex = nil
+		err = repo.PushReference(ctx, indexDesc, bytes.NewReader(index), ref)
+		if err != nil {
+			t.Fatalf("Repository.PushReference(%s) error = %v", ref, err)
+		}
+		if !bytes.Equal(gotIndex, index) {
+			t.Errorf("Repository.PushReference(%s) = %v, want %v", ref, gotIndex, index)
+		}
+	}
+
+	// test with mismatched digest reference
+	ref := digest.FromBytes([]byte("foo")).String()
+	err = repo.PushReference(ctx, indexDesc, bytes.NewReader(index), ref)
+	if !errors.Is(err, errdef.ErrInvalidDigest) {
+		t.Errorf("Repository.PushReference() error = %v, wantErr %v", err, errdef.ErrInvalidDigest)
+	}
+}
+
 func TestRepository_FetchReference(t *testing.T) {
 	blob := []byte("hello world")
 	blobDesc := ocispec.Descriptor{
@@ -780,11 +1245,370 @@ func TestRepository_FetchReference(t *testing.T) {
 	if _, err := buf.ReadFrom(rc); err != nil {
 		t.Errorf("fail to read: %v", err)
 	}
-	if err := rc.Close(); err != nil {
-		t.Errorf("fail to close: %v", err)
+	if err := rc.Close(); err != nil {
+		t.Errorf("fail to close: %v", err)
+	}
+	if got := buf.Bytes(); !bytes.Equal(got, index) {
+		t.Errorf("Repository.FetchReference() = %v, want %v", got, index)
+	}
+}
+
+func TestRepository_ResolveWithAnnotations(t *testing.T) {
+	manifest := []byte(`{"mediaType":"application/vnd.oci.image.manifest.v1+json","artifactType":"application/vnd.test","config":{},"layers":[],"annotations":{"foo":"bar"}}`)
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	ref := "foobar"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/test/manifests/" + manifestDesc.Digest.String(),
+			"/v2/test/manifests/" + ref:
+			w.Header().Set("Content-Type", manifestDesc.MediaType)
+			w.Header().Set("Docker-Content-Digest", manifestDesc.Digest.String())
+			if _, err := w.Write(manifest); err != nil {
+				t.Errorf("failed to write %q: %v", r.URL, err)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	ctx := context.Background()
+
+	want := manifestDesc
+	want.ArtifactType = "application/vnd.test"
+	want.Annotations = map[string]string{"foo": "bar"}
+	for _, reference := range []string{ref, manifestDesc.Digest.String()} {
+		got, err := repo.ResolveWithAnnotations(ctx, reference)
+		if err != nil {
+			t.Fatalf("Repository.ResolveWithAnnotations() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Repository.ResolveWithAnnotations() = %v, want %v", got, want)
+		}
+	}
+
+	// test not found
+	_, err = repo.ResolveWithAnnotations(ctx, "unknown")
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Repository.ResolveWithAnnotations() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+
+	// test size limit
+	repo.MaxMetadataBytes = manifestDesc.Size - 1
+	_, err = repo.ResolveWithAnnotations(ctx, ref)
+	if !errors.Is(err, errdef.ErrSizeExceedsLimit) {
+		t.Errorf("Repository.ResolveWithAnnotations() error = %v, wantErr %v", err, errdef.ErrSizeExceedsLimit)
+	}
+}
+
+func TestRepository_AcceptManifestMediaTypes(t *testing.T) {
+	index := []byte(`{"manifests":[]}`)
+	indexDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(index),
+		Size:      int64(len(index)),
+	}
+	manifestList := []byte(`{"manifests":[],"mediaType":"application/vnd.docker.distribution.manifest.list.v2+json"}`)
+	manifestListDesc := ocispec.Descriptor{
+		MediaType: docker.MediaTypeManifestList,
+		Digest:    digest.FromBytes(manifestList),
+		Size:      int64(len(manifestList)),
+	}
+	ref := "foobar"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var content []byte
+		var desc ocispec.Descriptor
+		switch r.URL.Path {
+		case "/v2/test/manifests/" + ref:
+			// negotiate the manifest format
+			if strings.Contains(r.Header.Get("Accept"), docker.MediaTypeManifestList) {
+				content, desc = manifestList, manifestListDesc
+			} else {
+				content, desc = index, indexDesc
+			}
+		case "/v2/test/manifests/" + manifestListDesc.Digest.String():
+			content, desc = manifestList, manifestListDesc
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", desc.MediaType)
+		w.Header().Set("Docker-Content-Digest", desc.Digest.String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		if r.Method == http.MethodGet {
+			if _, err := w.Write(content); err != nil {
+				t.Errorf("failed to write %q: %v", r.URL, err)
+			}
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	ctx := context.Background()
+
+	// test default negotiation
+	got, err := repo.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatalf("Repository.Resolve() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, manifestListDesc) {
+		t.Errorf("Repository.Resolve() = %v, want %v", got, manifestListDesc)
+	}
+
+	// test overridden negotiation
+	repo.AcceptManifestMediaTypes = []string{ocispec.MediaTypeImageIndex}
+	got, err = repo.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatalf("Repository.Resolve() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, indexDesc) {
+		t.Errorf("Repository.Resolve() = %v, want %v", got, indexDesc)
+	}
+	got, rc, err := repo.FetchReference(ctx, ref)
+	if err != nil {
+		t.Fatalf("Repository.FetchReference() error = %v", err)
+	}
+	rc.Close()
+	if !reflect.DeepEqual(got, indexDesc) {
+		t.Errorf("Repository.FetchReference() = %v, want %v", got, indexDesc)
+	}
+
+	// docker manifest lists are still identified as manifests
+	rc, err = repo.Fetch(ctx, manifestListDesc)
+	if err != nil {
+		t.Fatalf("Repository.Fetch() error = %v", err)
+	}
+	rc.Close()
+}
+
+func TestRepository_Mount(t *testing.T) {
+	blob := []byte("hello world")
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	uuid := "4fd53bc9-565d-4527-ab80-3e051ac4880c"
+	var mountable bool
+	var gotMount, gotFetch, gotCancel int
+	var gotBlob []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/test2/blobs/uploads/":
+			gotMount++
+			if got, want := r.URL.Query().Get("mount"), blobDesc.Digest.String(); got != want {
+				t.Errorf("unexpected mount param; got %q want %q", got, want)
+			}
+			if got, want := r.URL.Query().Get("from"), "test"; got != want {
+				t.Errorf("unexpected from param; got %q want %q", got, want)
+			}
+			if mountable {
+				w.Header().Set("Docker-Content-Digest", blobDesc.Digest.String())
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+			w.Header().Set("Location", "/v2/test2/blobs/uploads/"+uuid)
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/test2/blobs/uploads/"+uuid:
+			if got, want := r.URL.Query().Get("digest"), blobDesc.Digest.String(); got != want {
+				t.Errorf("unexpected digest param; got %q want %q", got, want)
+			}
+			buf := bytes.NewBuffer(nil)
+			if _, err := buf.ReadFrom(r.Body); err != nil {
+				t.Errorf("fail to read: %v", err)
+			}
+			gotBlob = buf.Bytes()
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodDelete && r.URL.Path == "/v2/test2/blobs/uploads/"+uuid:
+			gotCancel++
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/test/blobs/"+blobDesc.Digest.String():
+			gotFetch++
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Docker-Content-Digest", blobDesc.Digest.String())
+			if _, err := w.Write(blob); err != nil {
+				t.Errorf("failed to write %q: %v", r.URL, err)
+			}
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test2")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	ctx := context.Background()
+
+	// test mounting
+	mountable = true
+	getContent := func() (io.ReadCloser, error) {
+		t.Error("getContent() called on successful mount")
+		return nil, errors.New("should not be called")
+	}
+	if err := repo.Mount(ctx, blobDesc, "test", getContent); err != nil {
+		t.Fatalf("Repository.Mount() error = %v", err)
+	}
+	if gotMount != 1 || gotBlob != nil {
+		t.Errorf("mount count = %v, uploaded = %v, want 1, nil", gotMount, gotBlob)
+	}
+
+	// test falling back to uploading with getContent
+	mountable = false
+	getContent = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(blob)), nil
+	}
+	if err := repo.Mount(ctx, blobDesc, "test", getContent); err != nil {
+		t.Fatalf("Repository.Mount() error = %v", err)
+	}
+	if !bytes.Equal(gotBlob, blob) {
+		t.Errorf("uploaded blob = %v, want %v", gotBlob, blob)
+	}
+	if gotFetch != 0 {
+		t.Errorf("fetch count = %v, want 0", gotFetch)
+	}
+
+	// test falling back to uploading from the source repository
+	gotBlob = nil
+	if err := repo.Mount(ctx, blobDesc, "test", nil); err != nil {
+		t.Fatalf("Repository.Mount() error = %v", err)
+	}
+	if !bytes.Equal(gotBlob, blob) {
+		t.Errorf("uploaded blob = %v, want %v", gotBlob, blob)
+	}
+	if gotFetch != 1 {
+		t.Errorf("fetch count = %v, want 1", gotFetch)
+	}
+	if gotCancel != 0 {
+		t.Errorf("cancel count = %v, want 0", gotCancel)
+	}
+
+	// test cancelling the upload session if getContent fails
+	gotBlob = nil
+	errGetContent := errors.New("no content")
+	getContent = func() (io.ReadCloser, error) {
+		return nil, errGetContent
+	}
+	if err := repo.Mount(ctx, blobDesc, "test", getContent); !errors.Is(err, errGetContent) {
+		t.Fatalf("Repository.Mount() error = %v, wantErr %v", err, errGetContent)
+	}
+	if gotBlob != nil {
+		t.Errorf("uploaded blob = %v, want nil", gotBlob)
+	}
+	if gotCancel != 1 {
+		t.Errorf("cancel count = %v, want 1", gotCancel)
+	}
+}
+
+func TestRepository_FetchRange(t *testing.T) {
+	blob := []byte("hello world")
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	var rangeSupported bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v2/test/blobs/"+blobDesc.Digest.String() {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Docker-Content-Digest", blobDesc.Digest.String())
+		rangeHeader := r.Header.Get("Range")
+		if !rangeSupported || rangeHeader == "" {
+			if _, err := w.Write(blob); err != nil {
+				t.Errorf("failed to write %q: %v", r.URL, err)
+			}
+			return
+		}
+		var start, end int
+		if n, _ := fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end); n == 1 {
+			end = len(blob) - 1
+		} else if n != 2 {
+			t.Errorf("invalid range header: %s", rangeHeader)
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(blob)))
+		w.WriteHeader(http.StatusPartialContent)
+		if _, err := w.Write(blob[start : end+1]); err != nil {
+			t.Errorf("failed to write %q: %v", r.URL, err)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	ctx := context.Background()
+
+	for _, supported := range []bool{true, false} {
+		rangeSupported = supported
+		for _, offset := range []int64{0, 6, blobDesc.Size} {
+			rc, err := repo.FetchRange(ctx, blobDesc, offset)
+			if err != nil {
+				t.Fatalf("Repository.FetchRange(%d) error = %v", offset, err)
+			}
+			got, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				t.Fatalf("Repository.FetchRange(%d).Read() error = %v", offset, err)
+			}
+			if want := blob[offset:]; !bytes.Equal(got, want) {
+				t.Errorf("Repository.FetchRange(%d) = %q, want %q (range supported: %v)", offset, got, want, supported)
+			}
+		}
 	}
-	if got := buf.Bytes(); !bytes.Equal(got, index) {
-		t.Errorf("Repository.FetchReference() = %v, want %v", got, index)
+
+	if _, err := repo.FetchRange(ctx, blobDesc, blobDesc.Size+1); err == nil {
+		t.Errorf("Repository.FetchRange() error = %v, wantErr %v", err, true)
 	}
 }
 
@@ -858,6 +1682,106 @@ func TestRepository_Tags(t *testing.T) {
 	}
 }
 
+func TestRepository_TagsPage(t *testing.T) {
+	allTags := []string{"a", "b", "c", "d", "e"}
+	ignorePageSize := false
+	opaqueLink := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v2/test/tags/list" {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		q := r.URL.Query()
+		tags := allTags
+		if last := q.Get("last"); last != "" {
+			for i, tag := range tags {
+				if tag == last {
+					tags = tags[i+1:]
+					break
+				}
+			}
+		}
+		if n, err := strconv.Atoi(q.Get("n")); err == nil && !ignorePageSize && n < len(tags) {
+			tags = tags[:n]
+			if opaqueLink {
+				w.Header().Set("Link", `</v2/test/tags/list?cursor=opaque>; rel="next"`)
+			} else {
+				w.Header().Set("Link", fmt.Sprintf(`</v2/test/tags/list?n=%d&last=%s>; rel="next"`, n, tags[n-1]))
+			}
+		}
+		result := struct {
+			Tags []string `json:"tags"`
+		}{
+			Tags: tags,
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		ignorePageSize bool
+		opaqueLink     bool
+		want           [][]string
+	}{
+		{
+			name: "paginated",
+			want: [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+		},
+		{
+			name:       "opaque link",
+			opaqueLink: true,
+			want:       [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+		},
+		{
+			name:           "page size ignored",
+			ignorePageSize: true,
+			want:           [][]string{allTags},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ignorePageSize = tt.ignorePageSize
+			opaqueLink = tt.opaqueLink
+			var got [][]string
+			opts := TagsPageOptions{
+				PageSize: 2,
+			}
+			for {
+				tags, next, err := repo.TagsPage(ctx, opts)
+				if err != nil {
+					t.Fatalf("Repository.TagsPage() error = %v", err)
+				}
+				got = append(got, tags)
+				if next == "" {
+					break
+				}
+				if len(got) > len(tt.want) {
+					t.Fatalf("Repository.TagsPage() returns too many pages: %v", got)
+				}
+				opts.Last = next
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Repository.TagsPage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRepository_Predecessors(t *testing.T) {
 	manifest := []byte(`{"layers":[]}`)
 	manifestDesc := ocispec.Descriptor{
@@ -1089,6 +2013,88 @@ func TestRepository_Referrers(t *testing.T) {
 	}
 }
 
+func TestRepository_Referrers_PrevLink(t *testing.T) {
+	manifest := []byte(`{"layers":[]}`)
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	referrerSet := [][]ocispec.Descriptor{
+		{
+			{
+				MediaType:    ocispec.MediaTypeArtifactManifest,
+				Size:         1,
+				Digest:       digest.FromString("1"),
+				ArtifactType: "application/vnd.test",
+			},
+		},
+		{
+			{
+				MediaType:    ocispec.MediaTypeArtifactManifest,
+				Size:         2,
+				Digest:       digest.FromString("2"),
+				ArtifactType: "application/vnd.test",
+			},
+		},
+	}
+	path := "/v2/test/_oras/artifacts/referrers"
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != path {
+			t.Errorf("unexpected access: %s %q", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ORAS-Api-Version", "oras/1.0")
+		var referrers []ocispec.Descriptor
+		switch r.URL.Query().Get("test") {
+		case "next":
+			// the last page links to the previous page only
+			referrers = referrerSet[1]
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?digest=%s>; rel="prev"`, ts.URL, path, manifestDesc.Digest))
+		default:
+			referrers = referrerSet[0]
+			w.Header().Add("Link", fmt.Sprintf(`<%s?digest=%s&test=first>; rel="first"`, path, manifestDesc.Digest))
+			w.Header().Add("Link", fmt.Sprintf(`<%s?test=next>; rel="next"`, path))
+		}
+		result := struct {
+			Referrers []ocispec.Descriptor `json:"referrers"`
+		}{
+			Referrers: referrers,
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+
+	ctx := context.Background()
+	var got [][]ocispec.Descriptor
+	if err := repo.Referrers(ctx, manifestDesc, "", func(referrers []ocispec.Descriptor) error {
+		if len(got) >= len(referrerSet) {
+			t.Fatalf("out of index bound: %d", len(got))
+		}
+		got = append(got, referrers)
+		return nil
+	}); err != nil {
+		t.Errorf("Repository.Referrers() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, referrerSet) {
+		t.Errorf("Repository.Referrers() = %v, want %v", got, referrerSet)
+	}
+}
+
 func TestRepository_Referrers_Incompatible(t *testing.T) {
 	manifest := []byte(`{"layers":[]}`)
 	manifestDesc := ocispec.Descriptor{
@@ -1292,6 +2298,210 @@ func TestRepository_Referrers_Fallback(t *testing.T) {
 	}
 }
 
+func TestRepository_Ping(t *testing.T) {
+	manifest := []byte(`{"layers":[]}`)
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	var referrersAPI bool
+	var referrersRequests, legacyRequests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/":
+			w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		case "/v2/test/_oras/artifacts/referrers":
+			atomic.AddInt32(&referrersRequests, 1)
+			if !referrersAPI {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("ORAS-Api-Version", "oras/1.0")
+			if _, err := w.Write([]byte(`{"referrers":[]}`)); err != nil {
+				t.Errorf("failed to write response: %v", err)
+			}
+		case "/oras/artifacts/v1/test/manifests/" + manifestDesc.Digest.String() + "/referrers":
+			atomic.AddInt32(&legacyRequests, 1)
+			if _, err := w.Write([]byte(`{"references":[]}`)); err != nil {
+				t.Errorf("failed to write response: %v", err)
+			}
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, supported := range []bool{true, false} {
+		referrersAPI = supported
+		atomic.StoreInt32(&referrersRequests, 0)
+		atomic.StoreInt32(&legacyRequests, 0)
+
+		repo, err := NewRepository(uri.Host + "/test")
+		if err != nil {
+			t.Fatalf("NewRepository() error = %v", err)
+		}
+		repo.PlainHTTP = true
+		got, err := repo.Ping(ctx)
+		if err != nil {
+			t.Fatalf("Repository.Ping() error = %v", err)
+		}
+		want := registry.Capabilities{
+			APIVersion:   "registry/2.0",
+			ReferrersAPI: supported,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Repository.Ping() = %v, want %v", got, want)
+		}
+
+		// the Referrers API known to be unsupported should not be requested
+		if err := repo.Referrers(ctx, manifestDesc, "", func(referrers []ocispec.Descriptor) error {
+			return nil
+		}); err != nil {
+			t.Fatalf("Repository.Referrers() error = %v", err)
+		}
+		wantReferrers, wantLegacy := int32(2), int32(0)
+		if !supported {
+			wantReferrers, wantLegacy = 1, 1
+		}
+		if got := atomic.LoadInt32(&referrersRequests); got != wantReferrers {
+			t.Errorf("count(referrers requests) = %v, want %v", got, wantReferrers)
+		}
+		if got := atomic.LoadInt32(&legacyRequests); got != wantLegacy {
+			t.Errorf("count(legacy referrers requests) = %v, want %v", got, wantLegacy)
+		}
+	}
+}
+
+func TestRepository_Referrers_SkipReferrersAPI(t *testing.T) {
+	manifest := []byte(`{"layers":[]}`)
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	referrers := []ocispec.Descriptor{
+		{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			Size:         1,
+			Digest:       digest.FromString("1"),
+			ArtifactType: "application/vnd.foo",
+		},
+		{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			Size:         2,
+			Digest:       digest.FromString("2"),
+			ArtifactType: "application/vnd.bar",
+		},
+	}
+	index := ocispec.Index{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2,
+		},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: referrers,
+	}
+	indexJSON, err := json.Marshal(index)
+	if err != nil {
+		t.Fatalf("failed to marshal index: %v", err)
+	}
+	indexDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, indexJSON)
+	tag := "sha256-" + manifestDesc.Digest.Encoded()
+	var tagged bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/":
+			w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/test/manifests/"+tag:
+			if !tagged {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", indexDesc.MediaType)
+			w.Header().Set("Docker-Content-Digest", indexDesc.Digest.String())
+			if _, err := w.Write(indexJSON); err != nil {
+				t.Errorf("failed to write response: %v", err)
+			}
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	repo.SkipReferrersAPI = true
+	ctx := context.Background()
+
+	// the Referrers API should not be probed
+	caps, err := repo.Ping(ctx)
+	if err != nil {
+		t.Fatalf("Repository.Ping() error = %v", err)
+	}
+	wantCaps := registry.Capabilities{
+		APIVersion: "registry/2.0",
+	}
+	if !reflect.DeepEqual(caps, wantCaps) {
+		t.Errorf("Repository.Ping() = %v, want %v", caps, wantCaps)
+	}
+
+	// no referrers tag
+	var got []ocispec.Descriptor
+	if err := repo.Referrers(ctx, manifestDesc, "", func(referrers []ocispec.Descriptor) error {
+		got = append(got, referrers...)
+		return nil
+	}); err != nil {
+		t.Fatalf("Repository.Referrers() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Repository.Referrers() = %v, want none", got)
+	}
+
+	// list from the referrers tag
+	tagged = true
+	got = nil
+	if err := repo.Referrers(ctx, manifestDesc, "", func(referrers []ocispec.Descriptor) error {
+		got = append(got, referrers...)
+		return nil
+	}); err != nil {
+		t.Fatalf("Repository.Referrers() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, referrers) {
+		t.Errorf("Repository.Referrers() = %v, want %v", got, referrers)
+	}
+
+	// filter by artifact type
+	got = nil
+	if err := repo.Referrers(ctx, manifestDesc, "application/vnd.bar", func(referrers []ocispec.Descriptor) error {
+		got = append(got, referrers...)
+		return nil
+	}); err != nil {
+		t.Fatalf("Repository.Referrers() error = %v", err)
+	}
+	if want := referrers[1:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Repository.Referrers() = %v, want %v", got, want)
+	}
+}
+
 func TestRepository_Referrers_ServerFiltering(t *testing.T) {
 	manifest := []byte(`{"layers":[]}`)
 	manifestDesc := ocispec.Descriptor{
@@ -1543,8 +2753,50 @@ func TestRepository_Referrers_ClientFiltering(t *testing.T) {
 	}); err != nil {
 		t.Errorf("Repository.Referrers() error = %v", err)
 	}
-	if index != len(filteredReferrerSet) {
-		t.Errorf("fn invoked %d time(s), want %d", index, len(referrerSet))
+	if index != len(filteredReferrerSet) {
+		t.Errorf("fn invoked %d time(s), want %d", index, len(referrerSet))
+	}
+}
+
+func Test_isReferrersFilterApplied(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied string
+		want    bool
+	}{
+		{
+			name:    "no header",
+			applied: "",
+			want:    false,
+		},
+		{
+			name:    "artifactType applied",
+			applied: "artifactType",
+			want:    true,
+		},
+		{
+			name:    "multiple filters applied",
+			applied: "mediaType, artifactType",
+			want:    true,
+		},
+		{
+			name:    "other filters applied",
+			applied: "mediaType,annotations",
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				Header: http.Header{},
+			}
+			if tt.applied != "" {
+				resp.Header.Set(headerOCIFiltersApplied, tt.applied)
+			}
+			if got := isReferrersFilterApplied(resp, "artifactType"); got != tt.want {
+				t.Errorf("isReferrersFilterApplied() = %v, want %v", got, tt.want)
+			}
+		})
 	}
 }
 
@@ -2450,6 +3702,24 @@ func TestManifestStoreInterface(t *testing.T) {
 	}
 }
 
+func Test_ManifestStore_Manifests(t *testing.T) {
+	repo, err := NewRepository("localhost:5000/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	lister, ok := repo.Manifests().(registry.ManifestLister)
+	if !ok {
+		t.Fatal("manifestStore does not conform registry.ManifestLister")
+	}
+	err = lister.Manifests(context.Background(), func(manifests []ocispec.Descriptor) error {
+		t.Errorf("unexpected manifests: %v", manifests)
+		return nil
+	})
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("manifestStore.Manifests() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
+
 func Test_ManifestStore_Fetch(t *testing.T) {
 	manifest := []byte(`{"layers":[]}`)
 	manifestDesc := ocispec.Descriptor{
@@ -2518,6 +3788,13 @@ func Test_ManifestStore_Fetch(t *testing.T) {
 	if !errors.Is(err, errdef.ErrNotFound) {
 		t.Errorf("Manifests.Fetch() error = %v, wantErr %v", err, errdef.ErrNotFound)
 	}
+	var notFound *errdef.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Manifests.Fetch() error = %v, want *errdef.NotFoundError", err)
+	}
+	if !reflect.DeepEqual(notFound.Descriptor, contentDesc) {
+		t.Errorf("NotFoundError.Descriptor = %v, want %v", notFound.Descriptor, contentDesc)
+	}
 }
 
 func Test_ManifestStore_Push(t *testing.T) {
@@ -2571,6 +3848,56 @@ func Test_ManifestStore_Push(t *testing.T) {
 	}
 }
 
+func Test_ManifestStore_Push_UnknownBlobs(t *testing.T) {
+	config := digest.FromString("config")
+	layer := digest.FromString("layer")
+	manifest := []byte(`{"layers":[]}`)
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/v2/test/manifests/"+manifestDesc.Digest.String() {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		// the detail of the distribution registry is an object with a
+		// capitalized digest field
+		msg := `{"errors":[` +
+			`{"code":"MANIFEST_BLOB_UNKNOWN","message":"blob unknown to registry","detail":{"Digest":"` + config.String() + `"}},` +
+			`{"code":"MANIFEST_BLOB_UNKNOWN","message":"blob unknown to registry","detail":"` + layer.String() + `"}` +
+			`]}`
+		w.WriteHeader(http.StatusBadRequest)
+		if _, err := w.Write([]byte(msg)); err != nil {
+			t.Errorf("failed to write %q: %v", r.URL, err)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	ctx := context.Background()
+
+	err = repo.Push(ctx, manifestDesc, bytes.NewReader(manifest))
+	var unknownErr *errdef.UnknownBlobsError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("Repository.Push() error = %v, want %T", err, unknownErr)
+	}
+	want := []digest.Digest{config, layer}
+	if got := unknownErr.Blobs; !reflect.DeepEqual(got, want) {
+		t.Errorf("Repository.Push() unknown blobs = %v, want %v", got, want)
+	}
+}
+
 func Test_ManifestStore_Exists(t *testing.T) {
 	manifest := []byte(`{"layers":[]}`)
 	manifestDesc := ocispec.Descriptor{
@@ -2782,6 +4109,83 @@ func Test_ManifestStore_Resolve(t *testing.T) {
 	}
 }
 
+func Test_ManifestStore_Resolve_FallbackToGet(t *testing.T) {
+	manifest := []byte(`{"layers":[]}`)
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	ref := "foobar"
+	var headCount, getCount int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/test/manifests/" + manifestDesc.Digest.String(),
+			"/v2/test/manifests/" + ref:
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// the `Docker-Content-Digest` header is never returned
+		w.Header().Set("Content-Type", manifestDesc.MediaType)
+		w.Header().Set("Content-Length", strconv.Itoa(int(manifestDesc.Size)))
+		switch r.Method {
+		case http.MethodHead:
+			headCount++
+		case http.MethodGet:
+			getCount++
+			if _, err := w.Write(manifest); err != nil {
+				t.Errorf("failed to write %q: %v", r.URL, err)
+			}
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	store := repo.Manifests()
+	ctx := context.Background()
+
+	// resolving by digest trusts the digest without GET
+	got, err := store.Resolve(ctx, manifestDesc.Digest.String())
+	if err != nil {
+		t.Fatalf("Manifests.Resolve() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, manifestDesc) {
+		t.Errorf("Manifests.Resolve() = %v, want %v", got, manifestDesc)
+	}
+	if headCount != 1 || getCount != 0 {
+		t.Errorf("HEAD count = %v, GET count = %v, want 1, 0", headCount, getCount)
+	}
+
+	// resolving by tag falls back to GET
+	got, err = store.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatalf("Manifests.Resolve() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, manifestDesc) {
+		t.Errorf("Manifests.Resolve() = %v, want %v", got, manifestDesc)
+	}
+	if headCount != 2 || getCount != 1 {
+		t.Errorf("HEAD count = %v, GET count = %v, want 2, 1", headCount, getCount)
+	}
+
+	_, err = store.Resolve(ctx, "unknown")
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Manifests.Resolve() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+}
+
 func Test_ManifestStore_FetchReference(t *testing.T) {
 	manifest := []byte(`{"layers":[]}`)
 	manifestDesc := ocispec.Descriptor{
@@ -3642,3 +5046,246 @@ func TestRepository_ParseReference(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_GzipResponses(t *testing.T) {
+	manifest := []byte(`{"layers":[]}`)
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	blob := []byte("hello world")
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	tags := []string{"v1", "v2", "v3"}
+	gzipBytes := func(p []byte) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(p); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	tampered := false
+	digestHeader := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		acceptEncoding := r.Header.Get("Accept-Encoding")
+		var body []byte
+		switch r.URL.Path {
+		case "/v2/test/manifests/" + manifestDesc.Digest.String(), "/v2/test/manifests/latest":
+			w.Header().Set("Content-Type", manifestDesc.MediaType)
+			if digestHeader {
+				w.Header().Set("Docker-Content-Digest", manifestDesc.Digest.String())
+			}
+			body = manifest
+			if tampered {
+				body = []byte(`{"layers":{}}`)
+			}
+		case "/v2/test/tags/list":
+			var err error
+			body, err = json.Marshal(map[string][]string{"tags": tags})
+			if err != nil {
+				t.Errorf("failed to marshal tags: %v", err)
+			}
+		case "/v2/test/blobs/" + blobDesc.Digest.String():
+			if acceptEncoding != "identity" {
+				t.Errorf("Accept-Encoding of blob = %q, want identity", acceptEncoding)
+			}
+			w.Write(blob)
+			return
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if acceptEncoding != "gzip" {
+			t.Errorf("Accept-Encoding of %s = %q, want gzip", r.URL.Path, acceptEncoding)
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(gzipBytes(body))
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	ctx := context.Background()
+
+	// test fetching the manifest
+	rc, err := repo.Fetch(ctx, manifestDesc)
+	if err != nil {
+		t.Fatalf("Repository.Fetch() error = %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatalf("Repository.Fetch().Read() error = %v", err)
+	}
+	if !bytes.Equal(got, manifest) {
+		t.Errorf("Repository.Fetch() = %s, want %s", got, manifest)
+	}
+
+	// test fetching the manifest by reference with or without the digest
+	// header
+	for _, digestHeader = range []bool{true, false} {
+		desc, rc, err := repo.FetchReference(ctx, "latest")
+		if err != nil {
+			t.Fatalf("Repository.FetchReference() error = %v", err)
+		}
+		got, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Repository.FetchReference().Read() error = %v", err)
+		}
+		if !reflect.DeepEqual(desc, manifestDesc) {
+			t.Errorf("Repository.FetchReference() = %v, want %v", desc, manifestDesc)
+		}
+		if !bytes.Equal(got, manifest) {
+			t.Errorf("Repository.FetchReference() = %s, want %s", got, manifest)
+		}
+	}
+
+	// test listing the tags
+	var gotTags []string
+	if err := repo.Tags(ctx, "", func(tags []string) error {
+		gotTags = append(gotTags, tags...)
+		return nil
+	}); err != nil {
+		t.Fatalf("Repository.Tags() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotTags, tags) {
+		t.Errorf("Repository.Tags() = %v, want %v", gotTags, tags)
+	}
+
+	// test fetching the blob without gzip
+	rc, err = repo.Fetch(ctx, blobDesc)
+	if err != nil {
+		t.Fatalf("Repository.Fetch() error = %v", err)
+	}
+	got, err = io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatalf("Repository.Fetch().Read() error = %v", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("Repository.Fetch() = %s, want %s", got, blob)
+	}
+
+	// test verifying the decompressed manifest
+	tampered = true
+	if _, err := repo.Fetch(ctx, manifestDesc); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Repository.Fetch() error = %v, want %v", err, content.ErrMismatchedDigest)
+	}
+}
+
+func TestManifestStore_FetchReference_DigestMismatch(t *testing.T) {
+	manifest := []byte(`{"layers":[]}`)
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	tampered := []byte(`{"layers":{}}`)
+	ref := "foobar"
+	var headerDigest digest.Digest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/test/manifests/" + ref, "/v2/test/manifests/" + manifestDesc.Digest.String():
+			w.Header().Set("Content-Type", manifestDesc.MediaType)
+			if headerDigest != "" {
+				w.Header().Set("Docker-Content-Digest", headerDigest.String())
+			}
+			if _, err := w.Write(tampered); err != nil {
+				t.Errorf("failed to write %q: %v", r.URL, err)
+			}
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	store := repo.Manifests().(*manifestStore)
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		reference    string
+		headerDigest digest.Digest
+	}{
+		{
+			name:         "tag with the digest header not matching the body",
+			reference:    ref,
+			headerDigest: manifestDesc.Digest,
+		},
+		{
+			name:         "digest with the digest header not matching the body",
+			reference:    manifestDesc.Digest.String(),
+			headerDigest: manifestDesc.Digest,
+		},
+		{
+			name:      "digest without the digest header",
+			reference: manifestDesc.Digest.String(),
+		},
+		{
+			name:         "digest with the digest header not matching the reference",
+			reference:    manifestDesc.Digest.String(),
+			headerDigest: digest.FromBytes(tampered),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headerDigest = tt.headerDigest
+			_, rc, err := store.FetchReference(ctx, tt.reference)
+			if err == nil {
+				// the manifest is verified while being read
+				_, err = io.ReadAll(rc)
+				rc.Close()
+			}
+			if !errors.Is(err, content.ErrMismatchedDigest) {
+				t.Errorf("manifestStore.FetchReference() error = %v, wantErr %v", err, content.ErrMismatchedDigest)
+			}
+		})
+	}
+
+	// manifests larger than MaxMetadataBytes are streamed
+	repo.MaxMetadataBytes = 1
+	headerDigest = digest.FromBytes(tampered)
+	_, rc, err := store.FetchReference(ctx, ref)
+	if err != nil {
+		t.Fatalf("manifestStore.FetchReference() error = %v", err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+	if !bytes.Equal(got, tampered) {
+		t.Errorf("manifestStore.FetchReference() = %v, want %v", string(got), string(tampered))
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// timeoutError is returned for a request timed out by RequestTimeout.
+// It is a net.Error reporting a timeout, and thus is retryable.
+type timeoutError struct {
+	method  string
+	url     string
+	timeout time.Duration
+	idle    bool
+}
+
+// Error returns the error message.
+func (e *timeoutError) Error() string {
+	if e.idle {
+		return fmt.Sprintf("%s %q: no response body received within %v", e.method, e.url, e.timeout)
+	}
+	return fmt.Sprintf("%s %q: no response received within %v", e.method, e.url, e.timeout)
+}
+
+// Unwrap returns os.ErrDeadlineExceeded.
+func (e *timeoutError) Unwrap() error {
+	return os.ErrDeadlineExceeded
+}
+
+// Timeout returns true.
+func (e *timeoutError) Timeout() bool {
+	return true
+}
+
+// Temporary returns true.
+func (e *timeoutError) Temporary() bool {
+	return true
+}
+
+// timeoutClient is a Client aborting the requests not responding within a
+// timeout, and the reads of the response bodies stalled for the timeout.
+type timeoutClient struct {
+	Client
+	timeout time.Duration
+}
+
+// Do sends the request, and aborts it if no response is received within the
+// timeout.
+func (c *timeoutClient) Do(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	t := &requestTimer{
+		timeout: c.timeout,
+		cancel:  cancel,
+	}
+	t.timer = time.AfterFunc(c.timeout, t.expire)
+	resp, err := c.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		t.stop()
+		return nil, t.check(req, err, false)
+	}
+	// the timer is re-armed only while the body is being read, so that a
+	// slow consumer is not mistaken for a stalled registry
+	t.timer.Stop()
+	if resp.Body != nil {
+		resp.Body = &idleTimeoutReader{
+			ReadCloser: resp.Body,
+			req:        req,
+			timer:      t,
+		}
+	} else {
+		t.stop()
+	}
+	return resp, nil
+}
+
+// requestTimer cancels the context of a request on expiry.
+type requestTimer struct {
+	timer   *time.Timer
+	timeout time.Duration
+	cancel  context.CancelFunc
+	expired int32
+}
+
+// expire marks the timer expired, and cancels the request.
+func (t *requestTimer) expire() {
+	atomic.StoreInt32(&t.expired, 1)
+	t.cancel()
+}
+
+// stop stops the timer, and releases the context of the request.
+func (t *requestTimer) stop() {
+	t.timer.Stop()
+	t.cancel()
+}
+
+// check returns a *timeoutError instead of err if the timer has expired.
+func (t *requestTimer) check(req *http.Request, err error, idle bool) error {
+	if atomic.LoadInt32(&t.expired) == 0 {
+		return err
+	}
+	return &timeoutError{
+		method:  req.Method,
+		url:     req.URL.Redacted(),
+		timeout: t.timeout,
+		idle:    idle,
+	}
+}
+
+// idleTimeoutReader aborts the read of a response body if no byte is
+// received within the timeout.
+type idleTimeoutReader struct {
+	io.ReadCloser
+	req   *http.Request
+	timer *requestTimer
+}
+
+// Read reads up to len(p) bytes into p.
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	r.timer.timer.Reset(r.timer.timeout)
+	n, err := r.ReadCloser.Read(p)
+	r.timer.timer.Stop()
+	if err != nil && err != io.EOF {
+		err = r.timer.check(r.req, err, true)
+	}
+	return n, err
+}
+
+// Close closes the response body.
+func (r *idleTimeoutReader) Close() error {
+	r.timer.stop()
+	return r.ReadCloser.Close()
+}
+
+// timeoutRequests returns a client aborting the requests with timeout.
+// If timeout is not positive, client is returned as is.
+func timeoutRequests(client Client, timeout time.Duration) Client {
+	if timeout <= 0 {
+		return client
+	}
+	return &timeoutClient{
+		Client:  client,
+		timeout: timeout,
+	}
+}
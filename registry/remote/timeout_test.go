@@ -0,0 +1,137 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestRepository_RequestTimeout(t *testing.T) {
+	blob := []byte("hello world")
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	timeout := 100 * time.Millisecond
+	var headerDelay, chunkDelay int64 // in time.Duration
+	setDelays := func(header, chunk time.Duration) {
+		atomic.StoreInt64(&headerDelay, int64(header))
+		atomic.StoreInt64(&chunkDelay, int64(chunk))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/test/blobs/"+blobDesc.Digest.String() {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		select {
+		case <-time.After(time.Duration(atomic.LoadInt64(&headerDelay))):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Docker-Content-Digest", blobDesc.Digest.String())
+		w.WriteHeader(http.StatusOK)
+		// stream the blob byte by byte
+		delay := time.Duration(atomic.LoadInt64(&chunkDelay))
+		for _, b := range blob {
+			w.(http.Flusher).Flush()
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+			if _, err := w.Write([]byte{b}); err != nil {
+				return
+			}
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	repo.RequestTimeout = timeout
+	ctx := context.Background()
+
+	checkTimeout := func(t *testing.T, err error) {
+		t.Helper()
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("error = %v, wantErr %v", err, os.ErrDeadlineExceeded)
+		}
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Errorf("error = %v, want a timeout net.Error", err)
+		}
+		if errors.Is(err, context.Canceled) {
+			t.Errorf("error = %v, want not %v", err, context.Canceled)
+		}
+	}
+
+	t.Run("slow response", func(t *testing.T) {
+		setDelays(2*timeout, 0)
+		_, err := repo.Fetch(ctx, blobDesc)
+		checkTimeout(t, err)
+	})
+
+	t.Run("stalled body", func(t *testing.T) {
+		setDelays(0, 2*timeout)
+		rc, err := repo.Fetch(ctx, blobDesc)
+		if err != nil {
+			t.Fatalf("Repository.Fetch() error = %v", err)
+		}
+		defer rc.Close()
+		_, err = io.ReadAll(rc)
+		checkTimeout(t, err)
+	})
+
+	t.Run("slow but steady body", func(t *testing.T) {
+		// the whole body takes longer than the timeout, while each byte
+		// arrives within it
+		setDelays(0, timeout/5)
+		rc, err := repo.Fetch(ctx, blobDesc)
+		if err != nil {
+			t.Fatalf("Repository.Fetch() error = %v", err)
+		}
+		defer rc.Close()
+		got, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("io.ReadAll() error = %v", err)
+		}
+		if string(got) != string(blob) {
+			t.Errorf("Repository.Fetch() = %q, want %q", got, blob)
+		}
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/opencontainers/go-digest"
 	"oras.land/oras-go/v2/registry"
 )
 
@@ -87,6 +88,17 @@ func buildRepositoryBlobUploadURL(plainHTTP bool, ref registry.Reference) string
 	return buildRepositoryBaseURL(plainHTTP, ref) + "/blobs/uploads/"
 }
 
+// buildRepositoryBlobMountURL builds the URL for cross-repository mounting.
+// Format: <scheme>://<registry>/v2/<repository>/blobs/uploads/?mount=<digest>&from=<other_repository>
+// Reference: https://docs.docker.com/registry/spec/api/#blob
+func buildRepositoryBlobMountURL(plainHTTP bool, ref registry.Reference, d digest.Digest, fromRepo string) string {
+	return fmt.Sprintf("%s?mount=%s&from=%s",
+		buildRepositoryBlobUploadURL(plainHTTP, ref),
+		d,
+		fromRepo,
+	)
+}
+
 // buildArtifactReferrerURLLegacy builds the URL for accessing the manifest referrers API in artifact spec v1.0.0-draft.1.
 // Format: <scheme>://<registry>/oras/artifacts/v1/<repository>/manifests/<digest>/referrers?artifactType=<artifactType>
 // Reference: https://github.com/oras-project/artifacts-spec/blob/v1.0.0-draft.1/manifest-referrers-api.md
@@ -131,3 +143,13 @@ func buildArtifactReferrerURL(plainHTTP bool, ref registry.Reference, artifactTy
 func buildDiscoveryURL(plainHTTP bool, ref registry.Reference) string {
 	return buildRepositoryBaseURL(plainHTTP, ref) + "/_oci/ext/discover"
 }
+
+// parseLinkLast returns the `last` query parameter of the link URL, if
+// present.
+func parseLinkLast(link string) string {
+	linkURL, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return linkURL.Query().Get("last")
+}
@@ -16,11 +16,15 @@ limitations under the License.
 package remote
 
 import (
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
 )
 
 // defaultMaxMetadataBytes specifies the default limit on how many response
@@ -31,26 +35,116 @@ var defaultMaxMetadataBytes int64 = 4 * 1024 * 1024 // 4 MiB
 // errNoLink is returned by parseLink() when no Link header is present.
 var errNoLink = errors.New("no Link header in response")
 
-// parseLink returns the URL of the response's "Link" header, if present.
+// parseLink returns the URL of the next link in the response's "Link"
+// headers, if present.
+// The links of relation types other than "next", e.g. "prev", are skipped.
+// Reference: https://www.rfc-editor.org/rfc/rfc5988#section-5
 func parseLink(resp *http.Response) (string, error) {
-	link := resp.Header.Get("Link")
-	if link == "" {
+	headers := resp.Header.Values("Link")
+	if len(headers) == 0 {
 		return "", errNoLink
 	}
-	if link[0] != '<' {
-		return "", fmt.Errorf("invalid next link %q: missing '<'", link)
+	for _, header := range headers {
+		for {
+			header = strings.TrimLeft(header, " \t,")
+			if header == "" {
+				break
+			}
+			if header[0] != '<' {
+				return "", fmt.Errorf("invalid next link %q: missing '<'", header)
+			}
+			i := strings.IndexByte(header, '>')
+			if i == -1 {
+				return "", fmt.Errorf("invalid next link %q: missing '>'", header)
+			}
+			link := header[1:i]
+			header = header[i+1:]
+			params := header
+			if j := strings.IndexByte(header, ','); j != -1 {
+				params, header = header[:j], header[j+1:]
+			} else {
+				header = ""
+			}
+			if !isNextLink(params) {
+				continue
+			}
+
+			linkURL, err := resp.Request.URL.Parse(link)
+			if err != nil {
+				return "", err
+			}
+			return linkURL.String(), nil
+		}
 	}
-	if i := strings.IndexByte(link, '>'); i == -1 {
-		return "", fmt.Errorf("invalid next link %q: missing '>'", link)
-	} else {
-		link = link[1:i]
+	return "", errNoLink
+}
+
+// isNextLink checks if the link parameters have the relation type "next".
+// A link without the relation type is considered as the next link.
+func isNextLink(params string) bool {
+	for _, param := range strings.Split(params, ";") {
+		key, value, found := strings.Cut(param, "=")
+		if !found || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+			continue
+		}
+		for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+			if strings.EqualFold(rel, "next") {
+				return true
+			}
+		}
+		return false
 	}
+	return true
+}
+
+// acceptGzip advertises gzip in the `Accept-Encoding` header of the request,
+// of which the response is decoded by decodeGzip.
+// Setting the header explicitly prevents http.Transport from decompressing
+// the response on its own.
+func acceptGzip(req *http.Request) {
+	req.Header.Set("Accept-Encoding", "gzip")
+}
 
-	linkURL, err := resp.Request.URL.Parse(link)
-	if err != nil {
-		return "", err
+// decodeGzip transparently decompresses the body of the response encoded
+// with gzip. The Content-Length of the decoded response is unknown, and is
+// set to -1.
+func decodeGzip(resp *http.Response) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+	resp.Body = &gzipReadCloser{body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// gzipReadCloser decompresses the gzip body on read.
+// The gzip reader is created lazily on the first read, so that an empty body
+// of an error response can still be closed without error.
+type gzipReadCloser struct {
+	body io.ReadCloser
+	zr   *gzip.Reader
+	err  error
+}
+
+// Read reads the decompressed content.
+func (r *gzipReadCloser) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.zr == nil {
+		r.zr, r.err = gzip.NewReader(r.body)
+		if r.err != nil {
+			return 0, r.err
+		}
 	}
-	return linkURL.String(), nil
+	return r.zr.Read(p)
+}
+
+// Close closes the underlying body.
+func (r *gzipReadCloser) Close() error {
+	return r.body.Close()
 }
 
 // limitReader returns a Reader that reads from r but stops with EOF after n
@@ -61,3 +155,35 @@ func limitReader(r io.Reader, n int64) io.Reader {
 	}
 	return io.LimitReader(r, n)
 }
+
+// verifyReadCloser reads the response body verified against the descriptor.
+// Instead of io.EOF, the verification error is returned on mismatch.
+type verifyReadCloser struct {
+	resp *http.Response
+	vr   *content.VerifyReader
+}
+
+// newVerifyReadCloser wraps the body of resp for reading content verified
+// against desc.
+func newVerifyReadCloser(resp *http.Response, desc ocispec.Descriptor) *verifyReadCloser {
+	return &verifyReadCloser{
+		resp: resp,
+		vr:   content.NewVerifyReader(resp.Body, desc),
+	}
+}
+
+// Read reads up to len(p) bytes into p, and verifies the content on EOF.
+func (r *verifyReadCloser) Read(p []byte) (int, error) {
+	n, err := r.vr.Read(p)
+	if err == io.EOF {
+		if verr := r.vr.Verify(); verr != nil {
+			return n, fmt.Errorf("%s %q: %w", r.resp.Request.Method, r.resp.Request.URL, verr)
+		}
+	}
+	return n, err
+}
+
+// Close closes the response body.
+func (r *verifyReadCloser) Close() error {
+	return r.resp.Body.Close()
+}
@@ -47,6 +47,30 @@ func Test_parseLink(t *testing.T) {
 			header: `<https://localhost:5001/v2/_catalog?last=alpine&n=1>; rel="next"`,
 			want:   "https://localhost:5001/v2/_catalog?last=alpine&n=1",
 		},
+		{
+			name:   "multiple links",
+			url:    "https://localhost:5000/v2/_catalog?last=alpine&n=1",
+			header: `</v2/_catalog?n=1>; rel="prev", </v2/_catalog?last=busybox&n=1>; rel="next"`,
+			want:   "https://localhost:5000/v2/_catalog?last=busybox&n=1",
+		},
+		{
+			name:   "multiple relation types",
+			url:    "https://localhost:5000/v2/_catalog",
+			header: `</v2/_catalog?last=alpine&n=1>; title="page 2"; rel="last next"`,
+			want:   "https://localhost:5000/v2/_catalog?last=alpine&n=1",
+		},
+		{
+			name:   "no relation type",
+			url:    "https://localhost:5000/v2/_catalog",
+			header: `</v2/_catalog?last=alpine&n=1>`,
+			want:   "https://localhost:5000/v2/_catalog?last=alpine&n=1",
+		},
+		{
+			name:    "no next link",
+			url:     "https://localhost:5000/v2/_catalog?last=alpine&n=1",
+			header:  `</v2/_catalog?n=1>; rel="prev"`,
+			wantErr: true,
+		},
 		{
 			name:    "invalid header",
 			url:     "https://localhost:5000/v2/_catalog",
@@ -39,6 +39,7 @@ type Repository interface {
 	content.TagResolver
 	ReferenceFetcher
 	ReferencePusher
+	TagLister
 
 	// Blobs provides access to the blob CAS only, which contains config blobs,
 	// layers, and other generic blobs.
@@ -46,7 +47,51 @@ type Repository interface {
 
 	// Manifests provides access to the manifest CAS only.
 	Manifests() ManifestStore
+}
+
+// BlobStore is a CAS with the ability to stat and delete its content.
+type BlobStore interface {
+	content.Storage
+	content.Deleter
+	content.Resolver
+	ReferenceFetcher
+}
+
+// ManifestStore is a CAS with the ability to stat and delete its content.
+// Besides, ManifestStore provides reference tagging.
+type ManifestStore interface {
+	BlobStore
+	content.Tagger
+	ReferencePusher
+}
 
+// Mounter allows cross-repository blob mounts.
+// For backward compatibility reasons, this is not implemented by BlobStore:
+// use a type assertion to check availability.
+type Mounter interface {
+	// Mount makes the blob with the given descriptor in fromRepo available in
+	// the repository signified by the receiver.
+	// If the mount is not performed by the registry, the blob is uploaded
+	// with the content returned by getContent, or fetched from fromRepo if
+	// getContent is nil.
+	Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) error
+}
+
+// ManifestLister lists all the manifests in a repository, including the
+// untagged ones, which are not discoverable by the tag listing.
+// For backward compatibility reasons, this is not implemented by
+// ManifestStore: use a type assertion to check availability.
+type ManifestLister interface {
+	// Manifests lists the manifests in the repository, and passes them to fn
+	// in batches.
+	// ErrUnsupported is returned if listing is not supported by the backend.
+	Manifests(ctx context.Context, fn func(manifests []ocispec.Descriptor) error) error
+}
+
+// TagLister lists the tags of a repository.
+// It is implemented by Repository, and by the local stores supporting tag
+// listing, such as the OCI layout stores.
+type TagLister interface {
 	// Tags lists the tags available in the repository.
 	// Since the returned tag list may be paginated by the underlying
 	// implementation, a function should be passed in to process the paginated
@@ -65,22 +110,6 @@ type Repository interface {
 	Tags(ctx context.Context, last string, fn func(tags []string) error) error
 }
 
-// BlobStore is a CAS with the ability to stat and delete its content.
-type BlobStore interface {
-	content.Storage
-	content.Deleter
-	content.Resolver
-	ReferenceFetcher
-}
-
-// ManifestStore is a CAS with the ability to stat and delete its content.
-// Besides, ManifestStore provides reference tagging.
-type ManifestStore interface {
-	BlobStore
-	content.Tagger
-	ReferencePusher
-}
-
 // ReferencePusher provides advanced push with the tag service.
 type ReferencePusher interface {
 	// PushReference pushes the manifest with a reference tag.
@@ -95,12 +124,13 @@ type ReferenceFetcher interface {
 
 // ReferrerFinder provides the Referrers API.
 // Reference: https://github.com/oras-project/artifacts-spec/blob/main/manifest-referrers-api.md
+// See also `Referrers()` in this package.
 type ReferrerFinder interface {
 	Referrers(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func(referrers []ocispec.Descriptor) error) error
 }
 
 // Tags lists the tags available in the repository.
-func Tags(ctx context.Context, repo Repository) ([]string, error) {
+func Tags(ctx context.Context, repo TagLister) ([]string, error) {
 	var res []string
 	if err := repo.Tags(ctx, "", func(tags []string) error {
 		res = append(res, tags...)
@@ -110,3 +140,17 @@ func Tags(ctx context.Context, repo Repository) ([]string, error) {
 	}
 	return res, nil
 }
+
+// Referrers lists all the referrers of the given manifest descriptor, with
+// the artifact type if specified.
+// The referrers are returned in the order of the paginated results.
+func Referrers(ctx context.Context, finder ReferrerFinder, desc ocispec.Descriptor, artifactType string) ([]ocispec.Descriptor, error) {
+	var res []ocispec.Descriptor
+	if err := finder.Referrers(ctx, desc, artifactType, func(referrers []ocispec.Descriptor) error {
+		res = append(res, referrers...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	_ "crypto/sha256"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// testReferrerFinder serves the referrers page by page.
+type testReferrerFinder struct {
+	pages [][]ocispec.Descriptor
+	err   error
+}
+
+func (f *testReferrerFinder) Referrers(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func(referrers []ocispec.Descriptor) error) error {
+	for _, page := range f.pages {
+		var filtered []ocispec.Descriptor
+		for _, referrer := range page {
+			if artifactType == "" || referrer.ArtifactType == artifactType {
+				filtered = append(filtered, referrer)
+			}
+		}
+		if err := fn(filtered); err != nil {
+			return err
+		}
+	}
+	return f.err
+}
+
+func TestReferrers(t *testing.T) {
+	generateReferrer := func(content, artifactType string) ocispec.Descriptor {
+		return ocispec.Descriptor{
+			MediaType:    ocispec.MediaTypeArtifactManifest,
+			ArtifactType: artifactType,
+			Digest:       digest.FromString(content),
+			Size:         int64(len(content)),
+		}
+	}
+	referrers := []ocispec.Descriptor{
+		generateReferrer("foo", "application/vnd.test"),
+		generateReferrer("bar", "application/vnd.other"),
+		generateReferrer("hello", "application/vnd.test"),
+	}
+	finder := &testReferrerFinder{
+		pages: [][]ocispec.Descriptor{referrers[:2], referrers[2:]},
+	}
+	subject := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	ctx := context.Background()
+
+	got, err := Referrers(ctx, finder, subject, "")
+	if err != nil {
+		t.Fatalf("Referrers() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, referrers) {
+		t.Errorf("Referrers() = %v, want %v", got, referrers)
+	}
+
+	got, err = Referrers(ctx, finder, subject, "application/vnd.test")
+	if err != nil {
+		t.Fatalf("Referrers() error = %v", err)
+	}
+	if want := []ocispec.Descriptor{referrers[0], referrers[2]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Referrers() = %v, want %v", got, want)
+	}
+
+	errTest := errors.New("test error")
+	finder.err = errTest
+	if _, err := Referrers(ctx, finder, subject, ""); !errors.Is(err, errTest) {
+		t.Errorf("Referrers() error = %v, wantErr %v", err, errTest)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// defaultGraphRetryBackoff is the default value of
+// CopyGraphOptions.GraphRetryBackoff.
+const defaultGraphRetryBackoff = time.Second
+
+// nonRetryableErrors lists the errors that are not resolved by retrying.
+var nonRetryableErrors = []error{
+	context.Canceled,
+	context.DeadlineExceeded,
+	errdef.ErrAlreadyExists,
+	errdef.ErrUnsupported,
+	errdef.ErrInvalidReference,
+	errdef.ErrInvalidDigest,
+	errdef.ErrNotFound,
+	errdef.ErrUnsupportedVersion,
+	errdef.ErrMissingReference,
+	errdef.ErrSizeExceedsLimit,
+	errdef.ErrPreconditionFailed,
+	content.ErrInvalidDescriptorSize,
+	ErrQuotaExceeded,
+}
+
+// isRetryable returns true if the copy failed with err may succeed on retry.
+// Network errors are retryable. Other errors reporting their temporariness,
+// like the unexpected status codes of the remote registries, are retryable
+// only if they are temporary, so that authentication failures are not
+// retried.
+// Other errors are retryable unless they are well-known permanent failures.
+// A *PartialCopyError is retryable if any of its failures is retryable.
+func isRetryable(err error) bool {
+	var partialErr *PartialCopyError
+	if errors.As(err, &partialErr) {
+		for _, copyErr := range partialErr.Errors {
+			if !errors.Is(copyErr, ErrIncompleteSuccessors) && isRetryable(copyErr.Err) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, target := range nonRetryableErrors {
+		if errors.Is(err, target) {
+			return false
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		// network failures, including timeouts and connection resets
+		return true
+	}
+	var tempErr interface{ Temporary() bool }
+	if errors.As(err, &tempErr) {
+		return tempErr.Temporary()
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/ioutil"
+)
+
+// sourceCacheStorage is a read-only storage reading through a cache, which is
+// populated with the content fetched from the underlying storage.
+// The cache is best effort: the failures of populating it are ignored.
+type sourceCacheStorage struct {
+	content.ReadOnlyStorage
+	cache content.Storage
+}
+
+// Fetch fetches the content identified by the descriptor from the cache if
+// present, and from the underlying storage otherwise, caching the content
+// as it is read.
+func (s *sourceCacheStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if rc, err := s.cache.Fetch(ctx, target); err == nil {
+		return rc, nil
+	}
+	rc, err := s.ReadOnlyStorage.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// the content pushed is verified by the cache, and thus partial or
+		// corrupted content is never cached
+		if err := s.cache.Push(ctx, target, pr); err != nil {
+			pr.CloseWithError(err)
+		}
+	}()
+	w := &cacheWriter{w: pw}
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.TeeReader(rc, w),
+		Closer: ioutil.CloserFunc(func() error {
+			err := rc.Close()
+			pw.Close()
+			wg.Wait()
+			return err
+		}),
+	}, nil
+}
+
+// cacheWriter writes to a cache, and discards the writes after the first
+// failure so that the cache never fails the read.
+type cacheWriter struct {
+	w      io.Writer
+	failed bool
+}
+
+// Write writes p to the cache unless a previous write has failed.
+func (w *cacheWriter) Write(p []byte) (int, error) {
+	if !w.failed {
+		if _, err := w.w.Write(p); err != nil {
+			w.failed = true
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// fetchCountingTarget counts the fetches of each content.
+type fetchCountingTarget struct {
+	oras.Target
+	lock    sync.Mutex
+	fetches map[digest.Digest]int
+}
+
+func (t *fetchCountingTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	t.lock.Lock()
+	t.fetches[target.Digest]++
+	t.lock.Unlock()
+	return t.Target.Fetch(ctx, target)
+}
+
+func TestCopy_SourceCache(t *testing.T) {
+	ctx := context.Background()
+	storage := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := storage.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	base := push(ocispec.MediaTypeImageLayer, []byte("base layer"))
+	foo := push(ocispec.MediaTypeImageLayer, []byte("foo"))
+	bar := push(ocispec.MediaTypeImageLayer, []byte("bar"))
+	images := map[string][]ocispec.Descriptor{
+		"foo": {base, foo},
+		"bar": {base, bar},
+	}
+	for ref, layers := range images {
+		desc, err := oras.Pack(ctx, storage, layers, oras.PackOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.Tag(ctx, desc, ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		cacheSize int64
+		wantBase  int
+	}{
+		{
+			name:      "shared layer cached",
+			cacheSize: 1024,
+			wantBase:  1,
+		},
+		{
+			name:      "shared layer exceeding the cache",
+			cacheSize: 4,
+			wantBase:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &fetchCountingTarget{
+				Target:  storage,
+				fetches: make(map[digest.Digest]int),
+			}
+			opts := oras.CopyOptions{}
+			opts.SourceCache = memory.NewLRU(tt.cacheSize)
+			for _, ref := range []string{"foo", "bar"} {
+				dst := memory.New()
+				if _, err := oras.Copy(ctx, src, ref, dst, "", opts); err != nil {
+					t.Fatalf("Copy(%s) error = %v", ref, err)
+				}
+				for _, layer := range images[ref] {
+					if exists, err := dst.Exists(ctx, layer); err != nil || !exists {
+						t.Errorf("Copy(%s): dst.Exists(%s) = %v, %v, want true", ref, layer.Digest, exists, err)
+					}
+				}
+			}
+			if got := src.fetches[base.Digest]; got != tt.wantBase {
+				t.Errorf("count(Fetch(base)) = %v, want %v", got, tt.wantBase)
+			}
+		})
+	}
+}
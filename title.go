@@ -0,0 +1,111 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+// TitleNotFoundError is returned by FetchBlobByTitle when no blob of the
+// manifest is titled as requested. It matches errdef.ErrNotFound.
+type TitleNotFoundError struct {
+	// Reference is the reference of the manifest.
+	Reference string
+	// Title is the title not found.
+	Title string
+	// Available is the list of the titles of the blobs in the manifest.
+	Available []string
+}
+
+// Error returns the error message listing the available titles.
+func (e *TitleNotFoundError) Error() string {
+	return fmt.Sprintf("%s: blob titled %q: %v: available titles: [%s]", e.Reference, e.Title, errdef.ErrNotFound, strings.Join(e.Available, ", "))
+}
+
+// Unwrap returns errdef.ErrNotFound.
+func (e *TitleNotFoundError) Unwrap() error {
+	return errdef.ErrNotFound
+}
+
+// FetchBlobByTitle fetches the blob of the manifest identified by the
+// reference, of which the annotation ocispec.AnnotationTitle equals title,
+// such as a file pushed by name. The layers of image manifests and the blobs
+// of artifact manifests are searched, and the first match is fetched.
+// Returns a *TitleNotFoundError listing the available titles if there is no
+// match, or errdef.ErrUnsupported if the reference does not point to an image
+// manifest or an artifact manifest.
+func FetchBlobByTitle(ctx context.Context, target ReadOnlyTarget, reference string, title string) (ocispec.Descriptor, io.ReadCloser, error) {
+	desc, manifestBytes, err := FetchBytes(ctx, target, reference, DefaultFetchBytesOptions)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	var blobs []ocispec.Descriptor
+	switch desc.MediaType {
+	case docker.MediaTypeManifest, ocispec.MediaTypeImageManifest:
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+			return ocispec.Descriptor{}, nil, fmt.Errorf("%s: %s: failed to decode manifest: %w", desc.Digest, desc.MediaType, err)
+		}
+		blobs = manifest.Layers
+	case ocispec.MediaTypeArtifactManifest:
+		var manifest ocispec.Artifact
+		if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+			return ocispec.Descriptor{}, nil, fmt.Errorf("%s: %s: failed to decode manifest: %w", desc.Digest, desc.MediaType, err)
+		}
+		blobs = manifest.Blobs
+	case artifactspec.MediaTypeArtifactManifest:
+		var manifest artifactspec.Manifest
+		if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+			return ocispec.Descriptor{}, nil, fmt.Errorf("%s: %s: failed to decode manifest: %w", desc.Digest, desc.MediaType, err)
+		}
+		for _, blob := range manifest.Blobs {
+			blobs = append(blobs, descriptor.ArtifactToOCI(blob))
+		}
+	default:
+		return ocispec.Descriptor{}, nil, fmt.Errorf("%s: %s: not an image manifest or an artifact manifest: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+
+	var available []string
+	for _, blob := range blobs {
+		blobTitle, ok := blob.Annotations[ocispec.AnnotationTitle]
+		if !ok {
+			continue
+		}
+		if blobTitle == title {
+			rc, err := target.Fetch(ctx, blob)
+			if err != nil {
+				return ocispec.Descriptor{}, nil, err
+			}
+			return blob, rc, nil
+		}
+		available = append(available, blobTitle)
+	}
+	return ocispec.Descriptor{}, nil, &TitleNotFoundError{
+		Reference: reference,
+		Title:     title,
+		Available: available,
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestFetchBlobByTitle(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	files := map[string][]byte{
+		"config.yaml": []byte("foo: bar"),
+		"README.md":   []byte("# hello"),
+	}
+	var layers []ocispec.Descriptor
+	for _, title := range []string{"config.yaml", "README.md"} {
+		desc := content.NewDescriptorFromBytes("application/vnd.test.file", files[title])
+		desc.Annotations = map[string]string{ocispec.AnnotationTitle: title}
+		if err := s.Push(ctx, desc, bytes.NewReader(files[title])); err != nil {
+			t.Fatal(err)
+		}
+		layers = append(layers, desc)
+	}
+	untitled := content.NewDescriptorFromBytes("application/vnd.test.file", []byte("untitled"))
+	if err := s.Push(ctx, untitled, bytes.NewReader([]byte("untitled"))); err != nil {
+		t.Fatal(err)
+	}
+	layers = append(layers, untitled)
+	manifestDesc, err := oras.Pack(ctx, s, layers, oras.PackOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := "foobar"
+	if err := s.Tag(ctx, manifestDesc, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	for title, want := range files {
+		desc, rc, err := oras.FetchBlobByTitle(ctx, s, ref, title)
+		if err != nil {
+			t.Fatalf("FetchBlobByTitle(%q) error = %v", title, err)
+		}
+		got, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("FetchBlobByTitle(%q) = %s, want %s", title, got, want)
+		}
+		if desc.Annotations[ocispec.AnnotationTitle] != title {
+			t.Errorf("FetchBlobByTitle(%q) descriptor = %v", title, desc)
+		}
+	}
+
+	_, _, err = oras.FetchBlobByTitle(ctx, s, ref, "missing.txt")
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("FetchBlobByTitle() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+	var titleErr *oras.TitleNotFoundError
+	if !errors.As(err, &titleErr) {
+		t.Fatalf("FetchBlobByTitle() error = %v, want %T", err, titleErr)
+	}
+	if want := []string{"config.yaml", "README.md"}; !reflect.DeepEqual(titleErr.Available, want) {
+		t.Errorf("TitleNotFoundError.Available = %v, want %v", titleErr.Available, want)
+	}
+}
+
+func TestFetchBlobByTitle_Unsupported(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	index := []byte(`{"schemaVersion":2,"manifests":[]}`)
+	indexDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, index)
+	if err := s.Push(ctx, indexDesc, bytes.NewReader(index)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Tag(ctx, indexDesc, "index"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := oras.FetchBlobByTitle(ctx, s, "index", "foo"); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("FetchBlobByTitle() error = %v, wantErr %v", err, errdef.ErrUnsupported)
+	}
+}
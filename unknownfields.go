@@ -0,0 +1,138 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// marshalPreservingUnknown marshals v, which is decoded from raw and possibly
+// modified, into JSON where the fields of raw unknown to the type of v, such
+// as the extension fields of manifests and descriptors, are preserved.
+// The nested objects and the lists of objects are handled recursively.
+// The result is identical to json.Marshal(v) if raw has no unknown field.
+func marshalPreservingUnknown(raw []byte, v interface{}) ([]byte, error) {
+	marshaled, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return mergeUnknown(raw, marshaled, reflect.ValueOf(v))
+}
+
+// mergeUnknown merges the fields of raw unknown to the type of v into
+// marshaled, which is the JSON encoding of v.
+func mergeUnknown(raw, marshaled []byte, v reflect.Value) ([]byte, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return marshaled, nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		var rawFields, fields map[string]json.RawMessage
+		if json.Unmarshal(raw, &rawFields) != nil || json.Unmarshal(marshaled, &fields) != nil {
+			return marshaled, nil
+		}
+		known := make(map[string]reflect.Value)
+		jsonFields(v, known)
+		merged := false
+		for name, rawField := range rawFields {
+			field, ok := known[name]
+			if !ok {
+				fields[name] = rawField
+				merged = true
+				continue
+			}
+			marshaledField, ok := fields[name]
+			if !ok {
+				continue
+			}
+			mergedField, err := mergeUnknown(rawField, marshaledField, field)
+			if err != nil {
+				return nil, err
+			}
+			if !bytes.Equal(mergedField, marshaledField) {
+				fields[name] = mergedField
+				merged = true
+			}
+		}
+		if !merged {
+			return marshaled, nil
+		}
+		return json.Marshal(fields)
+	case reflect.Slice, reflect.Array:
+		var rawItems, items []json.RawMessage
+		if json.Unmarshal(raw, &rawItems) != nil || json.Unmarshal(marshaled, &items) != nil ||
+			len(rawItems) != len(items) || len(items) != v.Len() {
+			return marshaled, nil
+		}
+		merged := false
+		for i := range items {
+			mergedItem, err := mergeUnknown(rawItems[i], items[i], v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			if !bytes.Equal(mergedItem, items[i]) {
+				items[i] = mergedItem
+				merged = true
+			}
+		}
+		if !merged {
+			return marshaled, nil
+		}
+		return json.Marshal(items)
+	default:
+		return marshaled, nil
+	}
+}
+
+// jsonFields collects the fields of the struct v by their JSON names,
+// including the fields promoted from the embedded structs.
+func jsonFields(v reflect.Value, fields map[string]reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := v.Field(i)
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				jsonFields(embedded, fields)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = v.Field(i)
+	}
+}